@@ -0,0 +1,54 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ItemError is the failure of a batch operation on a single subscription
+type ItemError struct {
+	SubscriptionID int
+	FriendlyName   string
+	Err            error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("subscription %d (%s): %v", e.SubscriptionID, e.FriendlyName, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// AggregateError collects every per-subscription failure of a batch operation rather than
+// just the first. errors.Is and errors.As search all of the wrapped errors.
+type AggregateError struct {
+	Errors []*ItemError
+}
+
+func (e *AggregateError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d of the batch operations failed: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+func (e *AggregateError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// aggregate returns an *AggregateError of errs, or nil if there are none
+func aggregate(errs []*ItemError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &AggregateError{Errors: errs}
+}
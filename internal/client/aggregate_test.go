@@ -0,0 +1,159 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAggregateError(t *testing.T) {
+	if err := aggregate(nil); err != nil {
+		t.Errorf("aggregate(nil) = %v, want nil", err)
+	}
+
+	notFound := &APIError{StatusCode: http.StatusNotFound, Method: http.MethodGet, Path: "/tags"}
+	err := aggregate([]*ItemError{
+		{SubscriptionID: 1, FriendlyName: "app-prod", Err: context.DeadlineExceeded},
+		{SubscriptionID: 2, FriendlyName: "app-dev", Err: notFound},
+	})
+
+	message := err.Error()
+	for _, want := range []string{"2 of the batch operations failed", "subscription 1 (app-prod)", "subscription 2 (app-dev)"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("error %q doesn't mention %q", message, want)
+		}
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("errors.Is doesn't find the first item's error")
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr != notFound {
+		t.Error("errors.As doesn't find the second item's error")
+	}
+	var itemErr *ItemError
+	if !errors.As(err, &itemErr) || itemErr.SubscriptionID != 1 {
+		t.Errorf("errors.As found item %v, want the first one", itemErr)
+	}
+}
+
+func TestAggregateErrorSearchesEveryItem(t *testing.T) {
+	err := aggregate([]*ItemError{
+		{SubscriptionID: 1, FriendlyName: "app-prod", Err: errors.New("boom")},
+		{SubscriptionID: 2, FriendlyName: "app-dev", Err: errors.New("boom")},
+		{SubscriptionID: 3, FriendlyName: "app-test", Err: fmt.Errorf("tagging: %w", ErrStopped)},
+	})
+
+	if !errors.Is(err, ErrStopped) {
+		t.Error("errors.Is doesn't find an error wrapped by the last item")
+	}
+	if errors.Is(err, context.Canceled) {
+		t.Error("errors.Is finds an error that no item wraps")
+	}
+
+	var aggregateErr *AggregateError
+	if !errors.As(err, &aggregateErr) || len(aggregateErr.Errors) != 3 {
+		t.Fatalf("error %v, want an *AggregateError of all 3 failures", err)
+	}
+	for i, itemErr := range aggregateErr.Errors {
+		if itemErr.SubscriptionID != i+1 {
+			t.Errorf("failure %d is of subscription %d, want the failures in order", i, itemErr.SubscriptionID)
+		}
+	}
+	if !errors.Is(aggregateErr.Errors[2], ErrStopped) || errors.Is(aggregateErr.Errors[0], ErrStopped) {
+		t.Error("ItemError doesn't unwrap to its own error only")
+	}
+}
+
+func TestApplyTagsToAllSubscriptionsCollectsEveryFailure(t *testing.T) {
+	var mu sync.Mutex
+	puts := map[string]string{}
+	c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		base := "/api/v1/azureplans/873834/azuresubscriptions"
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == base:
+			writeJSON(w, http.StatusOK, map[string]interface{}{"Items": testSubscriptions(4), "TotalHits": 4})
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/2/tags"):
+			http.Error(w, `{"Message": "not found"}`, http.StatusNotFound)
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/3/tags"):
+			writeJSON(w, http.StatusOK, map[string]string{"Owner": "data"})
+		case r.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, map[string]string{})
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/4/tags"):
+			http.Error(w, `{"Message": "forbidden"}`, http.StatusForbidden)
+		case r.Method == http.MethodPut:
+			mu.Lock()
+			puts[r.URL.Path] = r.Method
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	results, err := c.ApplyTagsToAllSubscriptions(context.Background(), testPlanID, map[string]string{"owner": "data"})
+
+	var aggregateErr *AggregateError
+	if !errors.As(err, &aggregateErr) {
+		t.Fatalf("error %v, want an *AggregateError", err)
+	}
+	var failed []int
+	for _, itemErr := range aggregateErr.Errors {
+		failed = append(failed, itemErr.SubscriptionID)
+	}
+	if len(failed) != 2 || failed[0] != 2 || failed[1] != 4 {
+		t.Errorf("failed subscriptions %v, want 2 and 4", failed)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Error("errors.As doesn't find the API errors")
+	}
+
+	if len(results) != 4 || results[0].Err != nil || !results[2].Skipped || results[1].Err == nil || results[3].Err == nil {
+		t.Errorf("results = %+v, want 1 tagged, 2 failed, 3 skipped and 4 failed", results)
+	}
+	if len(puts) != 1 {
+		t.Errorf("tagged %v, want only subscription 1", puts)
+	}
+}
+
+func TestApplyTagsToAllSubscriptionsStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var once sync.Once
+	c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/azureplans/873834/azuresubscriptions" {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"Items": testSubscriptions(20), "TotalHits": 20})
+			return
+		}
+		// Cancel as soon as the first subscription is being tagged
+		once.Do(cancel)
+		writeJSON(w, http.StatusOK, map[string]string{})
+	})
+
+	results, err := c.ApplyTagsToAllSubscriptions(ctx, testPlanID, map[string]string{"owner": "data"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error %v, want context.Canceled", err)
+	}
+	if len(results) != 20 {
+		t.Fatalf("%d results, want one per subscription", len(results))
+	}
+	notStarted := 0
+	for _, result := range results {
+		if result.Err == nil || result.SubscriptionID == 0 {
+			t.Errorf("result %+v, want the subscription reported as not tagged", result)
+		}
+		if result.Err != nil && strings.HasPrefix(result.Err.Error(), "not tagged") {
+			notStarted++
+		}
+	}
+	if started := len(results) - notStarted; started > defaultConcurrency+1 {
+		t.Errorf("started tagging %d subscriptions, want no more started after the cancellation", started)
+	}
+}
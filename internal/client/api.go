@@ -0,0 +1,107 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// Ensure Client satisfies SubscriptionAPI and SubscriptionSettingsSource.
+var _ SubscriptionAPI = &Client{}
+var _ SubscriptionSettingsSource = &Client{}
+
+// SubscriptionAPI is the part of the client the crayon_azure_subscription resource calls. Client
+// implements it against the Crayon API; fakes can implement it to drive the resource without HTTP.
+// The provider configuration the resource reads is passed separately, as SubscriptionSettings.
+type SubscriptionAPI interface {
+	// Subscription lifecycle
+	CreateAzureSubscriptionWithRequest(ctx context.Context, azurePlanID int, reqBody CreateAzureSubscriptionRequest) (*AzureSubscription, error)
+	CreateAzureSubscriptionDryRun(ctx context.Context, azurePlanID int, reqBody CreateAzureSubscriptionRequest) error
+	GetAzureSubscription(ctx context.Context, azurePlanID, subscriptionID int, expand ...string) (*AzureSubscription, error)
+	FindAzureSubscriptionByName(ctx context.Context, azurePlanID int, name string) (*AzureSubscription, error)
+	FindAzureSubscriptionsByName(ctx context.Context, azurePlanID int, name string) ([]AzureSubscription, error)
+	RenameAzureSubscription(ctx context.Context, azurePlanID, subscriptionID int, newName string) (*AzureSubscription, error)
+	CancelAzureSubscription(ctx context.Context, azurePlanID, subscriptionID int, effectiveDate time.Time) error
+	EnableAzureSubscription(ctx context.Context, azurePlanID, subscriptionID int) error
+	SuspendAzureSubscription(ctx context.Context, azurePlanID, subscriptionID int) error
+	ReactivateAzureSubscription(ctx context.Context, azurePlanID, subscriptionID int) error
+	WaitForSubscriptionStatus(ctx context.Context, azurePlanID, subscriptionID int, statuses []string, timeout time.Duration) (*AzureSubscription, error)
+	ReserveSubscriptionName(ctx context.Context, azurePlanID int, name string) error
+	ReleaseSubscriptionName(azurePlanID int, name string)
+
+	// Subscription settings
+	GetAzureSubscriptionTags(ctx context.Context, azurePlanID, subscriptionID int) (map[string]string, error)
+	SetAzureSubscriptionTag(ctx context.Context, azurePlanID, subscriptionID int, key, value string) error
+	UpdateAzureSubscriptionTags(ctx context.Context, azurePlanID, subscriptionID int, set map[string]string, remove []string) error
+	SetAzureSubscriptionQuantity(ctx context.Context, azurePlanID, subscriptionID, quantity int) error
+	SetAzureSubscriptionAutoRenew(ctx context.Context, azurePlanID, subscriptionID int, enabled bool) error
+	SetAzureSubscriptionPartnerOfRecord(ctx context.Context, azurePlanID, subscriptionID int, partnerID string) error
+
+	// Pending creates and approvals
+	GetSubscriptionOperationProgress(ctx context.Context, operationLocation string) (progress int, ok bool)
+	GetApprovalRequest(ctx context.Context, azurePlanID, requestID int) (*ApprovalRequest, error)
+	WithdrawApprovalRequest(ctx context.Context, azurePlanID, requestID int) error
+	TriggerCloudIQSync(ctx context.Context, azurePlanID int) error
+	GetARMSubscription(ctx context.Context, subscriptionID string) (*ARMSubscription, error)
+	NotifySubscriptionResolved(ctx context.Context, webhookURL string, sub *AzureSubscription) error
+	VerifyAzurePlanOrganization(ctx context.Context, azurePlanID int) error
+	CheckARMConfirmation(ctx context.Context) error
+}
+
+// SubscriptionSettings is the provider configuration the crayon_azure_subscription resource reads,
+// with defaults applied
+type SubscriptionSettings struct {
+	OrganizationID               int64
+	DefaultTags                  map[string]string
+	AllowedProjects              []string
+	SyncReadRetries              int
+	SyncReadInterval             time.Duration
+	SyncRecoveryTimeout          time.Duration // 0 disables the sync recovery
+	HasAzureCredentials          bool
+	ARMUnavailablePolicy         string
+	SkipOrganizationCheck        bool
+	SuppressPendingWarnings      bool
+	ProtectImportedSubscriptions bool
+	CleanupFailedCreates         bool
+
+	// PortalURL returns the Cloud-iQ portal link of a subscription
+	PortalURL func(azurePlanID, subscriptionID int) string
+
+	// StopContext is cancelled when the provider is asked to stop
+	StopContext context.Context
+}
+
+// SubscriptionSettingsSource is implemented by SubscriptionAPI backends that carry the provider
+// configuration, like Client. The resource uses DefaultSubscriptionSettings for other backends.
+type SubscriptionSettingsSource interface {
+	SubscriptionSettings() SubscriptionSettings
+}
+
+// DefaultSubscriptionSettings returns the settings of a provider configured with defaults only
+func DefaultSubscriptionSettings() SubscriptionSettings {
+	// An unconfigured Client applies the same defaults as a configured one
+	c := &Client{config: ClientConfig{StopContext: context.Background()}}
+	return c.SubscriptionSettings()
+}
+
+// SubscriptionSettings returns the configuration the crayon_azure_subscription resource reads
+func (c *Client) SubscriptionSettings() SubscriptionSettings {
+	return SubscriptionSettings{
+		OrganizationID:               c.GetOrganizationID(),
+		DefaultTags:                  c.GetDefaultTags(),
+		AllowedProjects:              c.AllowedProjects(),
+		SyncReadRetries:              c.GetSyncReadRetries(),
+		SyncReadInterval:             c.GetSyncReadInterval(),
+		SyncRecoveryTimeout:          c.GetSyncRecoveryTimeout(),
+		HasAzureCredentials:          c.HasAzureCredentials(),
+		ARMUnavailablePolicy:         c.ARMUnavailablePolicy(),
+		SkipOrganizationCheck:        c.SkipOrganizationCheck(),
+		SuppressPendingWarnings:      c.SuppressPendingWarnings(),
+		ProtectImportedSubscriptions: c.ProtectImportedSubscriptions(),
+		CleanupFailedCreates:         c.CleanupFailedCreates(),
+		PortalURL:                    c.SubscriptionPortalURL,
+		StopContext:                  c.StopContext(),
+	}
+}
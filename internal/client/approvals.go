@@ -0,0 +1,86 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// StatusPendingApproval is the status of a subscription whose create request awaits approval
+const StatusPendingApproval = "pending_approval"
+
+// Approval states of a subscription create request, normalized from the values Cloud-iQ reports
+const (
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved"
+	ApprovalRejected = "rejected"
+)
+
+// ApprovalRequest is a subscription create request in an organization's approval queue
+type ApprovalRequest struct {
+	ID     int    `json:"Id"`
+	Status string `json:"Status"`
+
+	// Reason is the approver's comment, if any
+	Reason string `json:"Reason,omitempty"`
+
+	// AzureSubscriptionID is the Crayon ID of the subscription created after approval, if reported
+	AzureSubscriptionID int `json:"AzureSubscriptionId,omitempty"`
+}
+
+// State returns the normalized approval state, or "" if Cloud-iQ reports an unknown status
+func (a *ApprovalRequest) State() string {
+	return approvalState(a.Status)
+}
+
+// AwaitingApproval reports whether a create request entered the approval queue rather than
+// creating the subscription
+func (s *AzureSubscription) AwaitingApproval() bool {
+	return s.ApprovalRequestID != 0 && approvalState(s.ApprovalStatus) == ApprovalPending
+}
+
+// approvalState normalizes an approval status reported by Cloud-iQ
+func approvalState(status string) string {
+	switch strings.ToLower(status) {
+	case "pending", "pendingapproval", "awaitingapproval", "submitted":
+		return ApprovalPending
+	case "approved", "completed":
+		return ApprovalApproved
+	case "rejected", "declined", "denied":
+		return ApprovalRejected
+	}
+	return ""
+}
+
+// GetApprovalRequest reads an approval request for a subscription create
+func (c *Client) GetApprovalRequest(ctx context.Context, azurePlanID, requestID int) (*ApprovalRequest, error) {
+	path := fmt.Sprintf("/api/v1/azureplans/%d/approvalrequests/%d", azurePlanID, requestID)
+
+	approval, _, err := request[ApprovalRequest](ctx, c, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval request %d: %w", requestID, err)
+	}
+
+	return approval, nil
+}
+
+// WithdrawApprovalRequest withdraws a create request that has not been approved yet
+func (c *Client) WithdrawApprovalRequest(ctx context.Context, azurePlanID, requestID int) error {
+	path := fmt.Sprintf("/api/v1/azureplans/%d/approvalrequests/%d/withdraw", azurePlanID, requestID)
+
+	if _, err := c.requestNoContent(ctx, http.MethodPost, path, nil); err != nil {
+		return fmt.Errorf("failed to withdraw approval request %d: %w", requestID, err)
+	}
+
+	tflog.Info(ctx, "Withdrew approval request", map[string]interface{}{
+		"azure_plan_id":       azurePlanID,
+		"approval_request_id": requestID,
+	})
+	return nil
+}
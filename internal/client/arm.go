@@ -0,0 +1,90 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Policies for creates whose confirmation via Azure ARM can't happen because no Azure credentials are available
+const (
+	// ARMUnavailableWarn warns and leaves the subscription pending until Cloud-iQ syncs (default)
+	ARMUnavailableWarn = "warn"
+	// ARMUnavailableError fails the create before anything is requested
+	ARMUnavailableError = "error"
+)
+
+// ErrARMUnavailable is returned when creates should be confirmed via Azure ARM, but no Azure
+// Service Principal, managed identity or Azure CLI session is available
+var ErrARMUnavailable = errors.New("ARM confirmation requested but no Azure credentials available")
+
+// ARMUnavailablePolicy returns what to do when creates can't be confirmed via Azure ARM, one of
+// the ARMUnavailable constants
+func (c *Client) ARMUnavailablePolicy() string {
+	if c.config.ARMUnavailablePolicy != "" {
+		return c.config.ARMUnavailablePolicy
+	}
+	return ARMUnavailableWarn
+}
+
+// CheckARMConfirmation reports whether asynchronous creates can be confirmed via Azure ARM, by
+// obtaining an ARM token from the Service Principal, the managed identity or the Azure CLI session. It returns an error
+// wrapping ErrARMUnavailable if not.
+func (c *Client) CheckARMConfirmation(ctx context.Context) error {
+	// Mock mode creates synchronously, so there is nothing to confirm
+	if c.config.MockMode {
+		return nil
+	}
+	if _, err := c.getAzureToken(ctx); err != nil {
+		return fmt.Errorf("%w: %w", ErrARMUnavailable, err)
+	}
+	return nil
+}
+
+// ErrARMPermissionDenied is returned when the Azure identity can't read a subscription via ARM
+var ErrARMPermissionDenied = errors.New("azure identity is not authorized to read the subscription")
+
+// armGet performs an ARM GET request for a subscription's resources using the Azure credentials
+// configured for polling, and returns the response body. what names the resource in errors.
+func (c *Client) armGet(ctx context.Context, armURL, subscriptionID, what string) ([]byte, error) {
+	token, err := c.getAzureToken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("azure auth failed: %w", err)
+	}
+
+	ctx, cancel := c.withStop(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", armURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("azure %s request failed: %w", what, err)
+	}
+	defer resp.Body.Close()
+
+	// ARM responses are mostly pages of lists
+	body, err := readResponseBody(resp.Body, c.maxListResponseSize())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read azure %s response: %w", what, err)
+	}
+
+	// ARM answers 403 for subscriptions the identity has no role on, and 404 when it can't see them at all
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w %s (status %d): %s", ErrARMPermissionDenied, subscriptionID, resp.StatusCode, string(body))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("azure %s request failed (status %d): %s", what, resp.StatusCode, string(body))
+	}
+
+	return body, nil
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// armCostExportsAPIVersion is the ARM API version used for Cost Management exports
+const armCostExportsAPIVersion = "2023-08-01"
+
+// ARMCostExportSchedule is when a Cost Management export runs
+type ARMCostExportSchedule struct {
+	// Status is Active or Inactive
+	Status     string `json:"status"`
+	Recurrence string `json:"recurrence"`
+}
+
+// ARMCostExportDestination is the storage account container an export writes to
+type ARMCostExportDestination struct {
+	ResourceID     string `json:"resourceId"`
+	Container      string `json:"container"`
+	RootFolderPath string `json:"rootFolderPath"`
+}
+
+// ARMCostExportDeliveryInfo describes where an export is delivered
+type ARMCostExportDeliveryInfo struct {
+	Destination ARMCostExportDestination `json:"destination"`
+}
+
+// ARMCostExportDefinition describes what an export contains
+type ARMCostExportDefinition struct {
+	// Type is e.g. ActualCost, AmortizedCost or Usage
+	Type      string `json:"type"`
+	Timeframe string `json:"timeframe"`
+}
+
+// ARMCostExportProperties are the properties of a Cost Management export
+type ARMCostExportProperties struct {
+	Schedule     ARMCostExportSchedule     `json:"schedule"`
+	Format       string                    `json:"format"`
+	DeliveryInfo ARMCostExportDeliveryInfo `json:"deliveryInfo"`
+	Definition   ARMCostExportDefinition   `json:"definition"`
+}
+
+// ARMCostExport is a Cost Management export configured on a subscription
+type ARMCostExport struct {
+	ID         string                  `json:"id"`
+	Name       string                  `json:"name"`
+	Properties ARMCostExportProperties `json:"properties"`
+}
+
+// ARMCostExportList is the response of the ARM Cost Management exports endpoint
+type ARMCostExportList struct {
+	Value []ARMCostExport `json:"value"`
+}
+
+// ListSubscriptionCostExports lists the Cost Management exports configured on an Azure
+// subscription. The Azure identity configured for polling needs
+// Microsoft.CostManagement/exports/read on the subscription (e.g. Cost Management Reader).
+func (c *Client) ListSubscriptionCostExports(ctx context.Context, subscriptionID string) ([]ARMCostExport, error) {
+	armURL := c.armURL(fmt.Sprintf("/subscriptions/%s/providers/Microsoft.CostManagement/exports?api-version=%s",
+		url.PathEscape(subscriptionID), armCostExportsAPIVersion))
+
+	body, err := c.armGet(ctx, armURL, subscriptionID, "cost management exports")
+	if err != nil {
+		return nil, err
+	}
+
+	var exports ARMCostExportList
+	if err := json.Unmarshal(body, &exports); err != nil {
+		return nil, fmt.Errorf("failed to parse azure cost management exports response: %w", err)
+	}
+
+	return exports.Value, nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// testCostExports is a Cost Management exports response as ARM returns it
+const testCostExports = `{"value": [{
+	"id": "/subscriptions/guid-1/providers/Microsoft.CostManagement/exports/monthly",
+	"name": "monthly",
+	"type": "Microsoft.CostManagement/exports",
+	"properties": {
+		"schedule": {"status": "Active", "recurrence": "Monthly", "recurrencePeriod": {"from": "2024-01-01T00:00:00Z"}},
+		"format": "Csv",
+		"deliveryInfo": {"destination": {
+			"resourceId": "/subscriptions/guid-1/resourceGroups/finance/providers/Microsoft.Storage/storageAccounts/costs",
+			"container": "exports",
+			"rootFolderPath": "app-prod"
+		}},
+		"definition": {"type": "ActualCost", "timeframe": "MonthToDate", "dataSet": {"granularity": "Daily"}}
+	}
+}]}`
+
+func TestListSubscriptionCostExports(t *testing.T) {
+	c := newARMTestClient(t, func(req *http.Request) *http.Response {
+		wantPath := "/subscriptions/guid-1/providers/Microsoft.CostManagement/exports"
+		if req.Host != "management.azure.com" || req.URL.Path != wantPath {
+			t.Errorf("requested %s%s, want management.azure.com%s", req.Host, req.URL.Path, wantPath)
+		}
+		if got := req.URL.Query().Get("api-version"); got != armCostExportsAPIVersion {
+			t.Errorf("api-version = %q, want %s", got, armCostExportsAPIVersion)
+		}
+		return jsonResponse(http.StatusOK, json.RawMessage(testCostExports))
+	})
+
+	got, err := c.ListSubscriptionCostExports(context.Background(), "guid-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ARMCostExport{{
+		ID:   "/subscriptions/guid-1/providers/Microsoft.CostManagement/exports/monthly",
+		Name: "monthly",
+		Properties: ARMCostExportProperties{
+			Schedule: ARMCostExportSchedule{Status: "Active", Recurrence: "Monthly"},
+			Format:   "Csv",
+			DeliveryInfo: ARMCostExportDeliveryInfo{Destination: ARMCostExportDestination{
+				ResourceID:     "/subscriptions/guid-1/resourceGroups/finance/providers/Microsoft.Storage/storageAccounts/costs",
+				Container:      "exports",
+				RootFolderPath: "app-prod",
+			}},
+			Definition: ARMCostExportDefinition{Type: "ActualCost", Timeframe: "MonthToDate"},
+		},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestListSubscriptionCostExportsNone(t *testing.T) {
+	c := newARMTestClient(t, func(req *http.Request) *http.Response {
+		return jsonResponse(http.StatusOK, json.RawMessage(`{"value": []}`))
+	})
+
+	got, err := c.ListSubscriptionCostExports(context.Background(), "guid-1")
+	if err != nil || len(got) != 0 {
+		t.Errorf("got %v, %v, want no exports", got, err)
+	}
+}
+
+func TestListSubscriptionCostExportsErrors(t *testing.T) {
+	tests := map[string]struct {
+		status         int
+		body           string
+		wantPermission bool
+	}{
+		"no cost management role": {status: http.StatusForbidden, body: `{"error": {"code": "AuthorizationFailed"}}`, wantPermission: true},
+		"server error":            {status: http.StatusInternalServerError, body: `{"error": {"code": "InternalError"}}`},
+		"malformed response":      {status: http.StatusOK, body: `{"value": {}}`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newARMTestClient(t, func(req *http.Request) *http.Response {
+				return jsonResponse(test.status, json.RawMessage(test.body))
+			})
+
+			_, err := c.ListSubscriptionCostExports(context.Background(), "guid-1")
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := errors.Is(err, ErrARMPermissionDenied); got != test.wantPermission {
+				t.Errorf("err = %v, want a permission error: %v", err, test.wantPermission)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// armRoleAssignmentsAPIVersion is the ARM API version used for role assignments
+const armRoleAssignmentsAPIVersion = "2022-04-01"
+
+// ARMRoleAssignmentProperties are the properties of an ARM role assignment
+type ARMRoleAssignmentProperties struct {
+	RoleDefinitionID string `json:"roleDefinitionId"`
+	PrincipalID      string `json:"principalId"`
+	PrincipalType    string `json:"principalType"`
+	Scope            string `json:"scope"`
+}
+
+// ARMRoleAssignment grants a principal a role on a scope within a subscription
+type ARMRoleAssignment struct {
+	ID         string                      `json:"id"`
+	Name       string                      `json:"name"`
+	Properties ARMRoleAssignmentProperties `json:"properties"`
+}
+
+// ARMRoleAssignmentList is a page of the ARM role assignments endpoint
+type ARMRoleAssignmentList struct {
+	Value    []ARMRoleAssignment `json:"value"`
+	NextLink string              `json:"nextLink"`
+}
+
+// ListSubscriptionRoleAssignments lists the role assignments on an Azure subscription and the
+// scopes below it, following ARM's nextLink pagination. The Azure identity configured for polling
+// needs Microsoft.Authorization/roleAssignments/read on the subscription (e.g. the Reader role).
+func (c *Client) ListSubscriptionRoleAssignments(ctx context.Context, subscriptionID string) ([]ARMRoleAssignment, error) {
+	nextURL := c.armURL(fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Authorization/roleAssignments?api-version=%s",
+		url.PathEscape(subscriptionID), armRoleAssignmentsAPIVersion))
+
+	var assignments []ARMRoleAssignment
+	for nextURL != "" {
+		body, err := c.armGet(ctx, nextURL, subscriptionID, "role assignments")
+		if err != nil {
+			return nil, err
+		}
+
+		var page ARMRoleAssignmentList
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse azure role assignments response: %w", err)
+		}
+		assignments = append(assignments, page.Value...)
+
+		// Only follow links back to ARM, so the Azure token is never sent elsewhere
+		if page.NextLink != "" && !strings.HasPrefix(page.NextLink, c.armURL("/")) {
+			return nil, fmt.Errorf("unexpected azure role assignments next link: %s", page.NextLink)
+		}
+		nextURL = page.NextLink
+	}
+
+	return assignments, nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestListSubscriptionRoleAssignments(t *testing.T) {
+	owner := ARMRoleAssignment{ID: "ra-1", Name: "ra-1", Properties: ARMRoleAssignmentProperties{
+		RoleDefinitionID: "/providers/Microsoft.Authorization/roleDefinitions/owner",
+		PrincipalID:      "user-1",
+		PrincipalType:    "User",
+		Scope:            "/subscriptions/guid-1",
+	}}
+	reader := ARMRoleAssignment{ID: "ra-2", Name: "ra-2", Properties: ARMRoleAssignmentProperties{
+		RoleDefinitionID: "/providers/Microsoft.Authorization/roleDefinitions/reader",
+		PrincipalID:      "sp-1",
+		PrincipalType:    "ServicePrincipal",
+		Scope:            "/subscriptions/guid-1/resourceGroups/app",
+	}}
+	nextLink := "https://management.azure.com/subscriptions/guid-1/providers/Microsoft.Authorization/roleAssignments?api-version=2022-04-01&$skiptoken=page2"
+
+	var pages []string
+	c := newARMTestClient(t, func(req *http.Request) *http.Response {
+		wantPath := "/subscriptions/guid-1/providers/Microsoft.Authorization/roleAssignments"
+		if req.Host != "management.azure.com" || req.URL.Path != wantPath {
+			t.Errorf("requested %s%s, want management.azure.com%s", req.Host, req.URL.Path, wantPath)
+		}
+		if got := req.URL.Query().Get("api-version"); got != armRoleAssignmentsAPIVersion {
+			t.Errorf("api-version = %q, want %s", got, armRoleAssignmentsAPIVersion)
+		}
+		skipToken := req.URL.Query().Get("$skiptoken")
+		pages = append(pages, skipToken)
+		if skipToken == "" {
+			return jsonResponse(http.StatusOK, ARMRoleAssignmentList{Value: []ARMRoleAssignment{owner}, NextLink: nextLink})
+		}
+		return jsonResponse(http.StatusOK, ARMRoleAssignmentList{Value: []ARMRoleAssignment{reader}})
+	})
+
+	got, err := c.ListSubscriptionRoleAssignments(context.Background(), "guid-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []ARMRoleAssignment{owner, reader}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if want := []string{"", "page2"}; !reflect.DeepEqual(pages, want) {
+		t.Errorf("requested pages %q, want %q", pages, want)
+	}
+}
+
+func TestListSubscriptionRoleAssignmentsRejectsForeignNextLink(t *testing.T) {
+	requests := 0
+	c := newARMTestClient(t, func(req *http.Request) *http.Response {
+		requests++
+		return jsonResponse(http.StatusOK, ARMRoleAssignmentList{NextLink: "https://attacker.test/roleAssignments"})
+	})
+
+	_, err := c.ListSubscriptionRoleAssignments(context.Background(), "guid-1")
+	if err == nil || !strings.Contains(err.Error(), "unexpected azure role assignments next link") {
+		t.Errorf("err = %v, want the next link rejected", err)
+	}
+	if requests != 1 {
+		t.Errorf("made %d ARM requests, want the next link not followed", requests)
+	}
+}
+
+func TestListSubscriptionRoleAssignmentsPermissionDenied(t *testing.T) {
+	c := newARMTestClient(t, func(req *http.Request) *http.Response {
+		return jsonResponse(http.StatusForbidden, map[string]interface{}{"error": map[string]string{"code": "AuthorizationFailed"}})
+	})
+
+	_, err := c.ListSubscriptionRoleAssignments(context.Background(), "guid-1")
+	if !errors.Is(err, ErrARMPermissionDenied) {
+		t.Errorf("err = %v, want ErrARMPermissionDenied", err)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// armSubscriptionAPIVersion is the ARM API version used to read a subscription
+const armSubscriptionAPIVersion = "2022-12-01"
+
+// ARM spending limit values reported in the subscription policies
+const (
+	ARMSpendingLimitOn               = "On"
+	ARMSpendingLimitOff              = "Off"
+	ARMSpendingLimitCurrentPeriodOff = "CurrentPeriodOff"
+)
+
+// DefaultARMTargetState is the ARM state a created subscription must reach before it counts as
+// confirmed when ClientConfig.ARMTargetState is unset
+const DefaultARMTargetState = "Enabled"
+
+// ARMTargetStateAny confirms a created subscription as soon as it appears in ARM, in any state
+const ARMTargetStateAny = "any"
+
+// ARMSubscriptionStates are the states ARM reports for subscriptions
+var ARMSubscriptionStates = []string{"Enabled", "Warned", "PastDue", "Disabled", "Deleted"}
+
+// ARMSubscriptionPolicies are the offer policies of an Azure subscription
+type ARMSubscriptionPolicies struct {
+	QuotaID string `json:"quotaId"`
+
+	// SpendingLimit is empty for offers that have no spending limit at all
+	SpendingLimit string `json:"spendingLimit"`
+}
+
+// ARMSubscription is an Azure subscription as reported by ARM
+type ARMSubscription struct {
+	SubscriptionID       string                  `json:"subscriptionId"`
+	DisplayName          string                  `json:"displayName"`
+	State                string                  `json:"state"`
+	SubscriptionPolicies ARMSubscriptionPolicies `json:"subscriptionPolicies"`
+}
+
+// SpendingCap reports whether the subscription's spending limit is enabled and whether it has
+// been reached, which disables the subscription until the next billing period. applicable is
+// false if the subscription's offer has no spending limit.
+func (s *ARMSubscription) SpendingCap() (enabled, reached, applicable bool) {
+	switch s.SubscriptionPolicies.SpendingLimit {
+	case ARMSpendingLimitOn:
+		return true, s.State == "Disabled", true
+	case ARMSpendingLimitOff, ARMSpendingLimitCurrentPeriodOff:
+		return false, false, true
+	}
+	return false, false, false
+}
+
+// GetARMSubscription reads an Azure subscription directly from ARM, using the Azure credentials
+// configured for polling
+func (c *Client) GetARMSubscription(ctx context.Context, subscriptionID string) (*ARMSubscription, error) {
+	subscriptionURL := c.armURL(fmt.Sprintf("/subscriptions/%s?api-version=%s",
+		url.PathEscape(subscriptionID), armSubscriptionAPIVersion))
+
+	body, err := c.armGet(ctx, subscriptionURL, subscriptionID, "subscription")
+	if err != nil {
+		return nil, err
+	}
+
+	var subscription ARMSubscription
+	if err := json.Unmarshal(body, &subscription); err != nil {
+		return nil, fmt.Errorf("failed to parse azure subscription response: %w", err)
+	}
+
+	return &subscription, nil
+}
+
+// armTargetState returns the ARM state a created subscription must reach to be confirmed
+func (c *Client) armTargetState() string {
+	if c.config.ARMTargetState != "" {
+		return c.config.ARMTargetState
+	}
+	return DefaultARMTargetState
+}
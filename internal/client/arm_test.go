@@ -0,0 +1,76 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCheckARMConfirmation(t *testing.T) {
+	t.Run("service principal", func(t *testing.T) {
+		c := newTokenTestClient(t, func(req *http.Request) *http.Response {
+			return jsonResponse(http.StatusOK, AzureTokenResponse{AccessToken: "azure-token", ExpiresIn: 3600})
+		})
+		if err := c.CheckARMConfirmation(context.Background()); err != nil {
+			t.Errorf("err = %v, want ARM confirmation available", err)
+		}
+	})
+
+	t.Run("service principal rejected", func(t *testing.T) {
+		c := newTokenTestClient(t, func(req *http.Request) *http.Response {
+			return jsonResponse(http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		})
+		if err := c.CheckARMConfirmation(context.Background()); !errors.Is(err, ErrARMUnavailable) {
+			t.Errorf("err = %v, want %v", err, ErrARMUnavailable)
+		}
+	})
+
+	t.Run("no credentials", func(t *testing.T) {
+		// Without a Service Principal the Azure CLI is tried, so make sure there is none
+		t.Setenv("PATH", t.TempDir())
+		c, err := NewClient(ClientConfig{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.CheckARMConfirmation(context.Background()); !errors.Is(err, ErrARMUnavailable) {
+			t.Errorf("err = %v, want %v", err, ErrARMUnavailable)
+		}
+
+		// Polling ARM fails the same way, so callers can tell the cause
+		if _, err := c.WaitForAzureSubscription(context.Background(), "app-prod", time.Minute); !errors.Is(err, ErrARMUnavailable) {
+			t.Errorf("wait err = %v, want %v", err, ErrARMUnavailable)
+		}
+	})
+
+	t.Run("mock mode", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+		c, err := NewClient(ClientConfig{MockMode: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.CheckARMConfirmation(context.Background()); err != nil {
+			t.Errorf("err = %v, want nothing to confirm in mock mode", err)
+		}
+	})
+}
+
+func TestARMUnavailablePolicy(t *testing.T) {
+	for configured, want := range map[string]string{
+		"":                  ARMUnavailableWarn,
+		ARMUnavailableWarn:  ARMUnavailableWarn,
+		ARMUnavailableError: ARMUnavailableError,
+	} {
+		c, err := NewClient(ClientConfig{ARMUnavailablePolicy: configured})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := c.ARMUnavailablePolicy(); got != want {
+			t.Errorf("ARMUnavailablePolicy() with %q configured = %q, want %q", configured, got, want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// armComputeUsagesAPIVersion is the ARM API version used for compute quota usages
+const armComputeUsagesAPIVersion = "2023-07-01"
+
+// ARMUsageName is the name of an ARM quota
+type ARMUsageName struct {
+	Value          string `json:"value"`
+	LocalizedValue string `json:"localizedValue"`
+}
+
+// ARMUsage is the current usage and limit of a single ARM quota
+type ARMUsage struct {
+	Name         ARMUsageName `json:"name"`
+	CurrentValue int64        `json:"currentValue"`
+	Limit        int64        `json:"limit"`
+	Unit         string       `json:"unit"`
+}
+
+// ARMUsageList is the response of the ARM usages endpoint
+type ARMUsageList struct {
+	Value []ARMUsage `json:"value"`
+}
+
+// GetARMSubscriptionUsage lists the compute quota usages (e.g. vCPU limits) of an Azure
+// subscription in a region, using the Azure credentials configured for polling
+func (c *Client) GetARMSubscriptionUsage(ctx context.Context, subscriptionID, location string) ([]ARMUsage, error) {
+	usagesURL := c.armURL(fmt.Sprintf("/subscriptions/%s/providers/Microsoft.Compute/locations/%s/usages?api-version=%s",
+		url.PathEscape(subscriptionID), url.PathEscape(location), armComputeUsagesAPIVersion))
+
+	body, err := c.armGet(ctx, usagesURL, subscriptionID, "usages")
+	if err != nil {
+		return nil, err
+	}
+
+	var list ARMUsageList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse azure usages response: %w", err)
+	}
+
+	return list.Value, nil
+}
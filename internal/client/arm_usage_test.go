@@ -0,0 +1,78 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// newARMTestClient returns a client with Azure credentials whose ARM requests are answered by
+// respond; Azure AD token requests are answered with the token azure-token
+func newARMTestClient(t *testing.T, respond roundTripFunc) *Client {
+	t.Helper()
+	return newTokenTestClient(t, func(req *http.Request) *http.Response {
+		if isAzureTokenRequest(req) {
+			return jsonResponse(http.StatusOK, AzureTokenResponse{AccessToken: "azure-token", ExpiresIn: 3600})
+		}
+		if got := req.Header.Get("Authorization"); got != "Bearer azure-token" {
+			t.Errorf("ARM request with authorization %q, want the Azure token", got)
+		}
+		return respond(req)
+	})
+}
+
+func TestGetARMSubscriptionUsage(t *testing.T) {
+	usages := []ARMUsage{
+		{Name: ARMUsageName{Value: "cores", LocalizedValue: "Total Regional vCPUs"}, CurrentValue: 12, Limit: 100, Unit: "Count"},
+		{Name: ARMUsageName{Value: "virtualMachines", LocalizedValue: "Virtual Machines"}, CurrentValue: 3, Limit: 25000, Unit: "Count"},
+	}
+	c := newARMTestClient(t, func(req *http.Request) *http.Response {
+		wantPath := "/subscriptions/guid-1/providers/Microsoft.Compute/locations/westeurope/usages"
+		if req.Host != "management.azure.com" || req.URL.Path != wantPath {
+			t.Errorf("requested %s%s, want management.azure.com%s", req.Host, req.URL.Path, wantPath)
+		}
+		if got := req.URL.Query().Get("api-version"); got != armComputeUsagesAPIVersion {
+			t.Errorf("api-version = %q, want %s", got, armComputeUsagesAPIVersion)
+		}
+		return jsonResponse(http.StatusOK, ARMUsageList{Value: usages})
+	})
+
+	got, err := c.GetARMSubscriptionUsage(context.Background(), "guid-1", "westeurope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, usages) {
+		t.Errorf("got %+v, want %+v", got, usages)
+	}
+}
+
+func TestGetARMSubscriptionUsageErrors(t *testing.T) {
+	tests := map[string]struct {
+		status         int
+		wantPermission bool
+	}{
+		"no role on the subscription": {status: http.StatusForbidden, wantPermission: true},
+		"subscription not visible":    {status: http.StatusNotFound, wantPermission: true},
+		"server error":                {status: http.StatusInternalServerError},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newARMTestClient(t, func(req *http.Request) *http.Response {
+				return jsonResponse(test.status, map[string]interface{}{"error": map[string]string{"code": "AuthorizationFailed"}})
+			})
+
+			_, err := c.GetARMSubscriptionUsage(context.Background(), "guid-1", "westeurope")
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := errors.Is(err, ErrARMPermissionDenied); got != test.wantPermission {
+				t.Errorf("err = %v, want a permission error: %v", err, test.wantPermission)
+			}
+		})
+	}
+}
@@ -4,15 +4,19 @@
 package client
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // TokenResponse represents the OAuth token response
@@ -24,11 +28,41 @@ type TokenResponse struct {
 	Error        string `json:"Error,omitempty"`
 }
 
-// getToken returns a valid access token, refreshing if necessary
-func (c *Client) getToken() (string, error) {
+// scopedToken is a cached Crayon token for one OAuth scope
+type scopedToken struct {
+	value   string
+	exp     time.Time
+	refresh string // Refresh token issued with the access token, if any
+}
+
+// getToken returns a valid access token for scope, refreshing if necessary.
+// Tokens are cached per scope, so categories sharing a scope share a token.
+func (c *Client) getToken(ctx context.Context, scope string) (string, error) {
 	// Return cached token if still valid (with 60 second buffer)
-	if c.token != "" && time.Now().Before(c.tokenExp.Add(-60*time.Second)) {
-		return c.token, nil
+	if token, ok := c.cachedToken(scope); ok {
+		return token, nil
+	}
+
+	// Coalesce concurrent refreshes of the same scope into a single token request
+	return c.tokenFlight(scope).do(ctx, c.config.StopContext, func(ctx context.Context) (string, error) {
+		return c.refreshToken(ctx, scope)
+	})
+}
+
+// refreshToken requests a new Crayon token for scope and caches it. A refresh token from an
+// earlier response is tried first, so password credentials aren't re-sent every hour; if it is
+// rejected, e.g. because it expired or was revoked, the full grant is used instead.
+func (c *Client) refreshToken(ctx context.Context, scope string) (string, error) {
+	if refresh := c.cachedRefreshToken(scope); refresh != "" {
+		token, err := c.getTokenWithRefreshToken(ctx, scope, refresh)
+		if err == nil && token.AccessToken != "" {
+			// Servers that don't rotate refresh tokens omit them from refresh responses
+			if token.RefreshToken == "" {
+				token.RefreshToken = refresh
+			}
+			c.storeToken(scope, token)
+			return token.AccessToken, nil
+		}
 	}
 
 	// Determine which grant type to use
@@ -37,68 +71,148 @@ func (c *Client) getToken() (string, error) {
 
 	if c.config.Username != "" && c.config.Password != "" {
 		// Use Resource Owner Password Credentials flow (matches C# GetUserToken)
-		token, err = c.getTokenWithPassword()
+		token, err = c.getTokenWithPassword(ctx, scope)
 	} else {
 		// Use Client Credentials flow
-		token, err = c.getTokenWithClientCredentials()
+		token, err = c.getTokenWithClientCredentials(ctx, scope)
 	}
 
 	if err != nil {
 		return "", err
 	}
 
-	c.token = token.AccessToken
-	c.tokenExp = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	c.storeToken(scope, token)
 
-	return c.token, nil
+	return token.AccessToken, nil
+}
+
+// cachedToken returns the cached Crayon token for scope if it is still valid (with 60 second buffer).
+// The Crayon and Azure tokens use separate locks, and neither is held during a refresh.
+func (c *Client) cachedToken(scope string) (string, bool) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	token := c.tokens[scope]
+	if token.value != "" && time.Now().Before(token.exp.Add(-60*time.Second)) {
+		return token.value, true
+	}
+	return "", false
+}
+
+// cachedRefreshToken returns the refresh token cached for scope, if any, even if the access token expired
+func (c *Client) cachedRefreshToken(scope string) string {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	return c.tokens[scope].refresh
+}
+
+// storeToken caches a Crayon token response for scope, with its expiry counted from now
+func (c *Client) storeToken(scope string, token *TokenResponse) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	if c.tokens == nil {
+		c.tokens = make(map[string]scopedToken)
+	}
+	c.tokens[scope] = scopedToken{
+		value:   token.AccessToken,
+		exp:     time.Now().Add(time.Duration(token.ExpiresIn) * time.Second),
+		refresh: token.RefreshToken,
+	}
+}
+
+// tokenFlight returns the flightGroup coalescing token refreshes for scope
+func (c *Client) tokenFlight(scope string) *flightGroup {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	if c.tokenFlights == nil {
+		c.tokenFlights = make(map[string]*flightGroup)
+	}
+	if c.tokenFlights[scope] == nil {
+		c.tokenFlights[scope] = &flightGroup{}
+	}
+	return c.tokenFlights[scope]
+}
+
+// tokenScope returns the OAuth scope to request for an operation category
+func (c *Client) tokenScope(category string) string {
+	if scope := c.config.TokenScopes[category]; scope != "" {
+		return scope
+	}
+	return DefaultTokenScope
+}
+
+// operationCategory classifies a request for choosing its token scope: consumption and budget
+// endpoints are billing, other GETs are reads and everything else is a write
+func operationCategory(method, path string) string {
+	if strings.Contains(path, "/consumption") || strings.Contains(path, "/budget") {
+		return OperationBilling
+	}
+	if method == http.MethodGet {
+		return OperationRead
+	}
+	return OperationWrite
 }
 
 // getTokenWithClientCredentials uses the client credentials grant type
-func (c *Client) getTokenWithClientCredentials() (*TokenResponse, error) {
+func (c *Client) getTokenWithClientCredentials(ctx context.Context, scope string) (*TokenResponse, error) {
 	data := url.Values{}
 	data.Set("grant_type", "client_credentials")
-	data.Set("scope", "CustomerApi")
+	data.Set("scope", scope)
 
-	return c.requestToken(data)
+	return c.requestToken(ctx, data)
 }
 
 // getTokenWithPassword uses the resource owner password credentials grant type
 // This matches the C# implementation: GetUserToken(clientId, secret, username, password)
 // Crayon API requires: Basic Auth header + grant_type=password + username + password + scope
-func (c *Client) getTokenWithPassword() (*TokenResponse, error) {
+func (c *Client) getTokenWithPassword(ctx context.Context, scope string) (*TokenResponse, error) {
 	data := url.Values{}
 	data.Set("grant_type", "password")
 	data.Set("username", c.config.Username)
 	data.Set("password", c.config.Password)
-	data.Set("scope", "CustomerApi")
+	data.Set("scope", scope)
 
-	return c.requestToken(data)
+	return c.requestToken(ctx, data)
+}
+
+// getTokenWithRefreshToken uses the refresh token grant type to renew a token without the
+// password or client credentials grant
+func (c *Client) getTokenWithRefreshToken(ctx context.Context, scope, refreshToken string) (*TokenResponse, error) {
+	data := url.Values{}
+	data.Set("grant_type", "refresh_token")
+	data.Set("refresh_token", refreshToken)
+	data.Set("scope", scope)
+
+	return c.requestToken(ctx, data)
 }
 
 // requestToken performs the token request
 // Crayon API requires client_id:client_secret as Basic Auth header
-func (c *Client) requestToken(data url.Values) (*TokenResponse, error) {
+func (c *Client) requestToken(ctx context.Context, data url.Values) (*TokenResponse, error) {
 	tokenURL := c.config.BaseURL + "/api/v1/connect/token"
-	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(data.Encode()))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create token request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-
-	// Add Basic Auth header with base64(client_id:client_secret)
-	credentials := c.config.ClientID + ":" + c.config.ClientSecret
-	encodedCredentials := base64.StdEncoding.EncodeToString([]byte(credentials))
-	req.Header.Set("Authorization", "Basic "+encodedCredentials)
-
-
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.sendWithRetry(ctx, "token request", tokenRetryPredicate, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(data.Encode()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create token request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		// Add Basic Auth header with base64(client_id:client_secret)
+		credentials := c.config.ClientID + ":" + c.config.ClientSecret
+		encodedCredentials := base64.StdEncoding.EncodeToString([]byte(credentials))
+		req.Header.Set("Authorization", "Basic "+encodedCredentials)
+		return req, nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("token request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponseBody(resp.Body, c.maxResponseSize())
 	if err != nil {
 		return nil, fmt.Errorf("failed to read token response: %w", err)
 	}
@@ -107,7 +221,6 @@ func (c *Client) requestToken(data url.Values) (*TokenResponse, error) {
 		return nil, fmt.Errorf("token request failed (status %d): %s", resp.StatusCode, string(body))
 	}
 
-
 	var tokenResp TokenResponse
 	if err := json.Unmarshal(body, &tokenResp); err != nil {
 		return nil, fmt.Errorf("failed to parse token response: %w", err)
@@ -115,6 +228,7 @@ func (c *Client) requestToken(data url.Values) (*TokenResponse, error) {
 
 	return &tokenResp, nil
 }
+
 // AzureTokenResponse represents the Azure AD OAuth token response
 type AzureTokenResponse struct {
 	AccessToken string `json:"access_token"`
@@ -125,51 +239,91 @@ type AzureTokenResponse struct {
 // AzureCLITokenResponse represents the response from `az account get-access-token`
 type AzureCLITokenResponse struct {
 	AccessToken string `json:"accessToken"`
-	ExpiresOn   string `json:"expiresOn"`
+	ExpiresOn   string `json:"expiresOn"`  // Local time, e.g. "2024-01-13 00:45:00.000000"
+	ExpiresOnTS int64  `json:"expires_on"` // Unix time, only emitted by newer CLI versions
+}
+
+// azureCLIExpiresOnLayout is the layout of AzureCLITokenResponse.ExpiresOn
+const azureCLIExpiresOnLayout = "2006-01-02 15:04:05.999999"
+
+// azureCLITokenFallbackLifetime is assumed for Azure CLI tokens whose expiry can't be parsed
+const azureCLITokenFallbackLifetime = 50 * time.Minute
+
+// Expiry returns when the token expires, preferring the Unix time of newer CLI versions. ok is
+// false if the response carries no parseable expiry.
+func (r AzureCLITokenResponse) Expiry() (time.Time, bool) {
+	if r.ExpiresOnTS > 0 {
+		return time.Unix(r.ExpiresOnTS, 0), true
+	}
+	if r.ExpiresOn != "" {
+		if expiry, err := time.ParseInLocation(azureCLIExpiresOnLayout, r.ExpiresOn, time.Local); err == nil {
+			return expiry, true
+		}
+	}
+	return time.Time{}, false
 }
 
 // getAzureToken returns a valid Azure AD access token, refreshing if necessary
-// Supports two authentication methods:
+// Supports three authentication methods:
 // 1. Service Principal (if ARM_CLIENT_ID, ARM_CLIENT_SECRET, ARM_TENANT_ID are set)
-// 2. Azure CLI session (fallback - uses `az account get-access-token`)
-func (c *Client) getAzureToken() (string, error) {
+// 2. Managed identity (if ARM_USE_MSI is set - uses the host's identity endpoint)
+// 3. Azure CLI session (fallback - uses `az account get-access-token`)
+func (c *Client) getAzureToken(ctx context.Context) (string, error) {
 	// Return cached token if still valid (with 60 second buffer)
-	if c.azureToken != "" && time.Now().Before(c.azureTokenExp.Add(-60*time.Second)) {
-		return c.azureToken, nil
+	if token, ok := c.cachedAzureToken(); ok {
+		return token, nil
 	}
 
-	// Try Service Principal auth first (if credentials are configured)
-	if c.config.AzureClientID != "" && c.config.AzureClientSecret != "" && c.config.AzureTenantID != "" {
-		return c.getAzureTokenWithServicePrincipal()
+	// The Azure CLI and Azure AD aren't faked in mock mode
+	if c.config.MockMode {
+		return "", fmt.Errorf("azure token: %w", ErrMockMode)
 	}
 
-	// Fallback to Azure CLI session
-	return c.getAzureTokenWithCLI()
+	// Coalesce concurrent refreshes into a single token request
+	return c.azureTokenFlight.do(ctx, c.config.StopContext, func(ctx context.Context) (string, error) {
+		// Try Service Principal auth first (if credentials are configured)
+		if c.hasServicePrincipal() {
+			return c.getAzureTokenWithServicePrincipal(ctx)
+		}
+
+		// Replayed ARM responses don't check the token, and recorded ones never contain it
+		if c.config.CassettePath != "" && c.config.CassetteMode == CassetteModeReplay {
+			return redacted, nil
+		}
+
+		if c.config.AzureUseMSI {
+			return c.getAzureTokenWithManagedIdentity(ctx)
+		}
+
+		// Fallback to Azure CLI session
+		return c.getAzureTokenWithCLI(ctx)
+	})
 }
 
 // getAzureTokenWithServicePrincipal authenticates using client credentials (Service Principal)
-func (c *Client) getAzureTokenWithServicePrincipal() (string, error) {
-	tokenURL := fmt.Sprintf("https://login.microsoftonline.com/%s/oauth2/v2.0/token", c.config.AzureTenantID)
+func (c *Client) getAzureTokenWithServicePrincipal(ctx context.Context) (string, error) {
+	tokenURL := fmt.Sprintf("%s/%s/oauth2/v2.0/token", c.azureEndpoints().ActiveDirectory, url.PathEscape(c.config.AzureTenantID))
 	data := url.Values{}
 	data.Set("client_id", c.config.AzureClientID)
 	data.Set("client_secret", c.config.AzureClientSecret)
 	data.Set("grant_type", "client_credentials")
-	data.Set("scope", "https://management.azure.com/.default")
-
-	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(data.Encode()))
-	if err != nil {
-		return "", fmt.Errorf("failed to create azure token request: %w", err)
-	}
+	data.Set("scope", c.azureARMScope())
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.sendWithRetry(ctx, "azure token request", tokenRetryPredicate, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(data.Encode()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create azure token request: %w", err)
+		}
 
-	resp, err := c.httpClient.Do(req)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req, nil
+	})
 	if err != nil {
 		return "", fmt.Errorf("azure token request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponseBody(resp.Body, c.maxResponseSize())
 	if err != nil {
 		return "", fmt.Errorf("failed to read azure token response: %w", err)
 	}
@@ -183,17 +337,107 @@ func (c *Client) getAzureTokenWithServicePrincipal() (string, error) {
 		return "", fmt.Errorf("failed to parse azure token response: %w", err)
 	}
 
-	c.azureToken = tokenResp.AccessToken
-	c.azureTokenExp = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	c.storeAzureToken(tokenResp.AccessToken, time.Now().Add(time.Duration(tokenResp.ExpiresIn)*time.Second))
+
+	return tokenResp.AccessToken, nil
+}
+
+// Managed identity token endpoints
+const (
+	// imdsTokenURL is the Azure Instance Metadata Service endpoint of Azure VMs and most Azure hosts
+	imdsTokenURL = "http://169.254.169.254/metadata/identity/oauth2/token"
+	// imdsAPIVersion is the IMDS API version requested
+	imdsAPIVersion = "2018-02-01"
+	// appServiceAPIVersion is the API version requested from IDENTITY_ENDPOINT on App Service and Functions
+	appServiceAPIVersion = "2019-08-01"
+)
+
+// ManagedIdentityTokenResponse represents a managed identity token response. Both IMDS and App
+// Service report the expiry as a string.
+type ManagedIdentityTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresOn   string `json:"expires_on"` // Unix time
+	ExpiresIn   string `json:"expires_in"` // Seconds, IMDS only
+}
+
+// Expiry returns when the token expires. ok is false if the response carries no parseable expiry.
+func (r ManagedIdentityTokenResponse) Expiry() (time.Time, bool) {
+	if seconds, err := strconv.ParseInt(r.ExpiresOn, 10, 64); err == nil && seconds > 0 {
+		return time.Unix(seconds, 0), true
+	}
+	if seconds, err := strconv.ParseInt(r.ExpiresIn, 10, 64); err == nil && seconds > 0 {
+		return time.Now().Add(time.Duration(seconds) * time.Second), true
+	}
+	return time.Time{}, false
+}
+
+// getAzureTokenWithManagedIdentity gets a token for the host's managed identity, from the App
+// Service identity endpoint if the host provides one, otherwise from IMDS
+func (c *Client) getAzureTokenWithManagedIdentity(ctx context.Context) (string, error) {
+	tokenURL, apiVersion := imdsTokenURL, imdsAPIVersion
+	identityEndpoint, identityHeader := os.Getenv("IDENTITY_ENDPOINT"), os.Getenv("IDENTITY_HEADER")
+	if identityEndpoint != "" && identityHeader != "" {
+		tokenURL, apiVersion = identityEndpoint, appServiceAPIVersion
+	}
+
+	query := url.Values{}
+	query.Set("api-version", apiVersion)
+	query.Set("resource", azureResourceFromScope(c.azureARMScope()))
+	if c.config.AzureClientID != "" {
+		query.Set("client_id", c.config.AzureClientID)
+	}
+
+	tflog.Info(ctx, "Using the Azure managed identity", map[string]interface{}{
+		"user_assigned": c.config.AzureClientID != "",
+		"app_service":   tokenURL != imdsTokenURL,
+	})
+
+	resp, err := c.sendWithRetry(ctx, "managed identity token request", tokenRetryPredicate, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create managed identity token request: %w", err)
+		}
+
+		if tokenURL == imdsTokenURL {
+			req.Header.Set("Metadata", "true")
+		} else {
+			req.Header.Set("X-IDENTITY-HEADER", identityHeader)
+		}
+		return req, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("managed identity token request failed (is a managed identity assigned to this host?): %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := readResponseBody(resp.Body, c.maxResponseSize())
+	if err != nil {
+		return "", fmt.Errorf("failed to read managed identity token response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("managed identity token request failed (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var tokenResp ManagedIdentityTokenResponse
+	if err := json.Unmarshal(body, &tokenResp); err != nil {
+		return "", fmt.Errorf("failed to parse managed identity token response: %w", err)
+	}
+
+	expiry, ok := tokenResp.Expiry()
+	if !ok {
+		return "", fmt.Errorf("managed identity token response has no valid expiry: expires_on %q", tokenResp.ExpiresOn)
+	}
+	c.storeAzureToken(tokenResp.AccessToken, expiry)
 
-	return c.azureToken, nil
+	return tokenResp.AccessToken, nil
 }
 
 // getAzureTokenWithCLI gets a token from the Azure CLI session (az login)
-func (c *Client) getAzureTokenWithCLI() (string, error) {
-	fmt.Println("[INFO] No Azure Service Principal configured. Using Azure CLI session...")
+func (c *Client) getAzureTokenWithCLI(ctx context.Context) (string, error) {
+	tflog.Info(ctx, "No Azure Service Principal configured, using the Azure CLI session")
 
-	cmd := exec.Command("az", "account", "get-access-token", "--resource", "https://management.azure.com", "-o", "json")
+	cmd := exec.CommandContext(ctx, "az", "account", "get-access-token", "--resource", azureResourceFromScope(c.azureARMScope()), "-o", "json")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("failed to get token from Azure CLI (run 'az login' first): %w", err)
@@ -204,10 +448,50 @@ func (c *Client) getAzureTokenWithCLI() (string, error) {
 		return "", fmt.Errorf("failed to parse Azure CLI token response: %w", err)
 	}
 
-	c.azureToken = tokenResp.AccessToken
-	// Parse expiresOn (format: "2024-01-13 00:45:00.000000")
-	// For simplicity, just set expiry to 50 minutes from now
-	c.azureTokenExp = time.Now().Add(50 * time.Minute)
+	expiry, ok := tokenResp.Expiry()
+	if !ok {
+		tflog.Warn(ctx, "Could not parse the Azure CLI token expiry, assuming the default lifetime", map[string]interface{}{
+			"expires_on": tokenResp.ExpiresOn,
+			"lifetime":   azureCLITokenFallbackLifetime.String(),
+		})
+		expiry = time.Now().Add(azureCLITokenFallbackLifetime)
+	}
+	c.storeAzureToken(tokenResp.AccessToken, expiry)
+
+	return tokenResp.AccessToken, nil
+}
+
+// cachedAzureToken returns the cached Azure token if it is still valid (with 60 second buffer)
+func (c *Client) cachedAzureToken() (string, bool) {
+	c.azureTokenMu.Lock()
+	defer c.azureTokenMu.Unlock()
+
+	if c.azureToken != "" && time.Now().Before(c.azureTokenExp.Add(-60*time.Second)) {
+		return c.azureToken, true
+	}
+	return "", false
+}
+
+// storeAzureToken caches an Azure token
+func (c *Client) storeAzureToken(token string, exp time.Time) {
+	c.azureTokenMu.Lock()
+	defer c.azureTokenMu.Unlock()
+
+	c.azureToken = token
+	c.azureTokenExp = exp
+}
+
+// azureARMScope returns the OAuth scope to request ARM tokens for, by default the ARM URL of the
+// configured Azure environment
+func (c *Client) azureARMScope() string {
+	if c.config.AzureARMScope != "" {
+		return c.config.AzureARMScope
+	}
+	return c.armURL("/.default")
+}
 
-	return c.azureToken, nil
+// azureResourceFromScope converts a v2 scope (https://management.azure.com/.default)
+// into the v1 resource form (https://management.azure.com) used by the Azure CLI
+func azureResourceFromScope(scope string) string {
+	return strings.TrimSuffix(scope, "/.default")
 }
@@ -0,0 +1,378 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAzureCLITokenExpiry(t *testing.T) {
+	epoch := time.Date(2024, 1, 13, 0, 45, 0, 0, time.UTC)
+	local := time.Date(2024, 1, 13, 0, 45, 0, 0, time.Local)
+
+	tests := map[string]struct {
+		output string
+		want   time.Time
+		wantOK bool
+	}{
+		"expires_on epoch": {
+			output: `{"accessToken": "token", "expiresOn": "2024-01-13 00:45:00.000000", "expires_on": 1705106700}`,
+			want:   epoch,
+			wantOK: true,
+		},
+		"expiresOn local time": {
+			output: `{"accessToken": "token", "expiresOn": "2024-01-13 00:45:00.000000"}`,
+			want:   local,
+			wantOK: true,
+		},
+		"expiresOn without fractional seconds": {
+			output: `{"accessToken": "token", "expiresOn": "2024-01-13 00:45:00"}`,
+			want:   local,
+			wantOK: true,
+		},
+		"unparseable expiresOn": {
+			output: `{"accessToken": "token", "expiresOn": "tomorrow"}`,
+		},
+		"no expiry": {
+			output: `{"accessToken": "token"}`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var token AzureCLITokenResponse
+			if err := json.Unmarshal([]byte(test.output), &token); err != nil {
+				t.Fatal(err)
+			}
+
+			got, ok := token.Expiry()
+			if ok != test.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, test.wantOK)
+			}
+			if ok && !got.Equal(test.want) {
+				t.Errorf("expiry = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// newTokenTestClient returns a client whose requests are answered by respond, with Crayon and
+// Azure Service Principal credentials configured
+func newTokenTestClient(t *testing.T, respond roundTripFunc) *Client {
+	t.Helper()
+	c, err := NewClient(ClientConfig{
+		BaseURL:           "https://api.crayon.test",
+		ClientID:          "client",
+		ClientSecret:      "secret",
+		AzureClientID:     "azure-client",
+		AzureClientSecret: "azure-secret",
+		AzureTenantID:     "tenant",
+		MaxRetries:        -1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.httpClient.Transport = respond
+	return c
+}
+
+// isAzureTokenRequest reports whether req asks Azure AD for a token
+func isAzureTokenRequest(req *http.Request) bool {
+	return strings.HasSuffix(req.URL.Path, "/oauth2/v2.0/token")
+}
+
+func TestTokenRequestsCoalesced(t *testing.T) {
+	var crayonRequests, azureRequests int32
+	c := newTokenTestClient(t, func(req *http.Request) *http.Response {
+		// Keep the request in flight while the other callers arrive
+		time.Sleep(50 * time.Millisecond)
+		if isAzureTokenRequest(req) {
+			atomic.AddInt32(&azureRequests, 1)
+			return jsonResponse(http.StatusOK, AzureTokenResponse{AccessToken: "azure-token", ExpiresIn: 3600})
+		}
+		atomic.AddInt32(&crayonRequests, 1)
+		return jsonResponse(http.StatusOK, TokenResponse{AccessToken: "crayon-token", ExpiresIn: 3600})
+	})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 200)
+	for i := 0; i < 100; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if token, err := c.getToken(context.Background(), "CustomersApi"); err != nil || token != "crayon-token" {
+				errs <- err
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if token, err := c.getAzureToken(context.Background()); err != nil || token != "azure-token" {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("caller got no token: %v", err)
+	}
+	if crayonRequests != 1 || azureRequests != 1 {
+		t.Errorf("made %d Crayon and %d Azure token requests for 100 callers each, want 1 each", crayonRequests, azureRequests)
+	}
+}
+
+func TestTokenRefreshesIndependent(t *testing.T) {
+	release := make(chan struct{})
+	c := newTokenTestClient(t, func(req *http.Request) *http.Response {
+		if isAzureTokenRequest(req) {
+			<-release
+			return jsonResponse(http.StatusOK, AzureTokenResponse{AccessToken: "azure-token", ExpiresIn: 3600})
+		}
+		return jsonResponse(http.StatusOK, TokenResponse{AccessToken: "crayon-token", ExpiresIn: 3600})
+	})
+
+	azureDone := make(chan error, 1)
+	go func() {
+		_, err := c.getAzureToken(context.Background())
+		azureDone <- err
+	}()
+
+	// A slow Azure token refresh must not hold up Crayon tokens
+	crayonDone := make(chan error, 1)
+	go func() {
+		_, err := c.getToken(context.Background(), "CustomersApi")
+		crayonDone <- err
+	}()
+	select {
+	case err := <-crayonDone:
+		if err != nil {
+			t.Errorf("crayon token: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("the Crayon token waited for the Azure token refresh")
+	}
+
+	close(release)
+	if err := <-azureDone; err != nil {
+		t.Errorf("azure token: %v", err)
+	}
+}
+
+func TestTokenRequestCancelled(t *testing.T) {
+	aborted := make(chan struct{})
+	c := newTokenTestClient(t, func(req *http.Request) *http.Response {
+		select {
+		case <-req.Context().Done():
+			close(aborted)
+		case <-time.After(5 * time.Second):
+		}
+		return jsonResponse(http.StatusOK, TokenResponse{AccessToken: "crayon-token", ExpiresIn: 3600})
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := c.getToken(ctx, "CustomersApi"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("getToken returned after %v, long past the caller's deadline", elapsed)
+	}
+
+	select {
+	case <-aborted:
+	case <-time.After(2 * time.Second):
+		t.Error("the token request wasn't aborted once its only caller gave up")
+	}
+}
+
+func TestTokenRequestOutlivesCancelledCaller(t *testing.T) {
+	release := make(chan struct{})
+	c := newTokenTestClient(t, func(req *http.Request) *http.Response {
+		<-release
+		return jsonResponse(http.StatusOK, AzureTokenResponse{AccessToken: "azure-token", ExpiresIn: 3600})
+	})
+
+	// The first caller starts the refresh and gives up, the second joins it and waits
+	first, cancel := context.WithCancel(context.Background())
+	firstDone := make(chan error, 1)
+	go func() {
+		_, err := c.getAzureToken(first)
+		firstDone <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	secondDone := make(chan error, 1)
+	go func() {
+		_, err := c.getAzureToken(context.Background())
+		secondDone <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	if err := <-firstDone; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled caller: err = %v, want context.Canceled", err)
+	}
+	close(release)
+	if err := <-secondDone; err != nil {
+		t.Errorf("the other caller failed with the first one's cancellation: %v", err)
+	}
+}
+
+func TestAzureARMScope(t *testing.T) {
+	tests := map[string]struct {
+		config       ClientConfig
+		wantScope    string
+		wantResource string
+	}{
+		"default": {
+			wantScope:    DefaultAzureARMScope,
+			wantResource: "https://management.azure.com",
+		},
+		"other environment": {
+			config:       ClientConfig{AzureEnvironment: AzureEnvironmentChina},
+			wantScope:    "https://management.chinacloudapi.cn/.default",
+			wantResource: "https://management.chinacloudapi.cn",
+		},
+		"configured": {
+			config:       ClientConfig{AzureEnvironment: AzureEnvironmentChina, AzureARMScope: "https://arm.contoso.test/.default"},
+			wantScope:    "https://arm.contoso.test/.default",
+			wantResource: "https://arm.contoso.test",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := test.config
+			config.BaseURL = "https://api.crayon.test"
+			config.AzureClientID, config.AzureClientSecret, config.AzureTenantID = "azure-client", "azure-secret", "tenant"
+			c, err := NewClient(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var scope string
+			c.httpClient.Transport = roundTripFunc(func(req *http.Request) *http.Response {
+				if err := req.ParseForm(); err != nil {
+					t.Error(err)
+				}
+				scope = req.PostForm.Get("scope")
+				return jsonResponse(http.StatusOK, AzureTokenResponse{AccessToken: "azure-token", ExpiresIn: 3600})
+			})
+
+			if _, err := c.getAzureToken(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if scope != test.wantScope {
+				t.Errorf("requested scope %q, want %q", scope, test.wantScope)
+			}
+			if got := azureResourceFromScope(c.azureARMScope()); got != test.wantResource {
+				t.Errorf("Azure CLI resource = %q, want %q", got, test.wantResource)
+			}
+		})
+	}
+}
+
+func TestTokenScopes(t *testing.T) {
+	tests := map[string]struct {
+		scopes        map[string]string
+		wantRequested map[string]int
+		wantBearer    map[string]string
+	}{
+		"single default scope": {
+			wantRequested: map[string]int{DefaultTokenScope: 1},
+			wantBearer: map[string]string{
+				OperationRead:    "token-" + DefaultTokenScope,
+				OperationBilling: "token-" + DefaultTokenScope,
+				OperationWrite:   "token-" + DefaultTokenScope,
+			},
+		},
+		"scope per operation": {
+			scopes:        map[string]string{OperationRead: "CustomerApi.Read", OperationBilling: "CustomerApi.Billing"},
+			wantRequested: map[string]int{"CustomerApi.Read": 1, "CustomerApi.Billing": 1, DefaultTokenScope: 1},
+			wantBearer: map[string]string{
+				OperationRead:    "token-CustomerApi.Read",
+				OperationBilling: "token-CustomerApi.Billing",
+				OperationWrite:   "token-" + DefaultTokenScope,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			requested := map[string]int{}
+			bearer := map[string]string{}
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/v1/connect/token", func(w http.ResponseWriter, r *http.Request) {
+				scope := r.FormValue("scope")
+				requested[scope]++
+				writeJSON(w, http.StatusOK, TokenResponse{AccessToken: "token-" + scope, TokenType: "Bearer", ExpiresIn: 3600})
+			})
+			mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+				bearer[operationCategory(r.Method, r.URL.Path)] = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+				writeJSON(w, http.StatusOK, AzureSubscription{ID: 1})
+			})
+			server := httptest.NewServer(mux)
+			t.Cleanup(server.Close)
+
+			c, err := NewClient(ClientConfig{
+				BaseURL:      server.URL,
+				ClientID:     "client",
+				ClientSecret: "secret",
+				MaxRetries:   -1,
+				TokenScopes:  test.scopes,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := context.Background()
+			// Repeat every operation so a second token request per scope would show up
+			for i := 0; i < 2; i++ {
+				if _, err := c.GetAzureSubscription(ctx, 1, 1); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := c.GetAzureSubscriptionConsumption(ctx, 1, 1, "2024-05"); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := c.RenameAzureSubscription(ctx, 1, 1, "renamed"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if !reflect.DeepEqual(requested, test.wantRequested) {
+				t.Errorf("token requests per scope = %v, want %v", requested, test.wantRequested)
+			}
+			if !reflect.DeepEqual(bearer, test.wantBearer) {
+				t.Errorf("bearer tokens per operation = %v, want %v", bearer, test.wantBearer)
+			}
+		})
+	}
+}
+
+func TestOperationCategory(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         string
+	}{
+		{http.MethodGet, "/api/v1/azureplans/1/azuresubscriptions/2", OperationRead},
+		{http.MethodGet, "/api/v1/azureplans/1/azuresubscriptions/2/consumption", OperationBilling},
+		{http.MethodGet, "/api/v1/azureplans/1/azuresubscriptions/2/budget", OperationBilling},
+		{http.MethodPut, "/api/v1/azureplans/1/azuresubscriptions/2/budget", OperationBilling},
+		{http.MethodPatch, "/api/v1/azureplans/1/azuresubscriptions/2/rename", OperationWrite},
+		{http.MethodPost, "/api/v1/azureplans/1/azuresubscriptions", OperationWrite},
+	}
+	for _, test := range tests {
+		if got := operationCategory(test.method, test.path); got != test.want {
+			t.Errorf("operationCategory(%s, %s) = %q, want %q", test.method, test.path, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Azure environments (clouds), see ClientConfig.AzureEnvironment
+const (
+	AzureEnvironmentPublic       = "public"
+	AzureEnvironmentUSGovernment = "usgovernment"
+	AzureEnvironmentChina        = "china"
+	AzureEnvironmentGermany      = "germany"
+)
+
+// AzureEnvironments are the accepted values of ClientConfig.AzureEnvironment
+var AzureEnvironments = []string{AzureEnvironmentPublic, AzureEnvironmentUSGovernment, AzureEnvironmentChina, AzureEnvironmentGermany}
+
+// AzureEndpoints are the endpoints of an Azure environment
+type AzureEndpoints struct {
+	// ResourceManager is the Azure Resource Manager (ARM) URL, without a trailing slash
+	ResourceManager string
+	// ActiveDirectory is the Azure AD (Microsoft Entra ID) authority tokens are requested from
+	ActiveDirectory string
+}
+
+// azureEnvironmentEndpoints maps each Azure environment to its endpoints
+var azureEnvironmentEndpoints = map[string]AzureEndpoints{
+	AzureEnvironmentPublic: {
+		ResourceManager: "https://management.azure.com",
+		ActiveDirectory: "https://login.microsoftonline.com",
+	},
+	AzureEnvironmentUSGovernment: {
+		ResourceManager: "https://management.usgovcloudapi.net",
+		ActiveDirectory: "https://login.microsoftonline.us",
+	},
+	AzureEnvironmentChina: {
+		ResourceManager: "https://management.chinacloudapi.cn",
+		ActiveDirectory: "https://login.chinacloudapi.cn",
+	},
+	AzureEnvironmentGermany: {
+		ResourceManager: "https://management.microsoftazure.de",
+		ActiveDirectory: "https://login.microsoftonline.de",
+	},
+}
+
+// AzureEnvironmentEndpoints returns the endpoints of an Azure environment
+func AzureEnvironmentEndpoints(environment string) (AzureEndpoints, error) {
+	endpoints, ok := azureEnvironmentEndpoints[environment]
+	if !ok {
+		return AzureEndpoints{}, fmt.Errorf("azure environment must be one of %s, got %q", strings.Join(AzureEnvironments, ", "), environment)
+	}
+	return endpoints, nil
+}
+
+// azureEndpoints returns the endpoints of the configured Azure environment, defaulting to the public cloud
+func (c *Client) azureEndpoints() AzureEndpoints {
+	if endpoints, ok := azureEnvironmentEndpoints[c.config.AzureEnvironment]; ok {
+		return endpoints
+	}
+	return azureEnvironmentEndpoints[AzureEnvironmentPublic]
+}
+
+// armURL returns the ARM URL of a path such as /subscriptions/{id}
+func (c *Client) armURL(path string) string {
+	return c.azureEndpoints().ResourceManager + path
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// ErrBackoffExhausted is returned when a Backoff has no attempts or time left
+var ErrBackoffExhausted = errors.New("backoff exhausted")
+
+// Clock abstracts time so backoff schedules can be tested without waiting
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is the Clock backed by the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Backoff computes retry delays that grow exponentially from Base by Multiplier, capped at Max.
+// A Multiplier of 1 gives a fixed interval. The zero value of optional fields disables them.
+type Backoff struct {
+	Base       time.Duration
+	Max        time.Duration // 0 means no cap
+	Multiplier float64       // 0 means 2
+	// Jitter randomizes each delay by up to this fraction (0-1) in either direction
+	Jitter float64
+	// MaxAttempts limits the number of delays handed out; 0 means unlimited
+	MaxAttempts int
+	// Deadline stops the backoff once reached; delays never run past it. Zero means none.
+	Deadline time.Time
+
+	// Clock and Rand may be replaced in tests
+	Clock Clock
+	Rand  func() float64
+
+	attempt int
+}
+
+// Next returns the delay before the next attempt, or false once MaxAttempts or the Deadline is reached
+func (b *Backoff) Next() (time.Duration, bool) {
+	if b.MaxAttempts > 0 && b.attempt >= b.MaxAttempts {
+		return 0, false
+	}
+	now := b.clock().Now()
+	if !b.Deadline.IsZero() && !now.Before(b.Deadline) {
+		return 0, false
+	}
+
+	multiplier := b.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+	delay := float64(b.Base) * math.Pow(multiplier, float64(b.attempt))
+	if b.Jitter > 0 {
+		random := b.Rand
+		if random == nil {
+			random = rand.Float64
+		}
+		delay *= 1 - b.Jitter + 2*b.Jitter*random()
+	}
+	if b.Max > 0 && delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+	b.attempt++
+
+	return b.capToDeadline(now, time.Duration(delay)), true
+}
+
+// Attempt returns the number of delays handed out so far
+func (b *Backoff) Attempt() int {
+	return b.attempt
+}
+
+// Wait sleeps for the next delay, or at least minWait (e.g. a server's Retry-After) without
+// running past the Deadline. It returns ErrBackoffExhausted when no attempts are left and
+// ctx.Err() if ctx is cancelled first.
+func (b *Backoff) Wait(ctx context.Context, minWait time.Duration) error {
+	delay, ok := b.Next()
+	if !ok {
+		return ErrBackoffExhausted
+	}
+	if minWait > delay {
+		delay = b.capToDeadline(b.clock().Now(), minWait)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-b.clock().After(delay):
+		return nil
+	}
+}
+
+// WaitOrStop is Wait that also gives up once stop is done, returning ErrStopped. stop is the
+// context cancelled when the provider is asked to stop.
+func (b *Backoff) WaitOrStop(ctx, stop context.Context, minWait time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(stop, cancel)()
+
+	err := b.Wait(ctx, minWait)
+	if err != nil && stop.Err() != nil {
+		return ErrStopped
+	}
+	return err
+}
+
+// capToDeadline shortens delay so it ends no later than the Deadline
+func (b *Backoff) capToDeadline(now time.Time, delay time.Duration) time.Duration {
+	if !b.Deadline.IsZero() {
+		if remaining := b.Deadline.Sub(now); delay > remaining {
+			return remaining
+		}
+	}
+	return delay
+}
+
+func (b *Backoff) clock() Clock {
+	if b.Clock == nil {
+		return realClock{}
+	}
+	return b.Clock
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock whose timers fire at once, advancing the clock by their duration
+type fakeClock struct {
+	now   time.Time
+	waits []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+	fired := make(chan time.Time, 1)
+	fired <- c.now
+	return fired
+}
+
+// blockedClock is a Clock whose timers never fire
+type blockedClock struct{}
+
+func (blockedClock) Now() time.Time                         { return time.Now() }
+func (blockedClock) After(d time.Duration) <-chan time.Time { return nil }
+
+// delays returns the first n delays of b, stopping early once it is exhausted
+func delays(b *Backoff, n int) []time.Duration {
+	var result []time.Duration
+	for i := 0; i < n; i++ {
+		delay, ok := b.Next()
+		if !ok {
+			break
+		}
+		result = append(result, delay)
+	}
+	return result
+}
+
+func equalDelays(a, b []time.Duration) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBackoffSchedule(t *testing.T) {
+	tests := map[string]struct {
+		backoff Backoff
+		want    []time.Duration
+	}{
+		"exponential with default multiplier": {
+			backoff: Backoff{Base: time.Second},
+			want:    []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second},
+		},
+		"capped at max": {
+			backoff: Backoff{Base: time.Second, Multiplier: 3, Max: 5 * time.Second},
+			want:    []time.Duration{time.Second, 3 * time.Second, 5 * time.Second, 5 * time.Second},
+		},
+		"fixed interval": {
+			backoff: Backoff{Base: 30 * time.Second, Multiplier: 1},
+			want:    []time.Duration{30 * time.Second, 30 * time.Second, 30 * time.Second, 30 * time.Second},
+		},
+		"max attempts": {
+			backoff: Backoff{Base: time.Second, MaxAttempts: 2},
+			want:    []time.Duration{time.Second, 2 * time.Second},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := test.backoff
+			b.Clock = newFakeClock()
+			if got := delays(&b, 4); !equalDelays(got, test.want) {
+				t.Errorf("delays = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	tests := map[string]struct {
+		random float64
+		want   time.Duration
+	}{
+		"lowest":  {random: 0, want: 750 * time.Millisecond},
+		"middle":  {random: 0.5, want: time.Second},
+		"highest": {random: 1, want: 1250 * time.Millisecond},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := &Backoff{Base: time.Second, Jitter: 0.25, Clock: newFakeClock(), Rand: func() float64 { return test.random }}
+			if got, _ := b.Next(); got != test.want {
+				t.Errorf("delay = %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	// Jitter is applied before the cap, so delays never exceed Max
+	b := &Backoff{Base: time.Second, Max: time.Second, Jitter: 0.5, Clock: newFakeClock(), Rand: func() float64 { return 1 }}
+	if got, _ := b.Next(); got != time.Second {
+		t.Errorf("capped delay = %v, want 1s", got)
+	}
+}
+
+func TestBackoffDeadline(t *testing.T) {
+	clock := newFakeClock()
+	b := &Backoff{Base: time.Second, Deadline: clock.Now().Add(2500 * time.Millisecond), Clock: clock}
+
+	for b.Wait(context.Background(), 0) == nil {
+	}
+
+	// 1s, then 2s shortened to the 1.5s left, then nothing once the deadline is reached
+	want := []time.Duration{time.Second, 1500 * time.Millisecond}
+	if !equalDelays(clock.waits, want) {
+		t.Errorf("waits = %v, want %v", clock.waits, want)
+	}
+	if _, ok := b.Next(); ok {
+		t.Error("Next handed out a delay past the deadline")
+	}
+}
+
+func TestBackoffWait(t *testing.T) {
+	t.Run("exhausted", func(t *testing.T) {
+		b := &Backoff{Base: time.Second, MaxAttempts: 1, Clock: newFakeClock()}
+		if err := b.Wait(context.Background(), 0); err != nil {
+			t.Fatalf("first wait: %v", err)
+		}
+		if err := b.Wait(context.Background(), 0); !errors.Is(err, ErrBackoffExhausted) {
+			t.Errorf("second wait = %v, want ErrBackoffExhausted", err)
+		}
+	})
+
+	t.Run("min wait", func(t *testing.T) {
+		clock := newFakeClock()
+		b := &Backoff{Base: time.Second, Clock: clock}
+		if err := b.Wait(context.Background(), 10*time.Second); err != nil {
+			t.Fatal(err)
+		}
+		// A Retry-After is still bounded by the deadline
+		b.Deadline = clock.Now().Add(3 * time.Second)
+		if err := b.Wait(context.Background(), 10*time.Second); err != nil {
+			t.Fatal(err)
+		}
+		if want := []time.Duration{10 * time.Second, 3 * time.Second}; !equalDelays(clock.waits, want) {
+			t.Errorf("waits = %v, want %v", clock.waits, want)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		b := &Backoff{Base: time.Hour, Clock: blockedClock{}}
+		if err := b.Wait(ctx, 0); !errors.Is(err, context.Canceled) {
+			t.Errorf("wait = %v, want context.Canceled", err)
+		}
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		stop, cancel := context.WithCancel(context.Background())
+		cancel()
+		b := &Backoff{Base: time.Hour, Clock: blockedClock{}}
+		if err := b.WaitOrStop(context.Background(), stop, 0); !errors.Is(err, ErrStopped) {
+			t.Errorf("wait = %v, want ErrStopped", err)
+		}
+	})
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Billing agreement types a subscription can be billed under, as reported by BillingScope.Agreement
+const (
+	// BillingAgreementMCA is a Microsoft Customer Agreement
+	BillingAgreementMCA = "mca"
+	// BillingAgreementEA is an Enterprise Agreement
+	BillingAgreementEA = "ea"
+	// BillingAgreementMPA is a Microsoft Partner Agreement, i.e. CSP
+	BillingAgreementMPA = "mpa"
+)
+
+// billingAgreementAliases maps the agreement type names seen in API responses to the constants above
+var billingAgreementAliases = map[string]string{
+	"mca":                        BillingAgreementMCA,
+	"microsoftcustomeragreement": BillingAgreementMCA,
+	"ea":                         BillingAgreementEA,
+	"enterpriseagreement":        BillingAgreementEA,
+	"enrollment":                 BillingAgreementEA,
+	"mpa":                        BillingAgreementMPA,
+	"microsoftpartneragreement":  BillingAgreementMPA,
+	"csp":                        BillingAgreementMPA,
+}
+
+// BillingScope places a subscription in the Azure billing hierarchy. Which IDs are set depends on
+// the agreement: MCA uses a billing profile and invoice section, EA an enrollment account and MPA
+// a customer. API versions report it either as the ARM billing scope string or as an object.
+type BillingScope struct {
+	AgreementType string
+
+	// ScopeID is the ARM billing scope, if reported as such
+	ScopeID string
+
+	BillingAccountID    string
+	BillingProfileID    string
+	InvoiceSectionID    string
+	EnrollmentAccountID string
+	CustomerID          string
+}
+
+// billingScopeObject is the object form of a BillingScope, with the key variants seen in API responses
+type billingScopeObject struct {
+	AgreementType        string `json:"AgreementType"`
+	BillingAgreementType string `json:"BillingAgreementType"`
+	Type                 string `json:"Type"`
+	Scope                string `json:"Scope"`
+	BillingScope         string `json:"BillingScope"`
+	BillingAccountID     string `json:"BillingAccountId"`
+	EnrollmentNumber     string `json:"EnrollmentNumber"`
+	BillingProfileID     string `json:"BillingProfileId"`
+	InvoiceSectionID     string `json:"InvoiceSectionId"`
+	EnrollmentAccountID  string `json:"EnrollmentAccountId"`
+	CustomerID           string `json:"CustomerId"`
+}
+
+func (b *BillingScope) UnmarshalJSON(data []byte) error {
+	var scope string
+	if err := json.Unmarshal(data, &scope); err == nil {
+		*b = BillingScope{ScopeID: strings.TrimSpace(scope)}
+		return nil
+	}
+
+	var object billingScopeObject
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	*b = BillingScope{
+		AgreementType:       firstNonEmpty(object.AgreementType, object.BillingAgreementType, object.Type),
+		ScopeID:             firstNonEmpty(object.Scope, object.BillingScope),
+		BillingAccountID:    firstNonEmpty(object.BillingAccountID, object.EnrollmentNumber),
+		BillingProfileID:    object.BillingProfileID,
+		InvoiceSectionID:    object.InvoiceSectionID,
+		EnrollmentAccountID: object.EnrollmentAccountID,
+		CustomerID:          object.CustomerID,
+	}
+	return nil
+}
+
+// IsEmpty reports whether b is nil or carries no billing information
+func (b *BillingScope) IsEmpty() bool {
+	return b == nil || *b == BillingScope{}
+}
+
+// Agreement returns the billing agreement type (one of the BillingAgreement constants), taken from
+// the reported type or inferred from the scope, or an empty string if it can't be determined
+func (b *BillingScope) Agreement() string {
+	if b.IsEmpty() {
+		return ""
+	}
+	if agreement, ok := billingAgreementAliases[strings.ToLower(strings.ReplaceAll(b.AgreementType, " ", ""))]; ok {
+		return agreement
+	}
+
+	scope := strings.ToLower(b.Scope())
+	switch {
+	case b.EnrollmentAccountID != "" || strings.Contains(scope, "/enrollmentaccounts/"):
+		return BillingAgreementEA
+	case b.InvoiceSectionID != "" || b.BillingProfileID != "" || strings.Contains(scope, "/billingprofiles/"):
+		return BillingAgreementMCA
+	case b.CustomerID != "" || strings.Contains(scope, "/customers/"):
+		return BillingAgreementMPA
+	}
+	return ""
+}
+
+// Scope returns the ARM billing scope of the subscription, built from the reported IDs if the
+// scope itself isn't reported, or an empty string if the IDs don't determine it
+func (b *BillingScope) Scope() string {
+	if b.IsEmpty() {
+		return ""
+	}
+	if b.ScopeID != "" {
+		return b.ScopeID
+	}
+	if b.BillingAccountID == "" {
+		return ""
+	}
+
+	account := "/providers/Microsoft.Billing/billingAccounts/" + b.BillingAccountID
+	switch {
+	case b.EnrollmentAccountID != "":
+		return account + "/enrollmentAccounts/" + b.EnrollmentAccountID
+	case b.BillingProfileID != "" && b.InvoiceSectionID != "":
+		return account + "/billingProfiles/" + b.BillingProfileID + "/invoiceSections/" + b.InvoiceSectionID
+	case b.BillingProfileID != "":
+		return account + "/billingProfiles/" + b.BillingProfileID
+	case b.CustomerID != "":
+		return account + "/customers/" + b.CustomerID
+	}
+	return account
+}
+
+// EnrollmentAccount returns the EA enrollment account of the subscription, or an empty string for
+// other agreement types
+func (b *BillingScope) EnrollmentAccount() string {
+	if b.Agreement() != BillingAgreementEA {
+		return ""
+	}
+	if b.EnrollmentAccountID != "" {
+		return b.EnrollmentAccountID
+	}
+	return scopeSegment(b.Scope(), "enrollmentAccounts")
+}
+
+// scopeSegment returns the path segment following name in an ARM scope, matched case-insensitively
+func scopeSegment(scope, name string) string {
+	segments := strings.Split(scope, "/")
+	for i := 0; i < len(segments)-1; i++ {
+		if strings.EqualFold(segments[i], name) {
+			return segments[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBillingScope(t *testing.T) {
+	const (
+		mcaScope = "/providers/Microsoft.Billing/billingAccounts/5e98e158:xxxx_2019-05-31/billingProfiles/AW4F-xxxx/invoiceSections/SH3V-xxxx"
+		eaScope  = "/providers/Microsoft.Billing/billingAccounts/1234567/enrollmentAccounts/7654321"
+		mpaScope = "/providers/Microsoft.Billing/billingAccounts/99a13315:xxxx_2019-05-31/customers/2ed2c490-xxxx"
+	)
+
+	tests := map[string]struct {
+		body                             string
+		wantAgreement, wantScope, wantEA string
+	}{
+		"MCA scope": {
+			body:          `"` + mcaScope + `"`,
+			wantAgreement: BillingAgreementMCA, wantScope: mcaScope,
+		},
+		"MCA object": {
+			body:          `{"AgreementType": "MicrosoftCustomerAgreement", "BillingAccountId": "5e98e158:xxxx_2019-05-31", "BillingProfileId": "AW4F-xxxx", "InvoiceSectionId": "SH3V-xxxx"}`,
+			wantAgreement: BillingAgreementMCA, wantScope: mcaScope,
+		},
+		"EA scope": {
+			body:          `"` + eaScope + `"`,
+			wantAgreement: BillingAgreementEA, wantScope: eaScope, wantEA: "7654321",
+		},
+		"EA object": {
+			body:          `{"BillingAgreementType": "Enterprise Agreement", "EnrollmentNumber": "1234567", "EnrollmentAccountId": "7654321"}`,
+			wantAgreement: BillingAgreementEA, wantScope: eaScope, wantEA: "7654321",
+		},
+		"EA object with a scope": {
+			body:          `{"Type": "Enrollment", "Scope": "` + eaScope + `"}`,
+			wantAgreement: BillingAgreementEA, wantScope: eaScope, wantEA: "7654321",
+		},
+		"MPA object": {
+			body:          `{"Type": "CSP", "BillingAccountId": "99a13315:xxxx_2019-05-31", "CustomerId": "2ed2c490-xxxx"}`,
+			wantAgreement: BillingAgreementMPA, wantScope: mpaScope,
+		},
+		"agreement without IDs": {
+			body:          `{"AgreementType": "MCA"}`,
+			wantAgreement: BillingAgreementMCA,
+		},
+		"unknown agreement": {
+			body:      `{"AgreementType": "MOSP", "BillingAccountId": "42"}`,
+			wantScope: "/providers/Microsoft.Billing/billingAccounts/42",
+		},
+		"empty object": {body: `{}`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var scope BillingScope
+			if err := json.Unmarshal([]byte(test.body), &scope); err != nil {
+				t.Fatal(err)
+			}
+			if got := scope.Agreement(); got != test.wantAgreement {
+				t.Errorf("agreement = %q, want %q", got, test.wantAgreement)
+			}
+			if got := scope.Scope(); got != test.wantScope {
+				t.Errorf("scope = %q, want %q", got, test.wantScope)
+			}
+			if got := scope.EnrollmentAccount(); got != test.wantEA {
+				t.Errorf("enrollment account = %q, want %q", got, test.wantEA)
+			}
+		})
+	}
+
+	var empty *BillingScope
+	if !empty.IsEmpty() || empty.Agreement() != "" || empty.Scope() != "" || empty.EnrollmentAccount() != "" {
+		t.Error("a nil billing scope reported billing information")
+	}
+}
+
+func TestAzureSubscriptionBillingScope(t *testing.T) {
+	tests := map[string]struct {
+		body      string
+		wantScope string
+	}{
+		"canonical name": {
+			body:      `{"BillingScope": {"EnrollmentNumber": "1234567", "EnrollmentAccountId": "7654321"}}`,
+			wantScope: "/providers/Microsoft.Billing/billingAccounts/1234567/enrollmentAccounts/7654321",
+		},
+		"alternate name": {
+			body:      `{"billingAgreement": "/providers/Microsoft.Billing/billingAccounts/1234567/enrollmentAccounts/7654321"}`,
+			wantScope: "/providers/Microsoft.Billing/billingAccounts/1234567/enrollmentAccounts/7654321",
+		},
+		"empty canonical name": {
+			body:      `{"BillingScope": {}, "BillingHierarchy": {"BillingAccountId": "42", "CustomerId": "7"}}`,
+			wantScope: "/providers/Microsoft.Billing/billingAccounts/42/customers/7",
+		},
+		"not reported": {body: `{"Id": 42}`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var sub AzureSubscription
+			if err := json.Unmarshal([]byte(test.body), &sub); err != nil {
+				t.Fatal(err)
+			}
+			if got := sub.BillingScope.Scope(); got != test.wantScope {
+				t.Errorf("billing scope = %q, want %q", got, test.wantScope)
+			}
+		})
+	}
+}
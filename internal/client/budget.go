@@ -0,0 +1,88 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Budget represents the spending budget of a subscription
+type Budget struct {
+	Amount   float64 `json:"Amount"`
+	Currency string  `json:"Currency"`
+}
+
+// BudgetUtilization is a subscription's consumption for a period measured against its budget
+type BudgetUtilization struct {
+	Spent    float64
+	Currency string
+	// Budget, Remaining and Percent are nil when the subscription has no budget
+	Budget    *float64
+	Remaining *float64
+	Percent   *float64
+}
+
+// GetAzureSubscriptionBudget retrieves the budget of a subscription.
+// Returns nil without an error when the subscription has no budget.
+func (c *Client) GetAzureSubscriptionBudget(ctx context.Context, azurePlanID, subscriptionID int) (*Budget, error) {
+	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions/%d/budget", azurePlanID, subscriptionID)
+
+	result, status, err := request[Budget](ctx, c, http.MethodGet, path, nil)
+	if status == http.StatusNotFound || status == http.StatusNoContent {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetAzureSubscriptionBudgetUtilization measures a subscription's consumption for a period (YYYY-MM)
+// against its budget. Missing consumption data counts as nothing spent.
+func (c *Client) GetAzureSubscriptionBudgetUtilization(ctx context.Context, azurePlanID, subscriptionID int, period string) (*BudgetUtilization, error) {
+	budget, err := c.GetAzureSubscriptionBudget(ctx, azurePlanID, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+
+	utilization := &BudgetUtilization{}
+
+	consumption, err := c.GetAzureSubscriptionConsumption(ctx, azurePlanID, subscriptionID, period)
+	if err != nil {
+		tflog.Warn(ctx, "No consumption data for subscription, treating it as 0", map[string]interface{}{
+			"azure_plan_id":   azurePlanID,
+			"subscription_id": subscriptionID,
+			"error":           err.Error(),
+		})
+	} else {
+		utilization.Spent = consumption.Total
+		utilization.Currency = consumption.Currency
+	}
+
+	if budget == nil {
+		return utilization, nil
+	}
+
+	if utilization.Currency == "" {
+		utilization.Currency = budget.Currency
+	} else if budget.Currency != "" && budget.Currency != utilization.Currency {
+		return nil, fmt.Errorf("budget currency %s does not match consumption currency %s", budget.Currency, utilization.Currency)
+	}
+
+	remaining := budget.Amount - utilization.Spent
+	utilization.Budget = &budget.Amount
+	utilization.Remaining = &remaining
+	// A zero budget has no meaningful utilization
+	if budget.Amount > 0 {
+		percent := utilization.Spent / budget.Amount * 100
+		utilization.Percent = &percent
+	}
+
+	return utilization, nil
+}
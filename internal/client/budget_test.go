@@ -0,0 +1,119 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestGetAzureSubscriptionBudgetUtilization(t *testing.T) {
+	budget := func(amount float64) func(w http.ResponseWriter) {
+		return func(w http.ResponseWriter) { writeJSON(w, http.StatusOK, Budget{Amount: amount, Currency: "EUR"}) }
+	}
+	spent := func(total float64, currency string) func(w http.ResponseWriter) {
+		return func(w http.ResponseWriter) {
+			writeJSON(w, http.StatusOK, Consumption{Total: total, Currency: currency})
+		}
+	}
+	status := func(code int) func(w http.ResponseWriter) {
+		return func(w http.ResponseWriter) { w.WriteHeader(code) }
+	}
+	f := func(v float64) *float64 { return &v }
+
+	tests := map[string]struct {
+		budget      func(w http.ResponseWriter)
+		consumption func(w http.ResponseWriter)
+		want        BudgetUtilization
+		wantErr     bool
+	}{
+		"under budget": {
+			budget: budget(200), consumption: spent(50, "EUR"),
+			want: BudgetUtilization{Spent: 50, Currency: "EUR", Budget: f(200), Remaining: f(150), Percent: f(25)},
+		},
+		"over budget": {
+			budget: budget(200), consumption: spent(250, "EUR"),
+			want: BudgetUtilization{Spent: 250, Currency: "EUR", Budget: f(200), Remaining: f(-50), Percent: f(125)},
+		},
+		"no budget": {
+			budget: status(http.StatusNotFound), consumption: spent(50, "EUR"),
+			want: BudgetUtilization{Spent: 50, Currency: "EUR"},
+		},
+		"empty budget": {
+			budget: status(http.StatusNoContent), consumption: spent(50, "EUR"),
+			want: BudgetUtilization{Spent: 50, Currency: "EUR"},
+		},
+		"no consumption": {
+			budget: budget(200), consumption: status(http.StatusNotFound),
+			want: BudgetUtilization{Currency: "EUR", Budget: f(200), Remaining: f(200), Percent: f(0)},
+		},
+		"neither": {
+			budget: status(http.StatusNotFound), consumption: status(http.StatusNotFound),
+			want: BudgetUtilization{},
+		},
+		"zero budget": {
+			budget: budget(0), consumption: spent(50, "EUR"),
+			want: BudgetUtilization{Spent: 50, Currency: "EUR", Budget: f(0), Remaining: f(-50)},
+		},
+		"mismatched currencies": {
+			budget: budget(200), consumption: spent(50, "USD"),
+			wantErr: true,
+		},
+		"budget unreadable": {
+			budget: status(http.StatusInternalServerError), consumption: spent(50, "EUR"),
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			subscriptionPath := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions/42", testPlanID)
+			c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case subscriptionPath + "/budget":
+					test.budget(w)
+				case subscriptionPath + "/consumption":
+					test.consumption(w)
+				default:
+					http.NotFound(w, r)
+				}
+			})
+
+			got, err := c.GetAzureSubscriptionBudgetUtilization(context.Background(), testPlanID, 42, "2024-05")
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("got %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Spent != test.want.Spent || got.Currency != test.want.Currency {
+				t.Errorf("spent %v %s, want %v %s", got.Spent, got.Currency, test.want.Spent, test.want.Currency)
+			}
+			for field, values := range map[string][2]*float64{
+				"Budget":    {got.Budget, test.want.Budget},
+				"Remaining": {got.Remaining, test.want.Remaining},
+				"Percent":   {got.Percent, test.want.Percent},
+			} {
+				if !equalFloatPointers(values[0], values[1]) {
+					t.Errorf("%s = %s, want %s", field, formatFloatPointer(values[0]), formatFloatPointer(values[1]))
+				}
+			}
+		})
+	}
+}
+
+func equalFloatPointers(a, b *float64) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
+}
+
+func formatFloatPointer(f *float64) string {
+	if f == nil {
+		return "null"
+	}
+	return fmt.Sprint(*f)
+}
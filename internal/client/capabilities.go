@@ -0,0 +1,94 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Capabilities reports what the configured credentials are allowed to do
+type Capabilities struct {
+	// Organizations are the organizations the Crayon credentials can access
+	Organizations []OrganizationReference
+
+	// CanRead reports whether the customer tenants of the configured organization can be listed
+	CanRead bool
+
+	// CanCreate reports whether the credentials are authorized to create subscriptions, probed
+	// with the create dry-run endpoint so nothing is created. It's nil when that can't be told.
+	CanCreate *bool
+
+	// CanCancel reports whether the credentials are authorized to cancel subscriptions. The API
+	// has no way to check it without cancelling, so it's always nil (unknown).
+	CanCancel *bool
+
+	// AzurePollingAvailable reports whether an ARM token can be obtained for direct Azure polling
+	AzurePollingAvailable bool
+
+	// Notes explain why capabilities are unavailable
+	Notes []string
+}
+
+// OrganizationsResponse represents the response from the Organizations API
+type OrganizationsResponse = Page[OrganizationReference]
+
+// probeAzurePlanID is an Azure Plan ID that never exists. A create dry-run against it fails
+// validation when authorized and with 401/403 when not.
+const probeAzurePlanID = 0
+
+// Capabilities validates the credentials and probes what they can do. An error is only returned
+// if the Crayon credentials can't authenticate at all; other failures are reported in Notes.
+func (c *Client) Capabilities(ctx context.Context) (*Capabilities, error) {
+	if _, err := c.getToken(ctx, c.tokenScope(OperationRead)); err != nil {
+		return nil, fmt.Errorf("failed to authenticate: %w", err)
+	}
+
+	caps := &Capabilities{}
+
+	orgs, _, err := requestList[OrganizationsResponse](ctx, c, http.MethodGet, "/api/v1/organizations", nil)
+	if err != nil {
+		caps.Notes = append(caps.Notes, "listing organizations failed: "+err.Error())
+	} else {
+		caps.Organizations = orgs.Items
+	}
+
+	if _, err := c.GetCustomerTenants(ctx); err != nil {
+		caps.Notes = append(caps.Notes, fmt.Sprintf("listing customer tenants of organization %d failed: %v", c.config.OrganizationID, err))
+	} else {
+		caps.CanRead = true
+	}
+
+	caps.CanCreate = c.probeCreate(ctx, caps)
+	caps.Notes = append(caps.Notes, "permission to cancel subscriptions can't be checked without cancelling one")
+
+	if _, err := c.getAzureToken(ctx); err != nil {
+		caps.Notes = append(caps.Notes, "direct Azure polling unavailable: "+err.Error())
+	} else {
+		caps.AzurePollingAvailable = true
+	}
+
+	return caps, nil
+}
+
+// probeCreate sends a create dry-run for a non-existent Azure Plan and reports whether the
+// credentials were authorized to make it. Only a success, or a validation failure that can't
+// have changed anything, counts as authorized; other failures leave the permission unknown.
+func (c *Client) probeCreate(ctx context.Context, caps *Capabilities) *bool {
+	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions/validate", probeAzurePlanID)
+	status, err := c.requestNoContent(ctx, http.MethodPost, path, CreateAzureSubscriptionRequest{Name: "capability-probe"})
+
+	authorized := false
+	switch {
+	case status >= 200 && status < 300, status == http.StatusBadRequest:
+		authorized = true
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		caps.Notes = append(caps.Notes, fmt.Sprintf("not authorized to create subscriptions (status %d)", status))
+	default:
+		caps.Notes = append(caps.Notes, fmt.Sprintf("could not check permission to create subscriptions: %v", err))
+		return nil
+	}
+	return &authorized
+}
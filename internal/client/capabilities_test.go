@@ -0,0 +1,77 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestCapabilities(t *testing.T) {
+	validatePath := "/api/v1/azureplans/0/azuresubscriptions/validate"
+	yes, no := true, false
+
+	tests := map[string]struct {
+		validateStatus int
+		wantCreate     *bool
+	}{
+		"dry-run accepted":          {validateStatus: http.StatusNoContent, wantCreate: &yes},
+		"dry-run fails validation":  {validateStatus: http.StatusBadRequest, wantCreate: &yes},
+		"dry-run forbidden":         {validateStatus: http.StatusForbidden, wantCreate: &no},
+		"dry-run endpoint missing":  {validateStatus: http.StatusNotFound},
+		"dry-run fails on a server": {validateStatus: http.StatusInternalServerError},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var writes []string
+			c := newTestClient(t, ClientConfig{OrganizationID: 4051878}, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					writes = append(writes, r.Method+" "+r.URL.Path)
+				}
+				switch {
+				case r.Method == http.MethodPost && r.URL.Path == validatePath:
+					w.WriteHeader(test.validateStatus)
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v1/organizations":
+					writeJSON(w, http.StatusOK, map[string]interface{}{"Items": []OrganizationReference{{ID: 4051878, Name: "Contoso"}}})
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v1/CustomerTenants":
+					writeJSON(w, http.StatusOK, map[string]interface{}{"Items": []CustomerTenant{}})
+				default:
+					http.NotFound(w, r)
+				}
+			})
+
+			caps, err := c.Capabilities(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(writes) != 1 || writes[0] != http.MethodPost+" "+validatePath {
+				t.Errorf("sent writes %v, want only the create dry-run", writes)
+			}
+			if !equalBoolPointers(caps.CanCreate, test.wantCreate) {
+				t.Errorf("CanCreate = %s, want %s", formatBoolPointer(caps.CanCreate), formatBoolPointer(test.wantCreate))
+			}
+			if caps.CanCancel != nil {
+				t.Errorf("CanCancel = %v, want unknown", *caps.CanCancel)
+			}
+			if !caps.CanRead || len(caps.Organizations) != 1 {
+				t.Errorf("CanRead = %v with organizations %v, want the organization read", caps.CanRead, caps.Organizations)
+			}
+		})
+	}
+}
+
+func equalBoolPointers(a, b *bool) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
+}
+
+func formatBoolPointer(b *bool) string {
+	if b == nil {
+		return "unknown"
+	}
+	if *b {
+		return "true"
+	}
+	return "false"
+}
@@ -0,0 +1,289 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Cassette modes, see ClientConfig.CassetteMode
+const (
+	// CassetteModeRecord sends requests to the network and saves every interaction to the cassette
+	CassetteModeRecord = "record"
+	// CassetteModeReplay serves requests from the cassette without using the network
+	CassetteModeReplay = "replay"
+)
+
+// CassetteModes are the accepted values of ClientConfig.CassetteMode
+var CassetteModes = []string{CassetteModeRecord, CassetteModeReplay}
+
+// ErrNoRecordedInteraction is returned in replay mode for requests the cassette has no response for
+var ErrNoRecordedInteraction = errors.New("no recorded interaction matches the request")
+
+// redacted replaces secrets in saved cassettes
+const redacted = "REDACTED"
+
+// secretKeyFragments mark JSON keys, form fields and query parameters holding secrets. Keys
+// containing any of them, ignoring case, are redacted.
+var secretKeyFragments = []string{"token", "secret", "password", "authorization", "credential", "assertion"}
+
+// recordedResponseHeaders are the response headers saved to cassettes; the client reads no others
+var recordedResponseHeaders = []string{"Content-Type", "Retry-After", "Location", "Operation-Location"}
+
+// Interaction is a single request and its response, as saved in a cassette. Only the request's
+// method, URL and body are kept; headers such as Authorization are never saved.
+type Interaction struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	RequestBody  string      `json:"request_body,omitempty"`
+	Status       int         `json:"status"`
+	Header       http.Header `json:"header,omitempty"`
+	ResponseBody string      `json:"response_body,omitempty"`
+}
+
+// Cassette is a file of recorded API interactions, in the order they happened
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LoadCassette reads a cassette file
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	return &cassette, nil
+}
+
+// Save writes the cassette to path, readable only by the current user
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// newCassetteTransport returns the transport for a cassette mode, wrapping next when recording.
+// Recorded response bodies are read up to maxBodySize bytes.
+func newCassetteTransport(mode, path string, next http.RoundTripper, maxBodySize int64) (http.RoundTripper, error) {
+	switch mode {
+	case CassetteModeRecord:
+		return &recordingTransport{next: next, path: path, maxBodySize: maxBodySize, cassette: &Cassette{}}, nil
+	case CassetteModeReplay:
+		cassette, err := LoadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		return &replayTransport{cassette: cassette, used: make([]bool, len(cassette.Interactions))}, nil
+	}
+	return nil, fmt.Errorf("cassette mode must be one of %s, got %q", strings.Join(CassetteModes, ", "), mode)
+}
+
+// recordingTransport sends requests with next and appends each interaction, redacted, to a
+// cassette. The cassette is saved after every interaction, since the provider process may end
+// at any time.
+type recordingTransport struct {
+	next        http.RoundTripper
+	path        string
+	maxBodySize int64
+
+	mu       sync.Mutex
+	cassette *Cassette
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := readResponseBody(resp.Body, t.maxBodySize)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	header := http.Header{}
+	for _, key := range recordedResponseHeaders {
+		if values := resp.Header.Values(key); len(values) > 0 {
+			header[key] = values
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.cassette.Interactions = append(t.cassette.Interactions, Interaction{
+		Method:       req.Method,
+		URL:          redactURL(req.URL),
+		RequestBody:  redactBody(requestBody),
+		Status:       resp.StatusCode,
+		Header:       header,
+		ResponseBody: redactBody(responseBody),
+	})
+	if err := t.cassette.Save(t.path); err != nil {
+		tflog.Warn(req.Context(), "Failed to save the API interaction cassette", map[string]interface{}{
+			"cassette_path": t.path,
+			"error":         err.Error(),
+		})
+	}
+
+	return resp, nil
+}
+
+// replayTransport serves requests from a cassette. Each request is answered by the first unused
+// interaction with the same method, URL and body, compared after redaction, so repeated requests
+// such as polling replay their recorded responses in order.
+type replayTransport struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	requestBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	method, reqURL, body := req.Method, redactURL(req.URL), redactBody(requestBody)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, interaction := range t.cassette.Interactions {
+		if t.used[i] || interaction.Method != method || interaction.URL != reqURL || interaction.RequestBody != body {
+			continue
+		}
+		t.used[i] = true
+
+		header := interaction.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+			StatusCode:    interaction.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(strings.NewReader(interaction.ResponseBody)),
+			ContentLength: int64(len(interaction.ResponseBody)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrNoRecordedInteraction, method, reqURL)
+}
+
+// readRequestBody reads a request's body and restores it so the request can still be sent
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// isSecretKey reports whether a JSON key, form field or query parameter holds a secret
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, fragment := range secretKeyFragments {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURL returns u with secret query parameters redacted
+func redactURL(u *url.URL) string {
+	redactedURL := *u
+	redactedURL.User = nil
+	if u.RawQuery != "" {
+		redactedURL.RawQuery = redactValues(u.Query()).Encode()
+	}
+	return redactedURL.String()
+}
+
+// redactBody redacts secrets in a JSON or form encoded body. Other bodies are kept as they are.
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err == nil {
+		redactedBody, err := json.Marshal(redactJSON(value))
+		if err == nil {
+			return string(redactedBody)
+		}
+	}
+
+	if values, err := url.ParseQuery(string(body)); err == nil && strings.Contains(string(body), "=") {
+		return redactValues(values).Encode()
+	}
+
+	return string(body)
+}
+
+// redactJSON replaces the values of secret keys anywhere in a decoded JSON value
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isSecretKey(key) {
+				v[key] = redacted
+			} else {
+				v[key] = redactJSON(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+	return value
+}
+
+// redactValues replaces the values of secret keys in form fields or query parameters
+func redactValues(values url.Values) url.Values {
+	for key := range values {
+		if isSecretKey(key) {
+			values[key] = []string{redacted}
+		}
+	}
+	return values
+}
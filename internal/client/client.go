@@ -5,48 +5,239 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// DefaultAzureARMScope is the OAuth scope used for Azure ARM tokens in the public cloud. Other
+// Azure environments default to the scope of their own ARM URL.
+const DefaultAzureARMScope = "https://management.azure.com/.default"
+
+// DefaultOrganizationHeader is the header carrying the organization ID on Crayon API requests
+const DefaultOrganizationHeader = "X-Organization-Id"
+
+// DefaultHTTPTimeout limits each Crayon API request when ClientConfig.HTTPTimeout is unset
+const DefaultHTTPTimeout = 30 * time.Second
+
+// DefaultTokenScope is the OAuth scope requested for Crayon API tokens
+const DefaultTokenScope = "CustomerApi"
+
+// Operation categories that can be given their own token scope via ClientConfig.TokenScopes
+const (
+	OperationRead    = "read"
+	OperationWrite   = "write"
+	OperationBilling = "billing"
+)
+
+// OperationCategories lists the valid keys of ClientConfig.TokenScopes
+var OperationCategories = []string{OperationRead, OperationWrite, OperationBilling}
+
+// DefaultPageSize is the number of items requested per page from list endpoints
+const DefaultPageSize = 1000
+
+// MaxPageSize is the largest page size the Crayon API accepts
+const MaxPageSize = 1000
+
 // ErrAccepted indicates the request was accepted for processing (202) but returned no content
 var ErrAccepted = errors.New("request accepted")
 
+// ErrStopped is returned by polling loops that were aborted because the provider is shutting down
+var ErrStopped = errors.New("provider is shutting down")
+
+// ErrNoResponse is returned when a request was sent but no response was received, e.g. on a
+// timeout or a dropped connection, so it is unknown whether the API processed it
+var ErrNoResponse = errors.New("request failed")
+
 // ClientConfig holds the configuration for the Crayon API client
 type ClientConfig struct {
-	BaseURL           string
-	ClientID          string
-	ClientSecret      string
-	Username          string
-	Password          string
-	OrganizationID    int64
-	AzureClientID     string
-	AzureClientSecret string
-	AzureTenantID     string
+	BaseURL             string
+	ClientID            string
+	ClientSecret        string
+	Username            string
+	Password            string
+	OrganizationID      int64
+	AzureClientID       string
+	AzureClientSecret   string
+	AzureTenantID       string
+	AzureARMScope       string
+	UnknownStatusPolicy string
+	PageSize            int
+	SyncReadRetries     int
+	SyncReadInterval    time.Duration
+	DefaultTags         map[string]string
+	PortalURLTemplate   string
+
+	// AllowedProjects restricts the projects subscriptions can be grouped under. Empty allows any.
+	AllowedProjects []string
+
+	// OrganizationHeader names the header carrying the organization ID on every request,
+	// for endpoints that expect it there rather than as a query parameter. Empty disables it.
+	OrganizationHeader string
+
+	// SkipOrganizationCheck disables verifying that Azure Plans belong to OrganizationID
+	SkipOrganizationCheck bool
+
+	// SuppressPendingWarnings downgrades warnings about subscriptions pending sync to debug logs
+	SuppressPendingWarnings bool
+
+	// ProtectImportedSubscriptions refuses to cancel subscriptions that were imported rather than
+	// created by Terraform, unless the resource explicitly allows it
+	ProtectImportedSubscriptions bool
+
+	// CleanupFailedCreates cancels a subscription that turns out to exist after its create
+	// request failed without a definite answer, instead of only reporting it
+	CleanupFailedCreates bool
+
+	// SyncRecoveryTimeout is how long a subscription confirmed by ARM may stay missing from
+	// Cloud-iQ before Read triggers a sync and reports it. Zero disables the recovery.
+	SyncRecoveryTimeout time.Duration
+
+	// TokenScopes maps operation categories (see OperationCategories) to the OAuth scope requested
+	// for them, for least-privilege credentials. Unset categories use DefaultTokenScope.
+	TokenScopes map[string]string
+
+	// RetryPredicate decides which failed Crayon API requests are retried, e.g. to also retry a
+	// deployment-specific transient error code. Defaults to DefaultRetryPredicate.
+	RetryPredicate RetryPredicate
+
+	// MaxRetries is how often a retriable request is retried. Zero means DefaultMaxRetries and
+	// a negative value disables retries.
+	MaxRetries int
+
+	// RetryBaseDelay is the delay before the first retry, doubling with each further retry.
+	// Defaults to DefaultRetryBaseDelay.
+	RetryBaseDelay time.Duration
+
+	// MinTLSVersion is the lowest TLS version negotiated with the Crayon API, token endpoints,
+	// ARM and webhooks, such as "1.3". Defaults to DefaultMinTLSVersion.
+	MinTLSVersion string
+
+	// MaxResponseSize is the largest response body read, in bytes; list endpoints allow
+	// listResponseSizeMultiplier times as much. Defaults to DefaultMaxResponseSize.
+	MaxResponseSize int64
+
+	// HTTPTimeout limits each request to the Crayon API, including reading the response.
+	// Defaults to DefaultHTTPTimeout.
+	HTTPTimeout time.Duration
+
+	// ARMTargetState is the ARM state (e.g. "Enabled") a created subscription must reach before
+	// its creation counts as confirmed, or ARMTargetStateAny. Defaults to DefaultARMTargetState.
+	ARMTargetState string
+
+	// ARMUnavailablePolicy decides what happens when creates can't be confirmed via Azure ARM for
+	// lack of Azure credentials, one of the ARMUnavailable constants. Defaults to ARMUnavailableWarn.
+	ARMUnavailablePolicy string
+
+	// MockMode serves all Crayon API requests from an in-memory fake instead of the network,
+	// so configurations can be planned and applied without credentials. Azure ARM isn't faked.
+	MockMode bool
+
+	// AzureEnvironment is the Azure cloud ARM tokens and requests go to, one of the AzureEnvironment
+	// constants. Defaults to AzureEnvironmentPublic.
+	AzureEnvironment string
+
+	// AzureUseMSI obtains ARM tokens from the Azure managed identity of the host (Azure VMs, App
+	// Service, Azure DevOps agents) when no complete Service Principal is configured. AzureClientID
+	// selects a user-assigned identity; without it the system-assigned identity is used.
+	AzureUseMSI bool
+
+	// CassettePath is a file Crayon and Azure API interactions are recorded to or replayed from,
+	// depending on CassetteMode, e.g. to reproduce an issue against the exact API responses.
+	// Secrets are redacted from recorded interactions. Empty disables recording and replay.
+	CassettePath string
+	CassetteMode string
+
+	// StopContext is cancelled when the provider is asked to stop. Like the context passed to
+	// each method, it aborts in-flight requests and polling loops. Defaults to a context that
+	// is never cancelled.
+	StopContext context.Context
 }
 
 // Client is the Crayon API client
 type Client struct {
-	config        ClientConfig
-	httpClient    *http.Client
-	token         string
-	tokenExp      time.Time
-	azureToken    string
-	azureTokenExp time.Time
+	config           ClientConfig
+	httpClient       *http.Client
+	tokenMu          sync.Mutex
+	tokenFlights     map[string]*flightGroup
+	tokens           map[string]scopedToken
+	azureTokenMu     sync.Mutex
+	azureTokenFlight flightGroup
+	azureToken       string
+	azureTokenExp    time.Time
+	namesMu          sync.Mutex
+	reservedNames    map[string]bool
+	verifiedPlansMu  sync.Mutex
+	verifiedPlans    map[int]bool
+
+	// dryRunUnsupported is set once the API reports it has no create preflight endpoint
+	dryRunUnsupported atomic.Bool
+
+	// syncUnsupported is set once the API reports it has no endpoint to trigger a sync
+	syncUnsupported atomic.Bool
+
+	// createTagsUnsupported is set once the create endpoint rejects tags in the request body
+	createTagsUnsupported atomic.Bool
 }
 
 // NewClient creates a new Crayon API client
 func NewClient(config ClientConfig) (*Client, error) {
-	return &Client{
+	if config.PageSize == 0 {
+		config.PageSize = DefaultPageSize
+	}
+	if config.PageSize < 1 || config.PageSize > MaxPageSize {
+		return nil, fmt.Errorf("page size must be between 1 and %d, got %d", MaxPageSize, config.PageSize)
+	}
+	if config.StopContext == nil {
+		config.StopContext = context.Background()
+	}
+	if config.MinTLSVersion == "" {
+		config.MinTLSVersion = DefaultMinTLSVersion
+	}
+	minTLSVersion, err := ParseTLSVersion(config.MinTLSVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.AzureEnvironment == "" {
+		config.AzureEnvironment = AzureEnvironmentPublic
+	}
+	if _, err := AzureEnvironmentEndpoints(config.AzureEnvironment); err != nil {
+		return nil, err
+	}
+
+	timeout := config.HTTPTimeout
+	if timeout <= 0 {
+		timeout = DefaultHTTPTimeout
+	}
+
+	var transport http.RoundTripper = newTransport(minTLSVersion)
+	if config.MockMode {
+		transport = newMockTransport(config.OrganizationID)
+	}
+
+	c := &Client{
 		config: config,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   timeout,
+			Transport: transport,
 		},
-	}, nil
+	}
+	if config.CassettePath != "" {
+		// Recording reads whole responses, so it is bounded like the client's own reads
+		c.httpClient.Transport, err = newCassetteTransport(config.CassetteMode, config.CassettePath, transport, c.maxListResponseSize())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
 }
 
 // GetOrganizationID returns the configured organization ID
@@ -54,52 +245,171 @@ func (c *Client) GetOrganizationID() int64 {
 	return c.config.OrganizationID
 }
 
+// Authentication modes reported by GetAuthMode and GetAzureAuthMode
+const (
+	AuthModePassword          = "password"
+	AuthModeClientCredentials = "client_credentials"
+	AuthModeServicePrincipal  = "service_principal"
+	AuthModeAzureCLI          = "azure_cli"
+	AuthModeManagedIdentity   = "managed_identity"
+)
+
+// GetSyncReadRetries returns how many times Read looks for a pending subscription in Cloud-iQ
+func (c *Client) GetSyncReadRetries() int {
+	if c.config.SyncReadRetries < 1 {
+		return 1
+	}
+	return c.config.SyncReadRetries
+}
+
+// GetSyncReadInterval returns the delay between Read attempts for a pending subscription
+func (c *Client) GetSyncReadInterval() time.Duration {
+	if c.config.SyncReadInterval <= 0 {
+		return 30 * time.Second
+	}
+	return c.config.SyncReadInterval
+}
+
+// GetSyncRecoveryTimeout returns how long a pending subscription known to ARM may go unsynced
+// before Read intervenes, or zero if the recovery is disabled
+func (c *Client) GetSyncRecoveryTimeout() time.Duration {
+	return c.config.SyncRecoveryTimeout
+}
+
+// AllowedProjects returns the projects subscriptions may be grouped under, or nil if any is allowed
+func (c *Client) AllowedProjects() []string {
+	return c.config.AllowedProjects
+}
+
+// GetDefaultTags returns a copy of the tags applied to every subscription
+func (c *Client) GetDefaultTags() map[string]string {
+	return MergeTags(c.config.DefaultTags, nil)
+}
+
+// StopContext returns the context that is cancelled when the provider is asked to stop
+func (c *Client) StopContext() context.Context {
+	return c.config.StopContext
+}
+
+// withStop returns a context that is done when ctx is, or when the provider is asked to stop
+func (c *Client) withStop(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(c.config.StopContext, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// wait waits for the next delay of b (or at least minWait). It returns early with ctx.Err()
+// when ctx is cancelled, or ErrStopped if the provider is asked to stop.
+func (c *Client) wait(ctx context.Context, b *Backoff, minWait time.Duration) error {
+	return b.WaitOrStop(ctx, c.config.StopContext, minWait)
+}
+
+// SkipOrganizationCheck reports whether Azure Plans are used without verifying their organization
+func (c *Client) SkipOrganizationCheck() bool {
+	return c.config.SkipOrganizationCheck
+}
+
+// SuppressPendingWarnings reports whether warnings about pending subscriptions are only logged
+func (c *Client) SuppressPendingWarnings() bool {
+	return c.config.SuppressPendingWarnings
+}
+
+// ProtectImportedSubscriptions reports whether destroying imported subscriptions is refused
+func (c *Client) ProtectImportedSubscriptions() bool {
+	return c.config.ProtectImportedSubscriptions
+}
+
+// CleanupFailedCreates reports whether subscriptions left behind by failed creates are cancelled
+func (c *Client) CleanupFailedCreates() bool {
+	return c.config.CleanupFailedCreates
+}
+
+// GetBaseURL returns the configured Crayon API base URL
+func (c *Client) GetBaseURL() string {
+	return c.config.BaseURL
+}
+
+// GetAuthMode returns the OAuth grant used against the Crayon API
+func (c *Client) GetAuthMode() string {
+	if c.config.Username != "" && c.config.Password != "" {
+		return AuthModePassword
+	}
+	return AuthModeClientCredentials
+}
+
+// HasAzureCredentials reports whether a complete Azure Service Principal or a managed identity is configured
+func (c *Client) HasAzureCredentials() bool {
+	return c.hasServicePrincipal() || c.config.AzureUseMSI
+}
+
+// hasServicePrincipal reports whether a complete Azure Service Principal is configured
+func (c *Client) hasServicePrincipal() bool {
+	return c.config.AzureClientID != "" && c.config.AzureClientSecret != "" && c.config.AzureTenantID != ""
+}
+
+// GetAzureAuthMode returns how ARM tokens are obtained for direct Azure polling
+func (c *Client) GetAzureAuthMode() string {
+	if c.hasServicePrincipal() {
+		return AuthModeServicePrincipal
+	}
+	if c.config.AzureUseMSI {
+		return AuthModeManagedIdentity
+	}
+	return AuthModeAzureCLI
+}
+
 // doRequest performs an authenticated HTTP request
-func (c *Client) doRequest(method, path string, body interface{}) (*http.Response, error) {
-	token, err := c.getToken()
+func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	token, err := c.getToken(ctx, c.tokenScope(operationCategory(method, path)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get auth token: %w", err)
 	}
 
-	var reqBody io.Reader
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		jsonBody, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		reqBody = bytes.NewBuffer(jsonBody)
 	}
 
 	url := c.config.BaseURL + path
-	req, err := http.NewRequest(method, url, reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json")
-
+	return c.sendWithRetry(ctx, method+" "+path, c.retryPredicate(), func(ctx context.Context) (*http.Request, error) {
+		// Each attempt gets a fresh reader over the marshalled body
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(jsonBody)
+		}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
+		req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
 
-	return resp, nil
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json")
+		if c.config.OrganizationHeader != "" && c.config.OrganizationID != 0 {
+			req.Header.Set(c.config.OrganizationHeader, strconv.FormatInt(c.config.OrganizationID, 10))
+		}
+		return req, nil
+	})
 }
 
-// parseResponse parses a JSON response body
-func parseResponse[T any](resp *http.Response, result *T) error {
+// parseResponse parses a JSON response body of at most limit bytes. Non-2xx responses return an *APIError.
+func parseResponse[T any](resp *http.Response, result *T, limit int64) error {
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readResponseBody(resp.Body, limit)
 	if err != nil {
 		return fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return newAPIError(resp, body)
 	}
 
 	if len(body) == 0 {
@@ -119,36 +429,76 @@ func parseResponse[T any](resp *http.Response, result *T) error {
 	return nil
 }
 
-// readResponseBody reads the response body without closing the response.
-// The caller must close the response body.
-func readResponseBody(resp *http.Response) ([]byte, error) {
-	body, err := io.ReadAll(resp.Body)
+// request performs an authenticated request and decodes the JSON response into a new T.
+// The status code is returned alongside the result so callers can special-case statuses.
+// The response body is always closed; a 202 without a body returns ErrAccepted.
+func request[T any](ctx context.Context, c *Client, method, path string, body interface{}) (*T, int, error) {
+	return requestWithLimit[T](ctx, c, c.maxResponseSize(), method, path, body)
+}
+
+// requestList is request for list endpoints, which allow larger responses
+func requestList[T any](ctx context.Context, c *Client, method, path string, body interface{}) (*T, int, error) {
+	return requestWithLimit[T](ctx, c, c.maxListResponseSize(), method, path, body)
+}
+
+// requestWithLimit is request reading a response body of at most limit bytes
+func requestWithLimit[T any](ctx context.Context, c *Client, limit int64, method, path string, body interface{}) (*T, int, error) {
+	result, _, status, err := requestWithHeaders[T](ctx, c, limit, method, path, body)
+	return result, status, err
+}
+
+// requestWithHeaders is requestWithLimit for callers that also need the response headers, e.g.
+// the Location of an accepted create. The headers are returned whenever a response was received.
+func requestWithHeaders[T any](ctx context.Context, c *Client, limit int64, method, path string, body interface{}) (*T, http.Header, int, error) {
+	resp, err := c.doRequest(ctx, method, path, body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, nil, 0, err
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	var result T
+	if err := parseResponse(resp, &result, limit); err != nil {
+		return nil, resp.Header, resp.StatusCode, err
 	}
 
-	if len(body) == 0 {
-		if resp.StatusCode == http.StatusAccepted {
-			return nil, ErrAccepted
-		}
-		if resp.StatusCode == http.StatusNoContent {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("response body is empty (status %d)", resp.StatusCode)
+	return &result, resp.Header, resp.StatusCode, nil
+}
+
+// requestNoContent performs an authenticated request whose response body is not needed.
+// The status code is returned alongside any error, an *APIError for non-2xx responses; the
+// response body is always closed.
+func (c *Client) requestNoContent(ctx context.Context, method, path string, body interface{}) (int, error) {
+	resp, err := c.doRequest(ctx, method, path, body)
+	if err != nil {
+		return 0, err
 	}
+	defer resp.Body.Close()
 
-	return body, nil
-}
+	respBody, err := readResponseBody(resp.Body, c.maxResponseSize())
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
+	}
 
-// unmarshalResponse unmarshals a response body into the given result.
-func unmarshalResponse[T any](body []byte, statusCode int, result *T) error {
-	if err := json.Unmarshal(body, result); err != nil {
-		return fmt.Errorf("failed to parse response: %w (body: %s)", err, string(body))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, newAPIError(resp, respBody)
 	}
-	return nil
+
+	return resp.StatusCode, nil
 }
 
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date.
+// Returns zero if the header is absent or invalid.
+func parseRetryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
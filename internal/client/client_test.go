@@ -0,0 +1,270 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestClient returns a client for a fake Crayon API that issues tokens and passes every other
+// request to handler. Requests are not retried unless config sets MaxRetries.
+func newTestClient(t *testing.T, config ClientConfig, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/connect/token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(TokenResponse{AccessToken: "token", TokenType: "Bearer", ExpiresIn: 3600})
+	})
+	mux.Handle("/", handler)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	config.BaseURL = server.URL
+	config.ClientID = "client"
+	config.ClientSecret = "secret"
+	if config.MaxRetries == 0 {
+		config.MaxRetries = -1
+	}
+	c, err := NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// writeJSON writes value as the JSON body of a response with the given status
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// roundTripFunc is an http.RoundTripper answering requests in memory, e.g. to fake Azure ARM
+type roundTripFunc func(*http.Request) *http.Response
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp := f(req)
+	resp.Request = req
+	return resp, nil
+}
+
+// jsonResponse returns a response with value as its JSON body
+func jsonResponse(status int, value interface{}) *http.Response {
+	body, _ := json.Marshal(value)
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+	}
+}
+
+func TestRequest(t *testing.T) {
+	type item struct {
+		Name string
+	}
+
+	tests := map[string]struct {
+		status  int
+		body    interface{}
+		want    string
+		wantErr func(error) bool
+	}{
+		"200": {status: http.StatusOK, body: item{Name: "ok"}, want: "ok"},
+		"202 with a body": {
+			status: http.StatusAccepted, body: item{Name: "queued"}, want: "queued",
+		},
+		"202 without a body": {
+			status:  http.StatusAccepted,
+			wantErr: func(err error) bool { return errors.Is(err, ErrAccepted) },
+		},
+		"204": {status: http.StatusNoContent},
+		"404": {
+			status: http.StatusNotFound, body: "no such item",
+			wantErr: IsNotFound,
+		},
+		"500": {
+			status: http.StatusInternalServerError, body: "boom",
+			wantErr: func(err error) bool { return IsStatus(err, http.StatusInternalServerError) },
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+				if test.body == nil {
+					w.WriteHeader(test.status)
+					return
+				}
+				writeJSON(w, test.status, test.body)
+			})
+
+			result, status, err := request[item](context.Background(), c, http.MethodGet, "/api/v1/items/1", nil)
+			if status != test.status {
+				t.Errorf("status = %d, want %d", status, test.status)
+			}
+			if test.wantErr != nil {
+				if err == nil || !test.wantErr(err) {
+					t.Fatalf("err = %v, want a matching error", err)
+				}
+				if result != nil {
+					t.Errorf("result = %+v, want nil alongside the error", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Name != test.want {
+				t.Errorf("name = %q, want %q", result.Name, test.want)
+			}
+		})
+	}
+}
+
+func TestRequestAPIError(t *testing.T) {
+	c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusNotFound)
+	})
+
+	_, err := c.requestNoContent(context.Background(), http.MethodDelete, "/api/v1/items/1", nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want an *APIError", err)
+	}
+	if apiErr.Method != http.MethodDelete || apiErr.Path != "/api/v1/items/1" {
+		t.Errorf("APIError is for %s %s, want DELETE /api/v1/items/1", apiErr.Method, apiErr.Path)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := map[string]struct {
+		value string
+		min   time.Duration
+		max   time.Duration
+	}{
+		"absent":         {},
+		"seconds":        {value: "7", min: 7 * time.Second, max: 7 * time.Second},
+		"zero seconds":   {value: "0"},
+		"negative":       {value: "-5"},
+		"http date":      {value: time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), min: 58 * time.Second, max: time.Minute},
+		"past http date": {value: time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)},
+		"invalid":        {value: "soon"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			if test.value != "" {
+				header.Set("Retry-After", test.value)
+			}
+			if got := parseRetryAfter(header); got < test.min || got > test.max {
+				t.Errorf("parseRetryAfter(%q) = %v, want between %v and %v", test.value, got, test.min, test.max)
+			}
+		})
+	}
+}
+
+// TestStopAbortsPolling stops the provider while an ARM poll waits for its next attempt
+func TestStopAbortsPolling(t *testing.T) {
+	stop, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, err := NewClient(ClientConfig{
+		AzureClientID: "azure-client", AzureClientSecret: "azure-secret", AzureTenantID: "tenant",
+		StopContext: stop,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.httpClient.Transport = roundTripFunc(func(req *http.Request) *http.Response {
+		if isAzureTokenRequest(req) {
+			return jsonResponse(http.StatusOK, AzureTokenResponse{AccessToken: "azure-token", ExpiresIn: 3600})
+		}
+		// Not there yet, and shutting down before the next attempt
+		cancel()
+		return jsonResponse(http.StatusOK, AzureARMSubscriptionList{})
+	})
+
+	start := time.Now()
+	_, err = c.WaitForAzureSubscription(context.Background(), "app-prod", time.Hour)
+	if !errors.Is(err, ErrStopped) {
+		t.Errorf("err = %v, want the poll stopped", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("returned after %v, want promptly", elapsed)
+	}
+}
+
+// TestStopAbortsRequests stops the provider while a Cloud-iQ request is in flight
+func TestStopAbortsRequests(t *testing.T) {
+	stop, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := newTestClient(t, ClientConfig{StopContext: stop}, func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	})
+
+	start := time.Now()
+	_, err := c.WaitForSubscriptionStatus(context.Background(), testPlanID, 42, []string{"Active"}, time.Hour)
+	if err == nil {
+		t.Error("expected the request to be aborted")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("returned after %v, want promptly", elapsed)
+	}
+}
+
+func TestOrganizationHeader(t *testing.T) {
+	tests := map[string]struct {
+		header string
+		want   string
+	}{
+		"default header": {header: DefaultOrganizationHeader, want: "4051878"},
+		"custom header":  {header: "X-Crayon-Organization", want: "4051878"},
+		"disabled":       {},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var requests int
+			c := newTestClient(t, ClientConfig{OrganizationID: 4051878, OrganizationHeader: test.header}, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if test.header != "" {
+					if got := r.Header.Get(test.header); got != test.want {
+						t.Errorf("%s %s: %s = %q, want %q", r.Method, r.URL.Path, test.header, got, test.want)
+					}
+				}
+				if got := r.Header.Get(DefaultOrganizationHeader); test.header == "" && got != "" {
+					t.Errorf("%s %s: %s = %q with the header disabled", r.Method, r.URL.Path, DefaultOrganizationHeader, got)
+				}
+				switch r.URL.Path {
+				case "/api/v1/CustomerTenants":
+					if got := r.URL.Query().Get("OrganizationId"); got != "4051878" {
+						t.Errorf("OrganizationId = %q, want the query parameter kept", got)
+					}
+					writeJSON(w, http.StatusOK, map[string]interface{}{"Items": []CustomerTenant{}})
+				default:
+					writeJSON(w, http.StatusOK, AzureSubscription{ID: 42})
+				}
+			})
+
+			if _, err := c.GetCustomerTenants(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.GetAzureSubscription(context.Background(), testPlanID, 42); err != nil {
+				t.Fatal(err)
+			}
+			if requests != 2 {
+				t.Errorf("made %d requests, want 2", requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Consumption represents the consumption (cost) of a subscription for a billing period
+type Consumption struct {
+	Total    float64 `json:"Total"`
+	Currency string  `json:"Currency"`
+}
+
+// PlanConsumption is the consumption aggregated across all subscriptions of an Azure Plan
+type PlanConsumption struct {
+	Total    float64
+	Currency string
+	// SubscriptionCount is the number of subscriptions with consumption data
+	SubscriptionCount int
+	// MissingCount is the number of subscriptions whose consumption could not be retrieved
+	MissingCount int
+}
+
+// GetAzureSubscriptionConsumption retrieves the consumption of a subscription for a period (YYYY-MM)
+func (c *Client) GetAzureSubscriptionConsumption(ctx context.Context, azurePlanID, subscriptionID int, period string) (*Consumption, error) {
+	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions/%d/consumption?period=%s", azurePlanID, subscriptionID, url.QueryEscape(period))
+
+	result, _, err := request[Consumption](ctx, c, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetAzurePlanConsumption aggregates the consumption of every subscription in an Azure Plan
+// for a period (YYYY-MM). Subscriptions without consumption data are counted as missing
+// rather than failing the whole aggregation.
+func (c *Client) GetAzurePlanConsumption(ctx context.Context, azurePlanID int, period string) (*PlanConsumption, error) {
+	subs, err := c.GetAzureSubscriptions(ctx, azurePlanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+
+	results := make([]*Consumption, len(subs))
+	err = FanOut(ctx, len(subs), defaultConcurrency, func(i int) {
+		consumption, err := c.GetAzureSubscriptionConsumption(ctx, azurePlanID, subs[i].ID, period)
+		if err != nil {
+			tflog.Warn(ctx, "No consumption data for subscription", map[string]interface{}{
+				"subscription_id":   subs[i].ID,
+				"subscription_name": subs[i].FriendlyName,
+				"error":             err.Error(),
+			})
+			return
+		}
+		results[i] = consumption
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	total := &PlanConsumption{}
+	for _, consumption := range results {
+		if consumption == nil {
+			total.MissingCount++
+			continue
+		}
+		if total.Currency == "" {
+			total.Currency = consumption.Currency
+		} else if consumption.Currency != "" && consumption.Currency != total.Currency {
+			return nil, fmt.Errorf("subscriptions in Azure Plan %d report mixed currencies (%s, %s)", azurePlanID, total.Currency, consumption.Currency)
+		}
+		total.Total += consumption.Total
+		total.SubscriptionCount++
+	}
+
+	return total, nil
+}
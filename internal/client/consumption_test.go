@@ -0,0 +1,101 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// consumptionHandler serves testSubscriptions(n) and their consumption for 2024-05, in the currency
+// of currencies[id] (EUR if unset). Subscriptions without a total have no consumption data.
+func consumptionHandler(t *testing.T, n int, totals map[int]float64, currencies map[int]string) http.HandlerFunc {
+	listPath := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions", testPlanID)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == listPath {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"Items": testSubscriptions(n), "TotalHits": n})
+			return
+		}
+		var id int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(r.URL.Path, listPath), "/%d/consumption", &id); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		if period := r.URL.Query().Get("period"); period != "2024-05" {
+			t.Errorf("period = %q, want 2024-05", period)
+		}
+		total, ok := totals[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		currency := currencies[id]
+		if currency == "" {
+			currency = "EUR"
+		}
+		writeJSON(w, http.StatusOK, Consumption{Total: total, Currency: currency})
+	}
+}
+
+func TestGetAzurePlanConsumption(t *testing.T) {
+	tests := map[string]struct {
+		subs       int
+		totals     map[int]float64
+		currencies map[int]string
+		want       PlanConsumption
+		wantErr    string
+	}{
+		"all subscriptions": {
+			subs:   3,
+			totals: map[int]float64{1: 10.5, 2: 20, 3: 0.25},
+			want:   PlanConsumption{Total: 30.75, Currency: "EUR", SubscriptionCount: 3},
+		},
+		"missing data": {
+			subs:   4,
+			totals: map[int]float64{1: 10.5, 3: 4.5},
+			want:   PlanConsumption{Total: 15, Currency: "EUR", SubscriptionCount: 2, MissingCount: 2},
+		},
+		"no subscriptions": {
+			want: PlanConsumption{},
+		},
+		"mixed currencies": {
+			subs:       2,
+			totals:     map[int]float64{1: 1, 2: 2},
+			currencies: map[int]string{2: "USD"},
+			wantErr:    "mixed currencies",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClient(t, ClientConfig{}, consumptionHandler(t, test.subs, test.totals, test.currencies))
+
+			got, err := c.GetAzurePlanConsumption(context.Background(), testPlanID, "2024-05")
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Fatalf("err = %v, want %q", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != test.want {
+				t.Errorf("got %+v, want %+v", *got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetAzurePlanConsumptionListFails(t *testing.T) {
+	c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+
+	if _, err := c.GetAzurePlanConsumption(context.Background(), testPlanID, "2024-05"); err == nil {
+		t.Error("expected an error when the subscriptions can't be listed")
+	}
+}
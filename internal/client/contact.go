@@ -0,0 +1,76 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Contact is a person Cloud-iQ records for a subscription. API versions report contacts either
+// as a plain string (a name or email address) or as an object with a name and email address.
+type Contact struct {
+	Name  string
+	Email string
+}
+
+// contactObject is the object form of a Contact, with the key variants seen in API responses
+type contactObject struct {
+	Name         string `json:"Name"`
+	DisplayName  string `json:"DisplayName"`
+	FullName     string `json:"FullName"`
+	Email        string `json:"Email"`
+	EmailAddress string `json:"EmailAddress"`
+}
+
+func (c *Contact) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		value = strings.TrimSpace(value)
+		if strings.Contains(value, "@") {
+			*c = Contact{Email: value}
+		} else {
+			*c = Contact{Name: value}
+		}
+		return nil
+	}
+
+	var object contactObject
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	*c = Contact{
+		Name:  firstNonEmpty(object.Name, object.DisplayName, object.FullName),
+		Email: firstNonEmpty(object.Email, object.EmailAddress),
+	}
+	return nil
+}
+
+// IsEmpty reports whether c is nil or has neither a name nor an email address
+func (c *Contact) IsEmpty() bool {
+	return c == nil || (c.Name == "" && c.Email == "")
+}
+
+// String formats the contact as "Name <email>", or just whichever of the two is known
+func (c *Contact) String() string {
+	switch {
+	case c.IsEmpty():
+		return ""
+	case c.Name == "":
+		return c.Email
+	case c.Email == "":
+		return c.Name
+	}
+	return c.Name + " <" + c.Email + ">"
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestContactUnmarshal(t *testing.T) {
+	tests := map[string]struct {
+		body string
+		want Contact
+	}{
+		"name":                 {body: `"Jane Doe"`, want: Contact{Name: "Jane Doe"}},
+		"email":                {body: `" jane@contoso.com "`, want: Contact{Email: "jane@contoso.com"}},
+		"object":               {body: `{"Name": "Jane Doe", "Email": "jane@contoso.com"}`, want: Contact{Name: "Jane Doe", Email: "jane@contoso.com"}},
+		"object with variants": {body: `{"displayName": "Jane Doe", "emailAddress": "jane@contoso.com"}`, want: Contact{Name: "Jane Doe", Email: "jane@contoso.com"}},
+		"object with a name":   {body: `{"FullName": "Jane Doe"}`, want: Contact{Name: "Jane Doe"}},
+		"empty object":         {body: `{}`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got Contact
+			if err := json.Unmarshal([]byte(test.body), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+
+	var contact Contact
+	if err := json.Unmarshal([]byte(`42`), &contact); err == nil {
+		t.Error("a number unmarshalled as a contact")
+	}
+}
+
+func TestContactString(t *testing.T) {
+	tests := []struct {
+		contact *Contact
+		want    string
+	}{
+		{contact: nil, want: ""},
+		{contact: &Contact{}, want: ""},
+		{contact: &Contact{Name: "Jane Doe"}, want: "Jane Doe"},
+		{contact: &Contact{Email: "jane@contoso.com"}, want: "jane@contoso.com"},
+		{contact: &Contact{Name: "Jane Doe", Email: "jane@contoso.com"}, want: "Jane Doe <jane@contoso.com>"},
+	}
+	for _, test := range tests {
+		if got := test.contact.String(); got != test.want {
+			t.Errorf("%+v.String() = %q, want %q", test.contact, got, test.want)
+		}
+	}
+}
+
+func TestAzureSubscriptionContacts(t *testing.T) {
+	tests := map[string]struct {
+		body                       string
+		wantManager, wantTechnical string
+	}{
+		"canonical names": {
+			body:        `{"AccountManager": {"Name": "Jane Doe", "Email": "jane@contoso.com"}, "TechnicalContact": "ops@contoso.com"}`,
+			wantManager: "Jane Doe <jane@contoso.com>", wantTechnical: "ops@contoso.com",
+		},
+		"alternate names": {
+			body:        `{"manager": "Jane Doe", "techContact": {"displayName": "Ops Team"}}`,
+			wantManager: "Jane Doe", wantTechnical: "Ops Team",
+		},
+		"empty canonical names": {
+			body:        `{"AccountManager": {}, "AccountManagerContact": "Jane Doe", "TechnicalContact": ""}`,
+			wantManager: "Jane Doe",
+		},
+		"not reported": {body: `{"Id": 42}`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var sub AzureSubscription
+			if err := json.Unmarshal([]byte(test.body), &sub); err != nil {
+				t.Fatal(err)
+			}
+			if got := sub.AccountManager.String(); got != test.wantManager {
+				t.Errorf("account manager = %q, want %q", got, test.wantManager)
+			}
+			if got := sub.TechnicalContact.String(); got != test.wantTechnical {
+				t.Errorf("technical contact = %q, want %q", got, test.wantTechnical)
+			}
+		})
+	}
+}
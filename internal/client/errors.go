@@ -0,0 +1,70 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// APIError is a non-2xx response of the Crayon API or Azure ARM. Use errors.As, or helpers such as
+// IsNotFound, to tell responses apart instead of matching error strings.
+type APIError struct {
+	StatusCode int
+	Method     string
+	Path       string // Request path, without the query
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	if e.Method == "" {
+		return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("API error (status %d) for %s %s: %s", e.StatusCode, e.Method, e.Path, e.Body)
+}
+
+// newAPIError returns the *APIError of a non-2xx response whose body was read
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.Path = resp.Request.URL.Path
+	}
+	return apiErr
+}
+
+// IsStatus reports whether err is, or wraps, an *APIError with one of the given status codes
+func IsStatus(err error, statuses ...int) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, status := range statuses {
+		if apiErr.StatusCode == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNotFound reports whether err is an API error for a resource that doesn't exist (HTTP 404)
+func IsNotFound(err error) bool {
+	return IsStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether err is an API error for a missing or rejected token (HTTP 401)
+func IsUnauthorized(err error) bool {
+	return IsStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden reports whether err is an API error for a request the caller isn't allowed to make (HTTP 403)
+func IsForbidden(err error) bool {
+	return IsStatus(err, http.StatusForbidden)
+}
+
+// IsConflict reports whether err is an API error for a request conflicting with the resource's state (HTTP 409)
+func IsConflict(err error) bool {
+	return IsStatus(err, http.StatusConflict)
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Formats supported by ExportSubscriptions
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+)
+
+// ErrUnsupportedExportFormat is returned by ExportSubscriptions for formats other than CSV and JSON
+var ErrUnsupportedExportFormat = errors.New("unsupported export format")
+
+// exportColumns are the CSV header of ExportSubscriptions, in the order of ExportRecord's fields
+var exportColumns = []string{"id", "subscription_id", "name", "status", "azure_plan_id", "created"}
+
+// ExportRecord is a subscription as written by ExportSubscriptions
+type ExportRecord struct {
+	ID             int    `json:"id"`
+	SubscriptionID string `json:"subscription_id"`
+	Name           string `json:"name"`
+	Status         string `json:"status"`
+	AzurePlanID    int    `json:"azure_plan_id"`
+	// Created is RFC 3339 when the API's format is recognized, and verbatim otherwise
+	Created string `json:"created"`
+}
+
+// newExportRecord returns the export record of a subscription listed under azurePlanID
+func newExportRecord(sub AzureSubscription, azurePlanID int) ExportRecord {
+	created, ok := BillingTimestamp(sub.CreatedDate)
+	if !ok {
+		created = sub.CreatedDate
+	}
+	if sub.AzurePlanID == 0 {
+		sub.AzurePlanID = azurePlanID
+	}
+	return ExportRecord{
+		ID:             sub.ID,
+		SubscriptionID: sub.SubscriptionID,
+		Name:           sub.FriendlyName,
+		Status:         sub.Status,
+		AzurePlanID:    sub.AzurePlanID,
+		Created:        created,
+	}
+}
+
+// csv returns the record as a CSV row in the order of exportColumns
+func (r ExportRecord) csv() []string {
+	return []string{strconv.Itoa(r.ID), r.SubscriptionID, r.Name, r.Status, strconv.Itoa(r.AzurePlanID), r.Created}
+}
+
+// ExportSubscriptions writes an inventory of the subscriptions of the given Azure Plans to w, as
+// CSV with a header row or as a JSON array of ExportRecord. Without Azure Plans, every Azure Plan
+// of the configured organization's customer tenants is exported. Subscriptions are written page
+// by page as they are listed, so the inventory is never held in memory; on error, w holds a
+// partial export.
+func (c *Client) ExportSubscriptions(ctx context.Context, w io.Writer, format string, azurePlanIDs ...int) error {
+	var write func(ExportRecord) error
+	var finish func() error
+	switch format {
+	case ExportFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(exportColumns); err != nil {
+			return err
+		}
+		write = func(record ExportRecord) error {
+			return writer.Write(record.csv())
+		}
+		finish = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	case ExportFormatJSON:
+		if _, err := io.WriteString(w, "["); err != nil {
+			return err
+		}
+		separator := ""
+		write = func(record ExportRecord) error {
+			data, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(w, "%s\n%s", separator, data)
+			separator = ","
+			return err
+		}
+		finish = func() error {
+			_, err := io.WriteString(w, "\n]\n")
+			return err
+		}
+	default:
+		return fmt.Errorf("%w %q, must be %s or %s", ErrUnsupportedExportFormat, format, ExportFormatCSV, ExportFormatJSON)
+	}
+
+	if len(azurePlanIDs) == 0 {
+		planIDs, err := c.organizationAzurePlanIDs(ctx)
+		if err != nil {
+			return err
+		}
+		azurePlanIDs = planIDs
+	}
+
+	for _, azurePlanID := range azurePlanIDs {
+		err := c.GetAzureSubscriptionsFunc(ctx, azurePlanID, func(sub AzureSubscription) error {
+			return write(newExportRecord(sub, azurePlanID))
+		})
+		if err != nil {
+			return fmt.Errorf("failed to export the subscriptions of Azure Plan %d: %w", azurePlanID, err)
+		}
+	}
+
+	return finish()
+}
+
+// organizationAzurePlanIDs returns the Azure Plans of the configured organization's customer
+// tenants. Tenants without an Azure Plan are skipped.
+func (c *Client) organizationAzurePlanIDs(ctx context.Context) ([]int, error) {
+	tenants, err := c.GetCustomerTenants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customer tenants: %w", err)
+	}
+
+	var planIDs []int
+	for _, tenant := range tenants {
+		plan, err := c.GetAzurePlan(ctx, tenant.ID)
+		if err != nil {
+			tflog.Warn(ctx, "Skipping customer tenant in the export", map[string]interface{}{
+				"customer_tenant_id":   tenant.ID,
+				"customer_tenant_name": tenant.Name,
+				"error":                err.Error(),
+			})
+			continue
+		}
+		planIDs = append(planIDs, plan.ID)
+	}
+	return planIDs, nil
+}
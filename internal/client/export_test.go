@@ -0,0 +1,135 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// exportHandler serves the subscriptions of Azure Plans 100 and 200 and the empty Azure Plan 400,
+// the customer tenants 1 to 3 of organization 4051878 and the Azure Plans of tenants 1 and 2
+func exportHandler() http.HandlerFunc {
+	plans := map[string]json.RawMessage{
+		"/api/v1/azureplans/100/azuresubscriptions": json.RawMessage(`[
+			{"Id": 1, "SubscriptionId": "5e98e158-0001", "FriendlyName": "app-prod", "Status": "Active", "CreatedDate": "2024-05-01T10:30:00"},
+			{"Id": 2, "AzureSubscriptionId": "5e98e158-0002", "Name": "data, analytics", "Status": "Cancelled", "AzurePlanId": 100, "CreationDate": "2024-05-02"}
+		]`),
+		"/api/v1/azureplans/200/azuresubscriptions": json.RawMessage(`[
+			{"Id": 3, "FriendlyName": "sandbox \"eu\"", "Status": "Pending", "Created": "last Tuesday"}
+		]`),
+		"/api/v1/azureplans/400/azuresubscriptions": json.RawMessage(`[]`),
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case plans[r.URL.Path] != nil:
+			var items []json.RawMessage
+			if err := json.Unmarshal(plans[r.URL.Path], &items); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"Items": items, "TotalHits": len(items)})
+		case r.URL.Path == "/api/v1/CustomerTenants":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"Items": []CustomerTenant{{ID: 1, Name: "Contoso"}, {ID: 2, Name: "Fabrikam"}, {ID: 3, Name: "No Azure"}}})
+		case r.URL.Path == "/api/v1/CustomerTenants/1/azureplan":
+			writeJSON(w, http.StatusOK, AzurePlan{ID: 100})
+		case r.URL.Path == "/api/v1/CustomerTenants/2/azureplan":
+			writeJSON(w, http.StatusOK, AzurePlan{ID: 200})
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+func TestExportSubscriptionsCSV(t *testing.T) {
+	c := newTestClient(t, ClientConfig{}, exportHandler())
+
+	var out bytes.Buffer
+	if err := c.ExportSubscriptions(context.Background(), &out, ExportFormatCSV, 100, 200); err != nil {
+		t.Fatal(err)
+	}
+	want := "id,subscription_id,name,status,azure_plan_id,created\n" +
+		"1,5e98e158-0001,app-prod,Active,100,2024-05-01T10:30:00Z\n" +
+		"2,5e98e158-0002,\"data, analytics\",Cancelled,100,2024-05-02T00:00:00Z\n" +
+		"3,,\"sandbox \"\"eu\"\"\",Pending,200,last Tuesday\n"
+	if got := out.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestExportSubscriptionsJSON(t *testing.T) {
+	c := newTestClient(t, ClientConfig{}, exportHandler())
+
+	var out bytes.Buffer
+	if err := c.ExportSubscriptions(context.Background(), &out, ExportFormatJSON, 100, 200); err != nil {
+		t.Fatal(err)
+	}
+	var got []ExportRecord
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("export is not valid JSON: %v\n%s", err, out.String())
+	}
+	want := []ExportRecord{
+		{ID: 1, SubscriptionID: "5e98e158-0001", Name: "app-prod", Status: "Active", AzurePlanID: 100, Created: "2024-05-01T10:30:00Z"},
+		{ID: 2, SubscriptionID: "5e98e158-0002", Name: "data, analytics", Status: "Cancelled", AzurePlanID: 100, Created: "2024-05-02T00:00:00Z"},
+		{ID: 3, Name: `sandbox "eu"`, Status: "Pending", AzurePlanID: 200, Created: "last Tuesday"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// An export without subscriptions is still a valid, empty array
+	out.Reset()
+	if err := c.ExportSubscriptions(context.Background(), &out, ExportFormatJSON, 400); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil || len(got) != 0 {
+		t.Fatalf("export = %s, want an empty JSON array", out.String())
+	}
+}
+
+func TestExportSubscriptionsOrganization(t *testing.T) {
+	c := newTestClient(t, ClientConfig{OrganizationID: 4051878}, exportHandler())
+
+	var out bytes.Buffer
+	if err := c.ExportSubscriptions(context.Background(), &out, ExportFormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	var got []ExportRecord
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	for _, record := range got {
+		ids = append(ids, record.ID)
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2, 3}) {
+		t.Errorf("exported subscriptions %v, want those of both tenants with an Azure Plan", ids)
+	}
+}
+
+func TestExportSubscriptionsErrors(t *testing.T) {
+	c := newTestClient(t, ClientConfig{}, exportHandler())
+
+	var out bytes.Buffer
+	if err := c.ExportSubscriptions(context.Background(), &out, "xlsx", 100); !errors.Is(err, ErrUnsupportedExportFormat) {
+		t.Errorf("err = %v, want %v", err, ErrUnsupportedExportFormat)
+	}
+	if out.Len() != 0 {
+		t.Errorf("wrote %q for an unsupported format, want nothing", out.String())
+	}
+
+	err := c.ExportSubscriptions(context.Background(), &out, ExportFormatCSV, 100, 300)
+	if !IsNotFound(err) {
+		t.Errorf("err = %v, want the missing Azure Plan reported", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "Azure Plan 300") {
+		t.Errorf("err = %v, want it to name Azure Plan 300", err)
+	}
+}
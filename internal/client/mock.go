@@ -0,0 +1,327 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrMockMode is returned for operations the mock mode fake can't serve, such as Azure ARM calls
+var ErrMockMode = errors.New("not available in mock mode")
+
+// Fixtures served by the mock mode fake
+const (
+	// MockCustomerTenantID is the customer tenant every Azure Plan belongs to in mock mode
+	MockCustomerTenantID = 1
+
+	// mockTimestamp is reported as the creation and modification time of every subscription
+	mockTimestamp = "2024-01-01T00:00:00Z"
+)
+
+// mockRoute matches the Crayon API paths the fake serves. The first group is the resource and
+// the remaining groups its IDs and action, if any.
+var (
+	mockSubscriptionsRoute = regexp.MustCompile(`^/api/v1/azureplans/(\d+)/azuresubscriptions(?:/(validate|\d+)(?:/(\w+))?)?$`)
+	mockAzurePlanRoute     = regexp.MustCompile(`^/api/v1/azureplans/(\d+)(?:/(synchronize))?$`)
+	mockTenantRoute        = regexp.MustCompile(`^/api/v1/customertenants(?:/(\d+)(?:/(azureplan))?)?$`)
+	mockOrganizationRoute  = regexp.MustCompile(`^/api/v1/organizations(?:/(\d+)/(synchronize))?$`)
+)
+
+// mockTransport is an in-memory fake of the Crayon API, used instead of the network in mock
+// mode so configurations can be planned and applied without credentials. Responses are
+// deterministic: subscriptions get sequential IDs starting at 1001 and GUIDs derived from them.
+// Its data only lasts for the provider process, i.e. a single Terraform command; subscriptions
+// it doesn't know are reported as active fixtures.
+type mockTransport struct {
+	organizationID int64
+
+	mu            sync.Mutex
+	nextID        int
+	subscriptions map[int]*AzureSubscription
+}
+
+func newMockTransport(organizationID int64) *mockTransport {
+	return &mockTransport{
+		organizationID: organizationID,
+		nextID:         1001,
+		subscriptions:  map[int]*AzureSubscription{},
+	}
+}
+
+func (m *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status, result := m.serve(req, body)
+	return mockResponse(req, status, result), nil
+}
+
+// serve routes a request to the fake and returns the status and the value to encode as body
+func (m *mockTransport) serve(req *http.Request, body []byte) (int, interface{}) {
+	path := strings.ToLower(strings.TrimSuffix(req.URL.Path, "/"))
+
+	switch {
+	case !strings.HasPrefix(path, "/api/v1/"):
+		// Azure AD and ARM aren't faked
+		return http.StatusNotFound, mockError(req)
+	case path == "/api/v1/connect/token" && req.Method == http.MethodPost:
+		return http.StatusOK, TokenResponse{AccessToken: "mock-token", TokenType: "Bearer", ExpiresIn: 3600}
+	case path == "/api/v1/version" && req.Method == http.MethodGet:
+		return http.StatusOK, APIVersionInfo{APIVersion: strconv.Itoa(SupportedAPIMajorVersion)}
+	}
+
+	if match := mockSubscriptionsRoute.FindStringSubmatch(path); match != nil {
+		azurePlanID, _ := strconv.Atoi(match[1])
+		return m.serveSubscriptions(req, body, azurePlanID, match[2], match[3])
+	}
+	if match := mockAzurePlanRoute.FindStringSubmatch(path); match != nil {
+		azurePlanID, _ := strconv.Atoi(match[1])
+		switch {
+		case match[2] == "synchronize" && req.Method == http.MethodPost:
+			return http.StatusNoContent, nil
+		case match[2] == "" && req.Method == http.MethodGet:
+			return http.StatusOK, m.azurePlan(azurePlanID)
+		}
+	}
+	if match := mockTenantRoute.FindStringSubmatch(path); match != nil && req.Method == http.MethodGet {
+		tenantID, _ := strconv.Atoi(match[1])
+		switch {
+		case match[1] == "":
+			return http.StatusOK, Page[CustomerTenant]{Items: []CustomerTenant{m.customerTenant(MockCustomerTenantID)}, TotalCount: 1}
+		case match[2] == "azureplan":
+			return http.StatusOK, m.azurePlan(tenantID)
+		default:
+			return http.StatusOK, m.customerTenant(tenantID)
+		}
+	}
+	if match := mockOrganizationRoute.FindStringSubmatch(path); match != nil {
+		switch {
+		case match[2] == "synchronize" && req.Method == http.MethodPost:
+			return http.StatusNoContent, nil
+		case match[1] == "" && req.Method == http.MethodGet:
+			return http.StatusOK, Page[OrganizationReference]{Items: []OrganizationReference{m.organization()}, TotalCount: 1}
+		}
+	}
+
+	return http.StatusNotFound, mockError(req)
+}
+
+// serveSubscriptions serves the subscriptions of an Azure Plan. target is "validate", a
+// subscription ID or empty for the collection, and action the subscription sub-resource.
+func (m *mockTransport) serveSubscriptions(req *http.Request, body []byte, azurePlanID int, target, action string) (int, interface{}) {
+	// Like the real API, Azure Plan 0 never exists; Capabilities probes permission to create against it
+	if azurePlanID == 0 {
+		return http.StatusBadRequest, map[string]string{"error": "the azure plan does not exist"}
+	}
+
+	switch {
+	case target == "" && req.Method == http.MethodGet:
+		return http.StatusOK, m.listSubscriptions(req, azurePlanID)
+	case target == "" && req.Method == http.MethodPost:
+		return m.createSubscription(body, azurePlanID, false)
+	case target == "validate" && req.Method == http.MethodPost:
+		return m.createSubscription(body, azurePlanID, true)
+	}
+
+	id, _ := strconv.Atoi(target)
+	sub := m.subscription(azurePlanID, id)
+
+	var fields map[string]json.RawMessage
+	_ = json.Unmarshal(body, &fields)
+	field := func(key string, v interface{}) bool {
+		raw, ok := fields[key]
+		return ok && json.Unmarshal(raw, v) == nil
+	}
+
+	switch {
+	case action == "" && req.Method == http.MethodGet:
+		result := *sub
+		if !strings.Contains(strings.ToLower(req.URL.Query().Get("$expand")), "tags") {
+			result.Tags = nil
+		}
+		return http.StatusOK, result
+	case action == "tags" && req.Method == http.MethodGet:
+		return http.StatusOK, sub.Tags
+	case action == "tags" && req.Method == http.MethodPut:
+		tags := map[string]string{}
+		if err := json.Unmarshal(body, &tags); err != nil {
+			return http.StatusBadRequest, map[string]string{"error": "tags must be an object of strings"}
+		}
+		sub.Tags = tags
+		return http.StatusNoContent, nil
+	case action == "rename" && req.Method == http.MethodPatch:
+		var name string
+		if !field("name", &name) || name == "" {
+			return http.StatusBadRequest, map[string]string{"error": "name is required"}
+		}
+		sub.FriendlyName = name
+		return http.StatusOK, sub
+	case action == "partnerofrecord" && req.Method == http.MethodPatch:
+		field("partnerId", &sub.PartnerOfRecord)
+		return http.StatusNoContent, nil
+	case action == "quantity" && req.Method == http.MethodPatch:
+		field("quantity", &sub.Quantity)
+		return http.StatusNoContent, nil
+	case action == "autorenew" && req.Method == http.MethodPatch:
+		// Azure Plan subscriptions are pay-as-you-go and have no term to renew
+		return http.StatusConflict, map[string]string{"error": "auto-renewal does not apply to this offer"}
+	case action == "cancel" && req.Method == http.MethodPost:
+		sub.Status = "Cancelled"
+		return http.StatusNoContent, nil
+	case action == "suspend" && req.Method == http.MethodPost:
+		sub.Status = "Suspended"
+		return http.StatusNoContent, nil
+	case (action == "enable" || action == "reactivate") && req.Method == http.MethodPost:
+		if strings.EqualFold(sub.Status, "active") {
+			return http.StatusConflict, map[string]string{"error": "the subscription is already active"}
+		}
+		sub.Status = "Active"
+		return http.StatusNoContent, nil
+	}
+
+	return http.StatusNotFound, mockError(req)
+}
+
+// createSubscription creates a subscription from a create request body, or only validates it
+func (m *mockTransport) createSubscription(body []byte, azurePlanID int, validateOnly bool) (int, interface{}) {
+	var createReq CreateAzureSubscriptionRequest
+	if err := json.Unmarshal(body, &createReq); err != nil || createReq.Name == "" {
+		return http.StatusBadRequest, map[string]string{"error": "name is required"}
+	}
+	if validateOnly {
+		return http.StatusNoContent, nil
+	}
+
+	id := m.nextID
+	m.nextID++
+	sub := m.newSubscription(azurePlanID, id, createReq.Name)
+	sub.Quantity = createReq.Quantity
+	sub.SupportPlan = createReq.SupportPlan
+	if createReq.Tags != nil {
+		sub.Tags = createReq.Tags
+	}
+	m.subscriptions[id] = sub
+
+	return http.StatusCreated, sub
+}
+
+// listSubscriptions returns the requested page of an Azure Plan's subscriptions, ordered by ID
+func (m *mockTransport) listSubscriptions(req *http.Request, azurePlanID int) Page[AzureSubscription] {
+	var subs []AzureSubscription
+	for _, sub := range m.subscriptions {
+		if sub.AzurePlanID == azurePlanID {
+			listed := *sub
+			listed.Tags = nil
+			subs = append(subs, listed)
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID })
+
+	page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+	pageSize, _ := strconv.Atoi(req.URL.Query().Get("pageSize"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = DefaultPageSize
+	}
+	start := min((page-1)*pageSize, len(subs))
+	end := min(start+pageSize, len(subs))
+
+	return Page[AzureSubscription]{Items: subs[start:end], TotalCount: len(subs)}
+}
+
+// subscription returns a subscription of the fake, creating an active fixture for unknown IDs
+// so state from an earlier Terraform command can still be refreshed
+func (m *mockTransport) subscription(azurePlanID, id int) *AzureSubscription {
+	if sub, ok := m.subscriptions[id]; ok {
+		return sub
+	}
+	sub := m.newSubscription(azurePlanID, id, fmt.Sprintf("mock-subscription-%d", id))
+	m.subscriptions[id] = sub
+	return sub
+}
+
+func (m *mockTransport) newSubscription(azurePlanID, id int, name string) *AzureSubscription {
+	return &AzureSubscription{
+		ID:             id,
+		FriendlyName:   name,
+		SubscriptionID: fmt.Sprintf("00000000-0000-4000-8000-%012d", id),
+		Status:         "Active",
+		AzurePlanID:    azurePlanID,
+		LastModified:   mockTimestamp,
+		CreatedDate:    mockTimestamp,
+		Tags:           map[string]string{},
+	}
+}
+
+func (m *mockTransport) organization() OrganizationReference {
+	return OrganizationReference{ID: m.organizationID, Name: "Mock Organization"}
+}
+
+func (m *mockTransport) customerTenant(id int) CustomerTenant {
+	organization := m.organization()
+	return CustomerTenant{
+		ID:           id,
+		Domain:       fmt.Sprintf("mock-tenant-%d.onmicrosoft.com", id),
+		Name:         fmt.Sprintf("Mock Tenant %d", id),
+		Organization: &organization,
+	}
+}
+
+func (m *mockTransport) azurePlan(id int) AzurePlan {
+	return AzurePlan{
+		ID:               id,
+		CustomerTenantID: MockCustomerTenantID,
+		SubscriptionID:   fmt.Sprintf("mock-azure-plan-%d", id),
+	}
+}
+
+// mockError is the body of requests the fake doesn't serve
+func mockError(req *http.Request) map[string]string {
+	return map[string]string{"error": fmt.Sprintf("%s %s is %v", req.Method, req.URL.Path, ErrMockMode)}
+}
+
+// mockResponse encodes result as the JSON body of a response with the given status
+func mockResponse(req *http.Request, status int, result interface{}) *http.Response {
+	var body []byte
+	if status != http.StatusNoContent {
+		body, _ = json.Marshal(result)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
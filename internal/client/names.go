@@ -0,0 +1,48 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDuplicateSubscriptionName is returned when two subscriptions with the same name are created
+// under the same Azure Plan in one operation. Pending subscriptions are reconciled by name, so
+// same-named subscriptions can't be told apart.
+var ErrDuplicateSubscriptionName = errors.New("subscription name is already being created under this Azure Plan")
+
+// ReserveSubscriptionName records that this provider instance creates a subscription with the
+// given name under an Azure Plan. Reservations last for the lifetime of the provider process,
+// i.e. a single Terraform operation, unless released by ReleaseSubscriptionName. Names are compared case-insensitively, like Azure does.
+func (c *Client) ReserveSubscriptionName(ctx context.Context, azurePlanID int, name string) error {
+	key := reservedNameKey(azurePlanID, name)
+
+	c.namesMu.Lock()
+	defer c.namesMu.Unlock()
+
+	if c.reservedNames == nil {
+		c.reservedNames = map[string]bool{}
+	}
+	if c.reservedNames[key] {
+		return fmt.Errorf("%w: %q", ErrDuplicateSubscriptionName, name)
+	}
+	c.reservedNames[key] = true
+	return nil
+}
+
+// ReleaseSubscriptionName drops the reservation of a name, e.g. after its create failed, so the
+// create can be retried
+func (c *Client) ReleaseSubscriptionName(azurePlanID int, name string) {
+	c.namesMu.Lock()
+	defer c.namesMu.Unlock()
+	delete(c.reservedNames, reservedNameKey(azurePlanID, name))
+}
+
+// reservedNameKey identifies a name reservation. Names are compared case-insensitively.
+func reservedNameKey(azurePlanID int, name string) string {
+	return fmt.Sprintf("%d/%s", azurePlanID, strings.ToLower(name))
+}
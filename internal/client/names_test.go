@@ -0,0 +1,34 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestReserveSubscriptionName(t *testing.T) {
+	ctx := context.Background()
+	c := &Client{}
+
+	if err := c.ReserveSubscriptionName(ctx, testPlanID, "app-prod"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ReserveSubscriptionName(ctx, testPlanID, "app-prod"); !errors.Is(err, ErrDuplicateSubscriptionName) {
+		t.Errorf("reserving the name twice: %v, want ErrDuplicateSubscriptionName", err)
+	}
+	if err := c.ReserveSubscriptionName(ctx, testPlanID, "App-Prod"); !errors.Is(err, ErrDuplicateSubscriptionName) {
+		t.Errorf("reserving the name in another casing: %v, want ErrDuplicateSubscriptionName", err)
+	}
+	if err := c.ReserveSubscriptionName(ctx, testPlanID+1, "app-prod"); err != nil {
+		t.Errorf("reserving the name under another Azure Plan: %v", err)
+	}
+
+	// A failed create releases the name, so it can be retried
+	c.ReleaseSubscriptionName(testPlanID, "APP-PROD")
+	if err := c.ReserveSubscriptionName(ctx, testPlanID, "app-prod"); err != nil {
+		t.Errorf("reserving the name after releasing it: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// SubscriptionOperation represents the status of an asynchronous Cloud-iQ operation,
+// as returned by the Operation-Location of an accepted (202) create request
+type SubscriptionOperation struct {
+	Status string `json:"status"`
+
+	// PercentComplete is only reported by some Cloud-iQ versions
+	PercentComplete *float64 `json:"percentComplete,omitempty"`
+}
+
+// Progress returns the operation's percent complete (0-100), if the operation reports it
+func (o *SubscriptionOperation) Progress() (int, bool) {
+	if o == nil || o.PercentComplete == nil {
+		return 0, false
+	}
+	progress := int(math.Round(*o.PercentComplete))
+	if progress < 0 {
+		progress = 0
+	}
+	if progress > 100 {
+		progress = 100
+	}
+	return progress, true
+}
+
+// GetSubscriptionOperation fetches the status of an asynchronous operation from its
+// Operation-Location, given either as an absolute URL or a path relative to the base URL
+func (c *Client) GetSubscriptionOperation(ctx context.Context, operationLocation string) (*SubscriptionOperation, error) {
+	path, err := operationPath(operationLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	result, _, err := request[SubscriptionOperation](ctx, c, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetSubscriptionOperationProgress returns the percent complete of an asynchronous operation.
+// ok is false when there is no operation to ask or it doesn't report progress.
+func (c *Client) GetSubscriptionOperationProgress(ctx context.Context, operationLocation string) (progress int, ok bool) {
+	if operationLocation == "" {
+		return 0, false
+	}
+
+	operation, err := c.GetSubscriptionOperation(ctx, operationLocation)
+	if err != nil {
+		tflog.Warn(ctx, "Failed to read provisioning progress", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return 0, false
+	}
+
+	return operation.Progress()
+}
+
+// operationPath converts an Operation-Location header into a request path for doRequest
+func operationPath(operationLocation string) (string, error) {
+	u, err := url.Parse(operationLocation)
+	if err != nil || u.Path == "" {
+		return "", fmt.Errorf("invalid operation location %q", operationLocation)
+	}
+	if u.RawQuery != "" {
+		return u.Path + "?" + u.RawQuery, nil
+	}
+	return u.Path, nil
+}
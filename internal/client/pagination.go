@@ -0,0 +1,80 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// pageItemsKeys are the envelope keys Crayon list endpoints report their items under, in order of preference
+var pageItemsKeys = []string{"Items", "Value", "Data", "Results"}
+
+// pageTotalKeys are the envelope keys Crayon list endpoints report the total number of items under
+var pageTotalKeys = []string{"TotalHits", "TotalCount", "Total", "Count"}
+
+// Page is one page of a Crayon list endpoint. The API isn't consistent across endpoints, e.g.
+// {"Items": [...], "TotalHits": N} versus {"items": [...], "totalCount": N}, so keys are matched
+// case-insensitively against the common variants. A bare JSON array is accepted as a single page.
+type Page[T any] struct {
+	Items      []T
+	TotalCount int
+
+	// totalReported is false if the response didn't report a total
+	totalReported bool
+}
+
+func (p *Page[T]) UnmarshalJSON(data []byte) error {
+	*p = Page[T]{}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &p.Items); err != nil {
+			return err
+		}
+		p.TotalCount = len(p.Items)
+		p.totalReported = true
+		return nil
+	}
+
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return err
+	}
+
+	if raw, key, ok := lookupPageKey(envelope, pageItemsKeys); ok {
+		if err := json.Unmarshal(raw, &p.Items); err != nil {
+			return fmt.Errorf("failed to parse %q of list response: %w", key, err)
+		}
+	}
+	if raw, key, ok := lookupPageKey(envelope, pageTotalKeys); ok {
+		if err := json.Unmarshal(raw, &p.TotalCount); err != nil {
+			return fmt.Errorf("failed to parse %q of list response: %w", key, err)
+		}
+		p.totalReported = true
+	}
+	return nil
+}
+
+// Done reports whether a listing is complete after seen items, given the page that was just read
+// and the requested page size. Without a reported total, a short page marks the end.
+func (p *Page[T]) Done(seen, pageSize int) bool {
+	if len(p.Items) < pageSize {
+		return true
+	}
+	return p.totalReported && seen >= p.TotalCount
+}
+
+// lookupPageKey returns the first of keys present in envelope, ignoring case and null values
+func lookupPageKey(envelope map[string]json.RawMessage, keys []string) (json.RawMessage, string, bool) {
+	for _, key := range keys {
+		for k, raw := range envelope {
+			if strings.EqualFold(k, key) && string(raw) != "null" {
+				return raw, k, true
+			}
+		}
+	}
+	return nil, "", false
+}
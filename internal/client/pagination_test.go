@@ -0,0 +1,225 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+type pageItem struct {
+	ID int `json:"id"`
+}
+
+func TestPageUnmarshalJSON(t *testing.T) {
+	tests := map[string]struct {
+		body         string
+		wantItems    []pageItem
+		wantTotal    int
+		wantReported bool
+		wantErr      bool
+	}{
+		"Items and TotalHits": {
+			body:      `{"Items": [{"id": 1}, {"id": 2}], "TotalHits": 5}`,
+			wantItems: []pageItem{{1}, {2}}, wantTotal: 5, wantReported: true,
+		},
+		"lower case items and totalCount": {
+			body:      `{"items": [{"id": 1}], "totalCount": 3}`,
+			wantItems: []pageItem{{1}}, wantTotal: 3, wantReported: true,
+		},
+		"value and count": {
+			body:      `{"value": [{"id": 1}], "count": 1}`,
+			wantItems: []pageItem{{1}}, wantTotal: 1, wantReported: true,
+		},
+		"Data and Total": {
+			body:      `{"Data": [{"id": 1}], "Total": 8}`,
+			wantItems: []pageItem{{1}}, wantTotal: 8, wantReported: true,
+		},
+		"results": {
+			body:      `{"results": [{"id": 1}]}`,
+			wantItems: []pageItem{{1}},
+		},
+		"preferred key wins": {
+			body:      `{"Data": [{"id": 2}], "Items": [{"id": 1}]}`,
+			wantItems: []pageItem{{1}},
+		},
+		"bare array": {
+			body:      ` [{"id": 1}, {"id": 2}]`,
+			wantItems: []pageItem{{1}, {2}}, wantTotal: 2, wantReported: true,
+		},
+		"null items": {
+			body:      `{"Items": null, "Value": [{"id": 1}], "TotalHits": 1}`,
+			wantItems: []pageItem{{1}}, wantTotal: 1, wantReported: true,
+		},
+		"only null items": {
+			body:      `{"Items": null, "TotalHits": 0}`,
+			wantTotal: 0, wantReported: true,
+		},
+		"missing total": {
+			body:      `{"Items": [{"id": 1}]}`,
+			wantItems: []pageItem{{1}},
+		},
+		"null total": {
+			body:      `{"Items": [{"id": 1}], "TotalHits": null}`,
+			wantItems: []pageItem{{1}},
+		},
+		"malformed items": {
+			body:    `{"Items": {"id": 1}}`,
+			wantErr: true,
+		},
+		"malformed total": {
+			body:    `{"Items": [], "TotalHits": "many"}`,
+			wantErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var page Page[pageItem]
+			err := json.Unmarshal([]byte(test.body), &page)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(page.Items, test.wantItems) {
+				t.Errorf("Items = %v, want %v", page.Items, test.wantItems)
+			}
+			if page.TotalCount != test.wantTotal || page.totalReported != test.wantReported {
+				t.Errorf("TotalCount = %d (reported %v), want %d (reported %v)",
+					page.TotalCount, page.totalReported, test.wantTotal, test.wantReported)
+			}
+		})
+	}
+}
+
+func TestPageUnmarshalJSONResets(t *testing.T) {
+	var page Page[pageItem]
+	if err := json.Unmarshal([]byte(`{"Items": [{"id": 1}], "TotalHits": 1}`), &page); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"Items": []}`), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 0 || page.TotalCount != 0 || page.totalReported {
+		t.Errorf("page = %+v, want the previous page cleared", page)
+	}
+}
+
+func TestPageDone(t *testing.T) {
+	tests := map[string]struct {
+		body     string
+		seen     int
+		pageSize int
+		want     bool
+	}{
+		"short page":                 {body: `{"Items": [{"id": 1}], "TotalHits": 10}`, seen: 1, pageSize: 2, want: true},
+		"full page, more to come":    {body: `{"Items": [{"id": 1}, {"id": 2}], "TotalHits": 3}`, seen: 2, pageSize: 2},
+		"full page reaching total":   {body: `{"Items": [{"id": 1}, {"id": 2}], "TotalHits": 4}`, seen: 4, pageSize: 2, want: true},
+		"full page without total":    {body: `{"Items": [{"id": 1}, {"id": 2}]}`, seen: 4, pageSize: 2},
+		"empty page":                 {body: `{"Items": [], "TotalHits": 4}`, seen: 2, pageSize: 2, want: true},
+		"full bare array":            {body: `[{"id": 1}, {"id": 2}]`, seen: 2, pageSize: 2, want: true},
+		"total lower than seen":      {body: `{"Items": [{"id": 1}, {"id": 2}], "TotalHits": 1}`, seen: 2, pageSize: 2, want: true},
+		"short page without a total": {body: `{"value": [{"id": 1}]}`, seen: 1, pageSize: 2, want: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var page Page[pageItem]
+			if err := json.Unmarshal([]byte(test.body), &page); err != nil {
+				t.Fatal(err)
+			}
+			if got := page.Done(test.seen, test.pageSize); got != test.want {
+				t.Errorf("Done(%d, %d) = %v, want %v", test.seen, test.pageSize, got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetAzureSubscriptionsEnvelopes(t *testing.T) {
+	subs := testSubscriptions(5)
+
+	tests := map[string]struct {
+		envelope     func(items []AzureSubscription) interface{}
+		ignorePage   bool
+		wantRequests int32
+	}{
+		"Items and TotalHits": {
+			envelope: func(items []AzureSubscription) interface{} {
+				return map[string]interface{}{"Items": items, "TotalHits": len(subs)}
+			},
+			wantRequests: 3,
+		},
+		"lower case items and totalCount": {
+			envelope: func(items []AzureSubscription) interface{} {
+				return map[string]interface{}{"items": items, "totalCount": len(subs)}
+			},
+			wantRequests: 3,
+		},
+		"value and count": {
+			envelope: func(items []AzureSubscription) interface{} {
+				return map[string]interface{}{"value": items, "count": len(subs)}
+			},
+			wantRequests: 3,
+		},
+		"Data without a total": {
+			envelope:     func(items []AzureSubscription) interface{} { return map[string]interface{}{"Data": items} },
+			wantRequests: 3,
+		},
+		"bare array of every subscription": {
+			envelope:     func(items []AzureSubscription) interface{} { return items },
+			ignorePage:   true,
+			wantRequests: 1,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var requests int32
+			c := newTestClient(t, ClientConfig{PageSize: 2}, func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				items := subs
+				if !test.ignorePage {
+					page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+					start := min((page-1)*2, len(subs))
+					items = subs[start:min(start+2, len(subs))]
+				}
+				writeJSON(w, http.StatusOK, test.envelope(items))
+			})
+
+			got, err := c.GetAzureSubscriptions(context.Background(), testPlanID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, subs) {
+				t.Errorf("got %+v, want %+v", got, subs)
+			}
+			if requests != test.wantRequests {
+				t.Errorf("made %d requests, want %d", requests, test.wantRequests)
+			}
+		})
+	}
+}
+
+func TestGetCustomerTenantsEnvelope(t *testing.T) {
+	c := newTestClient(t, ClientConfig{OrganizationID: 4051878}, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"value": [{"Id": 1, "Name": "Contoso"}, {"Id": 2, "Name": "Fabrikam"}], "count": 2}`)
+	})
+
+	tenants, err := c.GetCustomerTenants(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tenants) != 2 || tenants[0].ID != 1 || tenants[1].Name != "Fabrikam" {
+		t.Errorf("tenants = %+v, want Contoso and Fabrikam", tenants)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultConcurrency is the number of concurrent API calls made by bulk helpers
+const defaultConcurrency = 5
+
+// FanOut calls fn for each index in [0, n), in order, using at most concurrency workers and
+// waits for all calls it started to complete. A concurrency of 0 means defaultConcurrency.
+// Once ctx is done no further calls are started and ctx's error is returned, so fn wasn't
+// called for some of the indices.
+func FanOut(ctx context.Context, n, concurrency int, fn func(i int)) error {
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	sem := make(chan struct{}, concurrency)
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		// A free worker and a done ctx may be ready at once
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	return nil
+}
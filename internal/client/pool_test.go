@@ -0,0 +1,79 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFanOut(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	called := make([]bool, 10)
+
+	err := FanOut(context.Background(), len(called), 3, func(i int) {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		called[i] = true
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, ok := range called {
+		if !ok {
+			t.Errorf("fn wasn't called for %d", i)
+		}
+	}
+	if peak > 3 {
+		t.Errorf("%d calls ran at once, want at most 3", peak)
+	}
+	if peak < 2 {
+		t.Errorf("calls didn't run concurrently")
+	}
+}
+
+func TestFanOutStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var called []int
+	err := FanOut(ctx, 10, 2, func(i int) {
+		mu.Lock()
+		called = append(called, i)
+		mu.Unlock()
+		if i == 2 {
+			cancel()
+		}
+		time.Sleep(5 * time.Millisecond)
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("FanOut = %v, want context.Canceled", err)
+	}
+	// Indices 0 to 2 were started before the cancellation; at most one more may have been
+	// started while index 2 cancelled
+	if len(called) < 3 || len(called) > 4 {
+		t.Errorf("called fn for %v, want no calls started after the cancellation", called)
+	}
+
+	called = nil
+	if err := FanOut(ctx, 10, 2, func(i int) { called = append(called, i) }); !errors.Is(err, context.Canceled) || len(called) != 0 {
+		t.Errorf("FanOut with a done context = %v, calling fn for %v; want context.Canceled and no calls", err, called)
+	}
+}
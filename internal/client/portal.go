@@ -0,0 +1,89 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultPortalURLTemplate is the Cloud-iQ portal deep link to a subscription.
+// Supported placeholders: {portal}, {organization_id}, {azure_plan_id} and {id}.
+const DefaultPortalURLTemplate = "{portal}/subscriptions/azure/{azure_plan_id}/{id}?organizationId={organization_id}"
+
+// ErrInvalidPortalURL indicates a link that isn't a Cloud-iQ portal link to an Azure subscription
+var ErrInvalidPortalURL = errors.New("not a Cloud-iQ subscription portal URL")
+
+// SubscriptionPortalLink holds the IDs in a Cloud-iQ portal link to a subscription
+type SubscriptionPortalLink struct {
+	// OrganizationID is zero if the link doesn't name an organization
+	OrganizationID int64
+	AzurePlanID    int
+	SubscriptionID int
+}
+
+// ParseSubscriptionPortalURL extracts the IDs from a portal link to a subscription as copied from
+// the browser, e.g. https://cloudiq.crayon.com/subscriptions/azure/873834/12345?organizationId=1234.
+// The link must follow the shape of DefaultPortalURLTemplate; a path prefix before it is allowed.
+func ParseSubscriptionPortalURL(raw string) (*SubscriptionPortalLink, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPortalURL, err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, fmt.Errorf("%w: expected an http(s) URL, got %q", ErrInvalidPortalURL, raw)
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	n := len(segments)
+	if n < 4 || segments[n-4] != "subscriptions" || segments[n-3] != "azure" {
+		return nil, fmt.Errorf("%w: expected a path ending in /subscriptions/azure/<azure_plan_id>/<id>, got %q", ErrInvalidPortalURL, u.Path)
+	}
+
+	link := &SubscriptionPortalLink{}
+	if link.AzurePlanID, err = strconv.Atoi(segments[n-2]); err != nil || link.AzurePlanID < 1 {
+		return nil, fmt.Errorf("%w: invalid Azure Plan ID %q", ErrInvalidPortalURL, segments[n-2])
+	}
+	if link.SubscriptionID, err = strconv.Atoi(segments[n-1]); err != nil || link.SubscriptionID < 1 {
+		return nil, fmt.Errorf("%w: invalid subscription ID %q", ErrInvalidPortalURL, segments[n-1])
+	}
+	if org := u.Query().Get("organizationId"); org != "" {
+		if link.OrganizationID, err = strconv.ParseInt(org, 10, 64); err != nil || link.OrganizationID < 1 {
+			return nil, fmt.Errorf("%w: invalid organization ID %q", ErrInvalidPortalURL, org)
+		}
+	}
+
+	return link, nil
+}
+
+// SubscriptionPortalURL returns a link to the subscription in the Cloud-iQ portal
+func (c *Client) SubscriptionPortalURL(azurePlanID, subscriptionID int) string {
+	template := c.config.PortalURLTemplate
+	if template == "" {
+		template = DefaultPortalURLTemplate
+	}
+
+	return strings.NewReplacer(
+		"{portal}", portalBaseURL(c.config.BaseURL),
+		"{organization_id}", strconv.FormatInt(c.config.OrganizationID, 10),
+		"{azure_plan_id}", strconv.Itoa(azurePlanID),
+		"{id}", strconv.Itoa(subscriptionID),
+	).Replace(template)
+}
+
+// portalBaseURL derives the portal origin from the API base URL. The public API is served from
+// api.<domain> while the portal lives on cloudiq.<domain>; other hosts are used as-is.
+func portalBaseURL(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return strings.TrimSuffix(baseURL, "/")
+	}
+	if strings.HasPrefix(u.Host, "api.") {
+		u.Host = "cloudiq." + strings.TrimPrefix(u.Host, "api.")
+	}
+	return u.Scheme + "://" + u.Host
+}
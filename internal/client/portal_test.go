@@ -0,0 +1,115 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSubscriptionPortalURL(t *testing.T) {
+	tests := map[string]struct {
+		baseURL  string
+		template string
+		want     string
+	}{
+		"public API": {
+			baseURL: "https://api.crayon.com",
+			want:    "https://cloudiq.crayon.com/subscriptions/azure/873834/42?organizationId=4051878",
+		},
+		"API base with a path": {
+			baseURL: "https://api.crayon.com/",
+			want:    "https://cloudiq.crayon.com/subscriptions/azure/873834/42?organizationId=4051878",
+		},
+		"portal on the API host": {
+			baseURL: "https://crayon.internal:8443",
+			want:    "https://crayon.internal:8443/subscriptions/azure/873834/42?organizationId=4051878",
+		},
+		"custom template": {
+			baseURL:  "https://api.crayon.com",
+			template: "https://portal.example.com/orgs/{organization_id}/plans/{azure_plan_id}/subs/{id}",
+			want:     "https://portal.example.com/orgs/4051878/plans/873834/subs/42",
+		},
+		"custom template on the derived portal": {
+			baseURL:  "https://api.crayon.com",
+			template: "{portal}/azure/{id}",
+			want:     "https://cloudiq.crayon.com/azure/42",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewClient(ClientConfig{BaseURL: test.baseURL, OrganizationID: 4051878, PortalURLTemplate: test.template})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.SubscriptionPortalURL(testPlanID, 42); got != test.want {
+				t.Errorf("SubscriptionPortalURL() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseSubscriptionPortalURL(t *testing.T) {
+	tests := map[string]struct {
+		url     string
+		want    SubscriptionPortalLink
+		wantErr bool
+	}{
+		"copied from the browser": {
+			url:  "https://cloudiq.crayon.com/subscriptions/azure/873834/12345?organizationId=4051878",
+			want: SubscriptionPortalLink{OrganizationID: 4051878, AzurePlanID: 873834, SubscriptionID: 12345},
+		},
+		"with a fragment and extra query": {
+			url:  "https://cloudiq.crayon.com/subscriptions/azure/873834/12345?tab=details&organizationId=4051878#usage",
+			want: SubscriptionPortalLink{OrganizationID: 4051878, AzurePlanID: 873834, SubscriptionID: 12345},
+		},
+		"path prefix and trailing slash": {
+			url:  "https://crayon.internal/portal/subscriptions/azure/873834/12345/",
+			want: SubscriptionPortalLink{AzurePlanID: 873834, SubscriptionID: 12345},
+		},
+		"surrounding whitespace": {
+			url:  "  http://cloudiq.crayon.com/subscriptions/azure/873834/12345\n",
+			want: SubscriptionPortalLink{AzurePlanID: 873834, SubscriptionID: 12345},
+		},
+		"not http":             {url: "ftp://cloudiq.crayon.com/subscriptions/azure/873834/12345", wantErr: true},
+		"no host":              {url: "https:///subscriptions/azure/873834/12345", wantErr: true},
+		"another page":         {url: "https://cloudiq.crayon.com/subscriptions/office365/873834/12345", wantErr: true},
+		"plan page":            {url: "https://cloudiq.crayon.com/subscriptions/azure/873834", wantErr: true},
+		"non-numeric plan":     {url: "https://cloudiq.crayon.com/subscriptions/azure/plan/12345", wantErr: true},
+		"zero subscription":    {url: "https://cloudiq.crayon.com/subscriptions/azure/873834/0", wantErr: true},
+		"invalid organization": {url: "https://cloudiq.crayon.com/subscriptions/azure/873834/12345?organizationId=acme", wantErr: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseSubscriptionPortalURL(test.url)
+			if test.wantErr {
+				if !errors.Is(err, ErrInvalidPortalURL) {
+					t.Errorf("got %+v, %v, want ErrInvalidPortalURL", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *got != test.want {
+				t.Errorf("got %+v, want %+v", *got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseSubscriptionPortalURLRoundTrip(t *testing.T) {
+	c, err := NewClient(ClientConfig{BaseURL: "https://api.crayon.com", OrganizationID: 4051878})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := ParseSubscriptionPortalURL(c.SubscriptionPortalURL(testPlanID, 42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (SubscriptionPortalLink{OrganizationID: 4051878, AzurePlanID: testPlanID, SubscriptionID: 42}); *link != want {
+		t.Errorf("got %+v, want %+v", *link, want)
+	}
+}
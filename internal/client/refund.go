@@ -0,0 +1,34 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import "time"
+
+// RefundWindow describes whether cancelling a subscription is refunded. Offers without a refund
+// window report neither RefundEligible nor CancellationWindowEnds.
+type RefundWindow struct {
+	// Eligible reports whether a cancellation at the time of the check would be refunded
+	Eligible bool
+
+	// Ends is when the refund window closes, in RFC 3339, if reported
+	Ends string
+}
+
+// RefundWindow returns the subscription's refund window as of now. ok is false for offers the
+// refund window doesn't apply to.
+func (s *AzureSubscription) RefundWindow(now time.Time) (window RefundWindow, ok bool) {
+	if ends, parsed := BillingTimestamp(s.CancellationWindowEnds); parsed {
+		end, _ := time.Parse(time.RFC3339, ends)
+		window = RefundWindow{Eligible: now.Before(end), Ends: ends}
+		// An explicit verdict from Cloud-iQ wins over the date, e.g. after a refund was already used
+		if s.RefundEligible != nil && !*s.RefundEligible {
+			window.Eligible = false
+		}
+		return window, true
+	}
+	if s.RefundEligible != nil {
+		return RefundWindow{Eligible: *s.RefundEligible}, true
+	}
+	return RefundWindow{}, false
+}
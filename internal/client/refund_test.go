@@ -0,0 +1,91 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestRefundWindow(t *testing.T) {
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	yes, no := true, false
+
+	tests := map[string]struct {
+		sub        AzureSubscription
+		wantWindow RefundWindow
+		wantOK     bool
+	}{
+		"eligible": {
+			sub:        AzureSubscription{CancellationWindowEnds: "2024-05-20T00:00:00Z"},
+			wantWindow: RefundWindow{Eligible: true, Ends: "2024-05-20T00:00:00Z"}, wantOK: true,
+		},
+		"eligible until a date": {
+			sub:        AzureSubscription{CancellationWindowEnds: "2024-05-16", RefundEligible: &yes},
+			wantWindow: RefundWindow{Eligible: true, Ends: "2024-05-16T00:00:00Z"}, wantOK: true,
+		},
+		"expired": {
+			sub:        AzureSubscription{CancellationWindowEnds: "2024-05-15T11:59:59Z", RefundEligible: &yes},
+			wantWindow: RefundWindow{Ends: "2024-05-15T11:59:59Z"}, wantOK: true,
+		},
+		"not eligible within the window": {
+			sub:        AzureSubscription{CancellationWindowEnds: "2024-05-20T00:00:00Z", RefundEligible: &no},
+			wantWindow: RefundWindow{Ends: "2024-05-20T00:00:00Z"}, wantOK: true,
+		},
+		"eligible without a date": {
+			sub:        AzureSubscription{RefundEligible: &yes},
+			wantWindow: RefundWindow{Eligible: true}, wantOK: true,
+		},
+		"date in an unknown format": {
+			sub:        AzureSubscription{CancellationWindowEnds: "20/05/2024", RefundEligible: &no},
+			wantWindow: RefundWindow{}, wantOK: true,
+		},
+		"not applicable": {sub: AzureSubscription{}},
+		"not applicable with an unknown date": {
+			sub: AzureSubscription{CancellationWindowEnds: "20/05/2024"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			window, ok := test.sub.RefundWindow(now)
+			if window != test.wantWindow || ok != test.wantOK {
+				t.Errorf("got %+v, %v, want %+v, %v", window, ok, test.wantWindow, test.wantOK)
+			}
+		})
+	}
+}
+
+func TestAzureSubscriptionRefundFieldNames(t *testing.T) {
+	yes, no := true, false
+	tests := map[string]struct {
+		body         string
+		wantEligible *bool
+		wantEnds     string
+	}{
+		"canonical names": {
+			body:         `{"RefundEligible": true, "CancellationWindowEnds": "2024-05-20"}`,
+			wantEligible: &yes, wantEnds: "2024-05-20",
+		},
+		"alternate names": {
+			body:         `{"isRefundable": false, "refundableUntil": "2024-05-20T00:00:00Z"}`,
+			wantEligible: &no, wantEnds: "2024-05-20T00:00:00Z",
+		},
+		"not reported": {body: `{"Id": 42}`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var sub AzureSubscription
+			if err := json.Unmarshal([]byte(test.body), &sub); err != nil {
+				t.Fatal(err)
+			}
+			if !equalBoolPointers(sub.RefundEligible, test.wantEligible) {
+				t.Errorf("refund eligible = %s, want %s", formatBoolPointer(sub.RefundEligible), formatBoolPointer(test.wantEligible))
+			}
+			if sub.CancellationWindowEnds != test.wantEnds {
+				t.Errorf("cancellation window ends = %q, want %q", sub.CancellationWindowEnds, test.wantEnds)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxResponseSize is the largest response body read when ClientConfig.MaxResponseSize is unset
+const DefaultMaxResponseSize = 4 << 20
+
+// listResponseSizeMultiplier raises the limit for list endpoints, whose pages legitimately grow
+// with the page size and the number of subscriptions
+const listResponseSizeMultiplier = 8
+
+// ErrResponseTooLarge is returned when a response body exceeds the configured maximum size
+var ErrResponseTooLarge = errors.New("response too large")
+
+// maxResponseSize returns the largest response body read from single-object endpoints
+func (c *Client) maxResponseSize() int64 {
+	if c.config.MaxResponseSize > 0 {
+		return c.config.MaxResponseSize
+	}
+	return DefaultMaxResponseSize
+}
+
+// maxListResponseSize returns the largest response body read from list endpoints
+func (c *Client) maxListResponseSize() int64 {
+	return c.maxResponseSize() * listResponseSizeMultiplier
+}
+
+// readResponseBody reads at most limit bytes of a response body, so a malfunctioning API or
+// proxy can't exhaust memory. Larger bodies fail with ErrResponseTooLarge.
+func readResponseBody(body io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: the body exceeds %d bytes", ErrResponseTooLarge, limit)
+	}
+	return data, nil
+}
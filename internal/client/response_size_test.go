@@ -0,0 +1,45 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestReadResponseBody(t *testing.T) {
+	if data, err := readResponseBody(strings.NewReader("12345"), 5); err != nil || string(data) != "12345" {
+		t.Errorf("body at the limit = %q, %v; want it read", data, err)
+	}
+	if _, err := readResponseBody(strings.NewReader("123456"), 5); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("body over the limit: err = %v, want ErrResponseTooLarge", err)
+	}
+}
+
+func TestResponseSizeLimits(t *testing.T) {
+	const limit = 1024
+	padding := strings.Repeat("x", 2*limit)
+
+	c := newTestClient(t, ClientConfig{MaxResponseSize: limit}, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/azuresubscriptions") {
+			// A 2 KiB page is within the list limit
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"Items":     []AzureSubscription{{ID: 1, FriendlyName: padding, Status: "Active"}},
+				"TotalHits": 1,
+			})
+			return
+		}
+		writeJSON(w, http.StatusOK, AzureSubscription{ID: 1, FriendlyName: padding, Status: "Active"})
+	})
+
+	if _, err := c.GetAzureSubscription(context.Background(), testPlanID, 1); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("oversized subscription: err = %v, want ErrResponseTooLarge", err)
+	}
+	if _, err := c.GetAzureSubscriptions(context.Background(), testPlanID); err != nil {
+		t.Errorf("list within %d times the limit: %v", listResponseSizeMultiplier, err)
+	}
+}
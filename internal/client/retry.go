@@ -0,0 +1,163 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// DefaultMaxRetries is how often a retriable Crayon API request is retried by default
+const DefaultMaxRetries = 3
+
+// DefaultRetryBaseDelay is the delay before the first retry when ClientConfig.RetryBaseDelay is unset
+const DefaultRetryBaseDelay = 500 * time.Millisecond
+
+// RetryPredicate decides whether a Crayon API request should be retried. resp is nil when the
+// request failed without a response, in which case err is set. The response body must not be read.
+type RetryPredicate func(req *http.Request, resp *http.Response, err error) bool
+
+// DefaultRetryPredicate retries rate limited (429) requests, which the API hasn't processed, and
+// transport errors and server errors (5xx other than 501) of idempotent requests. Writes such as
+// creates aren't retried on those, as the first attempt may have taken effect.
+func DefaultRetryPredicate(req *http.Request, resp *http.Response, err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+	default:
+		return false
+	}
+	if resp == nil {
+		return err != nil
+	}
+	return resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented
+}
+
+// retryPredicate returns the configured retry predicate, or DefaultRetryPredicate
+func (c *Client) retryPredicate() RetryPredicate {
+	if c.config.RetryPredicate != nil {
+		return c.config.RetryPredicate
+	}
+	return DefaultRetryPredicate
+}
+
+// retryBackoff returns the backoff between attempts of a retriable request
+func (c *Client) retryBackoff() *Backoff {
+	maxRetries := c.config.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+	baseDelay := c.config.RetryBaseDelay
+	if baseDelay <= 0 {
+		baseDelay = DefaultRetryBaseDelay
+	}
+	return &Backoff{
+		Base:        baseDelay,
+		Max:         30 * time.Second,
+		Jitter:      0.2,
+		MaxAttempts: maxRetries,
+	}
+}
+
+// tokenRetryPredicate retries token requests like idempotent requests: although they are POSTs,
+// issuing a second token has no side effects
+func tokenRetryPredicate(req *http.Request, resp *http.Response, err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if resp == nil {
+		return err != nil
+	}
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented)
+}
+
+// sendWithRetry sends the request built by newRequest, retrying it with exponential backoff
+// while retriable allows and retries are left, honoring Retry-After. newRequest is called for
+// every attempt so each one gets an unread body. Transport failures are wrapped in
+// ErrNoResponse; once retries run out, the last response is returned to the caller as is.
+// Cancelling ctx or stopping the provider aborts the request and any wait between attempts.
+func (c *Client) sendWithRetry(ctx context.Context, label string, retriable RetryPredicate, newRequest func(ctx context.Context) (*http.Request, error)) (*http.Response, error) {
+	ctx, cancel := c.withStop(ctx)
+	backoff := c.retryBackoff()
+	for {
+		req, err := newRequest(ctx)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		// A replayed cassette answers the same way every time, so a miss isn't worth retrying
+		retry := c.config.MaxRetries >= 0 && ctx.Err() == nil && !errors.Is(err, ErrNoRecordedInteraction) &&
+			retriable(req, resp, err)
+		var delay time.Duration
+		if retry {
+			delay, retry = backoff.Next()
+		}
+		if !retry {
+			if err != nil {
+				cancel()
+				return nil, fmt.Errorf("%w: %w", ErrNoResponse, err)
+			}
+			// The caller reads the body after we return, so the context lives until it's closed
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		if resp != nil {
+			if retryAfter := parseRetryAfter(resp.Header); retryAfter > delay {
+				delay = retryAfter
+			}
+			resp.Body.Close()
+		}
+		tflog.Warn(ctx, "Retrying request", map[string]interface{}{
+			"request": label,
+			"delay":   delay.Round(time.Millisecond).String(),
+			"attempt": backoff.Attempt(),
+			"reason":  retryReason(resp, err),
+		})
+		select {
+		case <-ctx.Done():
+			cancel()
+			if c.config.StopContext.Err() != nil {
+				return nil, fmt.Errorf("request failed: %w", ErrStopped)
+			}
+			return nil, fmt.Errorf("request failed: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// cancelOnClose releases a request's context once its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// retryReason describes why a request is retried, for logging
+func retryReason(resp *http.Response, err error) string {
+	if resp != nil {
+		return fmt.Sprintf("status %d", resp.StatusCode)
+	}
+	return err.Error()
+}
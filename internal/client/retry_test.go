@@ -0,0 +1,130 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDefaultRetryPredicate(t *testing.T) {
+	tests := []struct {
+		method string
+		status int
+		err    error
+		want   bool
+	}{
+		{method: http.MethodGet, status: http.StatusTooManyRequests, want: true},
+		{method: http.MethodPost, status: http.StatusTooManyRequests, want: true},
+		{method: http.MethodGet, status: http.StatusServiceUnavailable, want: true},
+		{method: http.MethodPut, status: http.StatusBadGateway, want: true},
+		{method: http.MethodDelete, status: http.StatusInternalServerError, want: true},
+		{method: http.MethodPost, status: http.StatusServiceUnavailable},
+		{method: http.MethodPatch, status: http.StatusInternalServerError},
+		{method: http.MethodGet, status: http.StatusNotImplemented},
+		{method: http.MethodGet, status: http.StatusBadRequest},
+		{method: http.MethodGet, status: http.StatusNotFound},
+		{method: http.MethodGet, err: errors.New("connection reset"), want: true},
+		{method: http.MethodPost, err: errors.New("connection reset")},
+		{method: http.MethodGet, err: context.Canceled},
+	}
+	for _, test := range tests {
+		req, _ := http.NewRequest(test.method, "https://api.crayon.test/api/v1/organizations", nil)
+		var resp *http.Response
+		if test.status != 0 {
+			resp = &http.Response{StatusCode: test.status}
+		}
+		if got := DefaultRetryPredicate(req, resp, test.err); got != test.want {
+			t.Errorf("DefaultRetryPredicate(%s, %d, %v) = %v, want %v", test.method, test.status, test.err, got, test.want)
+		}
+	}
+}
+
+// transientHandler fails the first failures requests with status and the X-Error-Code
+// TransientLock, then answers with a subscription. It counts all requests.
+func transientHandler(failures int32, status int, requests *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(requests, 1) <= failures {
+			w.Header().Set("X-Error-Code", "TransientLock")
+			http.Error(w, `{"Message": "locked"}`, status)
+			return
+		}
+		writeJSON(w, http.StatusOK, AzureSubscription{ID: 42})
+	}
+}
+
+func TestCustomRetryPredicate(t *testing.T) {
+	// Retries the deployment-specific transient lock, which Crayon answers with a 400
+	retryLocked := func(req *http.Request, resp *http.Response, err error) bool {
+		if resp != nil && resp.Header.Get("X-Error-Code") == "TransientLock" {
+			return true
+		}
+		return DefaultRetryPredicate(req, resp, err)
+	}
+
+	var requests int32
+	c := newTestClient(t, ClientConfig{RetryPredicate: retryLocked, MaxRetries: 3, RetryBaseDelay: time.Millisecond},
+		transientHandler(2, http.StatusBadRequest, &requests))
+
+	sub, err := c.RenameAzureSubscription(context.Background(), testPlanID, 42, "app-prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.ID != 42 || requests != 3 {
+		t.Errorf("got subscription %d after %d requests, want 42 after 3", sub.ID, requests)
+	}
+}
+
+func TestDefaultRetryPredicateKeepsBadRequestsTerminal(t *testing.T) {
+	var requests int32
+	c := newTestClient(t, ClientConfig{MaxRetries: 3, RetryBaseDelay: time.Millisecond},
+		transientHandler(2, http.StatusBadRequest, &requests))
+
+	_, err := c.GetAzureSubscription(context.Background(), testPlanID, 42)
+	if !IsStatus(err, http.StatusBadRequest) {
+		t.Errorf("err = %v, want the 400", err)
+	}
+	if requests != 1 {
+		t.Errorf("made %d requests, want the 400 not retried", requests)
+	}
+}
+
+func TestRetriesRunOut(t *testing.T) {
+	var requests int32
+	c := newTestClient(t, ClientConfig{MaxRetries: 2, RetryBaseDelay: time.Millisecond},
+		transientHandler(10, http.StatusServiceUnavailable, &requests))
+
+	_, err := c.GetAzureSubscription(context.Background(), testPlanID, 42)
+	if !IsStatus(err, http.StatusServiceUnavailable) {
+		t.Errorf("err = %v, want the last 503", err)
+	}
+	if requests != 3 {
+		t.Errorf("made %d requests, want the first one and 2 retries", requests)
+	}
+}
+
+func TestRetryResendsBody(t *testing.T) {
+	var bodies []string
+	c := newTestClient(t, ClientConfig{MaxRetries: 3, RetryBaseDelay: time.Millisecond}, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		writeJSON(w, http.StatusOK, AzureSubscription{ID: 42})
+	})
+
+	if _, err := c.RenameAzureSubscription(context.Background(), testPlanID, 42, "app-prod"); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 2 || bodies[0] == "" || bodies[1] != bodies[0] {
+		t.Errorf("sent bodies %q, want the same body on the retry", bodies)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"sync"
+)
+
+// flightCall is an in-flight or completed flightGroup call
+type flightCall struct {
+	done    chan struct{}
+	value   string
+	err     error
+	waiters int                // Callers still waiting for the result
+	cancel  context.CancelFunc // Aborts the call once no caller waits for it
+}
+
+// flightGroup coalesces concurrent calls so that only one runs at a time;
+// callers arriving while a call is in flight wait for and share its result
+type flightGroup struct {
+	mu   sync.Mutex
+	call *flightCall
+}
+
+// do runs fn unless a call is already in flight, in which case it waits for that call's result.
+// Each caller stops waiting when its ctx is done. fn runs under a context with the values of the
+// first caller's ctx, which is cancelled when stop is, or when every waiting caller has given up,
+// so one caller's cancellation doesn't fail the others.
+func (g *flightGroup) do(ctx, stop context.Context, fn func(ctx context.Context) (string, error)) (string, error) {
+	g.mu.Lock()
+	call := g.call
+	if call == nil {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &flightCall{done: make(chan struct{}), cancel: cancel}
+		g.call = call
+
+		go func() {
+			stopCall := context.AfterFunc(stop, cancel)
+			call.value, call.err = fn(callCtx)
+			stopCall()
+			cancel()
+
+			g.mu.Lock()
+			if g.call == call {
+				g.call = nil
+			}
+			g.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			// Later callers start a new call rather than join the aborted one
+			call.cancel()
+			if g.call == call {
+				g.call = nil
+			}
+		}
+		g.mu.Unlock()
+		return "", ctx.Err()
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Policies for handling subscription statuses the provider does not recognize
+const (
+	// UnknownStatusPreserve stores unrecognized statuses verbatim (default)
+	UnknownStatusPreserve = "preserve"
+	// UnknownStatusError fails the request when a status is not recognized
+	UnknownStatusError = "error"
+	// UnknownStatusMapToUnknown replaces unrecognized statuses with "unknown"
+	UnknownStatusMapToUnknown = "map-to-unknown"
+)
+
+// knownStatuses are the subscription statuses the provider understands
+var knownStatuses = map[string]bool{
+	"active":       true,
+	"cancelled":    true,
+	"canceled":     true,
+	"suspended":    true,
+	"disabled":     true,
+	"deleted":      true,
+	"pending":      true,
+	"provisioning": true,
+}
+
+// normalizeStatus applies the configured unknown status policy to a status reported by the API.
+// A missing (empty) status counts as unrecognized. The raw status is logged either way.
+func (c *Client) normalizeStatus(ctx context.Context, status string) (string, error) {
+	if knownStatuses[strings.ToLower(status)] {
+		tflog.Trace(ctx, "Subscription status", map[string]interface{}{
+			"status": status,
+		})
+		return status, nil
+	}
+
+	policy := c.unknownStatusPolicy()
+	message := "Unrecognized subscription status"
+	if status == "" {
+		message = "Subscription reported no status"
+	}
+	tflog.Warn(ctx, message, map[string]interface{}{
+		"status": status,
+		"policy": policy,
+	})
+
+	switch policy {
+	case UnknownStatusError:
+		if status == "" {
+			return "", fmt.Errorf("subscription reported no status")
+		}
+		return "", fmt.Errorf("unrecognized subscription status '%s'", status)
+	case UnknownStatusMapToUnknown:
+		return "unknown", nil
+	}
+	return status, nil
+}
+
+// unknownStatusPolicy returns the configured unknown status policy
+func (c *Client) unknownStatusPolicy() string {
+	if c.config.UnknownStatusPolicy != "" {
+		return c.config.UnknownStatusPolicy
+	}
+	return UnknownStatusPreserve
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestNormalizeStatus(t *testing.T) {
+	type statusTest struct {
+		status  string
+		want    string
+		wantErr string
+	}
+	// Every policy leaves recognized statuses alone, in any casing
+	known := map[string]statusTest{
+		"known status":               {status: "Active", want: "Active"},
+		"known status in lower case": {status: "suspended", want: "suspended"},
+		"known status in upper case": {status: "CANCELLED", want: "CANCELLED"},
+	}
+	policies := map[string]map[string]statusTest{
+		UnknownStatusPreserve: {
+			"novel status": {status: "Migrating", want: "Migrating"},
+			"no status":    {status: "", want: ""},
+		},
+		// The default policy is preserve
+		"": {
+			"novel status": {status: "Migrating", want: "Migrating"},
+			"no status":    {status: "", want: ""},
+		},
+		UnknownStatusError: {
+			"novel status": {status: "Migrating", wantErr: "unrecognized subscription status 'Migrating'"},
+			"no status":    {status: "", wantErr: "subscription reported no status"},
+		},
+		UnknownStatusMapToUnknown: {
+			"novel status": {status: "Migrating", want: "unknown"},
+			"no status":    {status: "", want: "unknown"},
+		},
+	}
+	for policy, tests := range policies {
+		for name, test := range known {
+			tests[name] = test
+		}
+		t.Run("policy "+policy, func(t *testing.T) {
+			c := &Client{config: ClientConfig{UnknownStatusPolicy: policy}}
+
+			for name, test := range tests {
+				t.Run(name, func(t *testing.T) {
+					got, err := c.normalizeStatus(context.Background(), test.status)
+					if test.wantErr != "" {
+						if err == nil || err.Error() != test.wantErr {
+							t.Fatalf("got %q, %v; want error %q", got, err, test.wantErr)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatal(err)
+					}
+					if got != test.want {
+						t.Errorf("got %q, want %q", got, test.want)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestUnknownStatusPolicyAppliesToListings(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"Items": []AzureSubscription{
+				{ID: 1, FriendlyName: "app-prod", Status: "Active", AzurePlanID: testPlanID},
+				{ID: 2, FriendlyName: "app-dev", Status: "Migrating", AzurePlanID: testPlanID},
+			},
+			"TotalHits": 2,
+		})
+	}
+
+	t.Run("map-to-unknown", func(t *testing.T) {
+		c := newTestClient(t, ClientConfig{UnknownStatusPolicy: UnknownStatusMapToUnknown}, handler)
+
+		sub, err := c.FindAzureSubscriptionByName(context.Background(), testPlanID, "app-dev")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sub.Status != "unknown" {
+			t.Errorf("found status %q, want unknown", sub.Status)
+		}
+
+		counts, err := c.CountAzureSubscriptionsByStatus(context.Background(), testPlanID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if counts["unknown"] != 1 || counts["migrating"] != 0 {
+			t.Errorf("counts = %v, want the novel status counted as unknown", counts)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		c := newTestClient(t, ClientConfig{UnknownStatusPolicy: UnknownStatusError}, handler)
+
+		if _, err := c.GetAzureSubscriptions(context.Background(), testPlanID); err == nil {
+			t.Error("listed a subscription with an unrecognized status")
+		}
+	})
+}
@@ -4,11 +4,17 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 // AzureSubscription represents a Crayon Azure Subscription
@@ -18,105 +24,575 @@ type AzureSubscription struct {
 	SubscriptionID string `json:"PublisherSubscriptionId"`
 	Status         string `json:"Status"`
 	AzurePlanID    int    `json:"AzurePlanId"`
+
+	// BillingAccountID is the billing account/payer the subscription rolls up to, if reported
+	BillingAccountID string `json:"BillingAccountId,omitempty"`
+
+	// PartnerOfRecord is the partner (PoR) attributed to the subscription, if reported
+	PartnerOfRecord string `json:"PartnerOfRecord,omitempty"`
+
+	// LastModified is when the subscription last changed, if reported (see LastModifiedTime)
+	LastModified string `json:"LastModified,omitempty"`
+
+	// CreatedDate is when the subscription was created, if reported
+	CreatedDate string `json:"CreatedDate,omitempty"`
+
+	// SupportPlan is the Azure support plan of the subscription, if reported
+	SupportPlan string `json:"SupportPlan,omitempty"`
+
+	// Quantity is the number of seats of quantity-based offers, if reported
+	Quantity int `json:"Quantity,omitempty"`
+
+	// CurrentBillingPeriodStart and CurrentBillingPeriodEnd bound the current billing cycle, and
+	// NextRenewalDate is when the subscription next renews, if reported (see BillingTimestamp)
+	CurrentBillingPeriodStart string `json:"CurrentBillingPeriodStart,omitempty"`
+	CurrentBillingPeriodEnd   string `json:"CurrentBillingPeriodEnd,omitempty"`
+	NextRenewalDate           string `json:"NextRenewalDate,omitempty"`
+
+	// RefundEligible and CancellationWindowEnds report whether cancelling the subscription is
+	// refunded and until when, for offers with a refund window (see RefundWindow)
+	RefundEligible         *bool  `json:"RefundEligible,omitempty"`
+	CancellationWindowEnds string `json:"CancellationWindowEnds,omitempty"`
+
+	// AutoRenew reports whether the subscription renews automatically at the end of its term.
+	// It is nil for offers without a renewal toggle.
+	AutoRenew *bool `json:"AutoRenew,omitempty"`
+
+	// AccountManager and TechnicalContact are the people Cloud-iQ records as contacts for the
+	// subscription, if reported
+	AccountManager   *Contact `json:"AccountManager,omitempty"`
+	TechnicalContact *Contact `json:"TechnicalContact,omitempty"`
+
+	// BillingScope places the subscription in the MCA, EA or MPA billing hierarchy, if reported
+	BillingScope *BillingScope `json:"BillingScope,omitempty"`
+
+	// ApprovalRequestID and ApprovalStatus are set when a create request entered an approval
+	// queue instead of creating the subscription (see AwaitingApproval)
+	ApprovalRequestID int    `json:"ApprovalRequestId,omitempty"`
+	ApprovalStatus    string `json:"ApprovalStatus,omitempty"`
+
+	// Tags is only populated when requested via the "tags" expand parameter
+	Tags map[string]string `json:"Tags,omitempty"`
+
+	// OperationLocation is the asynchronous create operation to poll for provisioning progress.
+	// It is only set on subscriptions returned by an accepted (202) create request.
+	OperationLocation string `json:"-"`
 }
 
-// AzureSubscriptionsResponse represents the list response
-type AzureSubscriptionsResponse struct {
-	Items      []AzureSubscription `json:"Items"`
-	TotalCount int                 `json:"TotalHits"`
+// azureSubscriptionFieldAliases lists alternate keys used by different Crayon API versions.
+// encoding/json already matches keys case-insensitively, so only differently named keys are listed.
+var azureSubscriptionFieldAliases = map[string][]string{
+	"FriendlyName":   {"Name", "DisplayName"},
+	"SubscriptionID": {"SubscriptionId", "AzureSubscriptionId"},
+	"LastModified":   {"LastModifiedDate", "ModifiedDate", "ModifiedAt"},
+	"CreatedDate":    {"CreationDate", "CreatedAt", "Created"},
+
+	"CurrentBillingPeriodStart": {"BillingPeriodStart", "BillingCycleStartDate"},
+	"CurrentBillingPeriodEnd":   {"BillingPeriodEnd", "BillingCycleEndDate"},
+	"NextRenewalDate":           {"RenewalDate"},
+
+	"RefundEligible":         {"IsRefundable", "Refundable", "IsRefundEligible"},
+	"CancellationWindowEnds": {"CancellationWindowEnd", "RefundWindowEnd", "RefundableUntil", "CancellationDeadline"},
+
+	"AutoRenew":        {"AutoRenewal", "AutoRenewEnabled", "IsAutoRenew"},
+	"AccountManager":   {"AccountManagerContact", "Manager"},
+	"TechnicalContact": {"TechnicalContactPerson", "TechContact"},
+	"BillingScope":     {"BillingAgreement", "BillingHierarchy"},
+}
+
+// UnmarshalJSON decodes a subscription, falling back to alternate field names when the
+// canonical ones are absent so API upgrades don't silently produce empty fields
+func (s *AzureSubscription) UnmarshalJSON(data []byte) error {
+	type plain AzureSubscription
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if s.FriendlyName == "" {
+		s.FriendlyName = lookupAlias(raw, azureSubscriptionFieldAliases["FriendlyName"])
+	}
+	if s.SubscriptionID == "" {
+		s.SubscriptionID = lookupAlias(raw, azureSubscriptionFieldAliases["SubscriptionID"])
+	}
+	if s.LastModified == "" {
+		s.LastModified = lookupAlias(raw, azureSubscriptionFieldAliases["LastModified"])
+	}
+	if s.CreatedDate == "" {
+		s.CreatedDate = lookupAlias(raw, azureSubscriptionFieldAliases["CreatedDate"])
+	}
+	if s.CurrentBillingPeriodStart == "" {
+		s.CurrentBillingPeriodStart = lookupAlias(raw, azureSubscriptionFieldAliases["CurrentBillingPeriodStart"])
+	}
+	if s.CurrentBillingPeriodEnd == "" {
+		s.CurrentBillingPeriodEnd = lookupAlias(raw, azureSubscriptionFieldAliases["CurrentBillingPeriodEnd"])
+	}
+	if s.NextRenewalDate == "" {
+		s.NextRenewalDate = lookupAlias(raw, azureSubscriptionFieldAliases["NextRenewalDate"])
+	}
+	if s.RefundEligible == nil {
+		s.RefundEligible = lookupBoolAlias(raw, azureSubscriptionFieldAliases["RefundEligible"])
+	}
+	if s.CancellationWindowEnds == "" {
+		s.CancellationWindowEnds = lookupAlias(raw, azureSubscriptionFieldAliases["CancellationWindowEnds"])
+	}
+	if s.AutoRenew == nil {
+		s.AutoRenew = lookupBoolAlias(raw, azureSubscriptionFieldAliases["AutoRenew"])
+	}
+	if s.AccountManager.IsEmpty() {
+		s.AccountManager = lookupContactAlias(raw, azureSubscriptionFieldAliases["AccountManager"])
+	}
+	if s.TechnicalContact.IsEmpty() {
+		s.TechnicalContact = lookupContactAlias(raw, azureSubscriptionFieldAliases["TechnicalContact"])
+	}
+	if s.BillingScope.IsEmpty() {
+		s.BillingScope = lookupBillingScopeAlias(raw, azureSubscriptionFieldAliases["BillingScope"])
+	}
+
+	return nil
 }
 
-// CreateAzureSubscriptionRequest represents the request to create a subscription
+// lastModifiedLayouts are the timestamp formats Crayon API versions use for LastModified
+var lastModifiedLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05"}
+
+// LastModifiedTime parses LastModified. ok is false if it is absent or in an unknown format.
+func (s AzureSubscription) LastModifiedTime() (time.Time, bool) {
+	for _, layout := range lastModifiedLayouts {
+		if t, err := time.Parse(layout, s.LastModified); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// LastModifiedTimestamp returns LastModified normalized to RFC 3339 (UTC if it carries no zone).
+// ok is false if it is absent or in an unknown format.
+func (s AzureSubscription) LastModifiedTimestamp() (string, bool) {
+	t, ok := s.LastModifiedTime()
+	if !ok {
+		return "", false
+	}
+	return t.Format(time.RFC3339), true
+}
+
+// billingLayouts are the formats Crayon API versions use for billing period dates
+var billingLayouts = append([]string{"2006-01-02"}, lastModifiedLayouts...)
+
+// BillingTimestamp normalizes a billing period date reported as a date or timestamp to RFC 3339
+// (dates are taken as midnight UTC). ok is false if value is absent or in an unknown format.
+func BillingTimestamp(value string) (string, bool) {
+	for _, layout := range billingLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(time.RFC3339), true
+		}
+	}
+	return "", false
+}
+
+// lookupAlias returns the first non-empty string value among keys, matched case-insensitively
+func lookupAlias(raw map[string]json.RawMessage, keys []string) string {
+	for _, key := range keys {
+		for k, v := range raw {
+			if !strings.EqualFold(k, key) {
+				continue
+			}
+			var value string
+			if err := json.Unmarshal(v, &value); err == nil && value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// lookupBoolAlias returns the first boolean value among keys, matched case-insensitively, or nil
+func lookupBoolAlias(raw map[string]json.RawMessage, keys []string) *bool {
+	for _, key := range keys {
+		for k, v := range raw {
+			if !strings.EqualFold(k, key) {
+				continue
+			}
+			var value bool
+			if err := json.Unmarshal(v, &value); err == nil {
+				return &value
+			}
+		}
+	}
+	return nil
+}
+
+// lookupContactAlias returns the first non-empty contact among keys, matched case-insensitively
+func lookupContactAlias(raw map[string]json.RawMessage, keys []string) *Contact {
+	for _, key := range keys {
+		for k, v := range raw {
+			if !strings.EqualFold(k, key) {
+				continue
+			}
+			var contact Contact
+			if err := json.Unmarshal(v, &contact); err == nil && !contact.IsEmpty() {
+				return &contact
+			}
+		}
+	}
+	return nil
+}
+
+// lookupBillingScopeAlias returns the first non-empty billing scope among keys, matched case-insensitively
+func lookupBillingScopeAlias(raw map[string]json.RawMessage, keys []string) *BillingScope {
+	for _, key := range keys {
+		for k, v := range raw {
+			if !strings.EqualFold(k, key) {
+				continue
+			}
+			var scope BillingScope
+			if err := json.Unmarshal(v, &scope); err == nil && !scope.IsEmpty() {
+				return &scope
+			}
+		}
+	}
+	return nil
+}
+
+// AzureSubscriptionsResponse represents the list response
+type AzureSubscriptionsResponse = Page[AzureSubscription]
+
+// CreateAzureSubscriptionRequest represents the request to create a subscription.
+// Optional fields are omitted from the body when empty.
 type CreateAzureSubscriptionRequest struct {
-	Name string `json:"name"`
+	Name        string            `json:"name"`
+	OfferID     string            `json:"offerId,omitempty"`
+	Quantity    int               `json:"quantity,omitempty"`
+	SupportPlan string            `json:"supportPlan,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+
+	// ExtraFields are passed through verbatim; keys must be in KnownCreateFields
+	ExtraFields map[string]string `json:"-"`
+
+	// ConfirmTimeout bounds polling Azure ARM for an asynchronously created subscription before
+	// it is left pending. Defaults to DefaultConfirmTimeout.
+	ConfirmTimeout time.Duration `json:"-"`
+}
+
+// DefaultConfirmTimeout is how long asynchronous creates are polled for in Azure ARM by default
+const DefaultConfirmTimeout = 20 * time.Minute
+
+// KnownCreateFields are the create body fields that may be supplied via ExtraFields
+var KnownCreateFields = []string{"offerId", "quantity", "reference", "description"}
+
+// MarshalJSON merges ExtraFields into the body; typed fields take precedence
+func (r CreateAzureSubscriptionRequest) MarshalJSON() ([]byte, error) {
+	type plain CreateAzureSubscriptionRequest
+	typed, err := json.Marshal(plain(r))
+	if err != nil || len(r.ExtraFields) == 0 {
+		return typed, err
+	}
+
+	merged := map[string]interface{}{}
+	for k, v := range r.ExtraFields {
+		merged[k] = v
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(typed, &fields); err != nil {
+		return nil, err
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return json.Marshal(merged)
+}
+
+// Validate checks that all ExtraFields keys are known create fields
+func (r CreateAzureSubscriptionRequest) Validate() error {
+	for k := range r.ExtraFields {
+		known := false
+		for _, field := range KnownCreateFields {
+			if k == field {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown create field '%s' (known fields: %s)", k, strings.Join(KnownCreateFields, ", "))
+		}
+	}
+	return nil
 }
 
+// ErrStopIteration can be returned from a GetAzureSubscriptionsFunc callback to
+// stop paging early without reporting an error
+var ErrStopIteration = errors.New("stop iteration")
+
 // GetAzureSubscriptions retrieves all Azure subscriptions for an Azure Plan
-func (c *Client) GetAzureSubscriptions(azurePlanID int) ([]AzureSubscription, error) {
-	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions?pageSize=1000", azurePlanID)
+func (c *Client) GetAzureSubscriptions(ctx context.Context, azurePlanID int) ([]AzureSubscription, error) {
+	var subs []AzureSubscription
+	err := c.GetAzureSubscriptionsFunc(ctx, azurePlanID, func(sub AzureSubscription) error {
+		subs = append(subs, sub)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subs, nil
+}
+
+// GetAzureSubscriptionsFunc streams the Azure subscriptions of an Azure Plan page by page,
+// calling fn for each one. Returning ErrStopIteration from fn stops fetching further pages;
+// any other error is returned to the caller.
+func (c *Client) GetAzureSubscriptionsFunc(ctx context.Context, azurePlanID int, fn func(AzureSubscription) error) error {
+	return c.listAzureSubscriptions(ctx, azurePlanID, nil, fn)
+}
 
-	resp, err := c.doRequest(http.MethodGet, path, nil)
+// CountAzureSubscriptionsByStatus counts the subscriptions of an Azure Plan per lowercased
+// status, streaming the list so only the counts are held in memory
+func (c *Client) CountAzureSubscriptionsByStatus(ctx context.Context, azurePlanID int) (map[string]int, error) {
+	counts := map[string]int{}
+	err := c.GetAzureSubscriptionsFunc(ctx, azurePlanID, func(sub AzureSubscription) error {
+		status := strings.ToLower(sub.Status)
+		if status == "" {
+			status = "unknown"
+		}
+		counts[status]++
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	return counts, nil
+}
+
+// GetAzureSubscriptionsModifiedSince retrieves the Azure subscriptions of an Azure Plan that changed
+// at or after since. The filter is passed to the API and also applied client-side for servers that
+// ignore it; subscriptions that don't report when they were modified are always included.
+func (c *Client) GetAzureSubscriptionsModifiedSince(ctx context.Context, azurePlanID int, since time.Time) ([]AzureSubscription, error) {
+	filter := url.Values{}
+	filter.Set("modifiedSince", since.UTC().Format(time.RFC3339))
 
-	body, err := readResponseBody(resp)
+	var subs []AzureSubscription
+	err := c.listAzureSubscriptions(ctx, azurePlanID, filter, func(sub AzureSubscription) error {
+		if modified, ok := sub.LastModifiedTime(); ok && modified.Before(since) {
+			return nil
+		}
+		subs = append(subs, sub)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return subs, nil
+}
+
+// listAzureSubscriptions pages through the Azure subscriptions of an Azure Plan matching filter,
+// until a short page, the reported total or a repeated page ends the listing
+func (c *Client) listAzureSubscriptions(ctx context.Context, azurePlanID int, filter url.Values, fn func(AzureSubscription) error) error {
+	seen := 0
+	previousFirstID := 0
+	for page := 1; ; page++ {
+		wrapped, err := c.getAzureSubscriptionsPage(ctx, azurePlanID, page, c.config.PageSize, filter)
+		if err != nil {
+			return err
+		}
+
+		// A server that ignores the page parameter returns the first page again; without a
+		// reported total that would never end. Everything it lists has been seen by then.
+		if len(wrapped.Items) > 0 {
+			if page > 1 && wrapped.Items[0].ID != 0 && wrapped.Items[0].ID == previousFirstID {
+				tflog.Warn(ctx, "Azure subscription list returned the same page twice, assuming the API doesn't support paging", map[string]interface{}{
+					"azure_plan_id": azurePlanID,
+					"page":          page,
+					"seen":          seen,
+				})
+				return nil
+			}
+			previousFirstID = wrapped.Items[0].ID
+		}
+
+		for _, sub := range wrapped.Items {
+			status, err := c.normalizeStatus(ctx, sub.Status)
+			if err != nil {
+				return fmt.Errorf("subscription %d: %w", sub.ID, err)
+			}
+			sub.Status = status
+
+			if err := fn(sub); err != nil {
+				if err == ErrStopIteration {
+					return nil
+				}
+				return err
+			}
+		}
+
+		seen += len(wrapped.Items)
+		if wrapped.Done(seen, c.config.PageSize) {
+			return nil
+		}
+	}
+}
+
+// getAzureSubscriptionsPage retrieves a single page of Azure subscriptions
+func (c *Client) getAzureSubscriptionsPage(ctx context.Context, azurePlanID, page, pageSize int, filter url.Values) (*AzureSubscriptionsResponse, error) {
+	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions?page=%d&pageSize=%d", azurePlanID, page, pageSize)
+	if len(filter) > 0 {
+		path += "&" + filter.Encode()
+	}
 
-	// Crayon API returns wrapped format {"Items": [...], "TotalHits": N}
-	var wrapped AzureSubscriptionsResponse
-	if err := unmarshalResponse(body, resp.StatusCode, &wrapped); err != nil {
+	// Crayon API returns wrapped format {"Items": [...], "TotalHits": N}; Page also accepts its variants
+	wrapped, _, err := requestList[AzureSubscriptionsResponse](ctx, c, http.MethodGet, path, nil)
+	if err != nil {
 		return nil, err
 	}
-	return wrapped.Items, nil
+	return wrapped, nil
 }
 
-// GetAzureSubscription retrieves a single Azure subscription by ID
-func (c *Client) GetAzureSubscription(azurePlanID, subscriptionID int) (*AzureSubscription, error) {
+// GetAzureSubscription retrieves a single Azure subscription by ID.
+// Related data (e.g. "tags") can be requested in the same call via expand; servers
+// that ignore $expand simply leave the related fields empty.
+func (c *Client) GetAzureSubscription(ctx context.Context, azurePlanID, subscriptionID int, expand ...string) (*AzureSubscription, error) {
 	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions/%d", azurePlanID, subscriptionID)
+	if len(expand) > 0 {
+		path += "?$expand=" + url.QueryEscape(strings.Join(expand, ","))
+	}
 
-	resp, err := c.doRequest(http.MethodGet, path, nil)
+	result, _, err := request[AzureSubscription](ctx, c, http.MethodGet, path, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var result AzureSubscription
-	if err := parseResponse(resp, &result); err != nil {
+	status, err := c.normalizeStatus(ctx, result.Status)
+	if err != nil {
 		return nil, err
 	}
+	result.Status = status
+
+	return result, nil
+}
+
+// GetAzureSubscriptionRaw retrieves a single Azure subscription as the raw API response body.
+// Meant for diagnosing field mapping issues; the body never contains credentials.
+func (c *Client) GetAzureSubscriptionRaw(ctx context.Context, azurePlanID, subscriptionID int) (string, error) {
+	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions/%d", azurePlanID, subscriptionID)
+
+	raw, _, err := request[json.RawMessage](ctx, c, http.MethodGet, path, nil)
+	if err != nil {
+		return "", err
+	}
 
-	return &result, nil
+	return string(*raw), nil
 }
 
 // CreateAzureSubscription creates a new Azure subscription under an Azure Plan
 // Uses fire-and-forget approach: returns immediately when API accepts the request (202)
 // The subscription will be created asynchronously by Azure/Crayon
-func (c *Client) CreateAzureSubscription(azurePlanID int, name string) (*AzureSubscription, error) {
-	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions", azurePlanID)
+// If the organization requires approval, a pending_approval subscription with ApprovalRequestID is returned
+func (c *Client) CreateAzureSubscription(ctx context.Context, azurePlanID int, name string) (*AzureSubscription, error) {
+	return c.CreateAzureSubscriptionWithRequest(ctx, azurePlanID, CreateAzureSubscriptionRequest{Name: name})
+}
 
-	reqBody := CreateAzureSubscriptionRequest{
-		Name: name,
+// rejectsTags reports whether a failed create response complains about the tags field
+func rejectsTags(status int, err error) bool {
+	if status != http.StatusBadRequest && status != http.StatusUnprocessableEntity {
+		return false
 	}
+	return strings.Contains(strings.ToLower(err.Error()), "tags")
+}
 
-	resp, err := c.doRequest(http.MethodPost, path, reqBody)
-	if err != nil {
+// ErrCreateOutcomeUnknown is returned when a create request failed in a way that doesn't tell
+// whether Cloud-iQ created the subscription: no response was received, or the server failed
+// after the request reached it
+var ErrCreateOutcomeUnknown = errors.New("it is unknown whether the subscription was created")
+
+// CreateAzureSubscriptionWithRequest creates a new Azure subscription from a full create request.
+// See CreateAzureSubscription for the asynchronous (202) behavior.
+func (c *Client) CreateAzureSubscriptionWithRequest(ctx context.Context, azurePlanID int, reqBody CreateAzureSubscriptionRequest) (*AzureSubscription, error) {
+	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions", azurePlanID)
+	name := reqBody.Name
+
+	if err := reqBody.Validate(); err != nil {
 		return nil, err
 	}
+	if c.createTagsUnsupported.Load() {
+		reqBody.Tags = nil
+	}
+
+	result, header, status, err := requestWithHeaders[AzureSubscription](ctx, c, c.maxResponseSize(), http.MethodPost, path, reqBody)
+	if errors.Is(err, ErrNoResponse) || (err != nil && status >= http.StatusInternalServerError) {
+		return nil, fmt.Errorf("%w: %w", ErrCreateOutcomeUnknown, err)
+	}
 
-	var result AzureSubscription
-	err = parseResponse(resp, &result)
+	// Create endpoints without tag support reject the field; create untagged and leave tagging
+	// to the caller (see AzureSubscription.HasTags)
+	if err != nil && len(reqBody.Tags) > 0 && rejectsTags(status, err) {
+		tflog.Info(ctx, "Create endpoint does not accept tags, creating the subscription untagged", map[string]interface{}{
+			"subscription_name": name,
+		})
+		c.createTagsUnsupported.Store(true)
+		reqBody.Tags = nil
+		return c.CreateAzureSubscriptionWithRequest(ctx, azurePlanID, reqBody)
+	}
 
 	// 202 Accepted means the request was accepted but subscription creation is async
 	if err == ErrAccepted {
-		fmt.Printf("[INFO] Subscription creation request accepted (HTTP 202). The subscription '%s' is being provisioned.\n", name)
-		
+		tflog.Info(ctx, "Subscription creation accepted, provisioning asynchronously", map[string]interface{}{
+			"subscription_name": name,
+			"status_code":       status,
+		})
+		operationLocation := header.Get("Operation-Location")
+
+		// If Crayon told us where the subscription lives, we already know its ID and can skip polling
+		if id, ok := subscriptionIDFromLocation(header.Get("Location")); ok {
+			tflog.Debug(ctx, "Crayon returned the subscription ID in the Location header", map[string]interface{}{
+				"subscription_name": name,
+				"subscription_id":   id,
+			})
+			if sub, getErr := c.GetAzureSubscription(ctx, azurePlanID, id); getErr == nil {
+				return sub, nil
+			}
+			return &AzureSubscription{
+				ID:                id,
+				FriendlyName:      name,
+				SubscriptionID:    "pending",      // Azure GUID not yet available
+				Status:            "provisioning", // Indicate it's being created
+				AzurePlanID:       azurePlanID,
+				OperationLocation: operationLocation,
+			}, nil
+		}
+
 		// Always try to poll Azure directly (uses SP if configured, falls back to CLI)
-		fmt.Printf("[INFO] Polling Azure ARM to confirm subscription creation...\n")
-		guid, pollErr := c.WaitForAzureSubscription(name, 20*time.Minute)
+		confirmTimeout := reqBody.ConfirmTimeout
+		if confirmTimeout <= 0 {
+			confirmTimeout = DefaultConfirmTimeout
+		}
+		guid, pollErr := c.waitForAzureSubscription(ctx, name, confirmTimeout, operationLocation)
 		if pollErr == nil {
 			// Found in Azure!
-			fmt.Printf("[INFO] Successfully confirmed subscription creation in Azure. GUID: %s\n", guid)
+			tflog.Info(ctx, "Confirmed subscription creation in Azure", map[string]interface{}{
+				"subscription_name": name,
+				"subscription_guid": guid,
+			})
 			return &AzureSubscription{
-				ID:             0,          // Still unknown until synced to Crayon
+				ID:             0, // Still unknown until synced to Crayon
 				FriendlyName:   name,
-				SubscriptionID: guid,       // Real Azure GUID
-				Status:         "active",   // Valid in Azure
+				SubscriptionID: guid,     // Real Azure GUID
+				Status:         "active", // Valid in Azure
 				AzurePlanID:    azurePlanID,
 			}, nil
 		}
-		
-		fmt.Printf("[WARN] Failed to confirm subscription in Azure: %v. Falling back to pending state.\n", pollErr)
-		fmt.Printf("[INFO] Note: It may take several minutes for the subscription to appear in Cloud-iQ after Azure provisions it.\n")
-		fmt.Printf("[INFO] You can click 'Synchronize' in Cloud-iQ portal or run 'terraform refresh' later to update the state.\n")
+
+		// It may take several minutes for the subscription to appear in Cloud-iQ after Azure provisions it
+		tflog.Warn(ctx, "Could not confirm subscription creation in Azure, leaving it pending until Cloud-iQ syncs it", map[string]interface{}{
+			"subscription_name": name,
+			"error":             pollErr.Error(),
+		})
 		return &AzureSubscription{
-			ID:             0,              // Will be populated after sync
-			FriendlyName:   name,
-			SubscriptionID: "pending",      // Azure GUID not yet available
-			Status:         "provisioning", // Indicate it's being created
-			AzurePlanID:    azurePlanID,
+			ID:                0, // Will be populated after sync
+			FriendlyName:      name,
+			SubscriptionID:    "pending",      // Azure GUID not yet available
+			Status:            "provisioning", // Indicate it's being created
+			AzurePlanID:       azurePlanID,
+			OperationLocation: operationLocation,
 		}, nil
 	}
 
@@ -124,59 +600,254 @@ func (c *Client) CreateAzureSubscription(azurePlanID int, name string) (*AzureSu
 		return nil, err
 	}
 
-	return &result, nil
+	// Organizations with an approval workflow queue the request; the subscription is only created once approved
+	if result.AwaitingApproval() {
+		tflog.Info(ctx, "Subscription awaits approval", map[string]interface{}{
+			"subscription_name":   name,
+			"approval_request_id": result.ApprovalRequestID,
+		})
+		result.ID = 0
+		result.FriendlyName = name
+		result.SubscriptionID = "pending"
+		result.Status = StatusPendingApproval
+		result.AzurePlanID = azurePlanID
+	}
+
+	return result, nil
+}
+
+// subscriptionIDFromLocation extracts the numeric Crayon subscription ID from a Location
+// header such as /api/v1/azureplans/1/azuresubscriptions/12345
+func subscriptionIDFromLocation(location string) (int, bool) {
+	if location == "" {
+		return 0, false
+	}
+	if u, err := url.Parse(location); err == nil {
+		location = u.Path
+	}
+	location = strings.TrimSuffix(location, "/")
+	id, err := strconv.Atoi(location[strings.LastIndex(location, "/")+1:])
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// ErrCreateRejected is returned by CreateAzureSubscriptionDryRun when Cloud-iQ would reject the create
+var ErrCreateRejected = errors.New("cloud-iq would reject the subscription")
+
+// ErrDryRunNotSupported indicates the Crayon API has no endpoint to validate a create without performing it
+var ErrDryRunNotSupported = errors.New("the Crayon API does not support validating subscription creation")
+
+// CreateAzureSubscriptionDryRun asks Cloud-iQ whether a create request would be accepted (valid name,
+// capacity left in the Azure Plan, offer available) without creating anything. A dedicated endpoint
+// is used so an API without preflight support can't mistake the request for a real create. Once
+// the API reports the endpoint missing, later calls return ErrDryRunNotSupported without a request.
+func (c *Client) CreateAzureSubscriptionDryRun(ctx context.Context, azurePlanID int, reqBody CreateAzureSubscriptionRequest) error {
+	if err := reqBody.Validate(); err != nil {
+		return err
+	}
+	if c.dryRunUnsupported.Load() {
+		return ErrDryRunNotSupported
+	}
+
+	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions/validate", azurePlanID)
+
+	status, err := c.requestNoContent(ctx, http.MethodPost, path, reqBody)
+	switch status {
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		c.dryRunUnsupported.Store(true)
+		return fmt.Errorf("%w (status %d)", ErrDryRunNotSupported, status)
+	case http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity:
+		return fmt.Errorf("%w: %w", ErrCreateRejected, err)
+	}
+	if err != nil {
+		return fmt.Errorf("dry-run request failed: %w", err)
+	}
+
+	return nil
 }
 
 // RenameAzureSubscription renames an Azure subscription
-func (c *Client) RenameAzureSubscription(azurePlanID, subscriptionID int, newName string) (*AzureSubscription, error) {
+func (c *Client) RenameAzureSubscription(ctx context.Context, azurePlanID, subscriptionID int, newName string) (*AzureSubscription, error) {
 	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions/%d/rename", azurePlanID, subscriptionID)
 
 	reqBody := map[string]string{
 		"name": newName,
 	}
 
-	resp, err := c.doRequest(http.MethodPatch, path, reqBody)
+	result, _, err := request[AzureSubscription](ctx, c, http.MethodPatch, path, reqBody)
 	if err != nil {
 		return nil, err
 	}
 
-	var result AzureSubscription
-	if err := parseResponse(resp, &result); err != nil {
-		return nil, err
-	}
-
-	return &result, nil
+	return result, nil
 }
 
-// CancelAzureSubscription cancels an Azure subscription
-func (c *Client) CancelAzureSubscription(azurePlanID, subscriptionID int) error {
+// ErrScheduledCancellationRejected is returned when Cloud-iQ refuses to schedule a cancellation
+var ErrScheduledCancellationRejected = errors.New("cloud-iq rejected the scheduled cancellation")
+
+// CancelAzureSubscription cancels an Azure subscription. A non-zero effectiveDate schedules the
+// cancellation for that day (e.g. the end of the billing period) instead of cancelling immediately.
+func (c *Client) CancelAzureSubscription(ctx context.Context, azurePlanID, subscriptionID int, effectiveDate time.Time) error {
 	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions/%d/cancel", azurePlanID, subscriptionID)
 
-	resp, err := c.doRequest(http.MethodPost, path, nil)
-	if err != nil {
-		return err
+	if effectiveDate.IsZero() {
+		if _, err := c.requestNoContent(ctx, http.MethodPost, path, nil); err != nil {
+			return fmt.Errorf("cancel request failed: %w", err)
+		}
+		return nil
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("cancel request failed with status %d", resp.StatusCode)
+	reqBody := map[string]string{
+		"effectiveDate": effectiveDate.Format("2006-01-02"),
+	}
+
+	status, err := c.requestNoContent(ctx, http.MethodPost, path, reqBody)
+
+	// Offers or API versions without scheduled cancellation reject the effective date
+	if status == http.StatusBadRequest || status == http.StatusConflict || status == http.StatusUnprocessableEntity {
+		return fmt.Errorf("%w: %w", ErrScheduledCancellationRejected, err)
+	}
+
+	if err != nil {
+		return fmt.Errorf("cancel request failed: %w", err)
 	}
 
 	return nil
 }
 
 // EnableAzureSubscription enables a cancelled Azure subscription
-func (c *Client) EnableAzureSubscription(azurePlanID, subscriptionID int) error {
+func (c *Client) EnableAzureSubscription(ctx context.Context, azurePlanID, subscriptionID int) error {
 	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions/%d/enable", azurePlanID, subscriptionID)
 
-	resp, err := c.doRequest(http.MethodPost, path, nil)
+	status, err := c.requestNoContent(ctx, http.MethodPost, path, nil)
+
+	// Enabling an already-active subscription is rejected with 409; treat it as a no-op
+	if status == http.StatusConflict && c.isSubscriptionActive(ctx, azurePlanID, subscriptionID) {
+		return nil
+	}
+
 	if err != nil {
-		return err
+		return fmt.Errorf("enable request failed: %w", err)
+	}
+
+	return nil
+}
+
+// isSubscriptionActive reports whether the subscription is currently active
+func (c *Client) isSubscriptionActive(ctx context.Context, azurePlanID, subscriptionID int) bool {
+	sub, err := c.GetAzureSubscription(ctx, azurePlanID, subscriptionID)
+	return err == nil && strings.EqualFold(sub.Status, "active")
+}
+
+// SetAzureSubscriptionPartnerOfRecord sets the Partner of Record (PoR) of an Azure subscription
+func (c *Client) SetAzureSubscriptionPartnerOfRecord(ctx context.Context, azurePlanID, subscriptionID int, partnerID string) error {
+	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions/%d/partnerofrecord", azurePlanID, subscriptionID)
+
+	reqBody := map[string]string{
+		"partnerId": partnerID,
+	}
+
+	if _, err := c.requestNoContent(ctx, http.MethodPatch, path, reqBody); err != nil {
+		return fmt.Errorf("set partner of record request failed: %w", err)
+	}
+
+	return nil
+}
+
+// ErrQuantityNotChangeable is returned when the subscription's offer doesn't allow changing its quantity
+var ErrQuantityNotChangeable = errors.New("the subscription's offer does not allow changing its quantity")
+
+// SetAzureSubscriptionQuantity changes the quantity (seats) of a quantity-based subscription
+func (c *Client) SetAzureSubscriptionQuantity(ctx context.Context, azurePlanID, subscriptionID, quantity int) error {
+	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions/%d/quantity", azurePlanID, subscriptionID)
+
+	reqBody := map[string]int{
+		"quantity": quantity,
+	}
+
+	status, err := c.requestNoContent(ctx, http.MethodPatch, path, reqBody)
+
+	// Offers that aren't billed by quantity reject the change
+	if status == http.StatusMethodNotAllowed || status == http.StatusConflict {
+		return fmt.Errorf("%w: %w", ErrQuantityNotChangeable, err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("enable request failed with status %d", resp.StatusCode)
+	if err != nil {
+		return fmt.Errorf("set quantity request failed: %w", err)
+	}
+
+	return nil
+}
+
+// ErrAutoRenewNotApplicable is returned when the subscription's offer has no auto-renewal toggle
+var ErrAutoRenewNotApplicable = errors.New("auto-renewal does not apply to the subscription's offer")
+
+// GetAzureSubscriptionAutoRenew reports whether a subscription renews automatically, or nil if
+// auto-renewal doesn't apply to its offer
+func (c *Client) GetAzureSubscriptionAutoRenew(ctx context.Context, azurePlanID, subscriptionID int) (*bool, error) {
+	subscription, err := c.GetAzureSubscription(ctx, azurePlanID, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	return subscription.AutoRenew, nil
+}
+
+// SetAzureSubscriptionAutoRenew turns automatic renewal of a subscription's term on or off
+func (c *Client) SetAzureSubscriptionAutoRenew(ctx context.Context, azurePlanID, subscriptionID int, enabled bool) error {
+	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions/%d/autorenew", azurePlanID, subscriptionID)
+
+	reqBody := map[string]bool{
+		"autoRenew": enabled,
+	}
+
+	status, err := c.requestNoContent(ctx, http.MethodPatch, path, reqBody)
+
+	// Offers without a term (e.g. pay-as-you-go Azure Plan subscriptions) reject the change
+	if status == http.StatusMethodNotAllowed || status == http.StatusConflict {
+		return fmt.Errorf("%w: %w", ErrAutoRenewNotApplicable, err)
+	}
+
+	if err != nil {
+		return fmt.Errorf("set auto-renew request failed: %w", err)
+	}
+
+	return nil
+}
+
+// SuspendAzureSubscription suspends an active Azure subscription
+func (c *Client) SuspendAzureSubscription(ctx context.Context, azurePlanID, subscriptionID int) error {
+	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions/%d/suspend", azurePlanID, subscriptionID)
+
+	if _, err := c.requestNoContent(ctx, http.MethodPost, path, nil); err != nil {
+		return fmt.Errorf("suspend request failed: %w", err)
+	}
+
+	return nil
+}
+
+// ErrReactivationWindowExpired indicates a cancelled subscription can no longer be reactivated
+var ErrReactivationWindowExpired = errors.New("reactivation grace period has expired")
+
+// ReactivateAzureSubscription reactivates a cancelled Azure subscription within its grace period
+func (c *Client) ReactivateAzureSubscription(ctx context.Context, azurePlanID, subscriptionID int) error {
+	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions/%d/reactivate", azurePlanID, subscriptionID)
+
+	status, err := c.requestNoContent(ctx, http.MethodPost, path, nil)
+
+	// Reactivating an already-active subscription is a no-op
+	if status == http.StatusConflict && c.isSubscriptionActive(ctx, azurePlanID, subscriptionID) {
+		return nil
+	}
+
+	// Crayon rejects reactivation of subscriptions past the grace window with 409/410
+	if status == http.StatusConflict || status == http.StatusGone {
+		return fmt.Errorf("%w: %w", ErrReactivationWindowExpired, err)
+	}
+
+	if err != nil {
+		return fmt.Errorf("reactivate request failed: %w", err)
 	}
 
 	return nil
@@ -190,87 +861,386 @@ type AzureARMSubscription struct {
 }
 
 type AzureARMSubscriptionList struct {
-	Value []AzureARMSubscription `json:"value"`
+	Value    []AzureARMSubscription `json:"value"`
+	NextLink string                 `json:"nextLink,omitempty"`
 }
 
-// WaitForAzureSubscription polls Azure ARM for a subscription with the given name
-// Returns the Azure Subscription GUID if found
-func (c *Client) WaitForAzureSubscription(name string, timeout time.Duration) (string, error) {
-	token, err := c.getAzureToken()
-	if err != nil {
-		fmt.Printf("[ERROR] Azure authentication failed: %v\n", err)
-		return "", fmt.Errorf("azure auth failed: %w", err)
-	}
+// armDisplayNameFilter returns the OData $filter selecting subscriptions by display name
+func armDisplayNameFilter(name string) string {
+	return "displayName eq '" + strings.ReplaceAll(name, "'", "''") + "'"
+}
 
-	fmt.Printf("[INFO] Polling Azure ARM for subscription '%s' (timeout: %v)...\n", name, timeout)
-	
-	deadline := time.Now().Add(timeout)
-	pollInterval := 30 * time.Second
-	
-	// Poll immediately, then every 30 seconds
-	for {
-		// Check if we've exceeded timeout
-		if time.Now().After(deadline) {
-			return "", fmt.Errorf("timeout waiting for subscription '%s' to appear in Azure", name)
-		}
+// listARMSubscriptions lists the Azure subscriptions visible to token, following nextLink across
+// pages. filter is an OData $filter ARM may apply server-side, or empty to list all. Non-200
+// responses return an *APIError, together with how long ARM asks to wait if it is throttling.
+func (c *Client) listARMSubscriptions(ctx context.Context, token, filter string) ([]AzureARMSubscription, time.Duration, error) {
+	pageURL := c.armURL("/subscriptions?api-version=" + armSubscriptionAPIVersion)
+	if filter != "" {
+		// ARM expects spaces in $filter as %20 rather than +
+		pageURL += "&$filter=" + strings.ReplaceAll(url.QueryEscape(filter), "+", "%20")
+	}
 
-		// List subscriptions: GET https://management.azure.com/subscriptions?api-version=2022-12-01
-		req, err := http.NewRequest("GET", "https://management.azure.com/subscriptions?api-version=2022-12-01", nil)
+	var subscriptions []AzureARMSubscription
+	visited := map[string]bool{}
+	for pageURL != "" {
+		visited[pageURL] = true
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
 		if err != nil {
-			return "", err
+			return nil, 0, err
 		}
 		req.Header.Set("Authorization", "Bearer "+token)
 
 		resp, err := c.httpClient.Do(req)
 		if err != nil {
-			fmt.Printf("[WARN] Failed to list Azure subscriptions: %v\n", err)
-			time.Sleep(pollInterval)
-			continue
+			return nil, 0, err
 		}
-		
-		body, err := io.ReadAll(resp.Body)
+
+		body, err := readResponseBody(resp.Body, c.maxListResponseSize())
 		resp.Body.Close()
 		if err != nil {
-			time.Sleep(pollInterval)
+			return nil, 0, fmt.Errorf("failed to read azure subscriptions response: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, parseRetryAfter(resp.Header), newAPIError(resp, body)
+		}
+
+		if remaining, ok := armRateLimitRemaining(resp.Header); ok && remaining < armRateLimitLowWatermark {
+			tflog.Warn(ctx, "Azure ARM read rate limit nearly exhausted", map[string]interface{}{
+				"remaining_requests": remaining,
+			})
+		}
+
+		var page AzureARMSubscriptionList
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, 0, fmt.Errorf("failed to parse azure subscriptions response: %w", err)
+		}
+		subscriptions = append(subscriptions, page.Value...)
+
+		// Only follow links back to ARM, so the Azure token is never sent elsewhere
+		if page.NextLink != "" && !strings.HasPrefix(page.NextLink, c.armURL("/")) {
+			return nil, 0, fmt.Errorf("unexpected azure subscriptions next link: %s", page.NextLink)
+		}
+		// A page linking back to one already read would otherwise be followed forever; every
+		// subscription ARM lists has been read by then
+		if visited[page.NextLink] {
+			tflog.Warn(ctx, "Azure subscriptions next link repeats an earlier page, ending the listing", map[string]interface{}{
+				"next_link": page.NextLink,
+			})
+			break
+		}
+		pageURL = page.NextLink
+	}
+
+	return subscriptions, 0, nil
+}
+
+// armRateLimitLowWatermark is the number of remaining ARM reads below which a warning is logged
+const armRateLimitLowWatermark = 10
+
+// armRateLimitRemaining returns the lowest x-ms-ratelimit-remaining-*-reads value reported by ARM
+func armRateLimitRemaining(header http.Header) (int, bool) {
+	remaining, found := 0, false
+	for _, key := range []string{"x-ms-ratelimit-remaining-subscription-reads", "x-ms-ratelimit-remaining-tenant-reads"} {
+		value := header.Get(key)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
 			continue
 		}
+		if !found || n < remaining {
+			remaining, found = n, true
+		}
+	}
+	return remaining, found
+}
+
+// ErrStatusWaitTimeout is returned when a subscription doesn't reach the awaited status in time
+var ErrStatusWaitTimeout = errors.New("timed out waiting for the subscription status")
+
+// WaitForSubscriptionStatus polls a subscription in Cloud-iQ until its status is one of statuses
+// (case-insensitive) and returns it. It gives up with ErrStatusWaitTimeout after timeout, and
+// with ctx.Err() or ErrStopped when ctx is cancelled or the provider is stopping.
+func (c *Client) WaitForSubscriptionStatus(ctx context.Context, azurePlanID, subscriptionID int, statuses []string, timeout time.Duration) (*AzureSubscription, error) {
+	poll := &Backoff{Base: 5 * time.Second, Max: 30 * time.Second, Deadline: time.Now().Add(timeout)}
+	for {
+		sub, err := c.GetAzureSubscription(ctx, azurePlanID, subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		for _, status := range statuses {
+			if strings.EqualFold(sub.Status, status) {
+				return sub, nil
+			}
+		}
+
+		tflog.Debug(ctx, "Waiting for subscription status", map[string]interface{}{
+			"subscription_id": subscriptionID,
+			"status":          sub.Status,
+			"target_statuses": strings.Join(statuses, ","),
+			"attempt":         poll.Attempt() + 1,
+		})
+		if err := c.wait(ctx, poll, 0); err != nil {
+			if errors.Is(err, ErrBackoffExhausted) {
+				return sub, fmt.Errorf("%w: subscription %d is still %s after %v", ErrStatusWaitTimeout, subscriptionID, sub.Status, timeout)
+			}
+			return sub, err
+		}
+	}
+}
+
+// WaitForAzureSubscription polls Azure ARM for a subscription with the given name
+// Returns the Azure Subscription GUID if found
+func (c *Client) WaitForAzureSubscription(ctx context.Context, name string, timeout time.Duration) (string, error) {
+	return c.waitForAzureSubscription(ctx, name, timeout, "")
+}
 
-		if resp.StatusCode != 200 {
-			fmt.Printf("[WARN] Azure API returned status %d: %s\n", resp.StatusCode, string(body))
-			time.Sleep(pollInterval)
+// waitForAzureSubscription is WaitForAzureSubscription that also logs the provisioning
+// progress reported by the create operation, if any
+func (c *Client) waitForAzureSubscription(ctx context.Context, name string, timeout time.Duration, operationLocation string) (string, error) {
+	token, err := c.getAzureToken(ctx)
+	if err != nil {
+		tflog.Error(ctx, "Azure authentication failed", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return "", fmt.Errorf("%w: %w", ErrARMUnavailable, err)
+	}
+
+	tflog.Info(ctx, "Polling Azure ARM for subscription", map[string]interface{}{
+		"subscription_name": name,
+		"timeout":           timeout.String(),
+	})
+
+	pollInterval := 30 * time.Second
+	poll := &Backoff{Base: pollInterval, Multiplier: 1, Deadline: time.Now().Add(timeout)}
+	targetState := c.armTargetState()
+	lastState := ""
+	wait := func(minWait time.Duration) error {
+		err := c.wait(ctx, poll, minWait)
+		if errors.Is(err, ErrBackoffExhausted) {
+			if lastState != "" {
+				return fmt.Errorf("timeout waiting for subscription '%s' to become %s in Azure (state: %s)", name, targetState, lastState)
+			}
+			return fmt.Errorf("timeout waiting for subscription '%s' to appear in Azure", name)
+		}
+		return err
+	}
+
+	ctx, cancel := c.withStop(ctx)
+	defer cancel()
+
+	// Filter by display name server-side, unless ARM rejects the filter
+	useFilter := true
+
+	// Poll immediately, then every 30 seconds
+	for {
+		filter := ""
+		if useFilter {
+			filter = armDisplayNameFilter(name)
+		}
+		subscriptions, retryAfter, err := c.listARMSubscriptions(ctx, token, filter)
+
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && useFilter && apiErr.StatusCode == http.StatusBadRequest {
+			// Scan the full listing client-side from now on, as without the filter
+			tflog.Debug(ctx, "Azure ARM rejected the display name filter, listing all subscriptions", map[string]interface{}{
+				"status_code":   apiErr.StatusCode,
+				"response_body": apiErr.Body,
+			})
+			useFilter = false
 			continue
 		}
 
-		var list AzureARMSubscriptionList
-		if err := json.Unmarshal(body, &list); err != nil {
-			time.Sleep(pollInterval)
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests {
+			// ARM throttling: back off for as long as ARM asks, but never past the deadline
+			tflog.Warn(ctx, "Azure ARM is rate limiting subscription list requests, backing off", map[string]interface{}{
+				"status_code": apiErr.StatusCode,
+				"retry_after": retryAfter.String(),
+			})
+			if err := wait(retryAfter); err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		if errors.As(err, &apiErr) {
+			tflog.Warn(ctx, "Azure ARM returned an unexpected status listing subscriptions", map[string]interface{}{
+				"status_code":   apiErr.StatusCode,
+				"response_body": apiErr.Body,
+			})
+		} else if err != nil {
+			tflog.Warn(ctx, "Failed to list Azure subscriptions", map[string]interface{}{
+				"subscription_name": name,
+				"attempt":           poll.Attempt() + 1,
+				"error":             err.Error(),
+			})
+		}
+		if err != nil {
+			if err := wait(0); err != nil {
+				return "", err
+			}
 			continue
 		}
 
-		for _, sub := range list.Value {
-			if sub.DisplayName == name {
-				fmt.Printf("[INFO] Found subscription in Azure! GUID: %s, State: %s\n", sub.SubscriptionID, sub.State)
+		// The filter may be ignored, so names are matched here either way
+		found := false
+		for _, sub := range subscriptions {
+			if sub.DisplayName != name {
+				continue
+			}
+			// Subscriptions can appear Warned, PastDue or Disabled before they are usable
+			if targetState == ARMTargetStateAny || strings.EqualFold(sub.State, targetState) {
+				tflog.Info(ctx, "Found subscription in Azure", map[string]interface{}{
+					"subscription_name": name,
+					"subscription_guid": sub.SubscriptionID,
+					"state":             sub.State,
+				})
 				return sub.SubscriptionID, nil
 			}
+			found = true
+			lastState = sub.State
 		}
 
-		fmt.Printf("[INFO] Subscription '%s' not found yet. Waiting %v before next check...\n", name, pollInterval)
-		time.Sleep(pollInterval)
+		fields := map[string]interface{}{
+			"subscription_name": name,
+			"attempt":           poll.Attempt() + 1,
+			"poll_interval":     pollInterval.String(),
+		}
+		if found {
+			fields["state"] = lastState
+			fields["target_state"] = targetState
+			tflog.Debug(ctx, "Subscription found in Azure but not yet in the target state", fields)
+		} else if progress, ok := c.GetSubscriptionOperationProgress(ctx, operationLocation); ok {
+			fields["progress_percent"] = progress
+			tflog.Debug(ctx, "Subscription not found in Azure yet", fields)
+		} else {
+			tflog.Debug(ctx, "Subscription not found in Azure yet", fields)
+		}
+		if err := wait(0); err != nil {
+			return "", err
+		}
 	}
 }
+
 // FindAzureSubscriptionByName searches for a subscription by name in an Azure Plan
 // Returns the subscription if found, or an error if not found
-func (c *Client) FindAzureSubscriptionByName(azurePlanID int, name string) (*AzureSubscription, error) {
-	subs, err := c.GetAzureSubscriptions(azurePlanID)
+func (c *Client) FindAzureSubscriptionByName(ctx context.Context, azurePlanID int, name string) (*AzureSubscription, error) {
+	var found *AzureSubscription
+	err := c.GetAzureSubscriptionsFunc(ctx, azurePlanID, func(sub AzureSubscription) error {
+		if sub.FriendlyName == name {
+			found = &sub
+			return ErrStopIteration
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
 	}
 
-	for _, sub := range subs {
+	if found == nil {
+		return nil, fmt.Errorf("subscription '%s' not found in Azure Plan %d", name, azurePlanID)
+	}
+
+	return found, nil
+}
+
+// FindAzureSubscriptionsByName returns every subscription with the given name in an Azure Plan.
+// Cloud-iQ doesn't enforce unique names, so callers that need a single subscription must check
+// there is exactly one.
+func (c *Client) FindAzureSubscriptionsByName(ctx context.Context, azurePlanID int, name string) ([]AzureSubscription, error) {
+	var found []AzureSubscription
+	err := c.GetAzureSubscriptionsFunc(ctx, azurePlanID, func(sub AzureSubscription) error {
 		if sub.FriendlyName == name {
-			return &sub, nil
+			found = append(found, sub)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+
+	return found, nil
+}
+
+// FindAzureSubscriptionByGUID searches for a subscription by its Azure subscription GUID in an Azure Plan
+// Returns the subscription if found, or an error if not found
+func (c *Client) FindAzureSubscriptionByGUID(ctx context.Context, azurePlanID int, guid string) (*AzureSubscription, error) {
+	var found *AzureSubscription
+	err := c.GetAzureSubscriptionsFunc(ctx, azurePlanID, func(sub AzureSubscription) error {
+		if strings.EqualFold(sub.SubscriptionID, guid) {
+			found = &sub
+			return ErrStopIteration
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("subscription %s not found in Azure Plan %d", guid, azurePlanID)
 	}
 
-	return nil, fmt.Errorf("subscription '%s' not found in Azure Plan %d", name, azurePlanID)
+	return found, nil
+}
+
+// ErrSyncNotSupported indicates the Crayon API has no endpoint to trigger a Cloud-iQ sync
+var ErrSyncNotSupported = errors.New("the Crayon API does not support triggering a sync")
+
+// TriggerAzurePlanSync asks Cloud-iQ to synchronize the subscriptions of an Azure Plan from Azure,
+// the API equivalent of the portal's 'Synchronize' button. The sync itself runs asynchronously.
+func (c *Client) TriggerAzurePlanSync(ctx context.Context, azurePlanID int) error {
+	path := fmt.Sprintf("/api/v1/azureplans/%d/synchronize", azurePlanID)
+
+	status, err := c.requestNoContent(ctx, http.MethodPost, path, nil)
+	if status == http.StatusNotFound || status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented {
+		return fmt.Errorf("%w (status %d)", ErrSyncNotSupported, status)
+	}
+	if err != nil {
+		return fmt.Errorf("sync request failed: %w", err)
+	}
+
+	tflog.Info(ctx, "Triggered Cloud-iQ sync of Azure Plan", map[string]interface{}{
+		"azure_plan_id": azurePlanID,
+	})
+	return nil
+}
+
+// TriggerOrganizationSync asks Cloud-iQ to synchronize all Azure Plans of an organization from Azure
+func (c *Client) TriggerOrganizationSync(ctx context.Context, organizationID int64) error {
+	path := fmt.Sprintf("/api/v1/organizations/%d/synchronize", organizationID)
+
+	status, err := c.requestNoContent(ctx, http.MethodPost, path, nil)
+	if status == http.StatusNotFound || status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented {
+		return fmt.Errorf("%w (status %d)", ErrSyncNotSupported, status)
+	}
+	if err != nil {
+		return fmt.Errorf("sync request failed: %w", err)
+	}
+
+	tflog.Info(ctx, "Triggered Cloud-iQ sync of organization", map[string]interface{}{
+		"organization_id": organizationID,
+	})
+	return nil
+}
+
+// TriggerCloudIQSync asks Cloud-iQ to pull newly created subscriptions of an Azure Plan from Azure.
+// The Azure Plan sync is preferred; where the API lacks it, the configured organization is synced
+// instead. Once the API reports neither is supported, later calls return ErrSyncNotSupported
+// without a request.
+func (c *Client) TriggerCloudIQSync(ctx context.Context, azurePlanID int) error {
+	if c.syncUnsupported.Load() {
+		return ErrSyncNotSupported
+	}
+
+	err := c.TriggerAzurePlanSync(ctx, azurePlanID)
+	if errors.Is(err, ErrSyncNotSupported) && c.config.OrganizationID != 0 {
+		err = c.TriggerOrganizationSync(ctx, c.config.OrganizationID)
+	}
+	if errors.Is(err, ErrSyncNotSupported) {
+		c.syncUnsupported.Store(true)
+	}
+	return err
 }
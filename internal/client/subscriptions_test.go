@@ -0,0 +1,1330 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testPlanID = 873834
+
+// testSubscriptions returns n active subscriptions with IDs 1 to n
+func testSubscriptions(n int) []AzureSubscription {
+	subs := make([]AzureSubscription, n)
+	for i := range subs {
+		subs[i] = AzureSubscription{ID: i + 1, FriendlyName: "sub-" + strconv.Itoa(i+1), Status: "Active", AzurePlanID: testPlanID}
+	}
+	return subs
+}
+
+// pagingHandler serves subs in pages of the requested size, counting the requests. Unless
+// reportTotal is set, the total is left out, and with ignorePage every request gets the first page.
+func pagingHandler(subs []AzureSubscription, reportTotal, ignorePage bool, requests *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+		if ignorePage {
+			page = 1
+		}
+
+		start := min((page-1)*pageSize, len(subs))
+		end := min(start+pageSize, len(subs))
+		body := map[string]interface{}{"Items": subs[start:end]}
+		if reportTotal {
+			body["TotalHits"] = len(subs)
+		}
+		writeJSON(w, http.StatusOK, body)
+	}
+}
+
+func TestGetAzureSubscriptionsPaging(t *testing.T) {
+	tests := map[string]struct {
+		subs         int
+		reportTotal  bool
+		ignorePage   bool
+		wantSubs     int
+		wantRequests int32
+	}{
+		"short last page": {
+			subs: 5, reportTotal: true,
+			wantSubs: 5, wantRequests: 3,
+		},
+		"full last page ends at the total": {
+			subs: 4, reportTotal: true,
+			wantSubs: 4, wantRequests: 2,
+		},
+		"full last page without a total": {
+			subs:     4,
+			wantSubs: 4, wantRequests: 3,
+		},
+		"paging ignored": {
+			subs: 5, reportTotal: true, ignorePage: true,
+			wantSubs: 2, wantRequests: 2,
+		},
+		"paging ignored without a total": {
+			subs: 5, ignorePage: true,
+			wantSubs: 2, wantRequests: 2,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var requests int32
+			handler := pagingHandler(testSubscriptions(test.subs), test.reportTotal, test.ignorePage, &requests)
+			c := newTestClient(t, ClientConfig{PageSize: 2}, handler)
+
+			subs, err := c.GetAzureSubscriptions(context.Background(), testPlanID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(subs) != test.wantSubs {
+				t.Errorf("got %d subscriptions, want %d", len(subs), test.wantSubs)
+			}
+			for i, sub := range subs {
+				if sub.ID != i+1 {
+					t.Errorf("subscription %d has ID %d, want each listed once in order", i, sub.ID)
+				}
+			}
+			if requests != test.wantRequests {
+				t.Errorf("made %d list requests, want %d", requests, test.wantRequests)
+			}
+		})
+	}
+}
+
+func TestPageSize(t *testing.T) {
+	for _, pageSize := range []int{-1, MaxPageSize + 1} {
+		if _, err := NewClient(ClientConfig{PageSize: pageSize}); err == nil {
+			t.Errorf("NewClient accepted page size %d", pageSize)
+		}
+	}
+
+	tests := map[string]struct {
+		pageSize int
+		want     string
+	}{
+		"default":    {want: strconv.Itoa(DefaultPageSize)},
+		"configured": {pageSize: 250, want: "250"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var sent []string
+			c := newTestClient(t, ClientConfig{PageSize: test.pageSize}, func(w http.ResponseWriter, r *http.Request) {
+				sent = append(sent, r.URL.Query().Get("pageSize"))
+				writeJSON(w, http.StatusOK, map[string]interface{}{"Items": testSubscriptions(1), "TotalHits": 1})
+			})
+
+			if _, err := c.GetAzureSubscriptions(context.Background(), testPlanID); err != nil {
+				t.Fatal(err)
+			}
+			if len(sent) != 1 || sent[0] != test.want {
+				t.Errorf("requested page sizes %v, want %s", sent, test.want)
+			}
+		})
+	}
+}
+
+func TestGetAzureSubscriptionsFuncStops(t *testing.T) {
+	var requests int32
+	c := newTestClient(t, ClientConfig{PageSize: 2}, pagingHandler(testSubscriptions(6), true, false, &requests))
+
+	seen := 0
+	err := c.GetAzureSubscriptionsFunc(context.Background(), testPlanID, func(sub AzureSubscription) error {
+		seen++
+		if sub.ID == 3 {
+			return ErrStopIteration
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seen != 3 || requests != 2 {
+		t.Errorf("saw %d subscriptions in %d requests, want 3 in 2", seen, requests)
+	}
+}
+
+func TestCreateAzureSubscriptionWithRequest(t *testing.T) {
+	createPath := "/api/v1/azureplans/873834/azuresubscriptions"
+	created := AzureSubscription{ID: 42, FriendlyName: "app-prod", Status: "Active", AzurePlanID: testPlanID}
+
+	tests := map[string]struct {
+		respond func(w http.ResponseWriter)
+		wantID  int
+		wantErr func(error) bool
+	}{
+		"200": {
+			respond: func(w http.ResponseWriter) { writeJSON(w, http.StatusOK, created) },
+			wantID:  42,
+		},
+		"202 with the subscription's location": {
+			respond: func(w http.ResponseWriter) {
+				w.Header().Set("Location", createPath+"/42")
+				w.WriteHeader(http.StatusAccepted)
+			},
+			wantID: 42,
+		},
+		"404": {
+			respond: func(w http.ResponseWriter) { http.Error(w, "no such plan", http.StatusNotFound) },
+			wantErr: IsNotFound,
+		},
+		"500": {
+			respond: func(w http.ResponseWriter) { http.Error(w, "boom", http.StatusInternalServerError) },
+			wantErr: func(err error) bool {
+				return errors.Is(err, ErrCreateOutcomeUnknown) && IsStatus(err, http.StatusInternalServerError)
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPost && r.URL.Path == createPath {
+					test.respond(w)
+					return
+				}
+				if r.Method == http.MethodGet && r.URL.Path == createPath+"/42" {
+					writeJSON(w, http.StatusOK, created)
+					return
+				}
+				http.NotFound(w, r)
+			})
+
+			sub, err := c.CreateAzureSubscriptionWithRequest(context.Background(), testPlanID, CreateAzureSubscriptionRequest{Name: "app-prod"})
+			if test.wantErr != nil {
+				if err == nil || !test.wantErr(err) {
+					t.Fatalf("err = %v, want a matching error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sub.ID != test.wantID {
+				t.Errorf("ID = %d, want %d", sub.ID, test.wantID)
+			}
+		})
+	}
+}
+
+// createTagsHandler serves creates of subscription 42, rejecting the tags field unless acceptTags is set,
+// and records whether each create request carried tags
+func createTagsHandler(acceptTags bool, sentTags *[]bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/azureplans/873834/azuresubscriptions" {
+			http.NotFound(w, r)
+			return
+		}
+		var body CreateAzureSubscriptionRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		*sentTags = append(*sentTags, body.Tags != nil)
+		if body.Tags != nil && !acceptTags {
+			http.Error(w, `{"Message":"Unknown field 'tags'"}`, http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusOK, AzureSubscription{ID: 42, FriendlyName: body.Name, Status: "Active", Tags: body.Tags})
+	}
+}
+
+func TestCreateAzureSubscriptionTags(t *testing.T) {
+	tags := map[string]string{"owner": "data", "cost-center": "42"}
+
+	t.Run("inline", func(t *testing.T) {
+		var sentTags []bool
+		c := newTestClient(t, ClientConfig{}, createTagsHandler(true, &sentTags))
+
+		sub, err := c.CreateAzureSubscriptionWithRequest(context.Background(), testPlanID, CreateAzureSubscriptionRequest{Name: "app-prod", Tags: tags})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(sentTags, []bool{true}) {
+			t.Errorf("sent tags in creates %v, want once", sentTags)
+		}
+		if !sub.HasTags(tags) {
+			t.Errorf("tags = %v, want the subscription created with %v", sub.Tags, tags)
+		}
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		var sentTags []bool
+		c := newTestClient(t, ClientConfig{}, createTagsHandler(false, &sentTags))
+
+		sub, err := c.CreateAzureSubscriptionWithRequest(context.Background(), testPlanID, CreateAzureSubscriptionRequest{Name: "app-prod", Tags: tags})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(sentTags, []bool{true, false}) {
+			t.Errorf("sent tags in creates %v, want a retry without them", sentTags)
+		}
+		if sub.ID != 42 || sub.HasTags(tags) {
+			t.Errorf("created %+v, want subscription 42 untagged so the caller tags it", sub)
+		}
+
+		// The endpoint is remembered not to accept tags
+		sentTags = nil
+		if _, err := c.CreateAzureSubscriptionWithRequest(context.Background(), testPlanID, CreateAzureSubscriptionRequest{Name: "app-dev", Tags: tags}); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(sentTags, []bool{false}) {
+			t.Errorf("sent tags in creates %v, want them left out right away", sentTags)
+		}
+	})
+
+	t.Run("other bad request", func(t *testing.T) {
+		var requests int32
+		c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			http.Error(w, `{"Message":"Invalid offer"}`, http.StatusBadRequest)
+		})
+
+		_, err := c.CreateAzureSubscriptionWithRequest(context.Background(), testPlanID, CreateAzureSubscriptionRequest{Name: "app-prod", Tags: tags})
+		if !IsStatus(err, http.StatusBadRequest) {
+			t.Errorf("err = %v, want the bad request", err)
+		}
+		if requests != 1 {
+			t.Errorf("sent %d creates, want the failure returned without a retry", requests)
+		}
+	})
+}
+
+func TestCreateAzureSubscriptionRequestJSON(t *testing.T) {
+	tests := map[string]struct {
+		req  CreateAzureSubscriptionRequest
+		want string
+	}{
+		"name only": {
+			req:  CreateAzureSubscriptionRequest{Name: "app-prod", ConfirmTimeout: time.Minute},
+			want: `{"name":"app-prod"}`,
+		},
+		"optional fields": {
+			req: CreateAzureSubscriptionRequest{
+				Name: "app-prod", OfferID: "MS-AZR-0017G", Quantity: 2, SupportPlan: "Standard",
+				Tags: map[string]string{"environment": "prod"},
+			},
+			want: `{"name":"app-prod","offerId":"MS-AZR-0017G","quantity":2,"supportPlan":"Standard","tags":{"environment":"prod"}}`,
+		},
+		"extra fields": {
+			req: CreateAzureSubscriptionRequest{
+				Name:        "app-prod",
+				ExtraFields: map[string]string{"reference": "PO-1234", "description": "Payments"},
+			},
+			want: `{"description":"Payments","name":"app-prod","reference":"PO-1234"}`,
+		},
+		"typed fields win over extra fields": {
+			req: CreateAzureSubscriptionRequest{
+				Name:        "app-prod",
+				Quantity:    3,
+				ExtraFields: map[string]string{"quantity": "5", "offerId": "MS-AZR-0017G"},
+			},
+			want: `{"name":"app-prod","offerId":"MS-AZR-0017G","quantity":3}`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			body, err := json.Marshal(test.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(body) != test.want {
+				t.Errorf("body = %s, want %s", body, test.want)
+			}
+		})
+	}
+}
+
+func TestCreateAzureSubscriptionRejectsUnknownFields(t *testing.T) {
+	var creates int
+	c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		creates++
+		http.NotFound(w, r)
+	})
+
+	req := CreateAzureSubscriptionRequest{Name: "app-prod", ExtraFields: map[string]string{"reference": "PO-1234", "costCenter": "42"}}
+	_, err := c.CreateAzureSubscriptionWithRequest(context.Background(), testPlanID, req)
+	if err == nil || !strings.Contains(err.Error(), "costCenter") {
+		t.Errorf("err = %v, want the unknown field named", err)
+	}
+	if creates != 0 {
+		t.Errorf("sent %d requests, want none", creates)
+	}
+
+	req.ExtraFields = map[string]string{"reference": "PO-1234"}
+	if err := req.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want known fields accepted", err)
+	}
+}
+
+func TestGetAzureSubscriptionExpand(t *testing.T) {
+	tests := map[string]struct {
+		expand []string
+		want   string
+	}{
+		"none":     {},
+		"tags":     {expand: []string{"tags"}, want: "tags"},
+		"multiple": {expand: []string{"tags", "billing"}, want: "tags,billing"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var query url.Values
+			c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.Query()
+				writeJSON(w, http.StatusOK, map[string]interface{}{"Id": 42, "Status": "Active", "Tags": map[string]string{"owner": "data"}})
+			})
+
+			sub, err := c.GetAzureSubscription(context.Background(), testPlanID, 42, test.expand...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := query.Get("$expand"); got != test.want || (test.want == "" && query.Has("$expand")) {
+				t.Errorf("$expand = %q, want %q", got, test.want)
+			}
+			if sub.Tags["owner"] != "data" {
+				t.Errorf("tags = %v, want the expanded tags", sub.Tags)
+			}
+		})
+	}
+}
+
+func TestAzureSubscriptionBillingAccount(t *testing.T) {
+	var sub AzureSubscription
+	if err := json.Unmarshal([]byte(`{"Id": 42, "BillingAccountId": "payer-7"}`), &sub); err != nil {
+		t.Fatal(err)
+	}
+	if sub.BillingAccountID != "payer-7" {
+		t.Errorf("BillingAccountID = %q, want payer-7", sub.BillingAccountID)
+	}
+}
+
+func TestAzureSubscriptionFieldNames(t *testing.T) {
+	want := AzureSubscription{ID: 42, FriendlyName: "app-prod", SubscriptionID: "guid-1", Status: "Active", AzurePlanID: testPlanID}
+
+	tests := map[string]string{
+		"PascalCase":                `{"Id": 42, "FriendlyName": "app-prod", "PublisherSubscriptionId": "guid-1", "Status": "Active", "AzurePlanId": 873834}`,
+		"camelCase":                 `{"id": 42, "friendlyName": "app-prod", "publisherSubscriptionId": "guid-1", "status": "Active", "azurePlanId": 873834}`,
+		"alternate names":           `{"Id": 42, "DisplayName": "app-prod", "AzureSubscriptionId": "guid-1", "Status": "Active", "AzurePlanId": 873834}`,
+		"alternate camelCase names": `{"id": 42, "name": "app-prod", "subscriptionId": "guid-1", "status": "Active", "azurePlanId": 873834}`,
+		"canonical names win": `{"Id": 42, "FriendlyName": "app-prod", "Name": "other", "PublisherSubscriptionId": "guid-1",
+			"SubscriptionId": "guid-2", "Status": "Active", "AzurePlanId": 873834}`,
+		"empty canonical names": `{"Id": 42, "FriendlyName": "", "DisplayName": "app-prod", "PublisherSubscriptionId": "",
+			"SubscriptionId": "guid-1", "Status": "Active", "AzurePlanId": 873834}`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			var sub AzureSubscription
+			if err := json.Unmarshal([]byte(body), &sub); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(sub, want) {
+				t.Errorf("got %+v, want %+v", sub, want)
+			}
+		})
+	}
+}
+
+func TestGetAzureSubscriptionFieldNames(t *testing.T) {
+	c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 42, "name": "app-prod", "subscriptionId": "guid-1", "status": "Active"}`))
+	})
+
+	sub, err := c.GetAzureSubscription(context.Background(), testPlanID, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.FriendlyName != "app-prod" || sub.SubscriptionID != "guid-1" {
+		t.Errorf("got name %q and subscription ID %q, want app-prod and guid-1", sub.FriendlyName, sub.SubscriptionID)
+	}
+}
+
+func TestGetAzureSubscriptionRaw(t *testing.T) {
+	body := `{"Id": 42, "FriendlyName": "app-prod", "PublisherSubscriptionId": "", "NewField": {"Nested": [1, 2]}}`
+	c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/azureplans/873834/azuresubscriptions/42" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	})
+
+	got, err := c.GetAzureSubscriptionRaw(context.Background(), testPlanID, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != body {
+		t.Errorf("got %s, want the response body as is: %s", got, body)
+	}
+	if strings.Contains(got, "token") || strings.Contains(got, "Bearer") {
+		t.Errorf("raw body contains credentials: %s", got)
+	}
+
+	if _, err := c.GetAzureSubscriptionRaw(context.Background(), testPlanID, 43); !IsStatus(err, http.StatusNotFound) {
+		t.Errorf("missing subscription: err = %v, want a 404 error", err)
+	}
+}
+
+func TestSubscriptionIDFromLocation(t *testing.T) {
+	tests := map[string]struct {
+		location string
+		want     int
+		wantOK   bool
+	}{
+		"path":           {location: "/api/v1/azureplans/1/azuresubscriptions/12345", want: 12345, wantOK: true},
+		"absolute URL":   {location: "https://api.crayon.com/api/v1/azureplans/1/azuresubscriptions/12345?x=1", want: 12345, wantOK: true},
+		"trailing slash": {location: "/api/v1/azureplans/1/azuresubscriptions/12345/", want: 12345, wantOK: true},
+		"bare ID":        {location: "12345", want: 12345, wantOK: true},
+		"empty":          {},
+		"not numeric":    {location: "/api/v1/operations/abc-def"},
+		"zero":           {location: "/api/v1/azureplans/1/azuresubscriptions/0"},
+		"collection":     {location: "/api/v1/azureplans/1/azuresubscriptions"},
+		"negative":       {location: "/api/v1/azureplans/1/azuresubscriptions/-3"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, ok := subscriptionIDFromLocation(test.location)
+			if got != test.want || ok != test.wantOK {
+				t.Errorf("subscriptionIDFromLocation(%q) = %d, %v; want %d, %v", test.location, got, ok, test.want, test.wantOK)
+			}
+		})
+	}
+}
+
+func TestCreateAzureSubscriptionLocationNotReadable(t *testing.T) {
+	createPath := "/api/v1/azureplans/873834/azuresubscriptions"
+	c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == createPath {
+			w.Header().Set("Location", createPath+"/42")
+			w.Header().Set("Operation-Location", "https://api.crayon.test/api/v1/operations/7")
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		// The subscription isn't readable yet
+		http.NotFound(w, r)
+	})
+
+	sub, err := c.CreateAzureSubscriptionWithRequest(context.Background(), testPlanID, CreateAzureSubscriptionRequest{Name: "app-prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.ID != 42 || sub.FriendlyName != "app-prod" || sub.Status != "provisioning" || sub.SubscriptionID != "pending" {
+		t.Errorf("created %+v, want subscription 42 pending", sub)
+	}
+	if sub.OperationLocation != "https://api.crayon.test/api/v1/operations/7" {
+		t.Errorf("operation location = %q, want the Operation-Location header", sub.OperationLocation)
+	}
+}
+
+func TestCancelAzureSubscription(t *testing.T) {
+	scheduled := time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		effectiveDate time.Time
+		status        int
+		wantBody      string
+		wantErr       func(error) bool
+	}{
+		"immediate": {status: http.StatusNoContent},
+		"scheduled": {effectiveDate: scheduled, status: http.StatusNoContent, wantBody: `{"effectiveDate":"2030-01-31"}`},
+		"scheduling rejected": {
+			effectiveDate: scheduled, status: http.StatusUnprocessableEntity, wantBody: `{"effectiveDate":"2030-01-31"}`,
+			wantErr: func(err error) bool { return errors.Is(err, ErrScheduledCancellationRejected) },
+		},
+		"scheduling conflicts": {
+			effectiveDate: scheduled, status: http.StatusConflict, wantBody: `{"effectiveDate":"2030-01-31"}`,
+			wantErr: func(err error) bool { return errors.Is(err, ErrScheduledCancellationRejected) },
+		},
+		"scheduling fails on a server": {
+			effectiveDate: scheduled, status: http.StatusInternalServerError, wantBody: `{"effectiveDate":"2030-01-31"}`,
+			wantErr: func(err error) bool {
+				return !errors.Is(err, ErrScheduledCancellationRejected) && IsStatus(err, http.StatusInternalServerError)
+			},
+		},
+		"immediate cancellation rejected": {
+			status:  http.StatusBadRequest,
+			wantErr: func(err error) bool { return !errors.Is(err, ErrScheduledCancellationRejected) },
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var body string
+			c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/api/v1/azureplans/873834/azuresubscriptions/42/cancel" {
+					http.NotFound(w, r)
+					return
+				}
+				raw, _ := io.ReadAll(r.Body)
+				body = strings.TrimSpace(string(raw))
+				w.WriteHeader(test.status)
+			})
+
+			err := c.CancelAzureSubscription(context.Background(), testPlanID, 42, test.effectiveDate)
+			if test.wantErr == nil && err != nil {
+				t.Fatal(err)
+			}
+			if test.wantErr != nil && (err == nil || !test.wantErr(err)) {
+				t.Errorf("err = %v, want a matching error", err)
+			}
+			if body != test.wantBody {
+				t.Errorf("body = %s, want %q", body, test.wantBody)
+			}
+		})
+	}
+}
+
+// statusHandler serves subscription 42 with the given statuses in turn, repeating the last one
+func statusHandler(requests *int32, statuses ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v1/azureplans/873834/azuresubscriptions/42" {
+			http.NotFound(w, r)
+			return
+		}
+		n := int(atomic.AddInt32(requests, 1))
+		if n > len(statuses) {
+			n = len(statuses)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"Id": 42, "Status": statuses[n-1]})
+	}
+}
+
+func TestWaitForSubscriptionStatus(t *testing.T) {
+	cancelled := []string{"cancelled", "deleted"}
+
+	t.Run("already there", func(t *testing.T) {
+		var requests int32
+		c := newTestClient(t, ClientConfig{}, statusHandler(&requests, "Cancelled"))
+
+		sub, err := c.WaitForSubscriptionStatus(context.Background(), testPlanID, 42, cancelled, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sub.Status != "Cancelled" || requests != 1 {
+			t.Errorf("got status %q after %d polls, want Cancelled after 1", sub.Status, requests)
+		}
+	})
+
+	t.Run("reaches the status", func(t *testing.T) {
+		var requests int32
+		c := newTestClient(t, ClientConfig{}, statusHandler(&requests, "Cancelling", "Deleted"))
+
+		// The poll interval is capped to the timeout, so a short one keeps the test fast
+		sub, err := c.WaitForSubscriptionStatus(context.Background(), testPlanID, 42, cancelled, 50*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sub.Status != "Deleted" || requests != 2 {
+			t.Errorf("got status %q after %d polls, want Deleted after 2", sub.Status, requests)
+		}
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		var requests int32
+		c := newTestClient(t, ClientConfig{}, statusHandler(&requests, "Cancelling"))
+
+		sub, err := c.WaitForSubscriptionStatus(context.Background(), testPlanID, 42, cancelled, 20*time.Millisecond)
+		if !errors.Is(err, ErrStatusWaitTimeout) {
+			t.Fatalf("err = %v, want %v", err, ErrStatusWaitTimeout)
+		}
+		if sub == nil || sub.Status != "Cancelling" {
+			t.Errorf("sub = %+v, want the last one polled", sub)
+		}
+	})
+
+	t.Run("context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var requests int32
+		poll := statusHandler(&requests, "Cancelling")
+		c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+			poll(w, r)
+			cancel()
+		})
+
+		start := time.Now()
+		_, err := c.WaitForSubscriptionStatus(ctx, testPlanID, 42, cancelled, time.Hour)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want %v", err, context.Canceled)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("returned after %v, want right after the cancellation", elapsed)
+		}
+	})
+
+	t.Run("poll fails", func(t *testing.T) {
+		c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"Message":"Subscription not found"}`, http.StatusNotFound)
+		})
+
+		_, err := c.WaitForSubscriptionStatus(context.Background(), testPlanID, 42, cancelled, time.Minute)
+		if !IsNotFound(err) {
+			t.Errorf("err = %v, want a not found error", err)
+		}
+	})
+}
+
+func TestEnableAzureSubscription(t *testing.T) {
+	enablePath := "/api/v1/azureplans/873834/azuresubscriptions/42/enable"
+
+	tests := map[string]struct {
+		status        int
+		currentStatus string
+		wantErr       bool
+	}{
+		"enabled":                  {status: http.StatusNoContent},
+		"already active":           {status: http.StatusConflict, currentStatus: "Active"},
+		"already active, any case": {status: http.StatusConflict, currentStatus: "ACTIVE"},
+		"conflict while suspended": {status: http.StatusConflict, currentStatus: "Suspended", wantErr: true},
+		"server error":             {status: http.StatusInternalServerError, currentStatus: "Active", wantErr: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var enables int
+			c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodPost && r.URL.Path == enablePath:
+					enables++
+					w.WriteHeader(test.status)
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v1/azureplans/873834/azuresubscriptions/42":
+					writeJSON(w, http.StatusOK, AzureSubscription{ID: 42, Status: test.currentStatus, AzurePlanID: testPlanID})
+				default:
+					http.NotFound(w, r)
+				}
+			})
+
+			err := c.EnableAzureSubscription(context.Background(), testPlanID, 42)
+			if (err != nil) != test.wantErr {
+				t.Errorf("err = %v, want an error: %v", err, test.wantErr)
+			}
+			if enables != 1 {
+				t.Errorf("sent %d enable requests, want 1", enables)
+			}
+		})
+	}
+}
+
+func TestReactivateAzureSubscription(t *testing.T) {
+	reactivatePath := "/api/v1/azureplans/873834/azuresubscriptions/42/reactivate"
+
+	tests := map[string]struct {
+		status        int
+		currentStatus string
+		wantErr       func(error) bool
+	}{
+		"reactivated": {status: http.StatusNoContent},
+		"already active": {
+			status:        http.StatusConflict,
+			currentStatus: "Active",
+		},
+		"grace period expired": {
+			status:        http.StatusConflict,
+			currentStatus: "Cancelled",
+			wantErr:       func(err error) bool { return errors.Is(err, ErrReactivationWindowExpired) },
+		},
+		"gone": {
+			status:  http.StatusGone,
+			wantErr: func(err error) bool { return errors.Is(err, ErrReactivationWindowExpired) },
+		},
+		"server error": {
+			status: http.StatusInternalServerError,
+			wantErr: func(err error) bool {
+				return !errors.Is(err, ErrReactivationWindowExpired) && IsStatus(err, http.StatusInternalServerError)
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodPost && r.URL.Path == reactivatePath:
+					w.WriteHeader(test.status)
+				case r.Method == http.MethodGet && r.URL.Path == "/api/v1/azureplans/873834/azuresubscriptions/42":
+					writeJSON(w, http.StatusOK, AzureSubscription{ID: 42, Status: test.currentStatus, AzurePlanID: testPlanID})
+				default:
+					http.NotFound(w, r)
+				}
+			})
+
+			err := c.ReactivateAzureSubscription(context.Background(), testPlanID, 42)
+			if test.wantErr == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !test.wantErr(err) {
+				t.Errorf("err = %v, want a matching error", err)
+			}
+		})
+	}
+}
+
+func TestSentinelErrorsWrapTheCause(t *testing.T) {
+	c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quantity is fixed", http.StatusConflict)
+	})
+
+	err := c.SetAzureSubscriptionQuantity(context.Background(), testPlanID, 42, 2)
+	if !errors.Is(err, ErrQuantityNotChangeable) {
+		t.Fatalf("err = %v, want ErrQuantityNotChangeable", err)
+	}
+	if !IsConflict(err) {
+		t.Errorf("err = %v, want the API error to stay inspectable", err)
+	}
+}
+
+func TestListARMSubscriptionsPaging(t *testing.T) {
+	page2 := "https://management.azure.com/subscriptions?api-version=2020-01-01&$skiptoken=page2"
+
+	tests := map[string]struct {
+		lastNextLink string
+		wantRequests int
+	}{
+		"follows nextLink":                   {wantRequests: 2},
+		"repeated nextLink ends the listing": {lastNextLink: page2, wantRequests: 2},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			requests := 0
+			c, err := NewClient(ClientConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.httpClient.Transport = roundTripFunc(func(req *http.Request) *http.Response {
+				requests++
+				if requests > 5 {
+					t.Fatal("kept following nextLink")
+				}
+				if req.Header.Get("Authorization") != "Bearer arm-token" {
+					t.Errorf("request without the ARM token: %v", req.Header)
+				}
+				if req.URL.Query().Get("$skiptoken") == "page2" {
+					return jsonResponse(http.StatusOK, AzureARMSubscriptionList{
+						Value:    []AzureARMSubscription{{SubscriptionID: "guid-2", DisplayName: "app-new"}},
+						NextLink: test.lastNextLink,
+					})
+				}
+				return jsonResponse(http.StatusOK, AzureARMSubscriptionList{
+					Value:    []AzureARMSubscription{{SubscriptionID: "guid-1", DisplayName: "app-prod"}},
+					NextLink: page2,
+				})
+			})
+
+			subs, _, err := c.listARMSubscriptions(context.Background(), "arm-token", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(subs) != 2 || subs[1].DisplayName != "app-new" {
+				t.Errorf("listed %+v, want both pages", subs)
+			}
+			if requests != test.wantRequests {
+				t.Errorf("made %d requests, want %d", requests, test.wantRequests)
+			}
+		})
+	}
+}
+
+// armStateClient returns a client whose ARM subscription list shows app-prod in the given states
+// in turn, repeating the last one, and counts the list requests
+func armStateClient(t *testing.T, targetState string, requests *int32, states ...string) *Client {
+	t.Helper()
+	c := newARMTestClient(t, func(req *http.Request) *http.Response {
+		n := int(atomic.AddInt32(requests, 1))
+		if n > len(states) {
+			n = len(states)
+		}
+		return jsonResponse(http.StatusOK, AzureARMSubscriptionList{Value: []AzureARMSubscription{
+			{SubscriptionID: "guid-other", DisplayName: "app-dev", State: "Enabled"},
+			{SubscriptionID: "guid-prod", DisplayName: "app-prod", State: states[n-1]},
+		}})
+	})
+	c.config.ARMTargetState = targetState
+	return c
+}
+
+func TestWaitForAzureSubscriptionTargetState(t *testing.T) {
+	t.Run("enabled right away", func(t *testing.T) {
+		var requests int32
+		c := armStateClient(t, "", &requests, "Enabled")
+
+		guid, err := c.WaitForAzureSubscription(context.Background(), "app-prod", time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if guid != "guid-prod" || requests != 1 {
+			t.Errorf("got %q after %d polls, want guid-prod after 1", guid, requests)
+		}
+	})
+
+	t.Run("warned then enabled", func(t *testing.T) {
+		var requests int32
+		c := armStateClient(t, "", &requests, "Warned", "enabled")
+
+		// The poll interval is capped to the timeout, so a short one keeps the test fast; the
+		// wait then lasts until the deadline, leaving room for a single further poll
+		guid, err := c.WaitForAzureSubscription(context.Background(), "app-prod", 50*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if guid != "guid-prod" || requests != 2 {
+			t.Errorf("got %q after %d polls, want guid-prod once enabled after 2", guid, requests)
+		}
+	})
+
+	t.Run("never enabled", func(t *testing.T) {
+		var requests int32
+		c := armStateClient(t, "", &requests, "Warned")
+
+		_, err := c.WaitForAzureSubscription(context.Background(), "app-prod", 20*time.Millisecond)
+		if err == nil || !strings.Contains(err.Error(), "to become Enabled") || !strings.Contains(err.Error(), "state: Warned") {
+			t.Errorf("err = %v, want a timeout naming the target and last state", err)
+		}
+	})
+
+	t.Run("configured state", func(t *testing.T) {
+		var requests int32
+		c := armStateClient(t, "PastDue", &requests, "Warned", "PastDue")
+
+		guid, err := c.WaitForAzureSubscription(context.Background(), "app-prod", 50*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if guid != "guid-prod" || requests != 2 {
+			t.Errorf("got %q after %d polls, want guid-prod once past due after 2", guid, requests)
+		}
+	})
+
+	t.Run("any state", func(t *testing.T) {
+		var requests int32
+		c := armStateClient(t, ARMTargetStateAny, &requests, "Warned")
+
+		guid, err := c.WaitForAzureSubscription(context.Background(), "app-prod", time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if guid != "guid-prod" || requests != 1 {
+			t.Errorf("got %q after %d polls, want guid-prod after 1", guid, requests)
+		}
+	})
+}
+
+func TestListARMSubscriptionsRejectsForeignNextLink(t *testing.T) {
+	c, err := NewClient(ClientConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.httpClient.Transport = roundTripFunc(func(req *http.Request) *http.Response {
+		if req.URL.Host != "management.azure.com" {
+			t.Errorf("sent the ARM token to %s", req.URL.Host)
+		}
+		return jsonResponse(http.StatusOK, AzureARMSubscriptionList{NextLink: "https://example.com/steal"})
+	})
+
+	if _, _, err := c.listARMSubscriptions(context.Background(), "arm-token", ""); err == nil {
+		t.Error("followed a nextLink away from ARM")
+	}
+}
+
+func TestListARMSubscriptionsThrottled(t *testing.T) {
+	c, err := NewClient(ClientConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.httpClient.Transport = roundTripFunc(func(req *http.Request) *http.Response {
+		resp := jsonResponse(http.StatusTooManyRequests, map[string]string{"error": "throttled"})
+		resp.Header.Set("Retry-After", "42")
+		return resp
+	})
+
+	_, retryAfter, err := c.listARMSubscriptions(context.Background(), "arm-token", "")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("err = %v, want a 429 *APIError", err)
+	}
+	if retryAfter != 42*time.Second {
+		t.Errorf("retry after %v, want the 42s ARM asked for", retryAfter)
+	}
+}
+
+func TestARMRateLimitRemaining(t *testing.T) {
+	tests := map[string]struct {
+		headers   map[string]string
+		want      int
+		wantFound bool
+	}{
+		"no headers": {},
+		"subscription reads": {
+			headers: map[string]string{"x-ms-ratelimit-remaining-subscription-reads": "11999"},
+			want:    11999, wantFound: true,
+		},
+		"lowest of both": {
+			headers: map[string]string{"x-ms-ratelimit-remaining-subscription-reads": "500", "x-ms-ratelimit-remaining-tenant-reads": "3"},
+			want:    3, wantFound: true,
+		},
+		"invalid value ignored": {
+			headers: map[string]string{"x-ms-ratelimit-remaining-subscription-reads": "many", "x-ms-ratelimit-remaining-tenant-reads": "8"},
+			want:    8, wantFound: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			for key, value := range test.headers {
+				header.Set(key, value)
+			}
+			got, found := armRateLimitRemaining(header)
+			if got != test.want || found != test.wantFound {
+				t.Errorf("armRateLimitRemaining = %d, %v; want %d, %v", got, found, test.want, test.wantFound)
+			}
+		})
+	}
+}
+
+func TestGetAzureSubscriptionNotFound(t *testing.T) {
+	c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"Message":"Subscription not found"}`, http.StatusNotFound)
+	})
+
+	_, err := c.GetAzureSubscription(context.Background(), testPlanID, 42)
+	if !IsNotFound(err) {
+		t.Errorf("err = %v, want a not found error", err)
+	}
+}
+
+func TestFindAzureSubscriptionByNameStopsAtMatch(t *testing.T) {
+	var requests int32
+	c := newTestClient(t, ClientConfig{PageSize: 2}, pagingHandler(testSubscriptions(10), true, false, &requests))
+
+	sub, err := c.FindAzureSubscriptionByName(context.Background(), testPlanID, "sub-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.ID != 3 {
+		t.Errorf("found subscription %d, want 3", sub.ID)
+	}
+	if requests != 2 {
+		t.Errorf("fetched %d pages, want only the 2 up to the match", requests)
+	}
+}
+
+func TestCreateAzureSubscriptionDryRun(t *testing.T) {
+	validatePath := "/api/v1/azureplans/873834/azuresubscriptions/validate"
+	tests := map[string]struct {
+		status  int
+		wantErr error
+	}{
+		"accepted":           {status: http.StatusNoContent},
+		"invalid name":       {status: http.StatusBadRequest, wantErr: ErrCreateRejected},
+		"plan at capacity":   {status: http.StatusConflict, wantErr: ErrCreateRejected},
+		"offer unavailable":  {status: http.StatusUnprocessableEntity, wantErr: ErrCreateRejected},
+		"no preflight":       {status: http.StatusNotFound, wantErr: ErrDryRunNotSupported},
+		"method not allowed": {status: http.StatusMethodNotAllowed, wantErr: ErrDryRunNotSupported},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var body CreateAzureSubscriptionRequest
+			c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != validatePath {
+					t.Errorf("sent %s %s, want only the dry-run", r.Method, r.URL.Path)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("dry-run body: %v", err)
+				}
+				w.WriteHeader(test.status)
+			})
+
+			err := c.CreateAzureSubscriptionDryRun(context.Background(), testPlanID, CreateAzureSubscriptionRequest{Name: "app-prod", Quantity: 1})
+			if test.wantErr == nil && err != nil || test.wantErr != nil && !errors.Is(err, test.wantErr) {
+				t.Errorf("err = %v, want %v", err, test.wantErr)
+			}
+			if body.Name != "app-prod" || body.Quantity != 1 {
+				t.Errorf("dry-run body %+v, want the create request", body)
+			}
+		})
+	}
+}
+
+func TestCreateAzureSubscriptionDryRunRemembersMissingPreflight(t *testing.T) {
+	var requests int32
+	c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.NotFound(w, r)
+	})
+
+	for i := 0; i < 3; i++ {
+		err := c.CreateAzureSubscriptionDryRun(context.Background(), testPlanID, CreateAzureSubscriptionRequest{Name: "app-prod", Quantity: 1})
+		if !errors.Is(err, ErrDryRunNotSupported) {
+			t.Fatalf("dry-run %d: err = %v, want ErrDryRunNotSupported", i, err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("sent %d dry-runs, want none after the API reported no preflight", requests)
+	}
+}
+
+func TestCreateAzureSubscriptionDryRunServerError(t *testing.T) {
+	c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"Message": "boom"}`, http.StatusInternalServerError)
+	})
+
+	err := c.CreateAzureSubscriptionDryRun(context.Background(), testPlanID, CreateAzureSubscriptionRequest{Name: "app-prod", Quantity: 1})
+	if err == nil || errors.Is(err, ErrCreateRejected) || errors.Is(err, ErrDryRunNotSupported) {
+		t.Errorf("err = %v, want a failed dry-run that is neither a rejection nor unsupported", err)
+	}
+}
+
+func TestLastModifiedTimestamp(t *testing.T) {
+	tests := map[string]struct {
+		body   string
+		want   string
+		wantOK bool
+	}{
+		"RFC 3339":        {body: `{"LastModified": "2024-05-31T23:59:59Z"}`, want: "2024-05-31T23:59:59Z", wantOK: true},
+		"with a fraction": {body: `{"LastModified": "2024-05-31T23:59:59.123+02:00"}`, want: "2024-05-31T23:59:59+02:00", wantOK: true},
+		"without a zone":  {body: `{"LastModified": "2024-05-31T23:59:59"}`, want: "2024-05-31T23:59:59Z", wantOK: true},
+		"alternate name":  {body: `{"modifiedDate": "2024-05-31T23:59:59Z"}`, want: "2024-05-31T23:59:59Z", wantOK: true},
+		"unknown format":  {body: `{"LastModified": "31/05/2024 23:59"}`},
+		"not reported":    {body: `{"Id": 42}`},
+		"null":            {body: `{"LastModified": null}`},
+		"date only":       {body: `{"LastModified": "2024-05-31"}`},
+		"empty":           {body: `{"LastModified": ""}`},
+		"not a string":    {body: `{"ModifiedAt": 1717199999}`},
+		"canonical wins":  {body: `{"LastModified": "2024-05-31T23:59:59Z", "ModifiedDate": "2020-01-01T00:00:00Z"}`, want: "2024-05-31T23:59:59Z", wantOK: true},
+		"empty canonical": {body: `{"LastModified": "", "ModifiedAt": "2020-01-01T00:00:00Z"}`, want: "2020-01-01T00:00:00Z", wantOK: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var sub AzureSubscription
+			if err := json.Unmarshal([]byte(test.body), &sub); err != nil {
+				t.Fatal(err)
+			}
+			got, ok := sub.LastModifiedTimestamp()
+			if got != test.want || ok != test.wantOK {
+				t.Errorf("LastModifiedTimestamp() = %q, %v, want %q, %v", got, ok, test.want, test.wantOK)
+			}
+		})
+	}
+}
+
+func TestBillingTimestamp(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   string
+		wantOK bool
+	}{
+		{value: "2024-05-01", want: "2024-05-01T00:00:00Z", wantOK: true},
+		{value: "2024-05-31T23:59:59Z", want: "2024-05-31T23:59:59Z", wantOK: true},
+		{value: "2024-05-31T23:59:59.123+02:00", want: "2024-05-31T23:59:59+02:00", wantOK: true},
+		{value: "2024-05-31T23:59:59", want: "2024-05-31T23:59:59Z", wantOK: true},
+		{value: ""},
+		{value: "31/05/2024"},
+	}
+	for _, test := range tests {
+		got, ok := BillingTimestamp(test.value)
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("BillingTimestamp(%q) = %q, %v, want %q, %v", test.value, got, ok, test.want, test.wantOK)
+		}
+	}
+}
+
+func TestAzureSubscriptionBillingPeriodFieldNames(t *testing.T) {
+	want := AzureSubscription{CurrentBillingPeriodStart: "2024-05-01", CurrentBillingPeriodEnd: "2024-05-31", NextRenewalDate: "2025-05-01"}
+
+	tests := map[string]string{
+		"canonical names": `{"CurrentBillingPeriodStart": "2024-05-01", "CurrentBillingPeriodEnd": "2024-05-31", "NextRenewalDate": "2025-05-01"}`,
+		"billing period":  `{"BillingPeriodStart": "2024-05-01", "BillingPeriodEnd": "2024-05-31", "RenewalDate": "2025-05-01"}`,
+		"billing cycle":   `{"billingCycleStartDate": "2024-05-01", "billingCycleEndDate": "2024-05-31", "renewalDate": "2025-05-01"}`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			var sub AzureSubscription
+			if err := json.Unmarshal([]byte(body), &sub); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(sub, want) {
+				t.Errorf("got %+v, want %+v", sub, want)
+			}
+		})
+	}
+}
+
+func TestTriggerCloudIQSync(t *testing.T) {
+	planSync := "/api/v1/azureplans/873834/synchronize"
+	organizationSync := "/api/v1/organizations/4051878/synchronize"
+	tests := map[string]struct {
+		planStatus, organizationStatus int
+		wantRequests                   []string
+		wantErr                        error
+	}{
+		"plan sync": {
+			planStatus:   http.StatusAccepted,
+			wantRequests: []string{planSync},
+		},
+		"organization sync fallback": {
+			planStatus:         http.StatusNotFound,
+			organizationStatus: http.StatusNoContent,
+			wantRequests:       []string{planSync, organizationSync},
+		},
+		"no sync endpoint": {
+			planStatus:         http.StatusNotFound,
+			organizationStatus: http.StatusMethodNotAllowed,
+			wantRequests:       []string{planSync, organizationSync},
+			wantErr:            ErrSyncNotSupported,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var requests []string
+			c := newTestClient(t, ClientConfig{OrganizationID: 4051878}, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("sent %s %s, want a POST", r.Method, r.URL.Path)
+				}
+				requests = append(requests, r.URL.Path)
+				switch r.URL.Path {
+				case planSync:
+					w.WriteHeader(test.planStatus)
+				case organizationSync:
+					w.WriteHeader(test.organizationStatus)
+				default:
+					http.NotFound(w, r)
+				}
+			})
+
+			err := c.TriggerCloudIQSync(context.Background(), testPlanID)
+			if test.wantErr == nil && err != nil || test.wantErr != nil && !errors.Is(err, test.wantErr) {
+				t.Errorf("err = %v, want %v", err, test.wantErr)
+			}
+			if !reflect.DeepEqual(requests, test.wantRequests) {
+				t.Errorf("requested %v, want %v", requests, test.wantRequests)
+			}
+		})
+	}
+}
+
+func TestTriggerCloudIQSyncRemembersMissingEndpoint(t *testing.T) {
+	var requests int32
+	c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.NotFound(w, r)
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := c.TriggerCloudIQSync(context.Background(), testPlanID); !errors.Is(err, ErrSyncNotSupported) {
+			t.Fatalf("sync %d: err = %v, want ErrSyncNotSupported", i, err)
+		}
+	}
+	// Without an organization there is no fallback, so only the Azure Plan sync is tried once
+	if requests != 1 {
+		t.Errorf("sent %d sync requests, want none after the API reported no sync endpoint", requests)
+	}
+}
+
+func TestTriggerCloudIQSyncServerError(t *testing.T) {
+	var requests int32
+	c := newTestClient(t, ClientConfig{OrganizationID: 4051878}, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.Error(w, `{"Message": "boom"}`, http.StatusInternalServerError)
+	})
+
+	for i := 0; i < 2; i++ {
+		err := c.TriggerCloudIQSync(context.Background(), testPlanID)
+		if err == nil || errors.Is(err, ErrSyncNotSupported) {
+			t.Fatalf("sync %d: err = %v, want a failed sync that is not unsupported", i, err)
+		}
+	}
+	// A failure is retried rather than remembered, and doesn't fall back to the organization
+	if requests != 2 {
+		t.Errorf("sent %d sync requests, want one Azure Plan sync per call", requests)
+	}
+}
+
+func TestCountAzureSubscriptionsByStatus(t *testing.T) {
+	subs := testSubscriptions(7)
+	for i, status := range []string{"Active", "active", "Cancelled", "Provisioning", "", "ACTIVE", "cancelled"} {
+		subs[i].Status = status
+	}
+	var requests int32
+	c := newTestClient(t, ClientConfig{PageSize: 3}, pagingHandler(subs, true, false, &requests))
+
+	counts, err := c.CountAzureSubscriptionsByStatus(context.Background(), testPlanID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"active": 3, "cancelled": 2, "provisioning": 1, "unknown": 1}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+	if requests != 3 {
+		t.Errorf("made %d list requests, want all 3 pages counted", requests)
+	}
+}
+
+func TestSetAzureSubscriptionAutoRenew(t *testing.T) {
+	tests := map[string]struct {
+		status            int
+		wantErr           bool
+		wantNotApplicable bool
+	}{
+		"set":            {status: http.StatusNoContent},
+		"no term":        {status: http.StatusMethodNotAllowed, wantErr: true, wantNotApplicable: true},
+		"no toggle":      {status: http.StatusConflict, wantErr: true, wantNotApplicable: true},
+		"server failure": {status: http.StatusInternalServerError, wantErr: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var body map[string]bool
+			c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPatch || r.URL.Path != "/api/v1/azureplans/873834/azuresubscriptions/42/autorenew" {
+					t.Errorf("sent %s %s, want a PATCH of the auto-renewal", r.Method, r.URL.Path)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("body: %v", err)
+				}
+				w.WriteHeader(test.status)
+			})
+
+			err := c.SetAzureSubscriptionAutoRenew(context.Background(), testPlanID, 42, false)
+			if (err != nil) != test.wantErr || errors.Is(err, ErrAutoRenewNotApplicable) != test.wantNotApplicable {
+				t.Errorf("err = %v, want error %v, not applicable %v", err, test.wantErr, test.wantNotApplicable)
+			}
+			if enabled, ok := body["autoRenew"]; !ok || enabled {
+				t.Errorf("body %v, want autoRenew false", body)
+			}
+		})
+	}
+}
+
+func TestAzureSubscriptionAutoRenewFieldNames(t *testing.T) {
+	yes, no := true, false
+	tests := map[string]struct {
+		body string
+		want *bool
+	}{
+		"canonical name": {body: `{"AutoRenew": true}`, want: &yes},
+		"alternate name": {body: `{"isAutoRenew": false}`, want: &no},
+		"not a boolean":  {body: `{"AutoRenewal": "yes"}`},
+		"not applicable": {body: `{"Id": 42}`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var sub AzureSubscription
+			if err := json.Unmarshal([]byte(test.body), &sub); err != nil {
+				t.Fatal(err)
+			}
+			if !equalBoolPointers(sub.AutoRenew, test.want) {
+				t.Errorf("AutoRenew = %s, want %s", formatBoolPointer(sub.AutoRenew), formatBoolPointer(test.want))
+			}
+		})
+	}
+}
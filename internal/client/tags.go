@@ -0,0 +1,200 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// EnvironmentTagKey is the Cloud-iQ tag used to record a subscription's environment
+const EnvironmentTagKey = "environment"
+
+// ProjectTagKey is the Cloud-iQ tag used to group subscriptions by project or application
+const ProjectTagKey = "project"
+
+// ExternalReferenceTagKey is the Cloud-iQ tag used to link a subscription to an external ticket or order
+const ExternalReferenceTagKey = "external-reference"
+
+// MaxTagValueLength is the longest tag value Azure accepts
+const MaxTagValueLength = 256
+
+// LookupTag returns the value of a tag. Cloud-iQ doesn't always preserve the casing of tag keys,
+// so a key differing only in case matches if there is no exact match.
+func LookupTag(tags map[string]string, key string) (string, bool) {
+	if value, ok := tags[key]; ok {
+		return value, true
+	}
+	for k, value := range tags {
+		if strings.EqualFold(k, key) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// deleteTag removes a tag under any casing of its key
+func deleteTag(tags map[string]string, key string) {
+	for k := range tags {
+		if strings.EqualFold(k, key) {
+			delete(tags, k)
+		}
+	}
+}
+
+// GetAzureSubscriptionTags retrieves the tags of an Azure subscription
+func (c *Client) GetAzureSubscriptionTags(ctx context.Context, azurePlanID, subscriptionID int) (map[string]string, error) {
+	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions/%d/tags", azurePlanID, subscriptionID)
+
+	result, _, err := request[map[string]string](ctx, c, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if *result == nil {
+		return map[string]string{}, nil
+	}
+	return *result, nil
+}
+
+// SetAzureSubscriptionTags replaces the tags of an Azure subscription
+func (c *Client) SetAzureSubscriptionTags(ctx context.Context, azurePlanID, subscriptionID int, tags map[string]string) error {
+	path := fmt.Sprintf("/api/v1/azureplans/%d/azuresubscriptions/%d/tags", azurePlanID, subscriptionID)
+
+	if tags == nil {
+		tags = map[string]string{}
+	}
+
+	if _, err := c.requestNoContent(ctx, http.MethodPut, path, tags); err != nil {
+		return fmt.Errorf("set tags request failed: %w", err)
+	}
+
+	return nil
+}
+
+// SetAzureSubscriptionTag sets (or, with an empty value, removes) a single tag
+// while leaving the subscription's other tags untouched
+func (c *Client) SetAzureSubscriptionTag(ctx context.Context, azurePlanID, subscriptionID int, key, value string) error {
+	if value == "" {
+		return c.UpdateAzureSubscriptionTags(ctx, azurePlanID, subscriptionID, nil, []string{key})
+	}
+	return c.UpdateAzureSubscriptionTags(ctx, azurePlanID, subscriptionID, map[string]string{key: value}, nil)
+}
+
+// HasTags reports whether the subscription already carries all of tags, e.g. because they were
+// applied by the create request
+func (s *AzureSubscription) HasTags(tags map[string]string) bool {
+	for key, value := range tags {
+		if current, ok := LookupTag(s.Tags, key); !ok || current != value {
+			return false
+		}
+	}
+	return true
+}
+
+// UpdateAzureSubscriptionTags sets the given tags and removes the given keys
+// while leaving the subscription's other tags untouched. Keys are matched
+// case-insensitively, so a tag stored under another casing is replaced rather than duplicated.
+func (c *Client) UpdateAzureSubscriptionTags(ctx context.Context, azurePlanID, subscriptionID int, set map[string]string, remove []string) error {
+	tags, err := c.GetAzureSubscriptionTags(ctx, azurePlanID, subscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	for _, key := range remove {
+		deleteTag(tags, key)
+	}
+	for key, value := range set {
+		deleteTag(tags, key)
+		tags[key] = value
+	}
+
+	return c.SetAzureSubscriptionTags(ctx, azurePlanID, subscriptionID, tags)
+}
+
+// MergeTags returns defaults overlaid with tags. Tags win on key conflicts.
+func MergeTags(defaults, tags map[string]string) map[string]string {
+	merged := make(map[string]string, len(defaults)+len(tags))
+	for key, value := range defaults {
+		merged[key] = value
+	}
+	for key, value := range tags {
+		merged[key] = value
+	}
+	return merged
+}
+
+// TagResult reports the outcome of applying tags to a single subscription
+type TagResult struct {
+	SubscriptionID int
+	FriendlyName   string
+	Skipped        bool // the subscription already had the exact tags
+	Err            error
+}
+
+// ApplyTagsToAllSubscriptions merges tags into the tags of every subscription in an Azure Plan.
+// Subscriptions that already carry the exact tags are skipped. One result is returned per
+// subscription so callers can report which succeeded and which failed; if any failed, an
+// *AggregateError listing every failure is returned alongside the results.
+func (c *Client) ApplyTagsToAllSubscriptions(ctx context.Context, azurePlanID int, tags map[string]string) ([]TagResult, error) {
+	subs, err := c.GetAzureSubscriptions(ctx, azurePlanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+
+	results := make([]TagResult, len(subs))
+	started := make([]bool, len(subs))
+	for i, sub := range subs {
+		results[i] = TagResult{SubscriptionID: sub.ID, FriendlyName: sub.FriendlyName}
+	}
+	err = FanOut(ctx, len(subs), defaultConcurrency, func(i int) {
+		started[i] = true
+		sub := subs[i]
+
+		current, err := c.GetAzureSubscriptionTags(ctx, azurePlanID, sub.ID)
+		if err != nil {
+			results[i].Err = fmt.Errorf("failed to get tags: %w", err)
+			return
+		}
+
+		if hasTags(current, tags) {
+			results[i].Skipped = true
+			return
+		}
+
+		for k, v := range tags {
+			deleteTag(current, k)
+			current[k] = v
+		}
+		results[i].Err = c.SetAzureSubscriptionTags(ctx, azurePlanID, sub.ID, current)
+	})
+	if err != nil {
+		for i := range results {
+			if !started[i] {
+				results[i].Err = fmt.Errorf("not tagged: %w", err)
+			}
+		}
+	}
+
+	var errs []*ItemError
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, &ItemError{SubscriptionID: result.SubscriptionID, FriendlyName: result.FriendlyName, Err: result.Err})
+		}
+	}
+
+	return results, aggregate(errs)
+}
+
+// hasTags reports whether current contains every key/value pair in want
+func hasTags(current, want map[string]string) bool {
+	for k, v := range want {
+		if cv, ok := LookupTag(current, k); !ok || cv != v {
+			return false
+		}
+	}
+	return true
+}
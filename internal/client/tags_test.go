@@ -0,0 +1,208 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLookupTag(t *testing.T) {
+	tags := map[string]string{"CostCenter": "1234", "costcenter": "5678", "Owner": "platform"}
+
+	tests := map[string]struct {
+		key       string
+		want      string
+		wantFound bool
+	}{
+		"exact match":             {key: "Owner", want: "platform", wantFound: true},
+		"other casing":            {key: "OWNER", want: "platform", wantFound: true},
+		"exact match wins":        {key: "costcenter", want: "5678", wantFound: true},
+		"missing":                 {key: "team"},
+		"other key with a prefix": {key: "Own"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, found := LookupTag(tags, test.key)
+			if got != test.want || found != test.wantFound {
+				t.Errorf("LookupTag(%q) = %q, %v; want %q, %v", test.key, got, found, test.want, test.wantFound)
+			}
+		})
+	}
+}
+
+// tagsHandler serves the tags of subscription 42 of testPlanID from tags, recording each PUT
+func tagsHandler(t *testing.T, tags map[string]string, puts *[]map[string]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/azureplans/873834/azuresubscriptions/42/tags" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, tags)
+		case http.MethodPut:
+			var put map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&put); err != nil {
+				t.Errorf("PUT body: %v", err)
+			}
+			*puts = append(*puts, put)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func TestUpdateAzureSubscriptionTags(t *testing.T) {
+	var puts []map[string]string
+	var methods []string
+	handler := tagsHandler(t, map[string]string{"owner": "platform", "team": "core", "keep": "me"}, &puts)
+	c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		handler(w, r)
+	})
+
+	err := c.UpdateAzureSubscriptionTags(context.Background(), testPlanID, 42, map[string]string{"owner": "data", "new": "tag"}, []string{"team", "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{http.MethodGet, http.MethodPut}; !reflect.DeepEqual(methods, want) {
+		t.Errorf("sent %v, want %v", methods, want)
+	}
+	want := map[string]string{"owner": "data", "new": "tag", "keep": "me"}
+	if len(puts) != 1 || !reflect.DeepEqual(puts[0], want) {
+		t.Errorf("PUT %v, want the full map %v", puts, want)
+	}
+}
+
+func TestSetAzureSubscriptionTagRemovesEmptyValues(t *testing.T) {
+	var puts []map[string]string
+	c := newTestClient(t, ClientConfig{}, tagsHandler(t, map[string]string{"owner": "platform", "keep": "me"}, &puts))
+
+	if err := c.SetAzureSubscriptionTag(context.Background(), testPlanID, 42, "owner", ""); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"keep": "me"}; len(puts) != 1 || !reflect.DeepEqual(puts[0], want) {
+		t.Errorf("PUT %v, want %v", puts, want)
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	got := MergeTags(map[string]string{"cost-center": "1234", "owner": "platform"}, map[string]string{"owner": "data"})
+	if want := map[string]string{"cost-center": "1234", "owner": "data"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeTags = %v, want %v with the tags winning", got, want)
+	}
+	if got := MergeTags(nil, nil); got == nil || len(got) != 0 {
+		t.Errorf("MergeTags(nil, nil) = %#v, want an empty map", got)
+	}
+}
+
+func TestAzureSubscriptionHasTags(t *testing.T) {
+	sub := AzureSubscription{Tags: map[string]string{"owner": "data", "env": "prod"}}
+	tests := []struct {
+		tags map[string]string
+		want bool
+	}{
+		{tags: nil, want: true},
+		{tags: map[string]string{"owner": "data"}, want: true},
+		{tags: map[string]string{"owner": "data", "env": "prod"}, want: true},
+		{tags: map[string]string{"owner": "web"}, want: false},
+		{tags: map[string]string{"cost-center": "42"}, want: false},
+	}
+	for _, test := range tests {
+		if got := sub.HasTags(test.tags); got != test.want {
+			t.Errorf("HasTags(%v) = %v, want %v", test.tags, got, test.want)
+		}
+	}
+}
+
+func TestUpdateAzureSubscriptionTagsMatchesKeysCaseInsensitively(t *testing.T) {
+	var puts []map[string]string
+	c := newTestClient(t, ClientConfig{}, tagsHandler(t, map[string]string{"OWNER": "platform", "Team": "core", "keep": "me"}, &puts))
+
+	err := c.UpdateAzureSubscriptionTags(context.Background(), testPlanID, 42, map[string]string{"owner": "data"}, []string{"team"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"owner": "data", "keep": "me"}
+	if len(puts) != 1 || !reflect.DeepEqual(puts[0], want) {
+		t.Errorf("PUT %v, want %v with the keys in other casings replaced", puts, want)
+	}
+}
+
+func TestGetAzureSubscriptionTagsWithoutTags(t *testing.T) {
+	c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, nil)
+	})
+
+	tags, err := c.GetAzureSubscriptionTags(context.Background(), testPlanID, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags == nil || len(tags) != 0 {
+		t.Errorf("tags = %#v, want an empty map to set tags in", tags)
+	}
+}
+
+func TestApplyTagsToAllSubscriptions(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	puts := map[string]map[string]string{}
+	c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/v1/azureplans/873834/azuresubscriptions":
+			writeJSON(w, http.StatusOK, map[string]interface{}{"Items": testSubscriptions(12), "TotalHits": 12})
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/1/tags"):
+			// Already tagged, under another casing
+			writeJSON(w, http.StatusOK, map[string]string{"Owner": "data", "team": "core"})
+		case r.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, map[string]string{"owner": "platform", "team": "core"})
+		case r.Method == http.MethodPut:
+			mu.Lock()
+			running++
+			peak = max(peak, running)
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+
+			var put map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&put); err != nil {
+				t.Errorf("PUT body: %v", err)
+			}
+			mu.Lock()
+			running--
+			puts[r.URL.Path] = put
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	results, err := c.ApplyTagsToAllSubscriptions(context.Background(), testPlanID, map[string]string{"owner": "data"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 12 || !results[0].Skipped || results[0].SubscriptionID != 1 || results[1].Skipped {
+		t.Errorf("results = %+v, want only subscription 1 skipped", results)
+	}
+	if len(puts) != 11 {
+		t.Errorf("tagged %d subscriptions, want 11", len(puts))
+	}
+	want := map[string]string{"owner": "data", "team": "core"}
+	for path, put := range puts {
+		if !reflect.DeepEqual(put, want) {
+			t.Errorf("PUT %s %v, want %v with the other tags kept", path, put, want)
+		}
+	}
+	if peak > defaultConcurrency {
+		t.Errorf("%d tag updates ran at once, want at most %d", peak, defaultConcurrency)
+	}
+}
@@ -4,23 +4,32 @@
 package client
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 )
 
+// ErrOrganizationMismatch is returned when an Azure Plan belongs to another organization than configured
+var ErrOrganizationMismatch = errors.New("azure plan does not belong to the configured organization")
+
 // CustomerTenant represents a Crayon customer tenant
 type CustomerTenant struct {
-	ID     int    `json:"id"`
-	Domain string `json:"domain"`
-	Name   string `json:"name"`
+	ID           int                    `json:"id"`
+	Domain       string                 `json:"domain"`
+	Name         string                 `json:"name"`
+	Organization *OrganizationReference `json:"organization,omitempty"`
 }
 
-// CustomerTenantsResponse represents the response from CustomerTenants API
-type CustomerTenantsResponse struct {
-	Items      []CustomerTenant `json:"items"`
-	TotalCount int              `json:"totalCount"`
+// OrganizationReference identifies the organization that owns a Crayon object
+type OrganizationReference struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
 }
 
+// CustomerTenantsResponse represents the response from CustomerTenants API
+type CustomerTenantsResponse = Page[CustomerTenant]
+
 // AzurePlan represents a Crayon Azure Plan
 type AzurePlan struct {
 	ID               int    `json:"id"`
@@ -28,36 +37,90 @@ type AzurePlan struct {
 	SubscriptionID   string `json:"subscriptionId"`
 }
 
-// GetCustomerTenants retrieves customer tenants for the organization
-func (c *Client) GetCustomerTenants() ([]CustomerTenant, error) {
+// GetCustomerTenants retrieves customer tenants for the organization.
+// The organization is sent both as a query parameter and, if configured, as a header.
+func (c *Client) GetCustomerTenants(ctx context.Context) ([]CustomerTenant, error) {
 	path := fmt.Sprintf("/api/v1/CustomerTenants?OrganizationId=%d", c.config.OrganizationID)
 
-	resp, err := c.doRequest(http.MethodGet, path, nil)
+	result, _, err := requestList[CustomerTenantsResponse](ctx, c, http.MethodGet, path, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var result CustomerTenantsResponse
-	if err := parseResponse(resp, &result); err != nil {
-		return nil, err
-	}
-
 	return result.Items, nil
 }
 
 // GetAzurePlan retrieves the Azure Plan for a customer tenant
-func (c *Client) GetAzurePlan(customerTenantID int) (*AzurePlan, error) {
+func (c *Client) GetAzurePlan(ctx context.Context, customerTenantID int) (*AzurePlan, error) {
 	path := fmt.Sprintf("/api/v1/CustomerTenants/%d/azureplan", customerTenantID)
 
-	resp, err := c.doRequest(http.MethodGet, path, nil)
+	result, _, err := request[AzurePlan](ctx, c, http.MethodGet, path, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var result AzurePlan
-	if err := parseResponse(resp, &result); err != nil {
+	return result, nil
+}
+
+// GetCustomerTenant retrieves a single customer tenant
+func (c *Client) GetCustomerTenant(ctx context.Context, customerTenantID int) (*CustomerTenant, error) {
+	path := fmt.Sprintf("/api/v1/CustomerTenants/%d", customerTenantID)
+
+	result, _, err := request[CustomerTenant](ctx, c, http.MethodGet, path, nil)
+	if err != nil {
 		return nil, err
 	}
 
-	return &result, nil
+	return result, nil
+}
+
+// GetAzurePlanByID retrieves an Azure Plan by its ID
+func (c *Client) GetAzurePlanByID(ctx context.Context, azurePlanID int) (*AzurePlan, error) {
+	path := fmt.Sprintf("/api/v1/azureplans/%d", azurePlanID)
+
+	result, _, err := request[AzurePlan](ctx, c, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// VerifyAzurePlanOrganization checks that an Azure Plan belongs to the configured organization
+// by resolving the plan's customer tenant. Plans that passed the check are remembered, so each
+// plan is resolved at most once per provider process.
+func (c *Client) VerifyAzurePlanOrganization(ctx context.Context, azurePlanID int) error {
+	c.verifiedPlansMu.Lock()
+	verified := c.verifiedPlans[azurePlanID]
+	c.verifiedPlansMu.Unlock()
+	if verified {
+		return nil
+	}
+
+	plan, err := c.GetAzurePlanByID(ctx, azurePlanID)
+	if err != nil {
+		return fmt.Errorf("failed to get azure plan %d: %w", azurePlanID, err)
+	}
+
+	tenant, err := c.GetCustomerTenant(ctx, plan.CustomerTenantID)
+	if err != nil {
+		return fmt.Errorf("failed to get customer tenant %d: %w", plan.CustomerTenantID, err)
+	}
+	if tenant.Organization == nil {
+		return fmt.Errorf("customer tenant %d does not report its organization", plan.CustomerTenantID)
+	}
+
+	if tenant.Organization.ID != c.config.OrganizationID {
+		return fmt.Errorf("%w: azure plan %d belongs to organization %d (customer tenant %s), but organization_id is %d",
+			ErrOrganizationMismatch, azurePlanID, tenant.Organization.ID, tenant.Name, c.config.OrganizationID)
+	}
+
+	c.verifiedPlansMu.Lock()
+	defer c.verifiedPlansMu.Unlock()
+	if c.verifiedPlans == nil {
+		c.verifiedPlans = map[int]bool{}
+	}
+	c.verifiedPlans[azurePlanID] = true
+
+	return nil
 }
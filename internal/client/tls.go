@@ -0,0 +1,39 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// DefaultMinTLSVersion is the lowest TLS version used for outbound connections by default
+const DefaultMinTLSVersion = "1.2"
+
+// ErrInsecureTLSVersion is returned for TLS versions that are too old to be used safely
+var ErrInsecureTLSVersion = errors.New("TLS versions before 1.2 are insecure and not supported")
+
+// ParseTLSVersion converts a TLS version such as "1.2" to its crypto/tls constant. TLS 1.0 and
+// 1.1 are rejected with ErrInsecureTLSVersion.
+func ParseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	case "1.0", "1.1":
+		return 0, fmt.Errorf("TLS %s: %w", version, ErrInsecureTLSVersion)
+	}
+	return 0, fmt.Errorf("unknown TLS version %q, expected 1.2 or 1.3", version)
+}
+
+// newTransport returns an HTTP transport based on the default one that negotiates at least
+// minTLSVersion
+func newTransport(minTLSVersion uint16) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{MinVersion: minTLSVersion}
+	return transport
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTLSVersion(t *testing.T) {
+	tests := []struct {
+		version      string
+		want         uint16
+		wantInsecure bool
+		wantErr      bool
+	}{
+		{version: "1.2", want: tls.VersionTLS12},
+		{version: "1.3", want: tls.VersionTLS13},
+		{version: "1.0", wantInsecure: true, wantErr: true},
+		{version: "1.1", wantInsecure: true, wantErr: true},
+		{version: "TLS1.2", wantErr: true},
+		{version: "", wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := ParseTLSVersion(test.version)
+		if got != test.want || (err != nil) != test.wantErr || errors.Is(err, ErrInsecureTLSVersion) != test.wantInsecure {
+			t.Errorf("ParseTLSVersion(%q) = %x, %v, want %x, error %v, insecure %v", test.version, got, err, test.want, test.wantErr, test.wantInsecure)
+		}
+	}
+}
+
+func TestMinTLSVersion(t *testing.T) {
+	tests := map[string]struct {
+		version string
+		want    uint16
+	}{
+		"default": {want: tls.VersionTLS12},
+		"1.3":     {version: "1.3", want: tls.VersionTLS13},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, err := NewClient(ClientConfig{BaseURL: "https://api.crayon.test", MinTLSVersion: test.version})
+			if err != nil {
+				t.Fatal(err)
+			}
+			transport, ok := c.httpClient.Transport.(*http.Transport)
+			if !ok || transport.TLSClientConfig == nil {
+				t.Fatalf("transport %T without a TLS config", c.httpClient.Transport)
+			}
+			if got := transport.TLSClientConfig.MinVersion; got != test.want {
+				t.Errorf("TLS MinVersion = %x, want %x", got, test.want)
+			}
+		})
+	}
+
+	if _, err := NewClient(ClientConfig{MinTLSVersion: "1.1"}); !errors.Is(err, ErrInsecureTLSVersion) {
+		t.Errorf("NewClient with TLS 1.1: err = %v, want ErrInsecureTLSVersion", err)
+	}
+}
+
+func TestMinTLSVersionRefusesOlderServers(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, TokenResponse{AccessToken: "token", ExpiresIn: 3600})
+	}))
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	for version, wantErr := range map[string]bool{"1.2": false, "1.3": true} {
+		c, err := NewClient(ClientConfig{BaseURL: server.URL, ClientID: "client", ClientSecret: "secret", MaxRetries: -1, MinTLSVersion: version})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Trust the test server's certificate, keeping the configured minimum version
+		c.httpClient.Transport.(*http.Transport).TLSClientConfig.RootCAs = server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+		_, err = c.getToken(context.Background(), DefaultTokenScope)
+		if (err != nil) != wantErr {
+			t.Errorf("min TLS %s against a TLS 1.2 server: err = %v, want error %v", version, err, wantErr)
+		}
+	}
+}
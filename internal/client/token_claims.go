@@ -0,0 +1,89 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// Policies for an organization_id that differs from the organization the Crayon token was issued for
+const (
+	// OrganizationMismatchWarn reports the mismatch as a warning (default)
+	OrganizationMismatchWarn = "warn"
+	// OrganizationMismatchError fails the provider configuration
+	OrganizationMismatchError = "error"
+)
+
+// organizationClaims are the JWT claims Crayon tokens carry the organization in, in order of preference
+var organizationClaims = []string{"organization_id", "organizationId", "org_id", "orgid"}
+
+// TokenOrganizationID returns the organization the Crayon access token was issued for, read from
+// its JWT claims without verifying the signature. ok is false if the token isn't a JWT or carries
+// no organization claim.
+func (c *Client) TokenOrganizationID(ctx context.Context) (int64, bool, error) {
+	token, err := c.getToken(ctx, c.tokenScope(OperationRead))
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to authenticate: %w", err)
+	}
+
+	claims, err := jwtClaims(token)
+	if err != nil {
+		tflog.Info(ctx, "Not checking the token's organization", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return 0, false, nil
+	}
+
+	id, ok := organizationClaim(claims)
+	return id, ok, nil
+}
+
+// jwtClaims decodes the payload of a JWT
+func jwtClaims(token string) (map[string]json.RawMessage, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("the access token is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the access token claims: %w", err)
+	}
+
+	var claims map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("failed to parse the access token claims: %w", err)
+	}
+	return claims, nil
+}
+
+// organizationClaim returns the first organization claim, matched case-insensitively, given
+// either as a number or a numeric string
+func organizationClaim(claims map[string]json.RawMessage) (int64, bool) {
+	for _, key := range organizationClaims {
+		for k, v := range claims {
+			if !strings.EqualFold(k, key) {
+				continue
+			}
+			var id int64
+			if err := json.Unmarshal(v, &id); err == nil {
+				return id, true
+			}
+			var value string
+			if err := json.Unmarshal(v, &value); err == nil {
+				if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+					return id, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
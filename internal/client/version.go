@@ -0,0 +1,85 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// SupportedAPIMajorVersion is the major version of the Crayon API this provider is written against
+const SupportedAPIMajorVersion = 1
+
+// ErrVersionCheckNotSupported is returned when the Crayon API doesn't report its version
+var ErrVersionCheckNotSupported = errors.New("the Crayon API does not report its version")
+
+// APIVersionInfo is the response of the Crayon API version endpoint
+type APIVersionInfo struct {
+	Version    string `json:"version"`
+	APIVersion string `json:"apiVersion"`
+}
+
+// String returns the reported version, preferring the API version over the build version
+func (i *APIVersionInfo) String() string {
+	return firstNonEmpty(i.APIVersion, i.Version)
+}
+
+// GetAPIVersion returns the version the Crayon API reports
+func (c *Client) GetAPIVersion(ctx context.Context) (*APIVersionInfo, error) {
+	info, status, err := request[APIVersionInfo](ctx, c, http.MethodGet, "/api/v1/version", nil)
+	if status == http.StatusNotFound || status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented {
+		return nil, fmt.Errorf("%w (status %d)", ErrVersionCheckNotSupported, status)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API version: %w", err)
+	}
+	return info, nil
+}
+
+// CheckAPICompatibility compares the Crayon API's major version with SupportedAPIMajorVersion.
+// It returns a description of the mismatch, or an empty string if the versions are compatible
+// or the reported version can't be interpreted.
+func (c *Client) CheckAPICompatibility(ctx context.Context) (string, error) {
+	info, err := c.GetAPIVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	version := info.String()
+	major, ok := parseMajorVersion(version)
+	if !ok {
+		tflog.Info(ctx, "Skipping the API compatibility check, unrecognized API version", map[string]interface{}{
+			"api_version": version,
+		})
+		return "", nil
+	}
+
+	switch {
+	case major > SupportedAPIMajorVersion:
+		return fmt.Sprintf("The Crayon API reports version %s, but this provider supports API version %d. "+
+			"The provider is likely too old for the API; upgrade it if requests fail unexpectedly.",
+			version, SupportedAPIMajorVersion), nil
+	case major < SupportedAPIMajorVersion:
+		return fmt.Sprintf("The Crayon API reports version %s, but this provider expects API version %d. "+
+			"Some features may not work against this API.", version, SupportedAPIMajorVersion), nil
+	}
+	return "", nil
+}
+
+// parseMajorVersion returns the major version of versions like "1", "v1" or "1.4.2"
+func parseMajorVersion(version string) (int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(strings.ToLower(version)), "v")
+	major, _, _ := strings.Cut(version, ".")
+	n, err := strconv.Atoi(major)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
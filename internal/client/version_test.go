@@ -0,0 +1,66 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestCheckAPICompatibility(t *testing.T) {
+	tests := map[string]struct {
+		respond      func(w http.ResponseWriter)
+		wantMismatch bool
+		wantErr      error
+	}{
+		"supported version": {
+			respond: func(w http.ResponseWriter) { writeJSON(w, http.StatusOK, APIVersionInfo{APIVersion: "1.4.2"}) },
+		},
+		"supported build version": {
+			respond: func(w http.ResponseWriter) { writeJSON(w, http.StatusOK, APIVersionInfo{Version: "v1"}) },
+		},
+		"newer major version": {
+			respond:      func(w http.ResponseWriter) { writeJSON(w, http.StatusOK, APIVersionInfo{APIVersion: "2.0"}) },
+			wantMismatch: true,
+		},
+		"older major version": {
+			respond:      func(w http.ResponseWriter) { writeJSON(w, http.StatusOK, APIVersionInfo{APIVersion: "0.9"}) },
+			wantMismatch: true,
+		},
+		"unrecognized version": {
+			respond: func(w http.ResponseWriter) { writeJSON(w, http.StatusOK, APIVersionInfo{APIVersion: "latest"}) },
+		},
+		"missing endpoint": {
+			respond: func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) },
+			wantErr: ErrVersionCheckNotSupported,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := newTestClient(t, ClientConfig{}, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/version" {
+					http.NotFound(w, r)
+					return
+				}
+				test.respond(w)
+			})
+
+			mismatch, err := c.CheckAPICompatibility(context.Background())
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("err = %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (mismatch != "") != test.wantMismatch {
+				t.Errorf("mismatch = %q, want a mismatch %v", mismatch, test.wantMismatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// webhookTimeout bounds how long a notification webhook may take
+const webhookTimeout = 10 * time.Second
+
+// SubscriptionWebhookPayload is the body POSTed to a notification webhook.
+// Text makes the payload directly usable with Slack and Teams incoming webhooks.
+type SubscriptionWebhookPayload struct {
+	Text           string `json:"text"`
+	Event          string `json:"event"`
+	ID             int    `json:"id"`
+	SubscriptionID string `json:"subscription_id"`
+	Name           string `json:"name"`
+	AzurePlanID    int    `json:"azure_plan_id"`
+	Status         string `json:"status"`
+}
+
+// NotifySubscriptionResolved POSTs the subscription details to a notification webhook
+func (c *Client) NotifySubscriptionResolved(ctx context.Context, webhookURL string, sub *AzureSubscription) error {
+	payload := SubscriptionWebhookPayload{
+		Text:           fmt.Sprintf("Azure subscription '%s' is ready (GUID: %s, status: %s)", sub.FriendlyName, sub.SubscriptionID, sub.Status),
+		Event:          "subscription.resolved",
+		ID:             sub.ID,
+		SubscriptionID: sub.SubscriptionID,
+		Name:           sub.FriendlyName,
+		AzurePlanID:    sub.AzurePlanID,
+		Status:         sub.Status,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := &http.Client{Timeout: webhookTimeout, Transport: c.httpClient.Transport}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook request failed with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNotifySubscriptionResolved(t *testing.T) {
+	sub := &AzureSubscription{ID: 42, FriendlyName: "app-prod", SubscriptionID: "guid-1", Status: "Active", AzurePlanID: testPlanID}
+
+	tests := map[string]struct {
+		status  int
+		wantErr bool
+	}{
+		"delivered":       {status: http.StatusOK},
+		"accepted":        {status: http.StatusAccepted},
+		"rejected":        {status: http.StatusBadRequest, wantErr: true},
+		"receiver unwell": {status: http.StatusBadGateway, wantErr: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var payload SubscriptionWebhookPayload
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+					t.Errorf("got %s with content type %q, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+				}
+				if auth := r.Header.Get("Authorization"); auth != "" {
+					t.Errorf("sent credentials %q to the webhook", auth)
+				}
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					t.Errorf("payload: %v", err)
+				}
+				w.WriteHeader(test.status)
+			}))
+			t.Cleanup(server.Close)
+			c, err := NewClient(ClientConfig{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = c.NotifySubscriptionResolved(context.Background(), server.URL+"/hook", sub)
+			if (err != nil) != test.wantErr {
+				t.Errorf("err = %v, want an error: %v", err, test.wantErr)
+			}
+			want := SubscriptionWebhookPayload{
+				Event: "subscription.resolved", ID: 42, SubscriptionID: "guid-1", Name: "app-prod", AzurePlanID: testPlanID, Status: "Active",
+			}
+			text := payload.Text
+			payload.Text = ""
+			if payload != want {
+				t.Errorf("payload = %+v, want %+v", payload, want)
+			}
+			if !strings.Contains(text, "app-prod") || !strings.Contains(text, "guid-1") {
+				t.Errorf("text = %q, want the subscription's name and GUID", text)
+			}
+		})
+	}
+}
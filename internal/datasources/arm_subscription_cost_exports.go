@@ -0,0 +1,191 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ARMSubscriptionCostExportsDataSource{}
+var _ datasource.DataSourceWithConfigure = &ARMSubscriptionCostExportsDataSource{}
+
+func NewARMSubscriptionCostExportsDataSource() datasource.DataSource {
+	return &ARMSubscriptionCostExportsDataSource{}
+}
+
+// ARMSubscriptionCostExportsDataSource exposes the Cost Management exports of a subscription via ARM.
+type ARMSubscriptionCostExportsDataSource struct {
+	client *client.Client
+}
+
+// ARMSubscriptionCostExportsDataSourceModel describes the data source data model.
+type ARMSubscriptionCostExportsDataSourceModel struct {
+	ID             types.String         `tfsdk:"id"`
+	SubscriptionID types.String         `tfsdk:"subscription_id"`
+	Exports        []ARMCostExportModel `tfsdk:"exports"`
+}
+
+// ARMCostExportModel describes a single export in the exports list.
+type ARMCostExportModel struct {
+	ID               types.String `tfsdk:"id"`
+	Name             types.String `tfsdk:"name"`
+	Status           types.String `tfsdk:"status"`
+	Recurrence       types.String `tfsdk:"recurrence"`
+	Type             types.String `tfsdk:"type"`
+	Timeframe        types.String `tfsdk:"timeframe"`
+	Format           types.String `tfsdk:"format"`
+	StorageAccountID types.String `tfsdk:"storage_account_id"`
+	Container        types.String `tfsdk:"container"`
+	RootFolderPath   types.String `tfsdk:"root_folder_path"`
+}
+
+func (d *ARMSubscriptionCostExportsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_arm_subscription_cost_exports"
+}
+
+func (d *ARMSubscriptionCostExportsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the Azure Cost Management exports configured on an Azure subscription, to audit cost " +
+			"reporting coverage. Uses the Azure credentials configured for polling.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The Azure subscription GUID.",
+				Computed:    true,
+			},
+			"subscription_id": schema.StringAttribute{
+				Description: "The Azure subscription GUID.",
+				Required:    true,
+			},
+			"exports": schema.ListNestedAttribute{
+				Description: "The Cost Management exports scoped to the subscription.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Description: "The resource ID of the export.",
+							Computed:    true,
+						},
+						"name": schema.StringAttribute{
+							Description: "The name of the export.",
+							Computed:    true,
+						},
+						"status": schema.StringAttribute{
+							Description: "Whether the export's schedule is Active or Inactive.",
+							Computed:    true,
+						},
+						"recurrence": schema.StringAttribute{
+							Description: "How often the export runs, e.g. Daily or Monthly.",
+							Computed:    true,
+						},
+						"type": schema.StringAttribute{
+							Description: "The cost data exported, e.g. ActualCost, AmortizedCost or Usage.",
+							Computed:    true,
+						},
+						"timeframe": schema.StringAttribute{
+							Description: "The period each run exports, e.g. MonthToDate.",
+							Computed:    true,
+						},
+						"format": schema.StringAttribute{
+							Description: "The file format of the export, e.g. Csv.",
+							Computed:    true,
+						},
+						"storage_account_id": schema.StringAttribute{
+							Description: "The resource ID of the storage account the export is written to.",
+							Computed:    true,
+						},
+						"container": schema.StringAttribute{
+							Description: "The storage container the export is written to.",
+							Computed:    true,
+						},
+						"root_folder_path": schema.StringAttribute{
+							Description: "The folder within the container the export is written to.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *ARMSubscriptionCostExportsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *ARMSubscriptionCostExportsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ARMSubscriptionCostExportsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	subscriptionID := data.SubscriptionID.ValueString()
+
+	tflog.Debug(ctx, "Reading ARM subscription cost management exports", map[string]interface{}{
+		"subscription_id": subscriptionID,
+	})
+
+	exports, err := d.client.ListSubscriptionCostExports(ctx, subscriptionID)
+	if errors.Is(err, client.ErrARMPermissionDenied) {
+		resp.Diagnostics.AddError(
+			"Permission Denied Reading Cost Management Exports",
+			"The configured Azure identity cannot read the Cost Management exports of subscription "+subscriptionID+". "+
+				"Grant it a role with Microsoft.CostManagement/exports/read on the subscription, e.g. Cost Management Reader."+
+				"\n\nError: "+err.Error(),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Cost Management Exports",
+			"Could not list cost management exports: "+err.Error(),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(subscriptionID)
+	data.Exports = make([]ARMCostExportModel, 0, len(exports))
+	for _, export := range exports {
+		properties := export.Properties
+		data.Exports = append(data.Exports, ARMCostExportModel{
+			ID:               types.StringValue(export.ID),
+			Name:             types.StringValue(export.Name),
+			Status:           types.StringValue(properties.Schedule.Status),
+			Recurrence:       types.StringValue(properties.Schedule.Recurrence),
+			Type:             types.StringValue(properties.Definition.Type),
+			Timeframe:        types.StringValue(properties.Definition.Timeframe),
+			Format:           types.StringValue(properties.Format),
+			StorageAccountID: types.StringValue(properties.DeliveryInfo.Destination.ResourceID),
+			Container:        types.StringValue(properties.DeliveryInfo.Destination.Container),
+			RootFolderPath:   types.StringValue(properties.DeliveryInfo.Destination.RootFolderPath),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
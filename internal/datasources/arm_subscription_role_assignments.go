@@ -0,0 +1,160 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ARMSubscriptionRoleAssignmentsDataSource{}
+var _ datasource.DataSourceWithConfigure = &ARMSubscriptionRoleAssignmentsDataSource{}
+
+func NewARMSubscriptionRoleAssignmentsDataSource() datasource.DataSource {
+	return &ARMSubscriptionRoleAssignmentsDataSource{}
+}
+
+// ARMSubscriptionRoleAssignmentsDataSource exposes who has access to a subscription via ARM.
+type ARMSubscriptionRoleAssignmentsDataSource struct {
+	client *client.Client
+}
+
+// ARMSubscriptionRoleAssignmentsDataSourceModel describes the data source data model.
+type ARMSubscriptionRoleAssignmentsDataSourceModel struct {
+	ID              types.String             `tfsdk:"id"`
+	SubscriptionID  types.String             `tfsdk:"subscription_id"`
+	RoleAssignments []ARMRoleAssignmentModel `tfsdk:"role_assignments"`
+}
+
+// ARMRoleAssignmentModel describes a single role assignment in the role_assignments list.
+type ARMRoleAssignmentModel struct {
+	ID               types.String `tfsdk:"id"`
+	PrincipalID      types.String `tfsdk:"principal_id"`
+	PrincipalType    types.String `tfsdk:"principal_type"`
+	RoleDefinitionID types.String `tfsdk:"role_definition_id"`
+	Scope            types.String `tfsdk:"scope"`
+}
+
+func (d *ARMSubscriptionRoleAssignmentsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_arm_subscription_role_assignments"
+}
+
+func (d *ARMSubscriptionRoleAssignmentsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the ARM role assignments of an Azure subscription and the scopes below it, to review who " +
+			"has access. Uses the Azure credentials configured for polling.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The Azure subscription GUID.",
+				Computed:    true,
+			},
+			"subscription_id": schema.StringAttribute{
+				Description: "The Azure subscription GUID.",
+				Required:    true,
+			},
+			"role_assignments": schema.ListNestedAttribute{
+				Description: "The role assignments on the subscription and its resource groups and resources.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Description: "The resource ID of the role assignment.",
+							Computed:    true,
+						},
+						"principal_id": schema.StringAttribute{
+							Description: "The object ID of the user, group or service principal granted the role.",
+							Computed:    true,
+						},
+						"principal_type": schema.StringAttribute{
+							Description: "The type of the principal, e.g. User, Group or ServicePrincipal.",
+							Computed:    true,
+						},
+						"role_definition_id": schema.StringAttribute{
+							Description: "The resource ID of the assigned role definition.",
+							Computed:    true,
+						},
+						"scope": schema.StringAttribute{
+							Description: "The scope the role is assigned on.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *ARMSubscriptionRoleAssignmentsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *ARMSubscriptionRoleAssignmentsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ARMSubscriptionRoleAssignmentsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	subscriptionID := data.SubscriptionID.ValueString()
+
+	tflog.Debug(ctx, "Reading ARM subscription role assignments", map[string]interface{}{
+		"subscription_id": subscriptionID,
+	})
+
+	assignments, err := d.client.ListSubscriptionRoleAssignments(ctx, subscriptionID)
+	if errors.Is(err, client.ErrARMPermissionDenied) {
+		resp.Diagnostics.AddError(
+			"Permission Denied Reading ARM Role Assignments",
+			"The configured Azure identity cannot read the role assignments of subscription "+subscriptionID+". "+
+				"Grant it a role with Microsoft.Authorization/roleAssignments/read on the subscription, e.g. Reader."+
+				"\n\nError: "+err.Error(),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading ARM Role Assignments",
+			"Could not list role assignments: "+err.Error(),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(subscriptionID)
+	data.RoleAssignments = make([]ARMRoleAssignmentModel, 0, len(assignments))
+	for _, assignment := range assignments {
+		data.RoleAssignments = append(data.RoleAssignments, ARMRoleAssignmentModel{
+			ID:               types.StringValue(assignment.ID),
+			PrincipalID:      types.StringValue(assignment.Properties.PrincipalID),
+			PrincipalType:    types.StringValue(assignment.Properties.PrincipalType),
+			RoleDefinitionID: types.StringValue(assignment.Properties.RoleDefinitionID),
+			Scope:            types.StringValue(assignment.Properties.Scope),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ARMSubscriptionUsageDataSource{}
+var _ datasource.DataSourceWithConfigure = &ARMSubscriptionUsageDataSource{}
+
+func NewARMSubscriptionUsageDataSource() datasource.DataSource {
+	return &ARMSubscriptionUsageDataSource{}
+}
+
+// ARMSubscriptionUsageDataSource exposes the ARM quota usage of a subscription in a region.
+type ARMSubscriptionUsageDataSource struct {
+	client *client.Client
+}
+
+// ARMSubscriptionUsageDataSourceModel describes the data source data model.
+type ARMSubscriptionUsageDataSourceModel struct {
+	ID             types.String    `tfsdk:"id"`
+	SubscriptionID types.String    `tfsdk:"subscription_id"`
+	Location       types.String    `tfsdk:"location"`
+	Usages         []ARMUsageModel `tfsdk:"usages"`
+}
+
+// ARMUsageModel describes a single quota in the usages list.
+type ARMUsageModel struct {
+	Name          types.String `tfsdk:"name"`
+	LocalizedName types.String `tfsdk:"localized_name"`
+	CurrentValue  types.Int64  `tfsdk:"current_value"`
+	Limit         types.Int64  `tfsdk:"limit"`
+	Unit          types.String `tfsdk:"unit"`
+}
+
+func (d *ARMSubscriptionUsageDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_arm_subscription_usage"
+}
+
+func (d *ARMSubscriptionUsageDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Exposes the ARM compute quota usage (e.g. vCPU limits) of an Azure subscription in a region. " +
+			"Uses the Azure credentials configured for polling.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Identifier in the form subscription_id:location.",
+				Computed:    true,
+			},
+			"subscription_id": schema.StringAttribute{
+				Description: "The Azure subscription GUID.",
+				Required:    true,
+			},
+			"location": schema.StringAttribute{
+				Description: "The Azure region to read quotas for, e.g. westeurope.",
+				Required:    true,
+			},
+			"usages": schema.ListNestedAttribute{
+				Description: "The quotas of the subscription in the region.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Description: "The quota name, e.g. standardDSv3Family.",
+							Computed:    true,
+						},
+						"localized_name": schema.StringAttribute{
+							Description: "The display name of the quota.",
+							Computed:    true,
+						},
+						"current_value": schema.Int64Attribute{
+							Description: "The current usage.",
+							Computed:    true,
+						},
+						"limit": schema.Int64Attribute{
+							Description: "The quota limit.",
+							Computed:    true,
+						},
+						"unit": schema.StringAttribute{
+							Description: "The unit of the usage and limit, e.g. Count.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *ARMSubscriptionUsageDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *ARMSubscriptionUsageDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ARMSubscriptionUsageDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	subscriptionID := data.SubscriptionID.ValueString()
+	location := data.Location.ValueString()
+
+	tflog.Debug(ctx, "Reading ARM subscription usage", map[string]interface{}{
+		"subscription_id": subscriptionID,
+		"location":        location,
+	})
+
+	usages, err := d.client.GetARMSubscriptionUsage(ctx, subscriptionID, location)
+	if errors.Is(err, client.ErrARMPermissionDenied) {
+		resp.Diagnostics.AddError(
+			"Permission Denied Reading ARM Subscription Usage",
+			"The configured Azure identity cannot read subscription "+subscriptionID+". Grant it at least the "+
+				"Reader role on the subscription.\n\nError: "+err.Error(),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading ARM Subscription Usage",
+			"Could not read quota usage: "+err.Error(),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(subscriptionID + ":" + location)
+	data.Usages = make([]ARMUsageModel, 0, len(usages))
+	for _, usage := range usages {
+		data.Usages = append(data.Usages, ARMUsageModel{
+			Name:          types.StringValue(usage.Name.Value),
+			LocalizedName: types.StringValue(usage.Name.LocalizedValue),
+			CurrentValue:  types.Int64Value(usage.CurrentValue),
+			Limit:         types.Int64Value(usage.Limit),
+			Unit:          types.StringValue(usage.Unit),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
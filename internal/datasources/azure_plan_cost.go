@@ -0,0 +1,150 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &AzurePlanCostDataSource{}
+var _ datasource.DataSourceWithConfigure = &AzurePlanCostDataSource{}
+
+func NewAzurePlanCostDataSource() datasource.DataSource {
+	return &AzurePlanCostDataSource{}
+}
+
+// AzurePlanCostDataSource exposes the total consumption of an Azure Plan for a period.
+type AzurePlanCostDataSource struct {
+	client *client.Client
+}
+
+// AzurePlanCostDataSourceModel describes the data source data model.
+type AzurePlanCostDataSourceModel struct {
+	ID                types.String  `tfsdk:"id"`
+	AzurePlanID       types.Int64   `tfsdk:"azure_plan_id"`
+	Period            types.String  `tfsdk:"period"`
+	Total             types.Float64 `tfsdk:"total"`
+	Currency          types.String  `tfsdk:"currency"`
+	SubscriptionCount types.Int64   `tfsdk:"subscription_count"`
+	MissingCount      types.Int64   `tfsdk:"missing_count"`
+}
+
+func (d *AzurePlanCostDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_azure_plan_cost"
+}
+
+func (d *AzurePlanCostDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Exposes the total consumption across all subscriptions of an Azure Plan for a billing period.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Identifier in the form azure_plan_id:period.",
+				Computed:    true,
+			},
+			"azure_plan_id": schema.Int64Attribute{
+				Description: "The Azure Plan ID.",
+				Required:    true,
+			},
+			"period": schema.StringAttribute{
+				Description: "The billing period in YYYY-MM format.",
+				Required:    true,
+			},
+			"total": schema.Float64Attribute{
+				Description: "The total consumption of the plan for the period.",
+				Computed:    true,
+			},
+			"currency": schema.StringAttribute{
+				Description: "The currency of the total.",
+				Computed:    true,
+			},
+			"subscription_count": schema.Int64Attribute{
+				Description: "The number of subscriptions included in the total.",
+				Computed:    true,
+			},
+			"missing_count": schema.Int64Attribute{
+				Description: "The number of subscriptions whose consumption data was unavailable.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *AzurePlanCostDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *AzurePlanCostDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AzurePlanCostDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	period := data.Period.ValueString()
+	if _, err := time.Parse("2006-01", period); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("period"),
+			"Invalid Period",
+			"period must be in YYYY-MM format. Got: "+period,
+		)
+		return
+	}
+
+	azurePlanID := int(data.AzurePlanID.ValueInt64())
+
+	tflog.Debug(ctx, "Reading Azure Plan consumption", map[string]interface{}{
+		"azure_plan_id": azurePlanID,
+		"period":        period,
+	})
+
+	consumption, err := d.client.GetAzurePlanConsumption(ctx, azurePlanID, period)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Azure Plan Cost",
+			"Could not read consumption for Azure Plan: "+err.Error(),
+		)
+		return
+	}
+
+	if consumption.MissingCount > 0 {
+		resp.Diagnostics.AddWarning(
+			"Incomplete Azure Plan Cost",
+			fmt.Sprintf("Consumption data was unavailable for %d subscription(s); the total excludes them.", consumption.MissingCount),
+		)
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("%d:%s", azurePlanID, period))
+	data.Total = types.Float64Value(consumption.Total)
+	data.Currency = types.StringValue(consumption.Currency)
+	data.SubscriptionCount = types.Int64Value(int64(consumption.SubscriptionCount))
+	data.MissingCount = types.Int64Value(int64(consumption.MissingCount))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestAzurePlanCostDataSource(t *testing.T) {
+	resp := readDataSource(t, &AzurePlanCostDataSource{client: newTestClient(t)}, map[string]tftypes.Value{
+		"azure_plan_id": tftypes.NewValue(tftypes.Number, testPlanID),
+		"period":        tftypes.NewValue(tftypes.String, "2024-05"),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("read: %v", resp.Diagnostics)
+	}
+	if len(resp.Diagnostics) != 1 || resp.Diagnostics[0].Summary() != "Incomplete Azure Plan Cost" {
+		t.Errorf("diagnostics = %v, want a warning for the subscription without consumption data", resp.Diagnostics)
+	}
+
+	var data AzurePlanCostDataSourceModel
+	if diags := resp.State.Get(context.Background(), &data); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if data.ID.ValueString() != "873834:2024-05" || data.Total.ValueFloat64() != 12.5 || data.Currency.ValueString() != "EUR" {
+		t.Errorf("id = %v, total = %v %v, want 873834:2024-05 and 12.5 EUR", data.ID, data.Total, data.Currency)
+	}
+	if data.SubscriptionCount.ValueInt64() != 1 || data.MissingCount.ValueInt64() != 1 {
+		t.Errorf("subscription_count = %v, missing_count = %v, want 1 and 1", data.SubscriptionCount, data.MissingCount)
+	}
+}
+
+func TestAzurePlanCostDataSourceInvalidPeriod(t *testing.T) {
+	resp := readDataSource(t, &AzurePlanCostDataSource{client: newTestClient(t)}, map[string]tftypes.Value{
+		"azure_plan_id": tftypes.NewValue(tftypes.Number, testPlanID),
+		"period":        tftypes.NewValue(tftypes.String, "May 2024"),
+	})
+	if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != "Invalid Period" {
+		t.Errorf("diagnostics = %v, want an invalid period error", resp.Diagnostics)
+	}
+}
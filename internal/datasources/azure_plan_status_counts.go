@@ -0,0 +1,121 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &AzurePlanStatusCountsDataSource{}
+var _ datasource.DataSourceWithConfigure = &AzurePlanStatusCountsDataSource{}
+
+func NewAzurePlanStatusCountsDataSource() datasource.DataSource {
+	return &AzurePlanStatusCountsDataSource{}
+}
+
+// AzurePlanStatusCountsDataSource counts the subscriptions of an Azure Plan by status.
+type AzurePlanStatusCountsDataSource struct {
+	client *client.Client
+}
+
+// AzurePlanStatusCountsDataSourceModel describes the data source data model.
+type AzurePlanStatusCountsDataSourceModel struct {
+	ID           types.String           `tfsdk:"id"`
+	AzurePlanID  types.Int64            `tfsdk:"azure_plan_id"`
+	StatusCounts map[string]types.Int64 `tfsdk:"status_counts"`
+	Total        types.Int64            `tfsdk:"total"`
+}
+
+func (d *AzurePlanStatusCountsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_azure_plan_status_counts"
+}
+
+func (d *AzurePlanStatusCountsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Counts the subscriptions of an Azure Plan by status, e.g. for dashboards, without exposing every subscription.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The Azure Plan ID.",
+				Computed:    true,
+			},
+			"azure_plan_id": schema.Int64Attribute{
+				Description: "The Azure Plan ID.",
+				Required:    true,
+			},
+			"status_counts": schema.MapAttribute{
+				Description: "The number of subscriptions per lowercased status, e.g. active, cancelled or provisioning. " +
+					"Subscriptions without a reported status are counted as unknown.",
+				ElementType: types.Int64Type,
+				Computed:    true,
+			},
+			"total": schema.Int64Attribute{
+				Description: "The total number of subscriptions of the Azure Plan.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *AzurePlanStatusCountsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *AzurePlanStatusCountsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AzurePlanStatusCountsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	azurePlanID := int(data.AzurePlanID.ValueInt64())
+
+	counts, err := d.client.CountAzureSubscriptionsByStatus(ctx, azurePlanID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Counting Azure Subscriptions",
+			"Could not list subscriptions of Azure Plan: "+err.Error(),
+		)
+		return
+	}
+
+	tflog.Debug(ctx, "Counted Azure subscriptions by status", map[string]interface{}{
+		"azure_plan_id": azurePlanID,
+		"statuses":      len(counts),
+	})
+
+	data.ID = types.StringValue(fmt.Sprintf("%d", azurePlanID))
+	data.StatusCounts = make(map[string]types.Int64, len(counts))
+	total := 0
+	for status, count := range counts {
+		data.StatusCounts[status] = types.Int64Value(int64(count))
+		total += count
+	}
+	data.Total = types.Int64Value(int64(total))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestAzurePlanStatusCountsDataSource(t *testing.T) {
+	statuses := []string{"Active", "Active", "Cancelled", "Provisioning", "active", ""}
+	subs := make([]client.AzureSubscription, len(statuses))
+	for i, status := range statuses {
+		subs[i] = client.AzureSubscription{ID: i + 1, FriendlyName: "sub-" + strconv.Itoa(i+1), Status: status, AzurePlanID: testPlanID}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/connect/token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(client.TokenResponse{AccessToken: "token", TokenType: "Bearer", ExpiresIn: 3600})
+	})
+	mux.HandleFunc("/api/v1/azureplans/873834/azuresubscriptions", func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+		start := min((page-1)*pageSize, len(subs))
+		end := min(start+pageSize, len(subs))
+		json.NewEncoder(w).Encode(map[string]interface{}{"Items": subs[start:end], "TotalHits": len(subs)})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	c, err := client.NewClient(client.ClientConfig{BaseURL: server.URL, ClientID: "client", ClientSecret: "secret", PageSize: 4, MaxRetries: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := readDataSource(t, &AzurePlanStatusCountsDataSource{client: c}, map[string]tftypes.Value{
+		"azure_plan_id": tftypes.NewValue(tftypes.Number, testPlanID),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("read: %v", resp.Diagnostics)
+	}
+
+	var data AzurePlanStatusCountsDataSourceModel
+	if diags := resp.State.Get(context.Background(), &data); diags.HasError() {
+		t.Fatal(diags)
+	}
+	want := map[string]int64{"active": 3, "cancelled": 1, "provisioning": 1, "unknown": 1}
+	if len(data.StatusCounts) != len(want) {
+		t.Errorf("status_counts = %v, want %v", data.StatusCounts, want)
+	}
+	for status, count := range want {
+		if got := data.StatusCounts[status].ValueInt64(); got != count {
+			t.Errorf("status_counts[%s] = %d, want %d", status, got, count)
+		}
+	}
+	if data.ID.ValueString() != "873834" || data.Total.ValueInt64() != 6 {
+		t.Errorf("id = %v, total = %v, want 873834 and 6", data.ID, data.Total)
+	}
+}
+
+func TestAzurePlanStatusCountsDataSourceListFails(t *testing.T) {
+	// The shared fake API has no Azure Plan 1
+	resp := readDataSource(t, &AzurePlanStatusCountsDataSource{client: newTestClient(t)}, map[string]tftypes.Value{
+		"azure_plan_id": tftypes.NewValue(tftypes.Number, 1),
+	})
+	if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != "Error Counting Azure Subscriptions" {
+		t.Errorf("diagnostics = %v, want a counting error", resp.Diagnostics)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &AzureSubscriptionDataSource{}
+var _ datasource.DataSourceWithConfigure = &AzureSubscriptionDataSource{}
+
+func NewAzureSubscriptionDataSource() datasource.DataSource {
+	return &AzureSubscriptionDataSource{}
+}
+
+// AzureSubscriptionDataSource looks up an existing subscription by name or GUID.
+type AzureSubscriptionDataSource struct {
+	client *client.Client
+}
+
+// AzureSubscriptionDataSourceModel describes the data source data model.
+type AzureSubscriptionDataSourceModel struct {
+	ID               types.String `tfsdk:"id"`
+	AzurePlanID      types.Int64  `tfsdk:"azure_plan_id"`
+	Name             types.String `tfsdk:"name"`
+	SubscriptionID   types.String `tfsdk:"subscription_id"`
+	Status           types.String `tfsdk:"status"`
+	BillingAccountID types.String `tfsdk:"billing_account_id"`
+	PartnerOfRecord  types.String `tfsdk:"partner_of_record"`
+	LastModified     types.String `tfsdk:"last_modified"`
+}
+
+func (d *AzureSubscriptionDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_azure_subscription"
+}
+
+func (d *AzureSubscriptionDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Looks up an existing subscription of an Azure Plan by name or Azure subscription GUID, e.g. to " +
+			"reference subscriptions not managed by Terraform.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "The internal Crayon ID of the subscription.",
+				Computed:    true,
+			},
+			"azure_plan_id": schema.Int64Attribute{
+				Description: "The Azure Plan ID to look the subscription up in.",
+				Required:    true,
+			},
+			"name": schema.StringAttribute{
+				Description: "The display name of the subscription. Exactly one of name and subscription_id must be set; " +
+					"the lookup fails if several subscriptions have this name.",
+				Optional: true,
+				Computed: true,
+			},
+			"subscription_id": schema.StringAttribute{
+				Description: "The Azure subscription GUID. Exactly one of name and subscription_id must be set.",
+				Optional:    true,
+				Computed:    true,
+			},
+			"status": schema.StringAttribute{
+				Description: "The current status of the subscription (e.g., active, cancelled).",
+				Computed:    true,
+			},
+			"billing_account_id": schema.StringAttribute{
+				Description: "The billing account (payer) the subscription rolls up to, if reported by Cloud-iQ.",
+				Computed:    true,
+			},
+			"partner_of_record": schema.StringAttribute{
+				Description: "The Partner of Record (PoR) attributed to the subscription, if reported by Cloud-iQ.",
+				Computed:    true,
+			},
+			"last_modified": schema.StringAttribute{
+				Description: "When the subscription last changed in Cloud-iQ (RFC 3339), if reported.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *AzureSubscriptionDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *AzureSubscriptionDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AzureSubscriptionDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	azurePlanID := int(data.AzurePlanID.ValueInt64())
+	byName := !data.Name.IsNull()
+	byGUID := !data.SubscriptionID.IsNull()
+	if byName == byGUID {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Invalid Subscription Lookup",
+			"Exactly one of name and subscription_id must be set to look up a subscription.",
+		)
+		return
+	}
+
+	tflog.Debug(ctx, "Looking up Azure subscription", map[string]interface{}{
+		"azure_plan_id":   azurePlanID,
+		"name":            data.Name.ValueString(),
+		"subscription_id": data.SubscriptionID.ValueString(),
+	})
+
+	var found *client.AzureSubscription
+	if byName {
+		name := data.Name.ValueString()
+		matches, err := d.client.FindAzureSubscriptionsByName(ctx, azurePlanID, name)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Reading Azure Subscription",
+				fmt.Sprintf("Could not look up subscription '%s': %s", name, err.Error()),
+			)
+			return
+		}
+		switch len(matches) {
+		case 0:
+			resp.Diagnostics.AddAttributeError(
+				path.Root("name"),
+				"Subscription Not Found",
+				fmt.Sprintf("No subscription named '%s' exists in Azure Plan %d.", name, azurePlanID),
+			)
+			return
+		case 1:
+			found = &matches[0]
+		default:
+			ids := make([]string, len(matches))
+			for i, match := range matches {
+				ids[i] = strconv.Itoa(match.ID)
+			}
+			resp.Diagnostics.AddAttributeError(
+				path.Root("name"),
+				"Ambiguous Subscription Name",
+				fmt.Sprintf("%d subscriptions are named '%s' in Azure Plan %d (IDs %v). Look the subscription up by "+
+					"subscription_id instead.", len(matches), name, azurePlanID, ids),
+			)
+			return
+		}
+	} else {
+		guid := data.SubscriptionID.ValueString()
+		var err error
+		found, err = d.client.FindAzureSubscriptionByGUID(ctx, azurePlanID, guid)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("subscription_id"),
+				"Error Reading Azure Subscription",
+				fmt.Sprintf("Could not look up subscription %s: %s", guid, err.Error()),
+			)
+			return
+		}
+	}
+
+	// Listings may omit details, so read the subscription itself
+	subscription, err := d.client.GetAzureSubscription(ctx, azurePlanID, found.ID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Azure Subscription",
+			fmt.Sprintf("Could not read subscription ID %d: %s", found.ID, err.Error()),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(strconv.Itoa(subscription.ID))
+	data.Name = types.StringValue(subscription.FriendlyName)
+	data.SubscriptionID = types.StringValue(subscription.SubscriptionID)
+	data.Status = types.StringValue(subscription.Status)
+	data.BillingAccountID = stringOrNull(subscription.BillingAccountID)
+	data.PartnerOfRecord = stringOrNull(subscription.PartnerOfRecord)
+	data.LastModified = types.StringNull()
+	if timestamp, ok := subscription.LastModifiedTimestamp(); ok {
+		data.LastModified = types.StringValue(timestamp)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// stringOrNull returns a null string for empty API values
+func stringOrNull(value string) types.String {
+	if value == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(value)
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &AzureSubscriptionBudgetDataSource{}
+var _ datasource.DataSourceWithConfigure = &AzureSubscriptionBudgetDataSource{}
+
+func NewAzureSubscriptionBudgetDataSource() datasource.DataSource {
+	return &AzureSubscriptionBudgetDataSource{}
+}
+
+// AzureSubscriptionBudgetDataSource exposes a subscription's spending for a period against its budget.
+type AzureSubscriptionBudgetDataSource struct {
+	client *client.Client
+}
+
+// AzureSubscriptionBudgetDataSourceModel describes the data source data model.
+type AzureSubscriptionBudgetDataSourceModel struct {
+	ID                       types.String  `tfsdk:"id"`
+	AzurePlanID              types.Int64   `tfsdk:"azure_plan_id"`
+	SubscriptionID           types.Int64   `tfsdk:"subscription_id"`
+	Period                   types.String  `tfsdk:"period"`
+	Budget                   types.Float64 `tfsdk:"budget"`
+	Spent                    types.Float64 `tfsdk:"spent"`
+	Remaining                types.Float64 `tfsdk:"remaining"`
+	BudgetUtilizationPercent types.Float64 `tfsdk:"budget_utilization_percent"`
+	Currency                 types.String  `tfsdk:"currency"`
+}
+
+func (d *AzureSubscriptionBudgetDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_azure_subscription_budget"
+}
+
+func (d *AzureSubscriptionBudgetDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Exposes a subscription's spending for a billing period measured against its budget.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Identifier in the form azure_plan_id:subscription_id:period.",
+				Computed:    true,
+			},
+			"azure_plan_id": schema.Int64Attribute{
+				Description: "The Azure Plan ID.",
+				Required:    true,
+			},
+			"subscription_id": schema.Int64Attribute{
+				Description: "The internal Crayon ID of the subscription.",
+				Required:    true,
+			},
+			"period": schema.StringAttribute{
+				Description: "The billing period in YYYY-MM format.",
+				Required:    true,
+			},
+			"budget": schema.Float64Attribute{
+				Description: "The budget of the subscription. Null if it has no budget.",
+				Computed:    true,
+			},
+			"spent": schema.Float64Attribute{
+				Description: "The consumption of the subscription for the period. 0 if no consumption data is available.",
+				Computed:    true,
+			},
+			"remaining": schema.Float64Attribute{
+				Description: "The budget left after the spending. Null if the subscription has no budget.",
+				Computed:    true,
+			},
+			"budget_utilization_percent": schema.Float64Attribute{
+				Description: "The spending as a percentage of the budget. Null if the subscription has no (or a zero) budget.",
+				Computed:    true,
+			},
+			"currency": schema.StringAttribute{
+				Description: "The currency of the amounts.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *AzureSubscriptionBudgetDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *AzureSubscriptionBudgetDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AzureSubscriptionBudgetDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	period := data.Period.ValueString()
+	if _, err := time.Parse("2006-01", period); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("period"),
+			"Invalid Period",
+			"period must be in YYYY-MM format. Got: "+period,
+		)
+		return
+	}
+
+	azurePlanID := int(data.AzurePlanID.ValueInt64())
+	subscriptionID := int(data.SubscriptionID.ValueInt64())
+
+	tflog.Debug(ctx, "Reading Azure subscription budget utilization", map[string]interface{}{
+		"azure_plan_id":   azurePlanID,
+		"subscription_id": subscriptionID,
+		"period":          period,
+	})
+
+	utilization, err := d.client.GetAzureSubscriptionBudgetUtilization(ctx, azurePlanID, subscriptionID, period)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Azure Subscription Budget",
+			"Could not read budget utilization: "+err.Error(),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("%d:%d:%s", azurePlanID, subscriptionID, period))
+	data.Budget = types.Float64PointerValue(utilization.Budget)
+	data.Spent = types.Float64Value(utilization.Spent)
+	data.Remaining = types.Float64PointerValue(utilization.Remaining)
+	data.BudgetUtilizationPercent = types.Float64PointerValue(utilization.Percent)
+	data.Currency = types.StringValue(utilization.Currency)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
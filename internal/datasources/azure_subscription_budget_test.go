@@ -0,0 +1,65 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestAzureSubscriptionBudgetDataSource(t *testing.T) {
+	tests := map[string]struct {
+		subscriptionID int
+		want           AzureSubscriptionBudgetDataSourceModel
+	}{
+		"with a budget": {
+			subscriptionID: 1,
+			want: AzureSubscriptionBudgetDataSourceModel{
+				Budget:                   types.Float64Value(50),
+				Spent:                    types.Float64Value(12.5),
+				Remaining:                types.Float64Value(37.5),
+				BudgetUtilizationPercent: types.Float64Value(25),
+				Currency:                 types.StringValue("EUR"),
+			},
+		},
+		"without a budget or consumption": {
+			subscriptionID: 2,
+			want: AzureSubscriptionBudgetDataSourceModel{
+				Budget:                   types.Float64Null(),
+				Spent:                    types.Float64Value(0),
+				Remaining:                types.Float64Null(),
+				BudgetUtilizationPercent: types.Float64Null(),
+				Currency:                 types.StringValue(""),
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := readDataSource(t, &AzureSubscriptionBudgetDataSource{client: newTestClient(t)}, map[string]tftypes.Value{
+				"azure_plan_id":   tftypes.NewValue(tftypes.Number, testPlanID),
+				"subscription_id": tftypes.NewValue(tftypes.Number, test.subscriptionID),
+				"period":          tftypes.NewValue(tftypes.String, "2024-05"),
+			})
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("read: %v", resp.Diagnostics)
+			}
+
+			var data AzureSubscriptionBudgetDataSourceModel
+			if diags := resp.State.Get(context.Background(), &data); diags.HasError() {
+				t.Fatal(diags)
+			}
+			if !data.Budget.Equal(test.want.Budget) || !data.Spent.Equal(test.want.Spent) || !data.Remaining.Equal(test.want.Remaining) {
+				t.Errorf("budget = %v, spent = %v, remaining = %v, want %v, %v, %v",
+					data.Budget, data.Spent, data.Remaining, test.want.Budget, test.want.Spent, test.want.Remaining)
+			}
+			if !data.BudgetUtilizationPercent.Equal(test.want.BudgetUtilizationPercent) || !data.Currency.Equal(test.want.Currency) {
+				t.Errorf("budget_utilization_percent = %v, currency = %v, want %v, %v",
+					data.BudgetUtilizationPercent, data.Currency, test.want.BudgetUtilizationPercent, test.want.Currency)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &AzureSubscriptionRawDataSource{}
+var _ datasource.DataSourceWithConfigure = &AzureSubscriptionRawDataSource{}
+
+func NewAzureSubscriptionRawDataSource() datasource.DataSource {
+	return &AzureSubscriptionRawDataSource{}
+}
+
+// AzureSubscriptionRawDataSource exposes the raw API response for a subscription, as a debugging aid.
+type AzureSubscriptionRawDataSource struct {
+	client *client.Client
+}
+
+// AzureSubscriptionRawDataSourceModel describes the data source data model.
+type AzureSubscriptionRawDataSourceModel struct {
+	ID             types.String `tfsdk:"id"`
+	AzurePlanID    types.Int64  `tfsdk:"azure_plan_id"`
+	SubscriptionID types.Int64  `tfsdk:"subscription_id"`
+	Body           types.String `tfsdk:"body"`
+}
+
+func (d *AzureSubscriptionRawDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_azure_subscription_raw"
+}
+
+func (d *AzureSubscriptionRawDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Debugging aid that exposes the raw Cloud-iQ API response for a subscription, e.g. to diagnose " +
+			"fields that come back empty after an API change. Not meant for use in regular configurations.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Identifier in the form azure_plan_id:subscription_id.",
+				Computed:    true,
+			},
+			"azure_plan_id": schema.Int64Attribute{
+				Description: "The Azure Plan ID.",
+				Required:    true,
+			},
+			"subscription_id": schema.Int64Attribute{
+				Description: "The internal Crayon ID of the subscription.",
+				Required:    true,
+			},
+			"body": schema.StringAttribute{
+				Description: "The raw JSON response body. It never contains credentials.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *AzureSubscriptionRawDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *AzureSubscriptionRawDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AzureSubscriptionRawDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	azurePlanID := int(data.AzurePlanID.ValueInt64())
+	subscriptionID := int(data.SubscriptionID.ValueInt64())
+
+	tflog.Debug(ctx, "Reading raw Azure subscription", map[string]interface{}{
+		"azure_plan_id":   azurePlanID,
+		"subscription_id": subscriptionID,
+	})
+
+	body, err := d.client.GetAzureSubscriptionRaw(ctx, azurePlanID, subscriptionID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Azure Subscription",
+			fmt.Sprintf("Could not read subscription ID %d: %s", subscriptionID, err.Error()),
+		)
+		return
+	}
+
+	data.ID = types.StringValue(fmt.Sprintf("%d:%d", azurePlanID, subscriptionID))
+	data.Body = types.StringValue(body)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
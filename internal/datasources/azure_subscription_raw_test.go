@@ -0,0 +1,42 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestAzureSubscriptionRawDataSource(t *testing.T) {
+	resp := readDataSource(t, &AzureSubscriptionRawDataSource{client: newTestClient(t)}, map[string]tftypes.Value{
+		"azure_plan_id":   tftypes.NewValue(tftypes.Number, testPlanID),
+		"subscription_id": tftypes.NewValue(tftypes.Number, 1),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("read: %v", resp.Diagnostics)
+	}
+
+	var data AzureSubscriptionRawDataSourceModel
+	if diags := resp.State.Get(context.Background(), &data); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if data.ID.ValueString() != "873834:1" {
+		t.Errorf("id = %v, want 873834:1", data.ID)
+	}
+	if data.Body.ValueString() != testRawSubscription {
+		t.Errorf("body = %s, want the raw response body %s", data.Body.ValueString(), testRawSubscription)
+	}
+}
+
+func TestAzureSubscriptionRawDataSourceNotFound(t *testing.T) {
+	resp := readDataSource(t, &AzureSubscriptionRawDataSource{client: newTestClient(t)}, map[string]tftypes.Value{
+		"azure_plan_id":   tftypes.NewValue(tftypes.Number, testPlanID),
+		"subscription_id": tftypes.NewValue(tftypes.Number, 3),
+	})
+	if !resp.Diagnostics.HasError() || resp.Diagnostics.Errors()[0].Summary() != "Error Reading Azure Subscription" {
+		t.Errorf("diagnostics = %v, want a read error", resp.Diagnostics)
+	}
+}
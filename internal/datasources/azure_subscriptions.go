@@ -0,0 +1,291 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &AzureSubscriptionsDataSource{}
+var _ datasource.DataSourceWithConfigure = &AzureSubscriptionsDataSource{}
+
+func NewAzureSubscriptionsDataSource() datasource.DataSource {
+	return &AzureSubscriptionsDataSource{}
+}
+
+// AzureSubscriptionsDataSource lists the subscriptions of an Azure Plan.
+type AzureSubscriptionsDataSource struct {
+	client *client.Client
+}
+
+// AzureSubscriptionsDataSourceModel describes the data source data model.
+type AzureSubscriptionsDataSourceModel struct {
+	ID                types.String             `tfsdk:"id"`
+	AzurePlanID       types.Int64              `tfsdk:"azure_plan_id"`
+	ModifiedSince     types.String             `tfsdk:"modified_since"`
+	IncludeTags       types.Bool               `tfsdk:"include_tags"`
+	Project           types.String             `tfsdk:"project"`
+	ExternalReference types.String             `tfsdk:"external_reference"`
+	EnrichmentTimeout types.Int64              `tfsdk:"enrichment_timeout"`
+	Subscriptions     []AzureSubscriptionModel `tfsdk:"subscriptions"`
+}
+
+// AzureSubscriptionModel describes a single subscription in the subscriptions list.
+type AzureSubscriptionModel struct {
+	ID             types.Int64  `tfsdk:"id"`
+	Name           types.String `tfsdk:"name"`
+	SubscriptionID types.String `tfsdk:"subscription_id"`
+	Status         types.String `tfsdk:"status"`
+	LastModified   types.String `tfsdk:"last_modified"`
+	Tags           types.Map    `tfsdk:"tags"`
+}
+
+func (d *AzureSubscriptionsDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_azure_subscriptions"
+}
+
+func (d *AzureSubscriptionsDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Lists the subscriptions of an Azure Plan, optionally only those changed since a timestamp.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Identifier in the form azure_plan_id or azure_plan_id:modified_since.",
+				Computed:    true,
+			},
+			"azure_plan_id": schema.Int64Attribute{
+				Description: "The Azure Plan ID.",
+				Required:    true,
+			},
+			"modified_since": schema.StringAttribute{
+				Description: "Only list subscriptions changed at or after this RFC 3339 timestamp, e.g. 2024-01-31T00:00:00Z. " +
+					"Subscriptions that don't report when they were modified are always listed.",
+				Optional: true,
+			},
+			"include_tags": schema.BoolAttribute{
+				Description: "Also read the tags of every subscription. They are fetched concurrently within enrichment_timeout.",
+				Optional:    true,
+			},
+			"project": schema.StringAttribute{
+				Description: "Only list subscriptions grouped under this project, i.e. with this 'project' tag. Requires " +
+					"reading the tags of every subscription, within enrichment_timeout.",
+				Optional: true,
+			},
+			"external_reference": schema.StringAttribute{
+				Description: "Only list subscriptions linked to this external ticket or order ID, i.e. with this " +
+					"'external-reference' tag. Requires reading the tags of every subscription, within enrichment_timeout.",
+				Optional: true,
+			},
+			"enrichment_timeout": schema.Int64Attribute{
+				Description: "Seconds to spend reading the tags of all subscriptions. Subscriptions whose tags couldn't be " +
+					"read in time are listed with null tags and a warning. Defaults to 60.",
+				Optional: true,
+			},
+			"subscriptions": schema.ListNestedAttribute{
+				Description: "The subscriptions of the Azure Plan.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.Int64Attribute{
+							Description: "The internal Crayon ID of the subscription.",
+							Computed:    true,
+						},
+						"name": schema.StringAttribute{
+							Description: "The display name of the subscription.",
+							Computed:    true,
+						},
+						"subscription_id": schema.StringAttribute{
+							Description: "The Azure subscription GUID.",
+							Computed:    true,
+						},
+						"status": schema.StringAttribute{
+							Description: "The current status of the subscription.",
+							Computed:    true,
+						},
+						"last_modified": schema.StringAttribute{
+							Description: "When the subscription last changed, if reported by Cloud-iQ.",
+							Computed:    true,
+						},
+						"tags": schema.MapAttribute{
+							Description: "The tags of the subscription. Null unless include_tags is set and they could be read.",
+							ElementType: types.StringType,
+							Computed:    true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *AzureSubscriptionsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *AzureSubscriptionsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data AzureSubscriptionsDataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	azurePlanID := int(data.AzurePlanID.ValueInt64())
+	id := fmt.Sprintf("%d", azurePlanID)
+
+	tflog.Debug(ctx, "Listing Azure subscriptions", map[string]interface{}{
+		"azure_plan_id":      azurePlanID,
+		"modified_since":     data.ModifiedSince.ValueString(),
+		"project":            data.Project.ValueString(),
+		"external_reference": data.ExternalReference.ValueString(),
+	})
+
+	var subs []client.AzureSubscription
+	var err error
+	if data.ModifiedSince.IsNull() {
+		subs, err = d.client.GetAzureSubscriptions(ctx, azurePlanID)
+	} else {
+		since, parseErr := time.Parse(time.RFC3339, data.ModifiedSince.ValueString())
+		if parseErr != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("modified_since"),
+				"Invalid Timestamp",
+				"modified_since must be an RFC 3339 timestamp, e.g. 2024-01-31T00:00:00Z. Got: "+data.ModifiedSince.ValueString(),
+			)
+			return
+		}
+		subs, err = d.client.GetAzureSubscriptionsModifiedSince(ctx, azurePlanID, since)
+		id += ":" + data.ModifiedSince.ValueString()
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Listing Azure Subscriptions",
+			"Could not list subscriptions of Azure Plan: "+err.Error(),
+		)
+		return
+	}
+
+	enrichmentTimeout := defaultEnrichmentTimeout
+	if !data.EnrichmentTimeout.IsNull() {
+		seconds := data.EnrichmentTimeout.ValueInt64()
+		if seconds < 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("enrichment_timeout"),
+				"Invalid Enrichment Timeout",
+				fmt.Sprintf("enrichment_timeout must be at least 1 second. Got: %d", seconds),
+			)
+			return
+		}
+		enrichmentTimeout = time.Duration(seconds) * time.Second
+	}
+
+	// Enrichment is best effort: the basic fields are listed even if it fails or times out
+	var tags []map[string]string
+	var tagErrs []error
+	tagFilters := map[string]string{}
+	if !data.Project.IsNull() {
+		tagFilters[client.ProjectTagKey] = data.Project.ValueString()
+	}
+	if !data.ExternalReference.IsNull() {
+		tagFilters[client.ExternalReferenceTagKey] = data.ExternalReference.ValueString()
+	}
+	filterByTags := len(tagFilters) > 0
+	if data.IncludeTags.ValueBool() || filterByTags {
+		tags, tagErrs = enrich(ctx, len(subs), enrichmentTimeout, func(ctx context.Context, i int) (map[string]string, error) {
+			subscriptionTags, err := d.client.GetAzureSubscriptionTags(ctx, azurePlanID, subs[i].ID)
+			if subscriptionTags == nil {
+				subscriptionTags = map[string]string{}
+			}
+			return subscriptionTags, err
+		})
+		failed := 0
+		var firstErr error
+		for _, err := range tagErrs {
+			if err != nil {
+				failed++
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+		}
+		if failed > 0 && filterByTags {
+			// Leaving them out would silently drop matching subscriptions from the listing
+			resp.Diagnostics.AddError(
+				"Error Listing Azure Subscriptions",
+				fmt.Sprintf("The tags of %d of %d subscriptions could not be read within %v, so the subscriptions can't "+
+					"be filtered by project or external_reference. Retry, or raise enrichment_timeout if the reads timed "+
+					"out. First error: %v", failed, len(subs), enrichmentTimeout, firstErr),
+			)
+			return
+		} else if failed > 0 {
+			resp.Diagnostics.AddWarning(
+				"Incomplete Subscription Inventory",
+				fmt.Sprintf("The tags of %d of %d subscriptions could not be read within %v and are null. First error: %v",
+					failed, len(subs), enrichmentTimeout, firstErr),
+			)
+		}
+	}
+
+	data.ID = types.StringValue(id)
+	data.Subscriptions = make([]AzureSubscriptionModel, 0, len(subs))
+	for i, sub := range subs {
+		if filterByTags && !matchesTags(tags[i], tagFilters) {
+			continue
+		}
+		lastModified := types.StringNull()
+		if sub.LastModified != "" {
+			lastModified = types.StringValue(sub.LastModified)
+		}
+		subscriptionTags := types.MapNull(types.StringType)
+		if data.IncludeTags.ValueBool() && tagErrs[i] == nil {
+			value, diags := types.MapValueFrom(ctx, types.StringType, tags[i])
+			resp.Diagnostics.Append(diags...)
+			subscriptionTags = value
+		}
+		data.Subscriptions = append(data.Subscriptions, AzureSubscriptionModel{
+			ID:             types.Int64Value(int64(sub.ID)),
+			Name:           types.StringValue(sub.FriendlyName),
+			SubscriptionID: types.StringValue(sub.SubscriptionID),
+			Status:         types.StringValue(sub.Status),
+			LastModified:   lastModified,
+			Tags:           subscriptionTags,
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// matchesTags reports whether tags has every key of filters with the same value
+func matchesTags(tags, filters map[string]string) bool {
+	for key, want := range filters {
+		if value, _ := client.LookupTag(tags, key); value != want {
+			return false
+		}
+	}
+	return true
+}
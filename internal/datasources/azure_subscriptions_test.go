@@ -0,0 +1,154 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+const testPlanID = 873834
+
+// testRawSubscription is the response body for subscription 1
+const testRawSubscription = `{"Id": 1, "FriendlyName": "alpha-prod", "Status": "Active", "AzurePlanId": 873834, "Unmapped": true}`
+
+// newTestClient returns a client for a fake Crayon API serving two subscriptions of testPlanID.
+// Subscription 1 is tagged with project alpha and consumed 12.5 EUR of a 50 EUR budget; reading
+// the tags and consumption of subscription 2 fails, and it has no budget.
+func newTestClient(t *testing.T) *client.Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/connect/token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(client.TokenResponse{AccessToken: "token", TokenType: "Bearer", ExpiresIn: 3600})
+	})
+	mux.HandleFunc("/api/v1/azureplans/873834/azuresubscriptions", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"Items": []client.AzureSubscription{
+				{ID: 1, FriendlyName: "alpha-prod", Status: "Active", AzurePlanID: testPlanID},
+				{ID: 2, FriendlyName: "beta-prod", Status: "Active", AzurePlanID: testPlanID},
+			},
+			"TotalCount": 2,
+		})
+	})
+	mux.HandleFunc("/api/v1/azureplans/873834/azuresubscriptions/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testRawSubscription))
+	})
+	mux.HandleFunc("/api/v1/azureplans/873834/azuresubscriptions/1/tags", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{client.ProjectTagKey: "alpha"})
+	})
+	mux.HandleFunc("/api/v1/azureplans/873834/azuresubscriptions/2/tags", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "tags unavailable", http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/api/v1/azureplans/873834/azuresubscriptions/1/consumption", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(client.Consumption{Total: 12.5, Currency: "EUR"})
+	})
+	mux.HandleFunc("/api/v1/azureplans/873834/azuresubscriptions/2/consumption", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "consumption unavailable", http.StatusNotFound)
+	})
+	mux.HandleFunc("/api/v1/azureplans/873834/azuresubscriptions/1/budget", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(client.Budget{Amount: 50, Currency: "EUR"})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	c, err := client.NewClient(client.ClientConfig{
+		BaseURL:      server.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		MaxRetries:   -1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// readSubscriptions reads the crayon_azure_subscriptions data source with the given attributes set
+func readSubscriptions(t *testing.T, c *client.Client, attributes map[string]tftypes.Value) *datasource.ReadResponse {
+	t.Helper()
+	values := map[string]tftypes.Value{"azure_plan_id": tftypes.NewValue(tftypes.Number, testPlanID)}
+	for name, value := range attributes {
+		values[name] = value
+	}
+	return readDataSource(t, &AzureSubscriptionsDataSource{client: c}, values)
+}
+
+// readDataSource reads d with the given attributes set and all others null
+func readDataSource(t *testing.T, d datasource.DataSource, attributes map[string]tftypes.Value) *datasource.ReadResponse {
+	t.Helper()
+	ctx := context.Background()
+
+	var schemaResp datasource.SchemaResponse
+	d.Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+	if schemaResp.Diagnostics.HasError() {
+		t.Fatalf("schema: %v", schemaResp.Diagnostics)
+	}
+
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	values := map[string]tftypes.Value{}
+	for name, attributeType := range objectType.AttributeTypes {
+		values[name] = tftypes.NewValue(attributeType, nil)
+	}
+	for name, value := range attributes {
+		values[name] = value
+	}
+
+	config := tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, values)}
+	resp := &datasource.ReadResponse{State: tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, nil)}}
+	d.Read(ctx, datasource.ReadRequest{Config: config}, resp)
+	return resp
+}
+
+func TestAzureSubscriptionsFilterFailsOnUnreadableTags(t *testing.T) {
+	resp := readSubscriptions(t, newTestClient(t), map[string]tftypes.Value{
+		"project": tftypes.NewValue(tftypes.String, "alpha"),
+	})
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("expected an error, since subscription 2 may belong to the project")
+	}
+	if summary := resp.Diagnostics.Errors()[0].Summary(); summary != "Error Listing Azure Subscriptions" {
+		t.Errorf("error summary = %q", summary)
+	}
+	if detail := resp.Diagnostics.Errors()[0].Detail(); !strings.Contains(detail, "1 of 2") {
+		t.Errorf("error detail doesn't report the failed reads: %s", detail)
+	}
+}
+
+func TestAzureSubscriptionsIncludeTagsWarnsOnUnreadableTags(t *testing.T) {
+	resp := readSubscriptions(t, newTestClient(t), map[string]tftypes.Value{
+		"include_tags": tftypes.NewValue(tftypes.Bool, true),
+	})
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics.Errors())
+	}
+	if resp.Diagnostics.WarningsCount() != 1 || resp.Diagnostics.Warnings()[0].Summary() != "Incomplete Subscription Inventory" {
+		t.Fatalf("expected an Incomplete Subscription Inventory warning, got %v", resp.Diagnostics)
+	}
+
+	var data AzureSubscriptionsDataSourceModel
+	if diags := resp.State.Get(context.Background(), &data); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if len(data.Subscriptions) != 2 {
+		t.Fatalf("listed %d subscriptions, want both", len(data.Subscriptions))
+	}
+	if data.Subscriptions[0].Tags.IsNull() {
+		t.Error("subscription 1 tags are null, want them read")
+	}
+	if !data.Subscriptions[1].Tags.IsNull() {
+		t.Error("subscription 2 tags are set, want null since they couldn't be read")
+	}
+}
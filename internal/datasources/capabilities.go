@@ -0,0 +1,160 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &CapabilitiesDataSource{}
+var _ datasource.DataSourceWithConfigure = &CapabilitiesDataSource{}
+
+func NewCapabilitiesDataSource() datasource.DataSource {
+	return &CapabilitiesDataSource{}
+}
+
+// CapabilitiesDataSource reports what the provider's credentials are allowed to do.
+type CapabilitiesDataSource struct {
+	client *client.Client
+}
+
+// CapabilitiesDataSourceModel describes the data source data model.
+type CapabilitiesDataSourceModel struct {
+	ID                    types.String        `tfsdk:"id"`
+	Organizations         []OrganizationModel `tfsdk:"organizations"`
+	CanRead               types.Bool          `tfsdk:"can_read"`
+	CanCreate             types.Bool          `tfsdk:"can_create"`
+	CanCancel             types.Bool          `tfsdk:"can_cancel"`
+	AzurePollingAvailable types.Bool          `tfsdk:"azure_polling_available"`
+	Notes                 []types.String      `tfsdk:"notes"`
+}
+
+// OrganizationModel describes a single organization in the organizations list.
+type OrganizationModel struct {
+	ID   types.Int64  `tfsdk:"id"`
+	Name types.String `tfsdk:"name"`
+}
+
+func (d *CapabilitiesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_capabilities"
+}
+
+func (d *CapabilitiesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Validates the provider's credentials and reports what they can do, to diagnose permission " +
+			"issues before they cause apply failures. Nothing is changed: permissions that can't be checked " +
+			"without a write are reported as unknown (null).",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Placeholder identifier.",
+				Computed:    true,
+			},
+			"organizations": schema.ListNestedAttribute{
+				Description: "The organizations the Crayon credentials can access.",
+				Computed:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.Int64Attribute{
+							Description: "The organization ID.",
+							Computed:    true,
+						},
+						"name": schema.StringAttribute{
+							Description: "The organization name.",
+							Computed:    true,
+						},
+					},
+				},
+			},
+			"can_read": schema.BoolAttribute{
+				Description: "Whether the customer tenants of organization_id can be read.",
+				Computed:    true,
+			},
+			"can_create": schema.BoolAttribute{
+				Description: "Whether the credentials are authorized to create subscriptions, checked with a create " +
+					"dry-run. Null if that can't be told.",
+				Computed:    true,
+			},
+			"can_cancel": schema.BoolAttribute{
+				Description: "Whether the credentials are authorized to cancel subscriptions. Always null, as it can't " +
+					"be checked without cancelling.",
+				Computed:    true,
+			},
+			"azure_polling_available": schema.BoolAttribute{
+				Description: "Whether an Azure ARM token can be obtained for direct Azure polling.",
+				Computed:    true,
+			},
+			"notes": schema.ListAttribute{
+				Description: "Explanations for unavailable capabilities.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *CapabilitiesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *CapabilitiesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	caps, err := d.client.Capabilities(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Capabilities",
+			"Could not validate the Crayon credentials: "+err.Error(),
+		)
+		return
+	}
+
+	tflog.Debug(ctx, "Probed credential capabilities", map[string]interface{}{
+		"can_read":                caps.CanRead,
+		"can_create":              caps.CanCreate,
+		"can_cancel":              caps.CanCancel,
+		"azure_polling_available": caps.AzurePollingAvailable,
+	})
+
+	data := CapabilitiesDataSourceModel{
+		ID:                    types.StringValue("capabilities"),
+		Organizations:         make([]OrganizationModel, 0, len(caps.Organizations)),
+		CanRead:               types.BoolValue(caps.CanRead),
+		CanCreate:             types.BoolPointerValue(caps.CanCreate),
+		CanCancel:             types.BoolPointerValue(caps.CanCancel),
+		AzurePollingAvailable: types.BoolValue(caps.AzurePollingAvailable),
+		Notes:                 make([]types.String, 0, len(caps.Notes)),
+	}
+	for _, org := range caps.Organizations {
+		data.Organizations = append(data.Organizations, OrganizationModel{
+			ID:   types.Int64Value(org.ID),
+			Name: types.StringValue(org.Name),
+		})
+	}
+	for _, note := range caps.Notes {
+		data.Notes = append(data.Notes, types.StringValue(note))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
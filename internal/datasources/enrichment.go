@@ -0,0 +1,65 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+)
+
+// maxConcurrentEnrichments bounds the API calls made at once to enrich a list
+const maxConcurrentEnrichments = 8
+
+// defaultEnrichmentTimeout bounds the total time spent enriching a list
+const defaultEnrichmentTimeout = 60 * time.Second
+
+// enrich calls fetch for each of n items concurrently, sharing a single deadline, and returns
+// each item's result and error. fetch is given a context that ends at the deadline. Items that
+// didn't complete before the deadline get the context's error; calls still in flight then are
+// abandoned and their results dropped.
+func enrich[T any](ctx context.Context, n int, timeout time.Duration, fetch func(ctx context.Context, i int) (T, error)) ([]T, []error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	results := make([]T, n)
+	errs := make([]error, n)
+	done := make([]bool, n)
+	collected := false
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		// Items not started by the deadline are reported below, as not done
+		_ = client.FanOut(ctx, n, maxConcurrentEnrichments, func(i int) {
+			result, err := fetch(ctx, i)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if !collected {
+				results[i], errs[i], done[i] = result, err, true
+			}
+		})
+	}()
+	select {
+	case <-finished:
+	case <-ctx.Done():
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	collected = true
+	for i := range errs {
+		if !done[i] {
+			errs[i] = context.DeadlineExceeded
+			if err := ctx.Err(); err != nil {
+				errs[i] = err
+			}
+		}
+	}
+	return results, errs
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEnrichPartialFailure(t *testing.T) {
+	results, errs := enrich(context.Background(), 5, time.Minute, func(ctx context.Context, i int) (int, error) {
+		if i == 2 {
+			return 0, fmt.Errorf("item %d failed", i)
+		}
+		return i * 10, nil
+	})
+
+	for i := range results {
+		if i == 2 {
+			if errs[i] == nil {
+				t.Errorf("item 2: expected an error")
+			}
+			continue
+		}
+		if errs[i] != nil || results[i] != i*10 {
+			t.Errorf("item %d = %d, %v; want %d, nil", i, results[i], errs[i], i*10)
+		}
+	}
+}
+
+func TestEnrichConcurrency(t *testing.T) {
+	var running, peak int32
+	_, errs := enrich(context.Background(), 4*maxConcurrentEnrichments, time.Minute, func(ctx context.Context, i int) (struct{}, error) {
+		now := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if now <= old || atomic.CompareAndSwapInt32(&peak, old, now) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return struct{}{}, nil
+	})
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("item %d: %v", i, err)
+		}
+	}
+	if peak > maxConcurrentEnrichments {
+		t.Errorf("%d fetches ran at once, want at most %d", peak, maxConcurrentEnrichments)
+	}
+}
+
+func TestEnrichTimeout(t *testing.T) {
+	// Item 0 completes, the others block past the deadline, and some are never started
+	n := 2 * maxConcurrentEnrichments
+	release := make(chan struct{})
+	defer close(release)
+
+	start := time.Now()
+	results, errs := enrich(context.Background(), n, 50*time.Millisecond, func(ctx context.Context, i int) (string, error) {
+		if i == 0 {
+			return "done", nil
+		}
+		// Ignores ctx, like a call stuck in flight
+		<-release
+		return "late", nil
+	})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("enrich returned after %v, long past its deadline", elapsed)
+	}
+
+	if errs[0] != nil || results[0] != "done" {
+		t.Errorf("item 0 = %q, %v; want done, nil", results[0], errs[0])
+	}
+	for i := 1; i < n; i++ {
+		if !errors.Is(errs[i], context.DeadlineExceeded) {
+			t.Errorf("item %d error = %v, want context.DeadlineExceeded", i, errs[i])
+		}
+		if results[i] != "" {
+			t.Errorf("item %d result = %q, want it dropped", i, results[i])
+		}
+	}
+}
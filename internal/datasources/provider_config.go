@@ -0,0 +1,106 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ProviderConfigDataSource{}
+var _ datasource.DataSourceWithConfigure = &ProviderConfigDataSource{}
+
+func NewProviderConfigDataSource() datasource.DataSource {
+	return &ProviderConfigDataSource{}
+}
+
+// ProviderConfigDataSource exposes the provider's effective (non-sensitive) configuration.
+type ProviderConfigDataSource struct {
+	client *client.Client
+}
+
+// ProviderConfigDataSourceModel describes the data source data model.
+type ProviderConfigDataSourceModel struct {
+	ID                  types.String `tfsdk:"id"`
+	BaseURL             types.String `tfsdk:"base_url"`
+	OrganizationID      types.Int64  `tfsdk:"organization_id"`
+	AuthMode            types.String `tfsdk:"auth_mode"`
+	HasAzureCredentials types.Bool   `tfsdk:"has_azure_credentials"`
+	AzureAuthMode       types.String `tfsdk:"azure_auth_mode"`
+}
+
+func (d *ProviderConfigDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_provider_config"
+}
+
+func (d *ProviderConfigDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Exposes the configuration the provider resolved from its arguments and environment variables. " +
+			"Secrets and passwords are never exposed.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Description: "Placeholder identifier.",
+				Computed:    true,
+			},
+			"base_url": schema.StringAttribute{
+				Description: "The Crayon API base URL in use.",
+				Computed:    true,
+			},
+			"organization_id": schema.Int64Attribute{
+				Description: "The Crayon Organization ID in use.",
+				Computed:    true,
+			},
+			"auth_mode": schema.StringAttribute{
+				Description: "The Crayon API authentication mode (password or client_credentials).",
+				Computed:    true,
+			},
+			"has_azure_credentials": schema.BoolAttribute{
+				Description: "Whether a complete Azure Service Principal or a managed identity is configured for direct Azure polling.",
+				Computed:    true,
+			},
+			"azure_auth_mode": schema.StringAttribute{
+				Description: "How Azure ARM tokens are obtained (service_principal, managed_identity or azure_cli).",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *ProviderConfigDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*client.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+
+	d.client = client
+}
+
+func (d *ProviderConfigDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	data := ProviderConfigDataSourceModel{
+		ID:                  types.StringValue("provider_config"),
+		BaseURL:             types.StringValue(d.client.GetBaseURL()),
+		OrganizationID:      types.Int64Value(d.client.GetOrganizationID()),
+		AuthMode:            types.StringValue(d.client.GetAuthMode()),
+		HasAzureCredentials: types.BoolValue(d.client.HasAzureCredentials()),
+		AzureAuthMode:       types.StringValue(d.client.GetAzureAuthMode()),
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package datasources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+)
+
+func TestProviderConfigDataSource(t *testing.T) {
+	tests := map[string]struct {
+		config            client.ClientConfig
+		wantAuthMode      string
+		wantAzureAuthMode string
+		wantAzureCreds    bool
+	}{
+		"client credentials and Azure CLI": {
+			config:            client.ClientConfig{ClientID: "client", ClientSecret: "secret"},
+			wantAuthMode:      client.AuthModeClientCredentials,
+			wantAzureAuthMode: client.AuthModeAzureCLI,
+		},
+		"password and service principal": {
+			config: client.ClientConfig{
+				ClientID: "client", ClientSecret: "secret", Username: "user", Password: "password",
+				AzureClientID: "azure-client", AzureClientSecret: "azure-secret", AzureTenantID: "tenant",
+			},
+			wantAuthMode:      client.AuthModePassword,
+			wantAzureAuthMode: client.AuthModeServicePrincipal,
+			wantAzureCreds:    true,
+		},
+		"incomplete service principal and managed identity": {
+			config:            client.ClientConfig{ClientID: "client", ClientSecret: "secret", AzureClientID: "azure-client", AzureUseMSI: true},
+			wantAuthMode:      client.AuthModeClientCredentials,
+			wantAzureAuthMode: client.AuthModeManagedIdentity,
+			wantAzureCreds:    true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := test.config
+			config.BaseURL = "https://api.crayon.test"
+			config.OrganizationID = 4051878
+			c, err := client.NewClient(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp := readDataSource(t, &ProviderConfigDataSource{client: c}, nil)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("read: %v", resp.Diagnostics)
+			}
+			var data ProviderConfigDataSourceModel
+			if diags := resp.State.Get(context.Background(), &data); diags.HasError() {
+				t.Fatal(diags)
+			}
+
+			if data.BaseURL.ValueString() != "https://api.crayon.test" || data.OrganizationID.ValueInt64() != 4051878 {
+				t.Errorf("base_url = %v, organization_id = %v, want the configured ones", data.BaseURL, data.OrganizationID)
+			}
+			if data.AuthMode.ValueString() != test.wantAuthMode {
+				t.Errorf("auth_mode = %v, want %s", data.AuthMode, test.wantAuthMode)
+			}
+			if data.AzureAuthMode.ValueString() != test.wantAzureAuthMode || data.HasAzureCredentials.ValueBool() != test.wantAzureCreds {
+				t.Errorf("azure_auth_mode = %v, has_azure_credentials = %v, want %s, %v",
+					data.AzureAuthMode, data.HasAzureCredentials, test.wantAzureAuthMode, test.wantAzureCreds)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// credentialsFile is the JSON document loaded from credentials_file. Every field is optional;
+// provider arguments and environment variables take precedence over it.
+type credentialsFile struct {
+	BaseURL           string `json:"base_url"`
+	ClientID          string `json:"client_id"`
+	ClientSecret      string `json:"client_secret"`
+	Username          string `json:"username"`
+	Password          string `json:"password"`
+	OrganizationID    int64  `json:"organization_id"`
+	AzureClientID     string `json:"azure_client_id"`
+	AzureClientSecret string `json:"azure_client_secret"`
+	AzureTenantID     string `json:"azure_tenant_id"`
+}
+
+// loadCredentialsFile reads a credentials file, expanding a leading ~ to the home directory.
+// Unknown keys are rejected so typos don't silently leave a credential unset. The returned
+// warning is non-empty if the file is readable by other users.
+func loadCredentialsFile(path string) (*credentialsFile, string, error) {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, "", fmt.Errorf("could not resolve the home directory: %w", err)
+		}
+		path = filepath.Join(home, rest)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var warning string
+	// Windows doesn't report meaningful permission bits
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o004 != 0 {
+		warning = fmt.Sprintf("The credentials file %s is readable by all users (mode %s). "+
+			"Restrict it with 'chmod 600 %s'.", path, info.Mode().Perm(), path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var creds credentialsFile
+	if err := decoder.Decode(&creds); err != nil {
+		return nil, "", fmt.Errorf("%s is not a valid credentials file: %w", path, err)
+	}
+
+	return &creds, warning, nil
+}
+
+// firstNonEmpty returns the first non-empty value
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
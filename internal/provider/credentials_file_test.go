@@ -0,0 +1,149 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// writeCredentialsFile writes content to a credentials file with the given mode and returns its path
+func writeCredentialsFile(t *testing.T, content string, mode os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+	// WriteFile applies the umask, so set the exact mode explicitly
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadCredentialsFile(t *testing.T) {
+	path := writeCredentialsFile(t, `{
+		"base_url": "https://api.crayon.test",
+		"client_id": "client",
+		"client_secret": "secret",
+		"organization_id": 4051878,
+		"azure_tenant_id": "tenant"
+	}`, 0o600)
+
+	creds, warning, err := loadCredentialsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := credentialsFile{BaseURL: "https://api.crayon.test", ClientID: "client", ClientSecret: "secret", OrganizationID: 4051878, AzureTenantID: "tenant"}
+	if *creds != want {
+		t.Errorf("loaded %+v, want %+v", *creds, want)
+	}
+	if warning != "" {
+		t.Errorf("warning %q for a file only its owner can read", warning)
+	}
+}
+
+func TestLoadCredentialsFileWorldReadable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows doesn't report meaningful permission bits")
+	}
+	path := writeCredentialsFile(t, `{"client_id": "client"}`, 0o644)
+
+	_, warning, err := loadCredentialsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(warning, "readable by all users") {
+		t.Errorf("warning %q, want the file reported as readable by all users", warning)
+	}
+}
+
+func TestLoadCredentialsFileInvalid(t *testing.T) {
+	tests := map[string]string{
+		"malformed JSON": `{"client_id": "client"`,
+		"unknown key":    `{"client_id": "client", "clientsecret": "secret"}`,
+		"wrong type":     `{"organization_id": "4051878"}`,
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := loadCredentialsFile(writeCredentialsFile(t, content, 0o600))
+			if err == nil || !strings.Contains(err.Error(), "is not a valid credentials file") {
+				t.Errorf("err = %v, want the file rejected", err)
+			}
+		})
+	}
+
+	if _, _, err := loadCredentialsFile(filepath.Join(t.TempDir(), "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("err = %v for a missing file, want not exist", err)
+	}
+}
+
+func TestConfigureCredentialsFilePrecedence(t *testing.T) {
+	var clientID, clientSecret, organization string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/connect/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, _ = r.BasicAuth()
+		json.NewEncoder(w).Encode(client.TokenResponse{AccessToken: testJWT(nil), TokenType: "Bearer", ExpiresIn: 3600})
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		organization = r.Header.Get(client.DefaultOrganizationHeader)
+		json.NewEncoder(w).Encode(map[string]interface{}{"Items": []client.CustomerTenant{}})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	path := writeCredentialsFile(t, `{
+		"base_url": "`+server.URL+`",
+		"client_id": "file-client",
+		"client_secret": "file-secret",
+		"organization_id": 42
+	}`, 0o600)
+	t.Setenv("CRAYON_BASE_URL", "")
+	t.Setenv("CRAYON_CLIENT_ID", "")
+	t.Setenv("CRAYON_SECRET", "env-secret")
+	t.Setenv("CRAYON_ORGANIZATION_ID", "")
+
+	// base_url and organization_id come from the file, client_id from the configuration and
+	// client_secret from the environment
+	resp := configure(t, "", map[string]tftypes.Value{
+		"credentials_file": tftypes.NewValue(tftypes.String, path),
+		"base_url":         tftypes.NewValue(tftypes.String, nil),
+		"client_id":        tftypes.NewValue(tftypes.String, "config-client"),
+		"client_secret":    tftypes.NewValue(tftypes.String, nil),
+		"organization_id":  tftypes.NewValue(tftypes.Number, nil),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("configure: %v", resp.Diagnostics)
+	}
+	if _, err := resp.ResourceData.(*client.Client).GetCustomerTenants(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if clientID != "config-client" || clientSecret != "env-secret" {
+		t.Errorf("authenticated as %s:%s, want config-client:env-secret", clientID, clientSecret)
+	}
+	if organization != "42" {
+		t.Errorf("organization header %q, want the file's 42", organization)
+	}
+}
+
+func TestConfigureCredentialsFileInvalid(t *testing.T) {
+	baseURL := newTestServer(t, testJWT(nil), http.NotFound, new(int32))
+	path := writeCredentialsFile(t, `{"client_id": `, 0o600)
+
+	resp := configure(t, baseURL, map[string]tftypes.Value{"credentials_file": tftypes.NewValue(tftypes.String, path)})
+	if !hasSummary(resp.Diagnostics, "Invalid Credentials File") {
+		t.Errorf("configure = %v, want Invalid Credentials File", resp.Diagnostics)
+	}
+}
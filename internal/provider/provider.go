@@ -6,15 +6,26 @@ package provider
 import (
 	"context"
 	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/datasources"
 	"github.com/crayon-cloud/terraform-provider-crayon/internal/resources"
 )
 
@@ -27,19 +38,50 @@ type CrayonProvider struct {
 	// provider is built and ran locally, and "test" when running acceptance
 	// testing.
 	version string
+
+	// stopCtx is cancelled when the provider process is asked to shut down
+	stopCtx context.Context
 }
 
 // CrayonProviderModel describes the provider data model.
 type CrayonProviderModel struct {
-	BaseURL           types.String `tfsdk:"base_url"`
-	ClientID          types.String `tfsdk:"client_id"`
-	ClientSecret      types.String `tfsdk:"client_secret"`
-	Username          types.String `tfsdk:"username"`
-	Password          types.String `tfsdk:"password"`
-	OrganizationID    types.Int64  `tfsdk:"organization_id"`
-	AzureClientID     types.String `tfsdk:"azure_client_id"`
-	AzureClientSecret types.String `tfsdk:"azure_client_secret"`
-	AzureTenantID     types.String `tfsdk:"azure_tenant_id"`
+	BaseURL                      types.String `tfsdk:"base_url"`
+	ClientID                     types.String `tfsdk:"client_id"`
+	ClientSecret                 types.String `tfsdk:"client_secret"`
+	Username                     types.String `tfsdk:"username"`
+	Password                     types.String `tfsdk:"password"`
+	OrganizationID               types.Int64  `tfsdk:"organization_id"`
+	AzureClientID                types.String `tfsdk:"azure_client_id"`
+	AzureClientSecret            types.String `tfsdk:"azure_client_secret"`
+	AzureTenantID                types.String `tfsdk:"azure_tenant_id"`
+	AzureARMScope                types.String `tfsdk:"azure_arm_scope"`
+	AzureUseMSI                  types.Bool   `tfsdk:"azure_use_msi"`
+	AzureEnvironment             types.String `tfsdk:"azure_environment"`
+	UnknownStatusPolicy          types.String `tfsdk:"unknown_status_policy"`
+	OrganizationMismatchPolicy   types.String `tfsdk:"organization_mismatch_policy"`
+	ARMUnavailablePolicy         types.String `tfsdk:"arm_unavailable_policy"`
+	PageSize                     types.Int64  `tfsdk:"page_size"`
+	MaxResponseSize              types.Int64  `tfsdk:"max_response_size"`
+	HTTPTimeoutSeconds           types.Int64  `tfsdk:"http_timeout_seconds"`
+	ARMTargetState               types.String `tfsdk:"arm_target_state"`
+	SyncReadRetries              types.Int64  `tfsdk:"sync_read_retries"`
+	SyncReadInterval             types.Int64  `tfsdk:"sync_read_interval"`
+	CheckBaseURL                 types.Bool   `tfsdk:"check_base_url"`
+	CheckOrganizationClaim       types.Bool   `tfsdk:"check_organization_claim"`
+	CheckAPIVersion              types.Bool   `tfsdk:"check_api_version"`
+	DefaultTags                  types.Map    `tfsdk:"default_tags"`
+	AllowedProjects              types.List   `tfsdk:"allowed_projects"`
+	PortalURLTemplate            types.String `tfsdk:"portal_url_template"`
+	OrganizationHeader           types.String `tfsdk:"organization_header"`
+	SkipOrganizationCheck        types.Bool   `tfsdk:"skip_organization_check"`
+	SuppressPendingWarnings      types.Bool   `tfsdk:"suppress_pending_warnings"`
+	SyncRecoveryTimeout          types.Int64  `tfsdk:"sync_recovery_timeout"`
+	TokenScopes                  types.Map    `tfsdk:"token_scopes"`
+	CredentialsFile              types.String `tfsdk:"credentials_file"`
+	ProtectImportedSubscriptions types.Bool   `tfsdk:"protect_imported_subscriptions"`
+	CleanupFailedCreates         types.Bool   `tfsdk:"cleanup_failed_creates"`
+	MinTLSVersion                types.String `tfsdk:"min_tls_version"`
+	MockMode                     types.Bool   `tfsdk:"mock_mode"`
 }
 
 func (p *CrayonProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -51,6 +93,12 @@ func (p *CrayonProvider) Schema(ctx context.Context, req provider.SchemaRequest,
 	resp.Schema = schema.Schema{
 		Description: "Terraform provider for Crayon Cloud-iQ API to manage Azure subscriptions.",
 		Attributes: map[string]schema.Attribute{
+			"credentials_file": schema.StringAttribute{
+				Description: "Path to a JSON file with any of base_url, client_id, client_secret, username, password, " +
+					"organization_id, azure_client_id, azure_client_secret and azure_tenant_id. Provider arguments and " +
+					"environment variables take precedence over the file. Can also be set via CRAYON_CREDENTIALS_FILE.",
+				Optional: true,
+			},
 			"base_url": schema.StringAttribute{
 				Description: "Base URL for Crayon API. Can also be set via CRAYON_BASE_URL environment variable. Defaults to https://api.crayon.com",
 				Optional:    true,
@@ -78,7 +126,8 @@ func (p *CrayonProvider) Schema(ctx context.Context, req provider.SchemaRequest,
 				Optional:    true,
 			},
 			"azure_client_id": schema.StringAttribute{
-				Description: "Azure Service Principal Client ID for direct subscription querying. Can also be set via ARM_CLIENT_ID.",
+				Description: "Azure Service Principal Client ID for direct subscription querying, or with azure_use_msi the " +
+					"client ID of a user-assigned managed identity. Can also be set via ARM_CLIENT_ID.",
 				Optional:    true,
 			},
 			"azure_client_secret": schema.StringAttribute{
@@ -90,6 +139,151 @@ func (p *CrayonProvider) Schema(ctx context.Context, req provider.SchemaRequest,
 				Description: "Azure Tenant ID for direct subscription querying. Can also be set via ARM_TENANT_ID.",
 				Optional:    true,
 			},
+			"azure_use_msi": schema.BoolAttribute{
+				Description: "Obtain Azure ARM tokens from the managed identity of the host, e.g. an Azure VM, App Service " +
+					"or Azure DevOps agent, when no complete Service Principal is configured. Uses the system-assigned " +
+					"identity unless azure_client_id selects a user-assigned one. Can also be set via ARM_USE_MSI.",
+				Optional: true,
+			},
+			"azure_environment": schema.StringAttribute{
+				Description: "Azure cloud to poll and request ARM tokens from: public, usgovernment, china or germany. " +
+					"Selects the ARM and Azure AD endpoints. Can also be set via ARM_ENVIRONMENT. Defaults to public.",
+				Optional: true,
+			},
+			"azure_arm_scope": schema.StringAttribute{
+				Description: "OAuth scope requested for Azure ARM tokens. Can also be set via ARM_SCOPE. Defaults to the ARM " +
+					"URL of azure_environment, e.g. https://management.azure.com/.default.",
+				Optional: true,
+			},
+			"arm_target_state": schema.StringAttribute{
+				Description: "ARM state a new subscription must reach before Azure polling counts its creation as " +
+					"confirmed, since subscriptions can appear Warned or PastDue before they are usable: one of " +
+					strings.Join(client.ARMSubscriptionStates, ", ") + ", or any to accept the first state seen. " +
+					"Defaults to " + client.DefaultARMTargetState + ".",
+				Optional: true,
+			},
+			"unknown_status_policy": schema.StringAttribute{
+				Description: "How to handle subscription statuses the provider does not recognize, including a missing status, in single reads and listings alike: preserve (store verbatim), error, or map-to-unknown. Defaults to preserve.",
+				Optional:    true,
+			},
+			"organization_mismatch_policy": schema.StringAttribute{
+				Description: "What to do when organization_id is set but the Crayon access token was issued for another " +
+					"organization, e.g. because credentials were copied from another configuration: warn or error. " +
+					"Only applies with check_organization_claim. Defaults to warn.",
+				Optional: true,
+			},
+			"arm_unavailable_policy": schema.StringAttribute{
+				Description: "What to do when a subscription is created but can't be confirmed via Azure ARM because no " +
+					"Azure Service Principal, managed identity or Azure CLI session is available: warn (create it and leave it pending " +
+					"until Cloud-iQ syncs) or error (fail before creating). Defaults to warn.",
+				Optional: true,
+			},
+			"page_size": schema.Int64Attribute{
+				Description: "Number of items requested per page from Crayon list endpoints (1-1000). Defaults to 1000.",
+				Optional:    true,
+			},
+			"max_response_size": schema.Int64Attribute{
+				Description: "Largest response body read from the Crayon API, token endpoints and Azure ARM, in MiB, so a " +
+					"malfunctioning API or proxy can't exhaust memory. List endpoints allow 8 times as much. Defaults to 4.",
+				Optional: true,
+			},
+			"http_timeout_seconds": schema.Int64Attribute{
+				Description: "Timeout in seconds for each request to the Crayon API, including reading the response. Raise it for " +
+					"organizations with many subscriptions, whose list pages can be slow. Can also be set via CRAYON_HTTP_TIMEOUT " +
+					"environment variable. Defaults to 30.",
+				Optional: true,
+			},
+			"sync_read_retries": schema.Int64Attribute{
+				Description: "Number of times a refresh looks for a pending subscription in Cloud-iQ before leaving it pending. Defaults to 1.",
+				Optional:    true,
+			},
+			"sync_read_interval": schema.Int64Attribute{
+				Description: "Seconds to wait between sync_read_retries attempts. Defaults to 30.",
+				Optional:    true,
+			},
+			"sync_recovery_timeout": schema.Int64Attribute{
+				Description: "Minutes a subscription confirmed by Azure may stay missing from Cloud-iQ before refreshes " +
+					"report the subscription GUID for manual resolution. " +
+					"Defaults to 60; set to 0 to disable.",
+				Optional: true,
+			},
+			"min_tls_version": schema.StringAttribute{
+				Description: "Lowest TLS version negotiated on all outbound connections (Crayon API, tokens, Azure ARM " +
+					"and webhooks): 1.2 or 1.3. Defaults to 1.2; older versions are insecure and rejected.",
+				Optional: true,
+			},
+			"check_base_url": schema.BoolAttribute{
+				Description: "Check during configuration that base_url is reachable, to report a wrong host before the " +
+					"first API call. Off by default, so offline planning works. The URL format is always validated.",
+				Optional: true,
+			},
+			"check_organization_claim": schema.BoolAttribute{
+				Description: "Check during configuration that the Crayon access token was issued for organization_id, " +
+					"handling a mismatch according to organization_mismatch_policy. Off by default, as it fetches a token " +
+					"and so needs network access for every plan.",
+				Optional: true,
+			},
+			"mock_mode": schema.BoolAttribute{
+				Description: "Serve all Crayon API requests from an in-memory fake with deterministic fixtures instead of " +
+					"the network, e.g. to test modules in CI without credentials. Data only lasts for a single Terraform " +
+					"command and Azure ARM lookups are unavailable. Can also be set with the CRAYON_MOCK environment variable.",
+				Optional: true,
+			},
+			"check_api_version": schema.BoolAttribute{
+				Description: "Compare the Crayon API's reported version with the one the provider supports during " +
+					"configuration, warning on a mismatch. Off by default, so offline planning works.",
+				Optional: true,
+			},
+			"default_tags": schema.MapAttribute{
+				Description: "Tags applied to every crayon_azure_subscription, e.g. cost center or owner. " +
+					"Tags set on the resource take precedence on key conflicts.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"allowed_projects": schema.ListAttribute{
+				Description: "Projects subscriptions may be grouped under with their project attribute. Any project is " +
+					"allowed when unset.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"token_scopes": schema.MapAttribute{
+				Description: "OAuth scopes to request Crayon API tokens with per operation category (read, write, billing), " +
+					"for credentials restricted to granular API permissions. Tokens are cached per scope. " +
+					"Categories not listed use " + client.DefaultTokenScope + ".",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"organization_header": schema.StringAttribute{
+				Description: "Header that carries organization_id on every Crayon API request, for endpoints that expect " +
+					"the organization there. Defaults to " + client.DefaultOrganizationHeader + "; set to an empty string to disable.",
+				Optional: true,
+			},
+			"skip_organization_check": schema.BoolAttribute{
+				Description: "Skip verifying at plan time that a subscription's azure_plan_id belongs to organization_id. " +
+					"The check costs two API calls per Azure Plan.",
+				Optional: true,
+			},
+			"suppress_pending_warnings": schema.BoolAttribute{
+				Description: "Log warnings about subscriptions still pending sync to Cloud-iQ at debug level instead of " +
+					"reporting them on every create and refresh. The pending state handling is unchanged. Defaults to false.",
+				Optional: true,
+			},
+			"protect_imported_subscriptions": schema.BoolAttribute{
+				Description: "Refuse to destroy (cancel) crayon_azure_subscription resources that were imported rather than " +
+					"created by Terraform, unless the resource sets allow_cancel_imported. Defaults to false.",
+				Optional: true,
+			},
+			"cleanup_failed_creates": schema.BoolAttribute{
+				Description: "When a create request fails without a definite answer (e.g. a timeout or a server error), " +
+					"cancel the subscription if it was created anyway, instead of only warning about it. Defaults to false.",
+				Optional: true,
+			},
+			"portal_url_template": schema.StringAttribute{
+				Description: "Template for the portal_url of subscriptions, for non-standard Cloud-iQ deployments. " +
+					"Supports the {portal}, {organization_id}, {azure_plan_id} and {id} placeholders. " +
+					"Defaults to " + client.DefaultPortalURLTemplate + ".",
+				Optional: true,
+			},
 		},
 	}
 }
@@ -103,11 +297,68 @@ func (p *CrayonProvider) Configure(ctx context.Context, req provider.ConfigureRe
 		return
 	}
 
+	// Values from the credentials file are the fallback after arguments and environment variables
+	creds := &credentialsFile{}
+	if credentialsPath := getConfigValue(config.CredentialsFile.ValueString(), "CRAYON_CREDENTIALS_FILE", ""); credentialsPath != "" {
+		loaded, warning, err := loadCredentialsFile(credentialsPath)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("credentials_file"),
+				"Invalid Credentials File",
+				"Could not load the credentials file: "+err.Error(),
+			)
+			return
+		}
+		if warning != "" {
+			resp.Diagnostics.AddAttributeWarning(path.Root("credentials_file"), "Insecure Credentials File", warning)
+		}
+		creds = loaded
+	}
+
+	mockMode := config.MockMode.ValueBool()
+	if config.MockMode.IsNull() {
+		if envMock := os.Getenv("CRAYON_MOCK"); envMock != "" {
+			parsed, err := strconv.ParseBool(envMock)
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("mock_mode"),
+					"Invalid Mock Mode",
+					"The CRAYON_MOCK environment variable must be a boolean such as true or false. Got: "+envMock,
+				)
+			}
+			mockMode = parsed
+		}
+	}
+
+	// Recording and replaying API interactions is a debugging aid, so it is only available through the environment
+	cassetteMode, cassettePath := "", ""
+	recordPath, replayPath := os.Getenv("CRAYON_RECORD_CASSETTE"), os.Getenv("CRAYON_REPLAY_CASSETTE")
+	switch {
+	case recordPath != "" && replayPath != "":
+		resp.Diagnostics.AddError(
+			"Conflicting Cassette Settings",
+			"CRAYON_RECORD_CASSETTE and CRAYON_REPLAY_CASSETTE can't both be set.",
+		)
+	case recordPath != "":
+		cassetteMode, cassettePath = client.CassetteModeRecord, recordPath
+		resp.Diagnostics.AddWarning(
+			"Recording API Interactions",
+			"Crayon and Azure API interactions are recorded to "+recordPath+" (CRAYON_RECORD_CASSETTE). "+
+				"Secrets are redacted, but review the file before sharing it, since it contains subscription details.",
+		)
+	case replayPath != "":
+		cassetteMode, cassettePath = client.CassetteModeReplay, replayPath
+		resp.Diagnostics.AddWarning(
+			"Replaying API Interactions",
+			"Crayon and Azure API requests are answered from "+replayPath+" (CRAYON_REPLAY_CASSETTE) instead of the network.",
+		)
+	}
+
 	// Get configuration values with environment variable fallbacks
-	baseURL := getConfigValue(config.BaseURL.ValueString(), "CRAYON_BASE_URL", "https://api.crayon.com")
-	clientID := getConfigValue(config.ClientID.ValueString(), "CRAYON_CLIENT_ID", "")
-	clientSecret := getConfigValue(config.ClientSecret.ValueString(), "CRAYON_SECRET", "")
-	username := getConfigValue(config.Username.ValueString(), "CRAYON_USERNAME", "")
+	baseURL := getConfigValue(config.BaseURL.ValueString(), "CRAYON_BASE_URL", firstNonEmpty(creds.BaseURL, "https://api.crayon.com"))
+	clientID := getConfigValue(config.ClientID.ValueString(), "CRAYON_CLIENT_ID", creds.ClientID)
+	clientSecret := getConfigValue(config.ClientSecret.ValueString(), "CRAYON_SECRET", creds.ClientSecret)
+	username := getConfigValue(config.Username.ValueString(), "CRAYON_USERNAME", creds.Username)
 	
 	// Password: check config first, then CRAYON_PASSWORD, then CRAYON_PASSWORD_BASE64_ENCODED (for C# CLI compatibility)
 	password := config.Password.ValueString()
@@ -120,11 +371,14 @@ func (p *CrayonProvider) Configure(ctx context.Context, req provider.ConfigureRe
 			if err == nil {
 				password = string(decoded)
 			}
+		} else {
+			password = creds.Password
 		}
 	}
 
 	// Handle organization ID
 	var organizationID int64 = 4051878 // Default value
+	organizationIDSet := true
 	if !config.OrganizationID.IsNull() {
 		organizationID = config.OrganizationID.ValueInt64()
 	} else if envOrgID := os.Getenv("CRAYON_ORGANIZATION_ID"); envOrgID != "" {
@@ -133,16 +387,46 @@ func (p *CrayonProvider) Configure(ctx context.Context, req provider.ConfigureRe
 		if _, err := parseIntFromEnv(envOrgID, &parsedID); err == nil {
 			organizationID = parsedID
 		}
+	} else if creds.OrganizationID != 0 {
+		organizationID = creds.OrganizationID
+	} else {
+		organizationIDSet = false
 	}
 
 	// Azure Credentials for direct querying (Optional but recommended for faster updates)
-	azureClientID := getConfigValue(config.AzureClientID.ValueString(), "ARM_CLIENT_ID", "")
-	azureClientSecret := getConfigValue(config.AzureClientSecret.ValueString(), "ARM_CLIENT_SECRET", "")
-	azureTenantID := getConfigValue(config.AzureTenantID.ValueString(), "ARM_TENANT_ID", "")
+	azureClientID := getConfigValue(config.AzureClientID.ValueString(), "ARM_CLIENT_ID", creds.AzureClientID)
+	azureClientSecret := getConfigValue(config.AzureClientSecret.ValueString(), "ARM_CLIENT_SECRET", creds.AzureClientSecret)
+	azureTenantID := getConfigValue(config.AzureTenantID.ValueString(), "ARM_TENANT_ID", creds.AzureTenantID)
+	azureARMScope := getConfigValue(config.AzureARMScope.ValueString(), "ARM_SCOPE", "")
+	azureEnvironment := getConfigValue(config.AzureEnvironment.ValueString(), "ARM_ENVIRONMENT", client.AzureEnvironmentPublic)
+	if _, err := client.AzureEnvironmentEndpoints(azureEnvironment); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("azure_environment"),
+			"Invalid Azure Environment",
+			"azure_environment must be one of: "+strings.Join(client.AzureEnvironments, ", ")+". Got: "+azureEnvironment,
+		)
+	}
 
-	// Validate Azure credentials if partially set
-	if (azureClientID != "" || azureClientSecret != "" || azureTenantID != "") &&
-		(azureClientID == "" || azureClientSecret == "" || azureTenantID == "") {
+	azureUseMSI := config.AzureUseMSI.ValueBool()
+	if config.AzureUseMSI.IsNull() {
+		if envMSI := os.Getenv("ARM_USE_MSI"); envMSI != "" {
+			parsed, err := strconv.ParseBool(envMSI)
+			if err != nil {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("azure_use_msi"),
+					"Invalid Azure Use MSI",
+					"The ARM_USE_MSI environment variable must be a boolean such as true or false. Got: "+envMSI,
+				)
+			}
+			azureUseMSI = parsed
+		}
+	}
+
+	// Validate Azure credentials if partially set. With a managed identity, azure_client_id alone
+	// selects a user-assigned identity.
+	partialServicePrincipal := (azureClientID != "" || azureClientSecret != "" || azureTenantID != "") &&
+		(azureClientID == "" || azureClientSecret == "" || azureTenantID == "")
+	if partialServicePrincipal && !(azureUseMSI && azureClientSecret == "") {
 		resp.Diagnostics.AddWarning(
 			"Incomplete Azure Configuration",
 			"To enable direct Azure subscription polling, all three Azure credentials must be provided: "+
@@ -151,17 +435,233 @@ func (p *CrayonProvider) Configure(ctx context.Context, req provider.ConfigureRe
 		)
 	}
 
+	unknownStatusPolicy := config.UnknownStatusPolicy.ValueString()
+	switch unknownStatusPolicy {
+	case "", client.UnknownStatusPreserve, client.UnknownStatusError, client.UnknownStatusMapToUnknown:
+	default:
+		resp.Diagnostics.AddAttributeError(
+			path.Root("unknown_status_policy"),
+			"Invalid Unknown Status Policy",
+			"unknown_status_policy must be one of: preserve, error, map-to-unknown. Got: "+unknownStatusPolicy,
+		)
+	}
+
+	armTargetState := config.ARMTargetState.ValueString()
+	if armTargetState != "" {
+		valid := false
+		for _, state := range append([]string{client.ARMTargetStateAny}, client.ARMSubscriptionStates...) {
+			if strings.EqualFold(armTargetState, state) {
+				armTargetState, valid = state, true
+			}
+		}
+		if !valid {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("arm_target_state"),
+				"Invalid ARM Target State",
+				fmt.Sprintf("arm_target_state must be one of: %s, any. Got: %s",
+					strings.Join(client.ARMSubscriptionStates, ", "), armTargetState),
+			)
+		}
+	}
+
+	organizationMismatchPolicy := config.OrganizationMismatchPolicy.ValueString()
+	switch organizationMismatchPolicy {
+	case "":
+		organizationMismatchPolicy = client.OrganizationMismatchWarn
+	case client.OrganizationMismatchWarn, client.OrganizationMismatchError:
+	default:
+		resp.Diagnostics.AddAttributeError(
+			path.Root("organization_mismatch_policy"),
+			"Invalid Organization Mismatch Policy",
+			"organization_mismatch_policy must be one of: warn, error. Got: "+organizationMismatchPolicy,
+		)
+	}
+
+	armUnavailablePolicy := config.ARMUnavailablePolicy.ValueString()
+	switch armUnavailablePolicy {
+	case "":
+		armUnavailablePolicy = client.ARMUnavailableWarn
+	case client.ARMUnavailableWarn, client.ARMUnavailableError:
+	default:
+		resp.Diagnostics.AddAttributeError(
+			path.Root("arm_unavailable_policy"),
+			"Invalid ARM Unavailable Policy",
+			"arm_unavailable_policy must be one of: warn, error. Got: "+armUnavailablePolicy,
+		)
+	}
+
+	pageSize := client.DefaultPageSize
+	if !config.PageSize.IsNull() {
+		pageSize = int(config.PageSize.ValueInt64())
+		if pageSize < 1 || pageSize > client.MaxPageSize {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("page_size"),
+				"Invalid Page Size",
+				fmt.Sprintf("page_size must be between 1 and %d. Got: %d", client.MaxPageSize, pageSize),
+			)
+		}
+	}
+
+	var maxResponseSize int64
+	if !config.MaxResponseSize.IsNull() {
+		maxResponseSize = config.MaxResponseSize.ValueInt64() << 20
+		if config.MaxResponseSize.ValueInt64() < 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("max_response_size"),
+				"Invalid Max Response Size",
+				fmt.Sprintf("max_response_size must be at least 1 (MiB). Got: %d", config.MaxResponseSize.ValueInt64()),
+			)
+		}
+	}
+
+	var httpTimeout time.Duration
+	if !config.HTTPTimeoutSeconds.IsNull() {
+		seconds := config.HTTPTimeoutSeconds.ValueInt64()
+		if seconds < 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("http_timeout_seconds"),
+				"Invalid HTTP Timeout",
+				fmt.Sprintf("http_timeout_seconds must be a positive number of seconds. Got: %d", seconds),
+			)
+		}
+		httpTimeout = time.Duration(seconds) * time.Second
+	} else if envTimeout := os.Getenv("CRAYON_HTTP_TIMEOUT"); envTimeout != "" {
+		var seconds int64
+		if _, err := parseIntFromEnv(envTimeout, &seconds); err != nil || seconds < 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("http_timeout_seconds"),
+				"Invalid HTTP Timeout",
+				"The CRAYON_HTTP_TIMEOUT environment variable must be a positive number of seconds. Got: "+envTimeout,
+			)
+		}
+		httpTimeout = time.Duration(seconds) * time.Second
+	}
+
+	syncReadRetries := 1
+	if !config.SyncReadRetries.IsNull() {
+		syncReadRetries = int(config.SyncReadRetries.ValueInt64())
+		if syncReadRetries < 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("sync_read_retries"),
+				"Invalid Sync Read Retries",
+				fmt.Sprintf("sync_read_retries must be at least 1. Got: %d", syncReadRetries),
+			)
+		}
+	}
+
+	syncReadInterval := 30 * time.Second
+	if !config.SyncReadInterval.IsNull() {
+		seconds := config.SyncReadInterval.ValueInt64()
+		if seconds < 1 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("sync_read_interval"),
+				"Invalid Sync Read Interval",
+				fmt.Sprintf("sync_read_interval must be at least 1 second. Got: %d", seconds),
+			)
+		}
+		syncReadInterval = time.Duration(seconds) * time.Second
+	}
+
+	syncRecoveryTimeout := 60 * time.Minute
+	if !config.SyncRecoveryTimeout.IsNull() {
+		minutes := config.SyncRecoveryTimeout.ValueInt64()
+		if minutes < 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("sync_recovery_timeout"),
+				"Invalid Sync Recovery Timeout",
+				fmt.Sprintf("sync_recovery_timeout must be 0 (disabled) or a positive number of minutes. Got: %d", minutes),
+			)
+		}
+		syncRecoveryTimeout = time.Duration(minutes) * time.Minute
+	}
+
+	minTLSVersion := client.DefaultMinTLSVersion
+	if !config.MinTLSVersion.IsNull() {
+		minTLSVersion = config.MinTLSVersion.ValueString()
+		if _, err := client.ParseTLSVersion(minTLSVersion); errors.Is(err, client.ErrInsecureTLSVersion) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("min_tls_version"),
+				"Insecure TLS Version",
+				fmt.Sprintf("TLS %s has known weaknesses and is deprecated (RFC 8996), so it can't be allowed. "+
+					"Set min_tls_version to 1.2 or 1.3.", minTLSVersion),
+			)
+		} else if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("min_tls_version"),
+				"Invalid TLS Version",
+				"min_tls_version must be 1.2 or 1.3. Got: "+minTLSVersion,
+			)
+		}
+	}
+
+	organizationHeader := client.DefaultOrganizationHeader
+	if !config.OrganizationHeader.IsNull() {
+		organizationHeader = config.OrganizationHeader.ValueString()
+	}
+
+	var defaultTags map[string]string
+	if !config.DefaultTags.IsNull() {
+		resp.Diagnostics.Append(config.DefaultTags.ElementsAs(ctx, &defaultTags, false)...)
+	}
+
+	var allowedProjects []string
+	if !config.AllowedProjects.IsNull() {
+		resp.Diagnostics.Append(config.AllowedProjects.ElementsAs(ctx, &allowedProjects, false)...)
+	}
+
+	var tokenScopes map[string]string
+	if !config.TokenScopes.IsNull() {
+		resp.Diagnostics.Append(config.TokenScopes.ElementsAs(ctx, &tokenScopes, false)...)
+		for category := range tokenScopes {
+			if !slices.Contains(client.OperationCategories, category) {
+				resp.Diagnostics.AddAttributeError(
+					path.Root("token_scopes"),
+					"Invalid Token Scopes",
+					fmt.Sprintf("Unknown operation category %q. Must be one of: %s", category, strings.Join(client.OperationCategories, ", ")),
+				)
+			}
+		}
+	}
+
+	// Catch typo'd base URLs here rather than deep inside the first API call
+	if err := validateBaseURL(baseURL); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("base_url"),
+			"Invalid Base URL",
+			err.Error(),
+		)
+	} else if config.CheckBaseURL.ValueBool() && !mockMode && cassetteMode != client.CassetteModeReplay {
+		if err := checkBaseURLReachable(baseURL); err != nil {
+			resp.Diagnostics.AddAttributeWarning(
+				path.Root("base_url"),
+				"Base URL Unreachable",
+				err.Error()+". Unset check_base_url to skip this check when planning offline.",
+			)
+		}
+	}
+
+	// The fake accepts any credentials
+	if mockMode {
+		clientID = firstNonEmpty(clientID, "mock")
+		clientSecret = firstNonEmpty(clientSecret, "mock")
+		resp.Diagnostics.AddWarning(
+			"Mock Mode Enabled",
+			"The provider serves all Crayon API requests from an in-memory fake. No real subscriptions are read or changed.",
+		)
+		tflog.Warn(ctx, "Mock mode is enabled; Crayon API requests are served from an in-memory fake")
+	}
+
 	// Validate required configuration
 	if clientID == "" {
 		resp.Diagnostics.AddError(
 			"Missing Client ID",
-			"The provider requires a client_id to be set either in the provider configuration or via CRAYON_CLIENT_ID environment variable.",
+			"The provider requires a client_id to be set either in the provider configuration, via CRAYON_CLIENT_ID environment variable or in the credentials_file.",
 		)
 	}
 	if clientSecret == "" {
 		resp.Diagnostics.AddError(
 			"Missing Client Secret",
-			"The provider requires a client_secret to be set either in the provider configuration or via CRAYON_SECRET environment variable.",
+			"The provider requires a client_secret to be set either in the provider configuration, via CRAYON_SECRET environment variable or in the credentials_file.",
 		)
 	}
 
@@ -174,19 +674,47 @@ func (p *CrayonProvider) Configure(ctx context.Context, req provider.ConfigureRe
 		"organization_id": organizationID,
 		"has_username":    username != "",
 		"has_azure_creds": azureClientID != "",
+		"azure_use_msi":   azureUseMSI,
+		"azure_env":       azureEnvironment,
 	})
 
 	// Create client with dual-auth support
 	crayonClient, err := client.NewClient(client.ClientConfig{
-		BaseURL:           baseURL,
-		ClientID:          clientID,
-		ClientSecret:      clientSecret,
-		Username:          username,
-		Password:          password,
-		OrganizationID:    organizationID,
-		AzureClientID:     azureClientID,
-		AzureClientSecret: azureClientSecret,
-		AzureTenantID:     azureTenantID,
+		BaseURL:                      baseURL,
+		ClientID:                     clientID,
+		ClientSecret:                 clientSecret,
+		Username:                     username,
+		Password:                     password,
+		OrganizationID:               organizationID,
+		AzureClientID:                azureClientID,
+		AzureClientSecret:            azureClientSecret,
+		AzureTenantID:                azureTenantID,
+		AzureARMScope:                azureARMScope,
+		AzureUseMSI:                  azureUseMSI,
+		AzureEnvironment:             azureEnvironment,
+		UnknownStatusPolicy:          unknownStatusPolicy,
+		ARMUnavailablePolicy:         armUnavailablePolicy,
+		PageSize:                     pageSize,
+		MaxResponseSize:              maxResponseSize,
+		HTTPTimeout:                  httpTimeout,
+		ARMTargetState:               armTargetState,
+		SyncReadRetries:              syncReadRetries,
+		SyncReadInterval:             syncReadInterval,
+		SyncRecoveryTimeout:          syncRecoveryTimeout,
+		DefaultTags:                  defaultTags,
+		TokenScopes:                  tokenScopes,
+		AllowedProjects:              allowedProjects,
+		StopContext:                  p.stopCtx,
+		PortalURLTemplate:            config.PortalURLTemplate.ValueString(),
+		OrganizationHeader:           organizationHeader,
+		SkipOrganizationCheck:        config.SkipOrganizationCheck.ValueBool(),
+		SuppressPendingWarnings:      config.SuppressPendingWarnings.ValueBool(),
+		ProtectImportedSubscriptions: config.ProtectImportedSubscriptions.ValueBool(),
+		CleanupFailedCreates:         config.CleanupFailedCreates.ValueBool(),
+		MinTLSVersion:                minTLSVersion,
+		MockMode:                     mockMode,
+		CassettePath:                 cassettePath,
+		CassetteMode:                 cassetteMode,
 	})
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -198,6 +726,19 @@ func (p *CrayonProvider) Configure(ctx context.Context, req provider.ConfigureRe
 		return
 	}
 
+	// Catch credentials of another organization before resources act on the wrong one
+	if organizationIDSet && config.CheckOrganizationClaim.ValueBool() {
+		resp.Diagnostics.Append(checkOrganizationClaim(ctx, crayonClient, organizationID, organizationMismatchPolicy)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Warn about breaking API changes up front rather than through cryptic parse failures later
+	if config.CheckAPIVersion.ValueBool() {
+		resp.Diagnostics.Append(checkAPIVersion(ctx, crayonClient)...)
+	}
+
 	// Make the client available to resources and data sources
 	resp.DataSourceData = crayonClient
 	resp.ResourceData = crayonClient
@@ -207,6 +748,53 @@ func (p *CrayonProvider) Configure(ctx context.Context, req provider.ConfigureRe
 	})
 }
 
+// checkOrganizationClaim compares organizationID with the organization the Crayon access token was
+// issued for. Tokens without an organization claim, and failures to get one, aren't reported.
+func checkOrganizationClaim(ctx context.Context, c *client.Client, organizationID int64, policy string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	claimed, ok, err := c.TokenOrganizationID(ctx)
+	if err != nil {
+		tflog.Debug(ctx, "Skipped the organization claim check", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return diags
+	}
+	if !ok || claimed == organizationID {
+		return diags
+	}
+
+	summary := "Organization Mismatch"
+	detail := fmt.Sprintf("organization_id is %d, but the Crayon credentials were issued for organization %d. "+
+		"Check that the credentials belong to the intended organization.", organizationID, claimed)
+	if policy == client.OrganizationMismatchError {
+		diags.AddAttributeError(path.Root("organization_id"), summary, detail)
+		return diags
+	}
+	diags.AddAttributeWarning(path.Root("organization_id"), summary,
+		detail+" Set organization_mismatch_policy = \"error\" to fail instead.")
+	return diags
+}
+
+// checkAPIVersion warns if the Crayon API reports a version the provider doesn't support. An API
+// that doesn't report its version, or can't be reached, isn't reported.
+func checkAPIVersion(ctx context.Context, c *client.Client) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	mismatch, err := c.CheckAPICompatibility(ctx)
+	if err != nil {
+		tflog.Debug(ctx, "Skipped the API compatibility check", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else if mismatch != "" {
+		diags.AddWarning(
+			"Crayon API Version Mismatch",
+			mismatch+" Unset check_api_version to disable this check.",
+		)
+	}
+	return diags
+}
+
 func (p *CrayonProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		resources.NewAzureSubscriptionResource,
@@ -215,14 +803,31 @@ func (p *CrayonProvider) Resources(ctx context.Context) []func() resource.Resour
 
 func (p *CrayonProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
-		// Data sources can be added here in the future
+		datasources.NewProviderConfigDataSource,
+		datasources.NewAzurePlanCostDataSource,
+		datasources.NewAzurePlanStatusCountsDataSource,
+		datasources.NewARMSubscriptionUsageDataSource,
+		datasources.NewARMSubscriptionRoleAssignmentsDataSource,
+		datasources.NewARMSubscriptionCostExportsDataSource,
+		datasources.NewAzureSubscriptionBudgetDataSource,
+		datasources.NewAzureSubscriptionDataSource,
+		datasources.NewAzureSubscriptionsDataSource,
+		datasources.NewAzureSubscriptionRawDataSource,
+		datasources.NewCapabilitiesDataSource,
 	}
 }
 
 func New(version string) func() provider.Provider {
+	return NewWithStopContext(context.Background(), version)
+}
+
+// NewWithStopContext is New with a context that is cancelled when the provider should stop.
+// Cancelling it aborts in-flight API requests and polling loops, leaving subscriptions pending.
+func NewWithStopContext(stopCtx context.Context, version string) func() provider.Provider {
 	return func() provider.Provider {
 		return &CrayonProvider{
 			version: version,
+			stopCtx: stopCtx,
 		}
 	}
 }
@@ -239,6 +844,50 @@ func getConfigValue(configValue, envVar, defaultValue string) string {
 	return defaultValue
 }
 
+// validateBaseURL checks that the base URL is an absolute https URL (http is allowed for localhost only)
+func validateBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("base_url %q could not be parsed: %w", baseURL, err)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("base_url %q must be an absolute URL such as https://api.crayon.com", baseURL)
+	}
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		if host := u.Hostname(); host == "localhost" || host == "127.0.0.1" || host == "::1" {
+			return nil
+		}
+		return fmt.Errorf("base_url %q must use https; http is only allowed for localhost", baseURL)
+	}
+	return fmt.Errorf("base_url %q must use https", baseURL)
+}
+
+// checkBaseURLReachable opens a TCP connection to the base URL's host
+func checkBaseURLReachable(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return err
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(u.Hostname(), port), 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("could not connect to %s: %v", u.Host, err)
+	}
+	conn.Close()
+	return nil
+}
+
 func parseIntFromEnv(value string, result *int64) (bool, error) {
 	if value == "" {
 		return false, nil
@@ -0,0 +1,407 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+const testOrganizationID = 4051878
+
+// testJWT returns an unsigned JWT carrying claims
+func testJWT(claims map[string]interface{}) string {
+	payload, _ := json.Marshal(claims)
+	return "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+// newTestServer returns the URL of a fake Crayon API that issues token and answers every other
+// request with handler, counting all requests
+func newTestServer(t *testing.T, token string, handler http.HandlerFunc, requests *int32) string {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/connect/token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(client.TokenResponse{AccessToken: token, TokenType: "Bearer", ExpiresIn: 3600})
+	})
+	mux.Handle("/", handler)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// newTestClient returns a client for the fake Crayon API at baseURL
+func newTestClient(t *testing.T, baseURL string) *client.Client {
+	t.Helper()
+	c, err := client.NewClient(client.ClientConfig{
+		BaseURL:        baseURL,
+		ClientID:       "client",
+		ClientSecret:   "secret",
+		OrganizationID: testOrganizationID,
+		MaxRetries:     -1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// configure configures the provider against baseURL with the given attributes set
+func configure(t *testing.T, baseURL string, attributes map[string]tftypes.Value) *provider.ConfigureResponse {
+	t.Helper()
+	ctx := context.Background()
+
+	p := New("test")()
+	var schemaResp provider.SchemaResponse
+	p.Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+
+	objectType := schemaResp.Schema.Type().TerraformType(ctx).(tftypes.Object)
+	values := map[string]tftypes.Value{}
+	for name, attributeType := range objectType.AttributeTypes {
+		values[name] = tftypes.NewValue(attributeType, nil)
+	}
+	values["base_url"] = tftypes.NewValue(tftypes.String, baseURL)
+	values["client_id"] = tftypes.NewValue(tftypes.String, "client")
+	values["client_secret"] = tftypes.NewValue(tftypes.String, "secret")
+	values["organization_id"] = tftypes.NewValue(tftypes.Number, testOrganizationID)
+	for name, value := range attributes {
+		values[name] = value
+	}
+
+	config := tfsdk.Config{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, values)}
+	resp := &provider.ConfigureResponse{}
+	p.Configure(ctx, provider.ConfigureRequest{Config: config}, resp)
+	return resp
+}
+
+// hasSummary reports whether diags has a diagnostic with the given summary
+func hasSummary(diags diag.Diagnostics, summary string) bool {
+	for _, d := range diags {
+		if d.Summary() == summary {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidateBaseURL(t *testing.T) {
+	tests := map[string]struct {
+		baseURL string
+		wantErr bool
+	}{
+		"https":              {baseURL: "https://api.crayon.com"},
+		"http on localhost":  {baseURL: "http://localhost:8080"},
+		"http on 127.0.0.1":  {baseURL: "http://127.0.0.1:8080"},
+		"http elsewhere":     {baseURL: "http://api.crayon.com", wantErr: true},
+		"other scheme":       {baseURL: "ftp://api.crayon.com", wantErr: true},
+		"missing scheme":     {baseURL: "api.crayon.com", wantErr: true},
+		"malformed":          {baseURL: "https://api.crayon.com:port", wantErr: true},
+		"control characters": {baseURL: "https://api.crayon.com/\x7f", wantErr: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateBaseURL(test.baseURL)
+			if test.wantErr && err == nil {
+				t.Errorf("%q was accepted", test.baseURL)
+			}
+			if !test.wantErr && err != nil {
+				t.Errorf("%q was rejected: %v", test.baseURL, err)
+			}
+		})
+	}
+}
+
+func TestCheckBaseURLReachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+
+	if err := checkBaseURLReachable("http://" + address); err != nil {
+		t.Errorf("listening address reported unreachable: %v", err)
+	}
+
+	listener.Close()
+	if err := checkBaseURLReachable("http://" + address); err == nil {
+		t.Error("closed address reported reachable")
+	}
+}
+
+func TestConfigureMakesNoRequestsByDefault(t *testing.T) {
+	var requests int32
+	baseURL := newTestServer(t, testJWT(map[string]interface{}{"organization_id": 1}), http.NotFound, &requests)
+
+	resp := configure(t, baseURL, nil)
+	if len(resp.Diagnostics) > 0 {
+		t.Fatalf("configure: %v", resp.Diagnostics)
+	}
+	if requests != 0 {
+		t.Errorf("configuring made %d requests, want none so offline planning works", requests)
+	}
+}
+
+func TestConfigurePageSize(t *testing.T) {
+	baseURL := newTestServer(t, testJWT(nil), http.NotFound, new(int32))
+
+	for _, pageSize := range []int{0, client.MaxPageSize + 1} {
+		resp := configure(t, baseURL, map[string]tftypes.Value{"page_size": tftypes.NewValue(tftypes.Number, pageSize)})
+		if !hasSummary(resp.Diagnostics, "Invalid Page Size") {
+			t.Errorf("page_size = %d: %v, want Invalid Page Size", pageSize, resp.Diagnostics)
+		}
+	}
+
+	resp := configure(t, baseURL, map[string]tftypes.Value{"page_size": tftypes.NewValue(tftypes.Number, 250)})
+	if resp.Diagnostics.HasError() {
+		t.Errorf("page_size = 250: %v", resp.Diagnostics)
+	}
+}
+
+func TestConfigureOrganizationHeader(t *testing.T) {
+	tests := map[string]struct {
+		value      tftypes.Value
+		wantHeader string
+	}{
+		"default":  {value: tftypes.NewValue(tftypes.String, nil), wantHeader: client.DefaultOrganizationHeader},
+		"custom":   {value: tftypes.NewValue(tftypes.String, "X-Crayon-Organization"), wantHeader: "X-Crayon-Organization"},
+		"disabled": {value: tftypes.NewValue(tftypes.String, "")},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var header http.Header
+			baseURL := newTestServer(t, testJWT(nil), func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Clone()
+				json.NewEncoder(w).Encode(map[string]interface{}{"Items": []client.CustomerTenant{}})
+			}, new(int32))
+
+			resp := configure(t, baseURL, map[string]tftypes.Value{"organization_header": test.value})
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("configure: %v", resp.Diagnostics)
+			}
+			if _, err := resp.ResourceData.(*client.Client).GetCustomerTenants(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, name := range []string{client.DefaultOrganizationHeader, "X-Crayon-Organization"} {
+				want := ""
+				if name == test.wantHeader {
+					want = "4051878"
+				}
+				if got := header.Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigureSuppressPendingWarnings(t *testing.T) {
+	baseURL := newTestServer(t, testJWT(nil), http.NotFound, new(int32))
+
+	tests := map[string]struct {
+		value tftypes.Value
+		want  bool
+	}{
+		"default":  {value: tftypes.NewValue(tftypes.Bool, nil)},
+		"disabled": {value: tftypes.NewValue(tftypes.Bool, false)},
+		"enabled":  {value: tftypes.NewValue(tftypes.Bool, true), want: true},
+	}
+	for name, test := range tests {
+		resp := configure(t, baseURL, map[string]tftypes.Value{"suppress_pending_warnings": test.value})
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("%s: configure: %v", name, resp.Diagnostics)
+		}
+		if got := resp.ResourceData.(*client.Client).SuppressPendingWarnings(); got != test.want {
+			t.Errorf("%s: SuppressPendingWarnings() = %v, want %v", name, got, test.want)
+		}
+	}
+}
+
+func TestConfigureTokenScopes(t *testing.T) {
+	baseURL := newTestServer(t, testJWT(nil), http.NotFound, new(int32))
+	scopes := func(values map[string]string) tftypes.Value {
+		elements := map[string]tftypes.Value{}
+		for category, scope := range values {
+			elements[category] = tftypes.NewValue(tftypes.String, scope)
+		}
+		return tftypes.NewValue(tftypes.Map{ElementType: tftypes.String}, elements)
+	}
+
+	resp := configure(t, baseURL, map[string]tftypes.Value{
+		"token_scopes": scopes(map[string]string{"read": "CustomerApi.Read", "billing": "CustomerApi.Billing"}),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("configure with known categories: %v", resp.Diagnostics)
+	}
+
+	resp = configure(t, baseURL, map[string]tftypes.Value{
+		"token_scopes": scopes(map[string]string{"admin": "CustomerApi.Admin"}),
+	})
+	if !hasSummary(resp.Diagnostics, "Invalid Token Scopes") {
+		t.Errorf("configure with an unknown category = %v, want an Invalid Token Scopes error", resp.Diagnostics)
+	}
+}
+
+func TestConfigureMinTLSVersion(t *testing.T) {
+	baseURL := newTestServer(t, testJWT(nil), http.NotFound, new(int32))
+
+	tests := map[string]struct {
+		value       tftypes.Value
+		wantSummary string
+	}{
+		"default": {value: tftypes.NewValue(tftypes.String, nil)},
+		"1.3":     {value: tftypes.NewValue(tftypes.String, "1.3")},
+		"1.1":     {value: tftypes.NewValue(tftypes.String, "1.1"), wantSummary: "Insecure TLS Version"},
+		"1.0":     {value: tftypes.NewValue(tftypes.String, "1.0"), wantSummary: "Insecure TLS Version"},
+		"unknown": {value: tftypes.NewValue(tftypes.String, "TLSv1.3"), wantSummary: "Invalid TLS Version"},
+	}
+	for name, test := range tests {
+		resp := configure(t, baseURL, map[string]tftypes.Value{"min_tls_version": test.value})
+		if test.wantSummary == "" && resp.Diagnostics.HasError() {
+			t.Errorf("%s: configure: %v", name, resp.Diagnostics)
+		}
+		if test.wantSummary != "" && !hasSummary(resp.Diagnostics, test.wantSummary) {
+			t.Errorf("%s: configure = %v, want %s", name, resp.Diagnostics, test.wantSummary)
+		}
+	}
+}
+
+func TestConfigureARMTargetState(t *testing.T) {
+	baseURL := newTestServer(t, testJWT(nil), http.NotFound, new(int32))
+
+	tests := map[string]struct {
+		value       tftypes.Value
+		wantSummary string
+	}{
+		"default":            {value: tftypes.NewValue(tftypes.String, nil)},
+		"state":              {value: tftypes.NewValue(tftypes.String, "PastDue")},
+		"state in lowercase": {value: tftypes.NewValue(tftypes.String, "warned")},
+		"any":                {value: tftypes.NewValue(tftypes.String, "any")},
+		"unknown":            {value: tftypes.NewValue(tftypes.String, "Active"), wantSummary: "Invalid ARM Target State"},
+	}
+	for name, test := range tests {
+		resp := configure(t, baseURL, map[string]tftypes.Value{"arm_target_state": test.value})
+		if test.wantSummary == "" && resp.Diagnostics.HasError() {
+			t.Errorf("%s: configure: %v", name, resp.Diagnostics)
+		}
+		if test.wantSummary != "" && !hasSummary(resp.Diagnostics, test.wantSummary) {
+			t.Errorf("%s: configure = %v, want %s", name, resp.Diagnostics, test.wantSummary)
+		}
+	}
+}
+
+func TestConfigureARMUnavailablePolicy(t *testing.T) {
+	baseURL := newTestServer(t, testJWT(nil), http.NotFound, new(int32))
+
+	tests := map[string]struct {
+		value       tftypes.Value
+		wantSummary string
+	}{
+		"default": {value: tftypes.NewValue(tftypes.String, nil)},
+		"warn":    {value: tftypes.NewValue(tftypes.String, "warn")},
+		"error":   {value: tftypes.NewValue(tftypes.String, "error")},
+		"unknown": {value: tftypes.NewValue(tftypes.String, "fail"), wantSummary: "Invalid ARM Unavailable Policy"},
+	}
+	for name, test := range tests {
+		resp := configure(t, baseURL, map[string]tftypes.Value{"arm_unavailable_policy": test.value})
+		if test.wantSummary == "" && resp.Diagnostics.HasError() {
+			t.Errorf("%s: configure: %v", name, resp.Diagnostics)
+		}
+		if test.wantSummary != "" && !hasSummary(resp.Diagnostics, test.wantSummary) {
+			t.Errorf("%s: configure = %v, want %s", name, resp.Diagnostics, test.wantSummary)
+		}
+	}
+}
+
+func TestConfigureChecksOrganizationClaim(t *testing.T) {
+	var requests int32
+	baseURL := newTestServer(t, testJWT(map[string]interface{}{"organization_id": 1}), http.NotFound, &requests)
+
+	resp := configure(t, baseURL, map[string]tftypes.Value{
+		"check_organization_claim": tftypes.NewValue(tftypes.Bool, true),
+	})
+	if resp.Diagnostics.HasError() || !hasSummary(resp.Diagnostics, "Organization Mismatch") {
+		t.Errorf("want an Organization Mismatch warning, got %v", resp.Diagnostics)
+	}
+}
+
+func TestCheckOrganizationClaim(t *testing.T) {
+	tests := map[string]struct {
+		token       string
+		policy      string
+		wantWarning bool
+		wantError   bool
+	}{
+		"matching claim": {
+			token: testJWT(map[string]interface{}{"organization_id": testOrganizationID}),
+		},
+		"matching claim as a string": {
+			token: testJWT(map[string]interface{}{"orgId": "4051878"}),
+		},
+		"mismatched claim": {
+			token:       testJWT(map[string]interface{}{"organization_id": 1}),
+			wantWarning: true,
+		},
+		"mismatched claim with the error policy": {
+			token:     testJWT(map[string]interface{}{"organization_id": 1}),
+			policy:    client.OrganizationMismatchError,
+			wantError: true,
+		},
+		"token without the claim": {
+			token:  testJWT(map[string]interface{}{"sub": "client"}),
+			policy: client.OrganizationMismatchError,
+		},
+		"opaque token": {
+			token:  "opaque",
+			policy: client.OrganizationMismatchError,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var requests int32
+			c := newTestClient(t, newTestServer(t, test.token, http.NotFound, &requests))
+
+			policy := test.policy
+			if policy == "" {
+				policy = client.OrganizationMismatchWarn
+			}
+			diags := checkOrganizationClaim(context.Background(), c, testOrganizationID, policy)
+			if diags.HasError() != test.wantError {
+				t.Errorf("errors: %v, want error %v", diags.Errors(), test.wantError)
+			}
+			if (diags.WarningsCount() > 0) != test.wantWarning {
+				t.Errorf("warnings: %v, want warning %v", diags.Warnings(), test.wantWarning)
+			}
+		})
+	}
+}
+
+func TestConfigureChecksAPIVersion(t *testing.T) {
+	var requests int32
+	baseURL := newTestServer(t, "token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(client.APIVersionInfo{APIVersion: "2.0"})
+	}, &requests)
+
+	resp := configure(t, baseURL, map[string]tftypes.Value{
+		"check_api_version": tftypes.NewValue(tftypes.Bool, true),
+	})
+	if resp.Diagnostics.HasError() || !hasSummary(resp.Diagnostics, "Crayon API Version Mismatch") {
+		t.Errorf("want a Crayon API Version Mismatch warning, got %v", resp.Diagnostics)
+	}
+}
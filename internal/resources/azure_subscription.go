@@ -4,25 +4,75 @@
 package resources
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
-	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
 )
 
+// operationLocationKey is the private state key holding the create operation of a pending subscription
+const operationLocationKey = "operation_location"
+
+// preventCancellationDetail explains how to cancel a subscription protected by prevent_cancellation
+const preventCancellationDetail = "The subscription has prevent_cancellation set. To cancel it, set prevent_cancellation " +
+	"to false (or remove it) and apply that change first, then destroy the subscription or set desired_status to cancelled."
+
+// provenanceKey is the private state key recording whether Terraform created or imported the subscription
+const provenanceKey = "provenance"
+
+// Provenances recorded under provenanceKey. Subscriptions from older provider versions have none.
+const (
+	provenanceCreated  = "created"
+	provenanceImported = "imported"
+)
+
+// protectImportedDetail explains how to destroy an imported subscription protected by the provider
+const protectImportedDetail = "The subscription was imported rather than created by Terraform, and the provider has " +
+	"protect_imported_subscriptions enabled, so destroying it would cancel a pre-existing subscription. To cancel it " +
+	"anyway, set allow_cancel_imported = true on the resource and apply that first. To stop managing it without " +
+	"cancelling, use 'terraform state rm' instead."
+
+// createdAtKey is the private state key holding when a pending subscription was requested
+const createdAtKey = "created_at"
+
+// syncTriggeredKey is the private state key recording that Read already triggered a Cloud-iQ sync
+const syncTriggeredKey = "sync_triggered"
+
+// deferredNameKey is the private state key holding the placeholder name a subscription was
+// created under with defer_naming, until it is renamed to its configured name
+const deferredNameKey = "deferred_name"
+
+// approvalRequestKey is the private state key holding the ID of the approval request a create
+// is waiting on
+const approvalRequestKey = "approval_request"
+
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &AzureSubscriptionResource{}
 var _ resource.ResourceWithImportState = &AzureSubscriptionResource{}
+var _ resource.ResourceWithModifyPlan = &AzureSubscriptionResource{}
 
 func NewAzureSubscriptionResource() resource.Resource {
 	return &AzureSubscriptionResource{}
@@ -30,17 +80,54 @@ func NewAzureSubscriptionResource() resource.Resource {
 
 // AzureSubscriptionResource defines the resource implementation.
 type AzureSubscriptionResource struct {
-	client *client.Client
+	client   client.SubscriptionAPI
+	settings client.SubscriptionSettings
 }
 
 // AzureSubscriptionResourceModel describes the resource data model.
 type AzureSubscriptionResourceModel struct {
-	ID             types.String `tfsdk:"id"`
-	AzurePlanID    types.Int64  `tfsdk:"azure_plan_id"`
-	Name           types.String `tfsdk:"name"`
-	SubscriptionID types.String `tfsdk:"subscription_id"`
-	Status         types.String `tfsdk:"status"`
-	CreateTimeout  types.Int64  `tfsdk:"create_timeout"`
+	ID                        types.String `tfsdk:"id"`
+	AzurePlanID               types.Int64  `tfsdk:"azure_plan_id"`
+	Name                      types.String `tfsdk:"name"`
+	SubscriptionID            types.String `tfsdk:"subscription_id"`
+	Status                    types.String `tfsdk:"status"`
+	CreateTimeout             types.Int64  `tfsdk:"create_timeout"`
+	Timeouts                  types.Object `tfsdk:"timeouts"`
+	Environment               types.String `tfsdk:"environment"`
+	Project                   types.String `tfsdk:"project"`
+	ExternalReference         types.String `tfsdk:"external_reference"`
+	DesiredStatus             types.String `tfsdk:"desired_status"`
+	BillingAccountID          types.String `tfsdk:"billing_account_id"`
+	Webhook                   types.String `tfsdk:"notification_webhook"`
+	ExtraFields               types.Map    `tfsdk:"extra_create_fields"`
+	PartnerOfRecord           types.String `tfsdk:"partner_of_record"`
+	Progress                  types.Int64  `tfsdk:"provisioning_progress"`
+	Tags                      types.Map    `tfsdk:"tags"`
+	TagsAll                   types.Map    `tfsdk:"tags_all"`
+	PortalURL                 types.String `tfsdk:"portal_url"`
+	CancellationDate          types.String `tfsdk:"cancellation_date"`
+	SupportPlan               types.String `tfsdk:"support_plan"`
+	Quantity                  types.Int64  `tfsdk:"quantity"`
+	PreventCancellation       types.Bool   `tfsdk:"prevent_cancellation"`
+	AllowCancelImported       types.Bool   `tfsdk:"allow_cancel_imported"`
+	WaitForCancellation       types.Bool   `tfsdk:"wait_for_cancellation"`
+	WaitForSync               types.Bool   `tfsdk:"wait_for_sync"`
+	CurrentBillingPeriodStart types.String `tfsdk:"current_billing_period_start"`
+	CurrentBillingPeriodEnd   types.String `tfsdk:"current_billing_period_end"`
+	NextRenewalDate           types.String `tfsdk:"next_renewal_date"`
+	LastModified              types.String `tfsdk:"last_modified"`
+	SpendingCapEnabled        types.Bool   `tfsdk:"spending_cap_enabled"`
+	SpendingCapReached        types.Bool   `tfsdk:"spending_cap_reached"`
+	ApprovalStatus            types.String `tfsdk:"approval_status"`
+	AccountManager            types.String `tfsdk:"account_manager"`
+	BillingAgreementType      types.String `tfsdk:"billing_agreement_type"`
+	BillingScope              types.String `tfsdk:"billing_scope"`
+	EnrollmentAccount         types.String `tfsdk:"enrollment_account"`
+	RefundEligible            types.Bool   `tfsdk:"refund_eligible"`
+	CancellationWindowEnds    types.String `tfsdk:"cancellation_window_ends"`
+	TechnicalContact          types.String `tfsdk:"technical_contact"`
+	DeferNaming               types.Bool   `tfsdk:"defer_naming"`
+	AutoRenew                 types.Bool   `tfsdk:"auto_renew"`
 }
 
 func (r *AzureSubscriptionResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -66,8 +153,9 @@ func (r *AzureSubscriptionResource) Schema(ctx context.Context, req resource.Sch
 				},
 			},
 			"name": schema.StringAttribute{
-				Description: "The display name of the Azure subscription.",
-				Required:    true,
+				Description: "The display name of the Azure subscription. Renames made outside Terraform are detected " +
+					"on refresh and reverted to this value on the next apply.",
+				Required: true,
 			},
 			"subscription_id": schema.StringAttribute{
 				Description: "The Azure subscription GUID.",
@@ -80,11 +168,284 @@ func (r *AzureSubscriptionResource) Schema(ctx context.Context, req resource.Sch
 				Description: "The current status of the subscription (e.g., active, cancelled).",
 				Computed:    true,
 			},
+			"billing_account_id": schema.StringAttribute{
+				Description: "The billing account (payer) the subscription rolls up to, if reported by Cloud-iQ.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"partner_of_record": schema.StringAttribute{
+				Description: "The Partner of Record (PoR) attributed to the subscription, as the partner's ID. Set it to " +
+					"attribute the subscription to a partner; otherwise the one reported by Cloud-iQ, if any.",
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"portal_url": schema.StringAttribute{
+				Description: "Link to the subscription in the Cloud-iQ portal. Null while the subscription is pending.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"current_billing_period_start": schema.StringAttribute{
+				Description: "Start of the subscription's current billing period (RFC 3339), if reported by Cloud-iQ.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"current_billing_period_end": schema.StringAttribute{
+				Description: "End of the subscription's current billing period (RFC 3339), if reported by Cloud-iQ.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"next_renewal_date": schema.StringAttribute{
+				Description: "When the subscription next renews (RFC 3339), if reported by Cloud-iQ.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"last_modified": schema.StringAttribute{
+				Description: "When the subscription last changed in Cloud-iQ (RFC 3339), if reported. Shows how fresh the " +
+					"refreshed state is.",
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"spending_cap_enabled": schema.BoolAttribute{
+				Description: "Whether the subscription's Azure spending limit is on. Read from Azure Resource Manager when " +
+					"the provider has an Azure Service Principal; null if it can't be read or the offer has no spending limit.",
+				Computed: true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"spending_cap_reached": schema.BoolAttribute{
+				Description: "Whether the spending limit has been reached, which disables the subscription until the next " +
+					"billing period. Null when spending_cap_enabled is not true.",
+				Computed: true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"account_manager": schema.StringAttribute{
+				Description: "The account manager Cloud-iQ records for the subscription, as \"Name <email>\" or whichever " +
+					"of the two is known. Null if not reported.",
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"technical_contact": schema.StringAttribute{
+				Description: "The technical contact Cloud-iQ records for the subscription, in the same format as " +
+					"account_manager. Null if not reported.",
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"billing_agreement_type": schema.StringAttribute{
+				Description: "The billing agreement the subscription is billed under: mca (Microsoft Customer Agreement), " +
+					"ea (Enterprise Agreement) or mpa (Microsoft Partner Agreement). Null if not reported.",
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"billing_scope": schema.StringAttribute{
+				Description: "The ARM billing scope of the subscription, e.g. the invoice section for MCA or the " +
+					"enrollment account for EA. Null if not reported.",
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"enrollment_account": schema.StringAttribute{
+				Description: "The EA enrollment account of the subscription. Null for other billing agreements.",
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"refund_eligible": schema.BoolAttribute{
+				Description: "Whether cancelling the subscription now would be refunded, as of the last refresh. Null for " +
+					"offers without a refund window.",
+				Computed: true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"cancellation_window_ends": schema.StringAttribute{
+				Description: "When the refund window for cancelling the subscription closes, in RFC 3339 format. Null if " +
+					"not reported or for offers without a refund window.",
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"approval_status": schema.StringAttribute{
+				Description: "The state of the create request in the organization's approval workflow: pending or approved. " +
+					"Null if the organization doesn't require approval.",
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"provisioning_progress": schema.Int64Attribute{
+				Description: "The percent complete (0-100) of a pending subscription's provisioning, if reported by Cloud-iQ. " +
+					"Null once the subscription has synced or when Cloud-iQ doesn't report progress.",
+				Computed: true,
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.UseStateForUnknown(),
+				},
+			},
 			"create_timeout": schema.Int64Attribute{
-				Description: "Timeout in minutes for waiting for subscription creation. Default is 10 minutes.",
+				Description: "Timeout in minutes for waiting for subscription creation. Deprecated: use timeouts.create.",
+				Optional:    true,
+				DeprecationMessage: "Use the timeouts block instead, e.g. timeouts { create = \"30m\" } for create_timeout = 30. " +
+					"create_timeout is only honored while timeouts.create is unset.",
+			},
+			"environment": schema.StringAttribute{
+				Description: "The environment of the subscription (dev, test, staging or prod). Stored as the 'environment' tag in Cloud-iQ.",
+				Optional:    true,
+				Validators: []validator.String{
+					stringOneOf("dev", "test", "staging", "prod"),
+				},
+			},
+			"project": schema.StringAttribute{
+				Description: "The project or application the subscription belongs to, for project-scoped inventory and " +
+					"cost views. Stored as the 'project' tag in Cloud-iQ. Must be one of the provider's allowed_projects, if set.",
+				Optional: true,
+			},
+			"external_reference": schema.StringAttribute{
+				Description: "The ticket or order ID in an external system the subscription was created for. Stored as the " +
+					"'external-reference' tag in Cloud-iQ; changes made outside Terraform show up as drift.",
+				Optional: true,
+				Validators: []validator.String{
+					stringLengthBetween(1, client.MaxTagValueLength),
+				},
+			},
+			"quantity": schema.Int64Attribute{
+				Description: "The number of seats, for offers billed by quantity. Changing it updates the subscription " +
+					"if its offer allows.",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64AtLeast(1),
+				},
+			},
+			"auto_renew": schema.BoolAttribute{
+				Description: "Whether the subscription renews automatically at the end of its term. Reported by Cloud-iQ " +
+					"when not set; null for offers without a renewal toggle.",
+				Optional: true,
+				Computed: true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"support_plan": schema.StringAttribute{
+				Description: "The Azure support plan of the subscription, if reported by Cloud-iQ. Can only be chosen at create.",
 				Optional:    true,
+				Computed:    true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"cancellation_date": schema.StringAttribute{
+				Description: "Schedule the cancellation on destroy for this day (YYYY-MM-DD), e.g. the end of the billing " +
+					"period, instead of cancelling immediately. Must be in the future when destroying.",
+				Optional: true,
+				Validators: []validator.String{
+					dateString(),
+				},
+			},
+			"prevent_cancellation": schema.BoolAttribute{
+				Description: "Refuse to cancel the subscription, on destroy or via desired_status, until this is set to false " +
+					"and applied. Unlike the prevent_destroy lifecycle argument it is stored in state, so it also protects " +
+					"the subscription after state moves and imports.",
+				Optional: true,
+			},
+			"defer_naming": schema.BoolAttribute{
+				Description: "Create the subscription under a placeholder name and rename it to name once its Crayon ID " +
+					"is known, for Azure Plans and offers that require naming after creation. Only affects creation.",
+				Optional: true,
+			},
+			"allow_cancel_imported": schema.BoolAttribute{
+				Description: "Allow destroying the subscription even though it was imported rather than created by Terraform, " +
+					"when the provider's protect_imported_subscriptions is enabled.",
+				Optional: true,
+			},
+			"wait_for_cancellation": schema.BoolAttribute{
+				Description: "Make destroy wait until Cloud-iQ reports the subscription as cancelled, for up to 30 minutes, " +
+					"instead of returning once the cancellation is requested. Ignored for scheduled cancellations.",
+				Optional: true,
+			},
+			"wait_for_sync": schema.BoolAttribute{
+				Description: "Make create wait until an asynchronously created subscription appears in Cloud-iQ, polling " +
+					"every sync_read_interval within the create timeout, so the apply records its Crayon ID instead of " +
+					"leaving it pending until a later refresh. Defaults to false.",
+				Optional: true,
+			},
+			"tags": schema.MapAttribute{
+				Description: "Tags of the subscription in Cloud-iQ. Merged over the provider's default_tags, " +
+					"taking precedence on key conflicts. Only the keys set here are tracked for drift.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"tags_all": schema.MapAttribute{
+				Description: "All tags managed on the subscription, including those inherited from the provider's default_tags.",
+				ElementType: types.StringType,
+				Computed:    true,
+			},
+			"extra_create_fields": schema.MapAttribute{
+				Description: "Additional raw fields sent in the create request body, for advanced use. " +
+					"Keys must be one of: " + strings.Join(client.KnownCreateFields, ", ") + ". Changing this forces a new subscription.",
+				ElementType: types.StringType,
+				Optional:    true,
+				PlanModifiers: []planmodifier.Map{
+					mapplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.Map{
+					mapKeysOneOf(client.KnownCreateFields...),
+				},
+			},
+			"notification_webhook": schema.StringAttribute{
+				Description: "URL that receives a POST with the subscription details once an asynchronously created " +
+					"subscription is confirmed in Azure or synced to Cloud-iQ. Compatible with Slack and Teams incoming webhooks. " +
+					"Delivery failures are logged and otherwise ignored.",
+				Optional: true,
+				Validators: []validator.String{
+					httpURL(),
+				},
+			},
+			"desired_status": schema.StringAttribute{
+				Description: "The status the subscription should be in (active, suspended or cancelled). Setting a cancelled " +
+					"subscription back to active reactivates it, which is only possible within Azure's grace period. " +
+					"A cancelled subscription cannot be suspended.",
+				Optional: true,
+				Validators: []validator.String{
+					stringOneOf("active", "suspended", "cancelled"),
+				},
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(map[string]string{
+				timeoutCreate: "How long creating the subscription may take, including polling Azure ARM for an " +
+					"asynchronously created subscription, and with wait_for_sync Cloud-iQ, before leaving it pending. " +
+					"Polling stops after 20 minutes by default.",
+				timeoutRead:   "How long refreshing the subscription may take. Unlimited by default.",
+				timeoutUpdate: "How long updating the subscription may take. Unlimited by default.",
+				timeoutDelete: "How long cancelling the subscription may take, including waiting for the cancellation " +
+					"with wait_for_cancellation. Unlimited by default, but wait_for_cancellation waits at most 30 minutes.",
+			}),
+		},
 	}
 }
 
@@ -94,17 +455,22 @@ func (r *AzureSubscriptionResource) Configure(ctx context.Context, req resource.
 		return
 	}
 
-	client, ok := req.ProviderData.(*client.Client)
+	api, ok := req.ProviderData.(client.SubscriptionAPI)
 
 	if !ok {
 		resp.Diagnostics.AddError(
 			"Unexpected Resource Configure Type",
-			fmt.Sprintf("Expected *client.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+			fmt.Sprintf("Expected a client.SubscriptionAPI, got: %T. Please report this issue to the provider developers.", req.ProviderData),
 		)
 		return
 	}
 
-	r.client = client
+	r.client = api
+	if source, ok := api.(client.SubscriptionSettingsSource); ok {
+		r.settings = source.SubscriptionSettings()
+	} else {
+		r.settings = client.DefaultSubscriptionSettings()
+	}
 }
 
 func (r *AzureSubscriptionResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -116,17 +482,104 @@ func (r *AzureSubscriptionResource) Create(ctx context.Context, req resource.Cre
 		return
 	}
 
+	// Polling for an asynchronous create stops on its own within the create timeout and leaves
+	// the subscription pending, so the state is still saved when the timeout is reached
+	ctx, cancel, diags := withTimeout(ctx, data.Timeouts, timeoutCreate)
+	defer cancel()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	tflog.Debug(ctx, "Creating Azure subscription", map[string]interface{}{
 		"azure_plan_id": data.AzurePlanID.ValueInt64(),
 		"name":          data.Name.ValueString(),
 	})
 
+	// Pending subscriptions are reconciled by name, so two same-named creates would end up
+	// tracking the same subscription
+	if err := r.client.ReserveSubscriptionName(ctx, int(data.AzurePlanID.ValueInt64()), data.Name.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Duplicate Subscription Name",
+			"Another crayon_azure_subscription in this configuration creates a subscription named '"+
+				data.Name.ValueString()+"' under the same Azure Plan. Subscriptions are matched to Cloud-iQ by name "+
+				"while pending, so same-named subscriptions can't be told apart. Please give each subscription a unique name.",
+		)
+		return
+	}
+	defer func() {
+		if resp.Diagnostics.HasError() {
+			r.client.ReleaseSubscriptionName(int(data.AzurePlanID.ValueInt64()), data.Name.ValueString())
+		}
+	}()
+
+	createReq, diags := createRequest(ctx, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if data.DeferNaming.ValueBool() {
+		// The configured name is applied by a rename once the Crayon ID is known
+		createReq.Name = deferredName()
+		placeholder, _ := json.Marshal(createReq.Name)
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, deferredNameKey, placeholder)...)
+	}
+
+	tags, diags := r.mergedTags(ctx, data.Tags)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.TagsAll = tagsMapValue(tags)
+
+	// Create the subscription already tagged, so tag policies never see it untagged. The client
+	// drops the tags if the create endpoint doesn't accept them.
+	if !data.Environment.IsNull() {
+		tags[client.EnvironmentTagKey] = data.Environment.ValueString()
+	}
+	if !data.Project.IsNull() {
+		tags[client.ProjectTagKey] = data.Project.ValueString()
+	}
+	if !data.ExternalReference.IsNull() {
+		tags[client.ExternalReferenceTagKey] = data.ExternalReference.ValueString()
+	}
+	createReq.Tags = tags
+
+	// Asynchronous creates are confirmed via Azure ARM; find out now rather than after creating
+	// that this can't happen
+	if err := r.client.CheckARMConfirmation(ctx); err != nil {
+		detail := "Asynchronous creates are confirmed by polling Azure ARM, which needs an Azure Service Principal " +
+			"(azure_client_id, azure_client_secret and azure_tenant_id), a managed identity (azure_use_msi) or an " +
+			"Azure CLI session ('az login'). " +
+			"Error: " + err.Error()
+		if r.settings.ARMUnavailablePolicy == client.ARMUnavailableError {
+			resp.Diagnostics.AddError("ARM Confirmation Unavailable", detail)
+			return
+		}
+		resp.Diagnostics.AddWarning(
+			"ARM Confirmation Unavailable",
+			detail+"\n\nIf Cloud-iQ creates the subscription asynchronously, it stays pending until Cloud-iQ syncs. "+
+				"Set arm_unavailable_policy = \"error\" to fail instead.",
+		)
+	}
+
+	// Only a subscription that wasn't there before the create may be cancelled if it fails
+	var existingIDs map[int]bool
+	if r.settings.CleanupFailedCreates {
+		existingIDs = r.subscriptionIDsNamed(ctx, int(data.AzurePlanID.ValueInt64()), createReq.Name)
+	}
+
 	// Create the subscription via Crayon API (fire-and-forget approach)
-	subscription, err := r.client.CreateAzureSubscription(
+	createStarted := time.Now()
+	subscription, err := r.client.CreateAzureSubscriptionWithRequest(ctx,
 		int(data.AzurePlanID.ValueInt64()),
-		data.Name.ValueString(),
+		createReq,
 	)
 	if err != nil {
+		if errors.Is(err, client.ErrCreateOutcomeUnknown) {
+			r.cleanupFailedCreate(ctx, &resp.Diagnostics, int(data.AzurePlanID.ValueInt64()), createReq.Name, existingIDs)
+		}
 		resp.Diagnostics.AddError(
 			"Error Creating Azure Subscription",
 			"Could not create subscription, unexpected error: "+err.Error(),
@@ -134,22 +587,153 @@ func (r *AzureSubscriptionResource) Create(ctx context.Context, req resource.Cre
 		return
 	}
 
+	// Optionally wait in this apply for Cloud-iQ to sync an asynchronously created subscription,
+	// for whatever is left of the create timeout
+	awaitingApproval := subscription.Status == client.StatusPendingApproval
+	syncNote := "You can click 'Synchronize' in the Cloud-iQ portal or run 'terraform refresh' later to update the state."
+	if subscription.ID == 0 && !awaitingApproval && data.WaitForSync.ValueBool() {
+		deadline := createStarted.Add(createReq.ConfirmTimeout)
+		synced, err := r.waitForSync(ctx, int(data.AzurePlanID.ValueInt64()), createReq.Name, deadline)
+		if err == nil {
+			subscription = synced
+		} else {
+			syncNote = fmt.Sprintf("It did not appear in Cloud-iQ within the create timeout (%v): %v. Run 'terraform refresh' "+
+				"later to update the state.", createReq.ConfirmTimeout, err)
+		}
+	}
+
 	// Map response to model
 	// Note: For async creation (202), ID will be 0 and SubscriptionID will be "pending"
-	if subscription.ID == 0 {
+	data.ApprovalStatus = types.StringNull()
+	if awaitingApproval {
+		// Nothing is created until the request is approved; Read follows the approval
+		data.ID = types.StringValue("pending-" + createReq.Name)
+		data.ApprovalStatus = types.StringValue(client.ApprovalPending)
+		approvalRequest, _ := json.Marshal(subscription.ApprovalRequestID)
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, approvalRequestKey, approvalRequest)...)
+		r.pendingWarning(ctx, &resp.Diagnostics,
+			"Subscription Awaiting Approval",
+			fmt.Sprintf("The organization requires approval for new subscriptions, so the create request for '%s' "+
+				"was submitted as approval request %d. The subscription is created once it is approved; run "+
+				"'terraform refresh' to follow the approval.", data.Name.ValueString(), subscription.ApprovalRequestID),
+		)
+	} else if subscription.ID == 0 {
 		// Async creation - use name as temporary ID and add warning
-		data.ID = types.StringValue("pending-" + data.Name.ValueString())
-		resp.Diagnostics.AddWarning(
+		data.ID = types.StringValue("pending-" + createReq.Name)
+		r.pendingWarning(ctx, &resp.Diagnostics,
 			"Subscription Creation In Progress",
 			"The subscription creation request was accepted but is being provisioned asynchronously. "+
-				"The subscription will appear in Cloud-iQ after Azure provisions it and Cloud-iQ syncs. "+
-				"You can click 'Synchronize' in the Cloud-iQ portal or run 'terraform refresh' later to update the state.",
+				"The subscription will appear in Cloud-iQ after Azure provisions it and Cloud-iQ syncs. "+syncNote,
 		)
 	} else {
 		data.ID = types.StringValue(strconv.Itoa(subscription.ID))
+		r.applyDeferredName(ctx, resp.Private, resp.Private, &resp.Diagnostics, int(data.AzurePlanID.ValueInt64()), subscription, data.Name.ValueString())
+	}
+	data.PortalURL = r.portalURL(int(data.AzurePlanID.ValueInt64()), subscription.ID)
+	data.Progress = types.Int64Null()
+	provenance, _ := json.Marshal(provenanceCreated)
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, provenanceKey, provenance)...)
+	if subscription.ID == 0 && !awaitingApproval {
+		// Start the clock for the sync recovery in Read
+		createdAt, _ := json.Marshal(time.Now().UTC().Format(time.RFC3339))
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, createdAtKey, createdAt)...)
+	}
+	if subscription.ID == 0 && subscription.OperationLocation != "" {
+		// Remember the operation so Read can report provisioning progress while pending
+		location, _ := json.Marshal(subscription.OperationLocation)
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, operationLocationKey, location)...)
+		if progress, ok := r.client.GetSubscriptionOperationProgress(ctx, subscription.OperationLocation); ok {
+			data.Progress = types.Int64Value(int64(progress))
+		}
 	}
 	data.SubscriptionID = types.StringValue(subscription.SubscriptionID)
 	data.Status = types.StringValue(subscription.Status)
+	data.BillingAccountID = stringOrNull(subscription.BillingAccountID)
+	data.CurrentBillingPeriodStart = billingTimestamp(subscription.CurrentBillingPeriodStart)
+	data.CurrentBillingPeriodEnd = billingTimestamp(subscription.CurrentBillingPeriodEnd)
+	data.NextRenewalDate = billingTimestamp(subscription.NextRenewalDate)
+	data.LastModified = lastModified(subscription)
+	data.AccountManager = contactOrNull(subscription.AccountManager)
+	data.TechnicalContact = contactOrNull(subscription.TechnicalContact)
+	setBillingScope(&data, subscription.BillingScope)
+	setRefundWindow(&data, subscription)
+	// The spending cap is read from ARM on the next refresh
+	data.SpendingCapEnabled = types.BoolNull()
+	data.SpendingCapReached = types.BoolNull()
+	if data.SupportPlan.IsUnknown() {
+		data.SupportPlan = stringOrNull(subscription.SupportPlan)
+	}
+
+	// Tag the subscription after creating it if the create request couldn't. If that isn't
+	// possible yet (pending) or fails, Read reports the tags as missing and the next apply sets them.
+	if len(tags) > 0 && subscription.ID != 0 && !subscription.HasTags(tags) {
+		err := r.client.UpdateAzureSubscriptionTags(ctx,
+			int(data.AzurePlanID.ValueInt64()),
+			subscription.ID,
+			tags,
+			nil,
+		)
+		if err != nil {
+			resp.Diagnostics.AddWarning(
+				"Could Not Set Subscription Tags",
+				"The subscription was created but its tags could not be set: "+err.Error()+". "+
+					"They will be retried on the next apply.",
+			)
+		}
+	}
+
+	// Like the tags, auto-renewal can only be set once we know the Crayon ID; Read reports a
+	// difference if it couldn't be set yet
+	if data.AutoRenew.IsUnknown() {
+		data.AutoRenew = types.BoolPointerValue(subscription.AutoRenew)
+	} else if subscription.ID != 0 && !data.AutoRenew.Equal(types.BoolPointerValue(subscription.AutoRenew)) {
+		err := r.client.SetAzureSubscriptionAutoRenew(ctx, int(data.AzurePlanID.ValueInt64()), subscription.ID, data.AutoRenew.ValueBool())
+		if err != nil {
+			resp.Diagnostics.AddWarning(
+				"Could Not Set Subscription Auto-Renewal",
+				"The subscription was created but its auto-renewal could not be set: "+err.Error()+". "+
+					"It will be retried on the next apply.",
+			)
+		}
+	}
+
+	// The Partner of Record is set the same way
+	if data.PartnerOfRecord.IsUnknown() {
+		data.PartnerOfRecord = stringOrNull(subscription.PartnerOfRecord)
+	} else if subscription.ID != 0 && data.PartnerOfRecord.ValueString() != subscription.PartnerOfRecord {
+		err := r.client.SetAzureSubscriptionPartnerOfRecord(ctx, int(data.AzurePlanID.ValueInt64()), subscription.ID, data.PartnerOfRecord.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddWarning(
+				"Could Not Set Subscription Partner of Record",
+				"The subscription was created but its Partner of Record could not be set: "+err.Error()+". "+
+					"It will be retried on the next apply.",
+			)
+		}
+	}
+
+	// New subscriptions start out active; move them to a different desired status once the Crayon
+	// ID is known. Otherwise Read reports the actual status and the next apply changes it.
+	if !data.DesiredStatus.IsNull() && subscription.ID != 0 {
+		current, _ := desiredStatusFromAPI(subscription.Status)
+		desired := data.DesiredStatus.ValueString()
+		if current != "" && desired != current {
+			err := r.transitionStatus(ctx, int(data.AzurePlanID.ValueInt64()), subscription.ID, current, desired)
+			if err != nil {
+				resp.Diagnostics.AddWarning(
+					"Could Not Set Subscription Status",
+					"The subscription was created but could not be changed to "+desired+": "+err.Error()+". "+
+						"It will be retried on the next apply.",
+				)
+			} else {
+				data.Status = types.StringValue(desired)
+			}
+		}
+	}
+
+	// Notify once the subscription has resolved (known Crayon ID or ARM-confirmed GUID)
+	if subscription.ID != 0 || subscription.SubscriptionID != "pending" {
+		r.notifyResolved(ctx, data.Webhook, subscription)
+	}
 
 	tflog.Info(ctx, "Created Azure subscription", map[string]interface{}{
 		"id":              subscription.ID,
@@ -170,29 +754,50 @@ func (r *AzureSubscriptionResource) Read(ctx context.Context, req resource.ReadR
 		return
 	}
 
+	ctx, cancel, diags := withTimeout(ctx, data.Timeouts, timeoutRead)
+	defer cancel()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	idValue := data.ID.ValueString()
 	azurePlanID := int(data.AzurePlanID.ValueInt64())
 
 	// Check if this is a pending subscription (created async, not yet synced)
 	if strings.HasPrefix(idValue, "pending-") {
 		subscriptionName := strings.TrimPrefix(idValue, "pending-")
+		if done := r.readApproval(ctx, req.Private, resp, azurePlanID, subscriptionName, &data); done {
+			return
+		}
+
 		tflog.Debug(ctx, "Checking for pending subscription sync", map[string]interface{}{
 			"name":          subscriptionName,
 			"azure_plan_id": azurePlanID,
 		})
 
 		// Try to find the subscription by name in Cloud-iQ
-		subscription, err := r.client.FindAzureSubscriptionByName(azurePlanID, subscriptionName)
+		subscription, err := r.findPendingSubscription(ctx, azurePlanID, subscriptionName)
 		if err != nil {
-			// Subscription not yet synced - keep the pending state
+			// Subscription not yet synced - keep the pending state, refreshing progress if reported
+			progress, ok := r.readProgress(ctx, req.Private)
+			if ok {
+				data.Progress = types.Int64Value(int64(progress))
+			}
 			tflog.Info(ctx, "Subscription not yet synced to Cloud-iQ", map[string]interface{}{
-				"name": subscriptionName,
+				"name":                  subscriptionName,
+				"provisioning_progress": data.Progress.ValueInt64Pointer(),
 			})
-			resp.Diagnostics.AddWarning(
+			guid := data.SubscriptionID.ValueString()
+			syncNote := r.triggerSync(ctx, req.Private, resp, azurePlanID, subscriptionName, guid)
+			if syncNote == "" {
+				syncNote = "Please click 'Synchronize' in the Cloud-iQ portal or wait for automatic sync, then run 'terraform refresh'."
+			}
+			r.pendingWarning(ctx, &resp.Diagnostics,
 				"Subscription Still Pending",
-				"The subscription '"+subscriptionName+"' has not yet appeared in Cloud-iQ. "+
-					"Please click 'Synchronize' in the Cloud-iQ portal or wait for automatic sync, then run 'terraform refresh'.",
+				"The subscription '"+subscriptionName+"' has not yet appeared in Cloud-iQ. "+syncNote,
 			)
+			r.recoverSync(ctx, req.Private, resp, azurePlanID, subscriptionName, guid, syncNote)
 			// Keep current state as-is
 			resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 			return
@@ -203,10 +808,47 @@ func (r *AzureSubscriptionResource) Read(ctx context.Context, req resource.ReadR
 			"id":              subscription.ID,
 			"subscription_id": subscription.SubscriptionID,
 		})
+		// Only notify if the create didn't already (it does once ARM confirms the GUID)
+		if data.SubscriptionID.ValueString() == "pending" {
+			r.notifyResolved(ctx, data.Webhook, subscription)
+		}
+
 		data.ID = types.StringValue(strconv.Itoa(subscription.ID))
+		data.PortalURL = r.portalURL(azurePlanID, subscription.ID)
 		data.SubscriptionID = types.StringValue(subscription.SubscriptionID)
 		data.Status = types.StringValue(subscription.Status)
+		r.applyDeferredName(ctx, req.Private, resp.Private, &resp.Diagnostics, azurePlanID, subscription, data.Name.ValueString())
 		data.Name = types.StringValue(subscription.FriendlyName)
+		data.BillingAccountID = stringOrNull(subscription.BillingAccountID)
+		data.PartnerOfRecord = stringOrNull(subscription.PartnerOfRecord)
+		data.CurrentBillingPeriodStart = billingTimestamp(subscription.CurrentBillingPeriodStart)
+		data.CurrentBillingPeriodEnd = billingTimestamp(subscription.CurrentBillingPeriodEnd)
+		data.NextRenewalDate = billingTimestamp(subscription.NextRenewalDate)
+		data.LastModified = lastModified(subscription)
+		data.AccountManager = contactOrNull(subscription.AccountManager)
+		data.TechnicalContact = contactOrNull(subscription.TechnicalContact)
+		setBillingScope(&data, subscription.BillingScope)
+		setRefundWindow(&data, subscription)
+		data.AutoRenew = types.BoolPointerValue(subscription.AutoRenew)
+		data.Progress = types.Int64Null()
+		resp.Diagnostics.Append(clearPrivateKey(ctx, resp.Private, operationLocationKey)...)
+		resp.Diagnostics.Append(clearPrivateKey(ctx, resp.Private, createdAtKey)...)
+		resp.Diagnostics.Append(clearPrivateKey(ctx, resp.Private, syncTriggeredKey)...)
+		if !data.Quantity.IsNull() && subscription.Quantity != 0 {
+			data.Quantity = types.Int64Value(int64(subscription.Quantity))
+		}
+		if subscription.SupportPlan != "" {
+			data.SupportPlan = types.StringValue(subscription.SupportPlan)
+		}
+
+		// Tags could not be applied while pending; surface them as drift
+		var reads []subRead
+		if r.tracksTags(data) {
+			tags := r.tagsSubRead(ctx, azurePlanID, subscription, &data)
+			tags.optional = true
+			reads = append(reads, tags)
+		}
+		runSubReads(ctx, reads, "subscription '"+subscription.FriendlyName+"'", &resp.Diagnostics)
 
 		resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
 		return
@@ -227,8 +869,21 @@ func (r *AzureSubscriptionResource) Read(ctx context.Context, req resource.ReadR
 		"azure_plan_id": azurePlanID,
 	})
 
-	// Get subscription from API
-	subscription, err := r.client.GetAzureSubscription(azurePlanID, subscriptionID)
+	// Get subscription from API, expanding related data we need in one round trip
+	var expand []string
+	if r.tracksTags(data) {
+		expand = append(expand, "tags")
+	}
+	subscription, err := r.client.GetAzureSubscription(ctx, azurePlanID, subscriptionID, expand...)
+	if client.IsNotFound(err) {
+		// The subscription is gone, so let Terraform plan to create it again
+		tflog.Warn(ctx, "Azure subscription not found, removing it from state", map[string]interface{}{
+			"id":            subscriptionID,
+			"azure_plan_id": azurePlanID,
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading Azure Subscription",
@@ -237,10 +892,70 @@ func (r *AzureSubscriptionResource) Read(ctx context.Context, req resource.ReadR
 		return
 	}
 
+	// Record the real name so a rename made outside Terraform (e.g. in the portal) shows up
+	// as a plan diff that renames the subscription back to the configured name. A placeholder
+	// from defer_naming that couldn't be renamed yet shows up the same way, without the warning.
+	deferred := r.applyDeferredName(ctx, req.Private, resp.Private, &resp.Diagnostics, azurePlanID, subscription, data.Name.ValueString())
+	if !deferred && !data.Name.IsNull() && subscription.FriendlyName != data.Name.ValueString() {
+		tflog.Warn(ctx, "Azure subscription renamed outside Terraform", map[string]interface{}{
+			"id":         subscriptionID,
+			"state_name": data.Name.ValueString(),
+			"api_name":   subscription.FriendlyName,
+		})
+		resp.Diagnostics.AddWarning(
+			"Subscription Renamed Outside Terraform",
+			fmt.Sprintf("The subscription %s is named '%s' in Cloud-iQ but '%s' in Terraform state. "+
+				"The next apply will rename it back to the name in your configuration; update the configuration "+
+				"instead to keep the new name.", idValue, subscription.FriendlyName, data.Name.ValueString()),
+		)
+	}
+
 	// Update model with fresh data
 	data.Name = types.StringValue(subscription.FriendlyName)
+	data.PortalURL = r.portalURL(azurePlanID, subscriptionID)
 	data.SubscriptionID = types.StringValue(subscription.SubscriptionID)
 	data.Status = types.StringValue(subscription.Status)
+	data.BillingAccountID = stringOrNull(subscription.BillingAccountID)
+	data.PartnerOfRecord = stringOrNull(subscription.PartnerOfRecord)
+	data.CurrentBillingPeriodStart = billingTimestamp(subscription.CurrentBillingPeriodStart)
+	data.CurrentBillingPeriodEnd = billingTimestamp(subscription.CurrentBillingPeriodEnd)
+	data.NextRenewalDate = billingTimestamp(subscription.NextRenewalDate)
+	data.LastModified = lastModified(subscription)
+	data.AccountManager = contactOrNull(subscription.AccountManager)
+	data.TechnicalContact = contactOrNull(subscription.TechnicalContact)
+	setBillingScope(&data, subscription.BillingScope)
+	setRefundWindow(&data, subscription)
+	data.AutoRenew = types.BoolPointerValue(subscription.AutoRenew)
+
+	// Keep the configured support plan when Cloud-iQ doesn't report one
+	if subscription.SupportPlan != "" {
+		data.SupportPlan = types.StringValue(subscription.SupportPlan)
+	}
+
+	// Only track the quantity when it is managed by this resource and reported by Cloud-iQ
+	if !data.Quantity.IsNull() && subscription.Quantity != 0 {
+		data.Quantity = types.Int64Value(int64(subscription.Quantity))
+	}
+
+	// Reflect the actual status so an externally changed status shows up as a diff
+	if !data.DesiredStatus.IsNull() {
+		if status, ok := desiredStatusFromAPI(subscription.Status); ok {
+			data.DesiredStatus = types.StringValue(status)
+		}
+	}
+
+	// Attributes that need further API calls are read concurrently
+	var reads []subRead
+	if r.tracksTags(data) {
+		reads = append(reads, r.tagsSubRead(ctx, azurePlanID, subscription, &data))
+	}
+	if r.settings.HasAzureCredentials && subscription.SubscriptionID != "" {
+		reads = append(reads, r.spendingCapSubRead(ctx, subscription.SubscriptionID, &data))
+	}
+	runSubReads(ctx, reads, "subscription ID "+idValue, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -257,6 +972,13 @@ func (r *AzureSubscriptionResource) Update(ctx context.Context, req resource.Upd
 		return
 	}
 
+	ctx, cancel, diags := withTimeout(ctx, data.Timeouts, timeoutUpdate)
+	defer cancel()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	idValue := state.ID.ValueString()
 
 	// Check if this is still a pending subscription
@@ -288,7 +1010,7 @@ func (r *AzureSubscriptionResource) Update(ctx context.Context, req resource.Upd
 		})
 
 		// Rename subscription
-		subscription, err := r.client.RenameAzureSubscription(
+		subscription, err := r.client.RenameAzureSubscription(ctx,
 			int(data.AzurePlanID.ValueInt64()),
 			subscriptionID,
 			data.Name.ValueString(),
@@ -326,23 +1048,350 @@ func (r *AzureSubscriptionResource) Update(ctx context.Context, req resource.Upd
 		data.Status = state.Status
 	}
 
-	// Save updated data into Terraform state
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
-}
+	// Apply a status transition if the desired status differs from the actual one
+	if !data.DesiredStatus.IsNull() {
+		current, _ := desiredStatusFromAPI(state.Status.ValueString())
+		desired := data.DesiredStatus.ValueString()
+		// Enforced here too, as plan-time checks can be bypassed (e.g. by targeting)
+		if desired == "cancelled" && desired != current &&
+			(data.PreventCancellation.ValueBool() || state.PreventCancellation.ValueBool()) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("desired_status"),
+				"Subscription Cancellation Prevented",
+				preventCancellationDetail,
+			)
+			return
+		}
+		if desired != current {
+			if err := r.transitionStatus(ctx, int(data.AzurePlanID.ValueInt64()), subscriptionID, current, desired); err != nil {
+				resp.Diagnostics.AddError(
+					"Error Updating Azure Subscription",
+					"Could not change subscription status to "+desired+": "+err.Error(),
+				)
+				return
+			}
+			data.Status = types.StringValue(desired)
+		}
+	}
 
-func (r *AzureSubscriptionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var data AzureSubscriptionResourceModel
+	if !data.Quantity.IsNull() && !data.Quantity.Equal(state.Quantity) {
+		tflog.Debug(ctx, "Updating Azure subscription quantity", map[string]interface{}{
+			"id":       subscriptionID,
+			"quantity": data.Quantity.ValueInt64(),
+		})
 
-	// Read Terraform prior state data into the model
-	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
-	if resp.Diagnostics.HasError() {
-		return
+		err := r.client.SetAzureSubscriptionQuantity(ctx, int(data.AzurePlanID.ValueInt64()), subscriptionID, int(data.Quantity.ValueInt64()))
+		if errors.Is(err, client.ErrQuantityNotChangeable) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("quantity"),
+				"Quantity Cannot Be Changed",
+				"The subscription's offer does not allow changing its quantity. Revert quantity to its previous value "+
+					"or replace the subscription.\n\nError: "+err.Error(),
+			)
+			return
+		}
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating Azure Subscription",
+				"Could not update subscription quantity: "+err.Error(),
+			)
+			return
+		}
 	}
 
-	idValue := data.ID.ValueString()
+	if !data.AutoRenew.IsUnknown() && !data.AutoRenew.IsNull() && !data.AutoRenew.Equal(state.AutoRenew) {
+		tflog.Debug(ctx, "Updating Azure subscription auto-renewal", map[string]interface{}{
+			"id":         subscriptionID,
+			"auto_renew": data.AutoRenew.ValueBool(),
+		})
+
+		err := r.client.SetAzureSubscriptionAutoRenew(ctx, int(data.AzurePlanID.ValueInt64()), subscriptionID, data.AutoRenew.ValueBool())
+		if errors.Is(err, client.ErrAutoRenewNotApplicable) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("auto_renew"),
+				"Auto-Renewal Not Applicable",
+				"The subscription's offer has no auto-renewal toggle. Remove auto_renew from the configuration.\n\nError: "+err.Error(),
+			)
+			return
+		}
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating Azure Subscription",
+				"Could not update subscription auto-renewal: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	if !data.PartnerOfRecord.IsUnknown() && !data.PartnerOfRecord.IsNull() && !data.PartnerOfRecord.Equal(state.PartnerOfRecord) {
+		tflog.Debug(ctx, "Updating Azure subscription Partner of Record", map[string]interface{}{
+			"id":                subscriptionID,
+			"partner_of_record": data.PartnerOfRecord.ValueString(),
+		})
+
+		err := r.client.SetAzureSubscriptionPartnerOfRecord(ctx, int(data.AzurePlanID.ValueInt64()), subscriptionID, data.PartnerOfRecord.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating Azure Subscription",
+				"Could not update subscription Partner of Record: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	// Apply tag changes, including changes to the provider's default_tags. The environment, project
+	// and external reference are tags too; all changes go in one update, so they can't overwrite
+	// each other or be left half-applied.
+	tags, diags := r.mergedTags(ctx, data.Tags)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.TagsAll = tagsMapValue(tags)
+
+	set := map[string]string{}
+	var remove []string
+	if !data.TagsAll.Equal(state.TagsAll) {
+		var previous map[string]string
+		if !state.TagsAll.IsNull() {
+			resp.Diagnostics.Append(state.TagsAll.ElementsAs(ctx, &previous, false)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+		}
+		for key := range previous {
+			if _, ok := tags[key]; !ok {
+				remove = append(remove, key)
+			}
+		}
+		for key, value := range tags {
+			set[key] = value
+		}
+	}
+	for _, attribute := range []struct {
+		key          string
+		value, prior types.String
+	}{
+		{client.EnvironmentTagKey, data.Environment, state.Environment},
+		{client.ProjectTagKey, data.Project, state.Project},
+		{client.ExternalReferenceTagKey, data.ExternalReference, state.ExternalReference},
+	} {
+		switch {
+		case attribute.value.Equal(attribute.prior):
+		case attribute.value.ValueString() == "":
+			remove = append(remove, attribute.key)
+		default:
+			set[attribute.key] = attribute.value.ValueString()
+		}
+	}
+
+	if len(set) > 0 || len(remove) > 0 {
+		tflog.Debug(ctx, "Updating Azure subscription tags", map[string]interface{}{
+			"id":      subscriptionID,
+			"tags":    set,
+			"removed": remove,
+		})
+
+		err := r.client.UpdateAzureSubscriptionTags(ctx, int(data.AzurePlanID.ValueInt64()), subscriptionID, set, remove)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error Updating Azure Subscription",
+				"Could not update subscription tags: "+err.Error(),
+			)
+			return
+		}
+	}
+
+	// Save updated data into Terraform state
+	resolveUnknowns(&data, state)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AzureSubscriptionResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// On destroy, only check that cancelling is allowed and a scheduled cancellation is still possible
+	if req.Plan.Raw.IsNull() {
+		var preventCancellation types.Bool
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("prevent_cancellation"), &preventCancellation)...)
+		if preventCancellation.ValueBool() {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("prevent_cancellation"),
+				"Subscription Cancellation Prevented",
+				preventCancellationDetail,
+			)
+			return
+		}
+
+		var allowCancelImported types.Bool
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("allow_cancel_imported"), &allowCancelImported)...)
+		if r.protectsImported(ctx, req.Private, allowCancelImported) {
+			resp.Diagnostics.AddError("Imported Subscription Protected", protectImportedDetail)
+			return
+		}
+
+		var cancellationDate types.String
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("cancellation_date"), &cancellationDate)...)
+		if _, err := parseCancellationDate(cancellationDate); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("cancellation_date"),
+				"Invalid Cancellation Date",
+				err.Error()+". Update or remove cancellation_date before destroying the subscription.",
+			)
+		}
+
+		var refundEligible types.Bool
+		var windowEnds types.String
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("refund_eligible"), &refundEligible)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("cancellation_window_ends"), &windowEnds)...)
+		refundWindowWarning(&resp.Diagnostics, refundEligible, windowEnds)
+		return
+	}
+
+	var plan AzureSubscriptionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Plan the effective tags so a change to the provider's default_tags shows up as an update
+	if !plan.Tags.IsUnknown() {
+		tags, diags := r.mergedTags(ctx, plan.Tags)
+		resp.Diagnostics.Append(diags...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("tags_all"), tagsMapValue(tags))...)
+	}
+
+	var state AzureSubscriptionResourceModel
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Catch Azure Plans of another organization before creating anything in them
+	if r.client != nil && !r.settings.SkipOrganizationCheck && !plan.AzurePlanID.IsUnknown() &&
+		!plan.AzurePlanID.Equal(state.AzurePlanID) {
+		if err := r.client.VerifyAzurePlanOrganization(ctx, int(plan.AzurePlanID.ValueInt64())); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("azure_plan_id"),
+				"Azure Plan Not In Organization",
+				"Could not verify that the Azure Plan belongs to the configured organization: "+err.Error()+". "+
+					"Set skip_organization_check = true in the provider to skip this check.",
+			)
+			return
+		}
+	}
+
+	// Keep subscriptions within the projects the provider allows
+	if r.client != nil && !plan.Project.IsNull() && !plan.Project.IsUnknown() {
+		if allowed := r.settings.AllowedProjects; len(allowed) > 0 && !slices.Contains(allowed, plan.Project.ValueString()) {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("project"),
+				"Project Not Allowed",
+				fmt.Sprintf("project must be one of the provider's allowed_projects: %s. Got: %s",
+					strings.Join(allowed, ", "), plan.Project.ValueString()),
+			)
+		}
+	}
+
+	// The remaining checks only apply to existing subscriptions; new ones are preflighted instead
+	if req.State.Raw.IsNull() {
+		if plan.DesiredStatus.ValueString() == "cancelled" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("desired_status"),
+				"Invalid Status Transition",
+				"A subscription cannot be created cancelled. Create it active or suspended, then set desired_status to cancelled.",
+			)
+			return
+		}
+		r.preflightCreate(ctx, plan, resp)
+		return
+	}
+
+	if !plan.SupportPlan.IsUnknown() && !plan.SupportPlan.IsNull() && !plan.SupportPlan.Equal(state.SupportPlan) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("support_plan"),
+			"Support Plan Cannot Be Changed",
+			"support_plan can only be chosen when the subscription is created. Change it in Cloud-iQ or the Azure portal "+
+				"instead, or remove it from the configuration.",
+		)
+	}
+
+	// Reject invalid status transitions at plan time
+	if !plan.DesiredStatus.IsNull() && !plan.DesiredStatus.IsUnknown() {
+		current, _ := desiredStatusFromAPI(state.Status.ValueString())
+		if err := validateStatusTransition(current, plan.DesiredStatus.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("desired_status"),
+				"Invalid Status Transition",
+				err.Error()+".",
+			)
+		}
+		if plan.PreventCancellation.ValueBool() && current != "cancelled" && plan.DesiredStatus.ValueString() == "cancelled" {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("desired_status"),
+				"Subscription Cancellation Prevented",
+				preventCancellationDetail,
+			)
+		}
+		if current != "cancelled" && plan.DesiredStatus.ValueString() == "cancelled" {
+			refundWindowWarning(&resp.Diagnostics, state.RefundEligible, state.CancellationWindowEnds)
+		}
+	}
+}
+
+// cancelledStatuses are the statuses that show a subscription's cancellation is complete
+var cancelledStatuses = []string{"cancelled", "canceled", "deleted", "disabled"}
+
+// cancellationWaitTimeout bounds how long destroy waits for a cancellation with wait_for_cancellation
+const cancellationWaitTimeout = 30 * time.Minute
+
+func (r *AzureSubscriptionResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data AzureSubscriptionResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel, diags := withTimeout(ctx, data.Timeouts, timeoutDelete)
+	defer cancel()
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Enforced here too, as plan-time checks can be bypassed (e.g. by targeting)
+	if data.PreventCancellation.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("prevent_cancellation"),
+			"Subscription Cancellation Prevented",
+			preventCancellationDetail,
+		)
+		return
+	}
+	if r.protectsImported(ctx, req.Private, data.AllowCancelImported) {
+		resp.Diagnostics.AddError("Imported Subscription Protected", protectImportedDetail)
+		return
+	}
+
+	idValue := data.ID.ValueString()
 
 	// Check if this is a pending subscription
 	if strings.HasPrefix(idValue, "pending-") {
+		// A request still awaiting approval can be withdrawn, so nothing is ever created
+		raw, _ := getPrivateKey(ctx, req.Private, approvalRequestKey)
+		var requestID int
+		if len(raw) > 0 && json.Unmarshal(raw, &requestID) == nil && requestID != 0 {
+			if err := r.client.WithdrawApprovalRequest(ctx, int(data.AzurePlanID.ValueInt64()), requestID); err != nil {
+				resp.Diagnostics.AddError(
+					"Error Deleting Azure Subscription",
+					fmt.Sprintf("Could not withdraw approval request %d: %v", requestID, err),
+				)
+			}
+			return
+		}
+
 		// Pending subscription - can't cancel via API since we don't have the Crayon ID
 		// Just remove from state. The subscription may or may not exist in Azure.
 		tflog.Warn(ctx, "Deleting pending subscription from state only (no Crayon ID available)", map[string]interface{}{
@@ -366,16 +1415,45 @@ func (r *AzureSubscriptionResource) Delete(ctx context.Context, req resource.Del
 		return
 	}
 
+	effectiveDate, err := parseCancellationDate(data.CancellationDate)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("cancellation_date"),
+			"Error Deleting Azure Subscription",
+			err.Error(),
+		)
+		return
+	}
+
 	tflog.Debug(ctx, "Cancelling Azure subscription", map[string]interface{}{
-		"id":            subscriptionID,
-		"azure_plan_id": data.AzurePlanID.ValueInt64(),
+		"id":                subscriptionID,
+		"azure_plan_id":     data.AzurePlanID.ValueInt64(),
+		"cancellation_date": data.CancellationDate.ValueString(),
 	})
 
 	// Cancel the subscription via Crayon API
-	err = r.client.CancelAzureSubscription(
+	err = r.client.CancelAzureSubscription(ctx,
 		int(data.AzurePlanID.ValueInt64()),
 		subscriptionID,
+		effectiveDate,
 	)
+	if errors.Is(err, client.ErrScheduledCancellationRejected) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("cancellation_date"),
+			"Scheduled Cancellation Rejected",
+			"Cloud-iQ does not allow scheduling the cancellation of this subscription. Remove cancellation_date "+
+				"to cancel it immediately.\n\nError: "+err.Error(),
+		)
+		return
+	}
+	if client.IsNotFound(err) {
+		// Deleted outside Terraform since the last refresh; there is nothing left to cancel
+		tflog.Warn(ctx, "Azure subscription not found, removing it from state without cancelling", map[string]interface{}{
+			"id":            subscriptionID,
+			"azure_plan_id": data.AzurePlanID.ValueInt64(),
+		})
+		return
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Deleting Azure Subscription",
@@ -384,6 +1462,25 @@ func (r *AzureSubscriptionResource) Delete(ctx context.Context, req resource.Del
 		return
 	}
 
+	if data.WaitForCancellation.ValueBool() && effectiveDate.IsZero() {
+		// The delete timeout bounds the wait through ctx as well, so only the default needs a limit here
+		waitTimeout, _ := timeout(data.Timeouts, timeoutDelete, cancellationWaitTimeout)
+		tflog.Debug(ctx, "Waiting for the cancellation to complete", map[string]interface{}{
+			"id": subscriptionID,
+		})
+		_, err := r.client.WaitForSubscriptionStatus(ctx, int(data.AzurePlanID.ValueInt64()), subscriptionID,
+			cancelledStatuses, waitTimeout)
+		// The cancellation was accepted, so the subscription is gone from Terraform's view either way
+		if err != nil {
+			resp.Diagnostics.AddWarning(
+				"Cancellation Not Confirmed",
+				fmt.Sprintf("The cancellation of subscription %d was requested, but could not be confirmed: %v. "+
+					"The subscription may still be cancelling; check Cloud-iQ before recreating it.", subscriptionID, err),
+			)
+			return
+		}
+	}
+
 	tflog.Info(ctx, "Cancelled Azure subscription", map[string]interface{}{
 		"id": subscriptionID,
 	})
@@ -392,12 +1489,36 @@ func (r *AzureSubscriptionResource) Delete(ctx context.Context, req resource.Del
 func (r *AzureSubscriptionResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
 	// Import format: "azure_plan_id:subscription_id"
 	// Example: "873834:12345"
-	
-	idParts := splitImportID(req.ID)
+	// The subscription's Cloud-iQ portal URL is accepted too and translated into that format
+	// Example: "https://cloudiq.crayon.com/subscriptions/azure/873834/12345?organizationId=1234"
+	importID := req.ID
+	if strings.Contains(importID, "://") {
+		link, err := client.ParseSubscriptionPortalURL(importID)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Invalid Import ID",
+				"Could not parse the portal URL: "+err.Error()+". Expected a link like "+
+					"https://cloudiq.crayon.com/subscriptions/azure/<azure_plan_id>/<id>?organizationId=<organization_id>",
+			)
+			return
+		}
+		if r.client != nil && link.OrganizationID != 0 && r.settings.OrganizationID != 0 &&
+			link.OrganizationID != r.settings.OrganizationID {
+			resp.Diagnostics.AddError(
+				"Organization Mismatch",
+				fmt.Sprintf("The portal URL belongs to organization %d, but the provider is configured for organization %d.",
+					link.OrganizationID, r.settings.OrganizationID),
+			)
+			return
+		}
+		importID = fmt.Sprintf("%d:%d", link.AzurePlanID, link.SubscriptionID)
+	}
+
+	idParts := splitImportID(importID)
 	if len(idParts) != 2 {
 		resp.Diagnostics.AddError(
 			"Invalid Import ID",
-			"Import ID must be in format 'azure_plan_id:subscription_id'. Got: "+req.ID,
+			"Import ID must be in format 'azure_plan_id:subscription_id' or a Cloud-iQ portal URL. Got: "+req.ID,
 		)
 		return
 	}
@@ -413,6 +1534,837 @@ func (r *AzureSubscriptionResource) ImportState(ctx context.Context, req resourc
 
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("azure_plan_id"), azurePlanID)...)
 	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), idParts[1])...)
+
+	// Remember that the subscription existed before Terraform managed it, for the destroy guard
+	provenance, _ := json.Marshal(provenanceImported)
+	resp.Diagnostics.Append(resp.Private.SetKey(ctx, provenanceKey, provenance)...)
+}
+
+// notifyResolved sends the subscription details to the notification webhook, if configured.
+// Failures are logged only.
+func (r *AzureSubscriptionResource) notifyResolved(ctx context.Context, webhook types.String, subscription *client.AzureSubscription) {
+	if webhook.IsNull() || webhook.ValueString() == "" {
+		return
+	}
+
+	if err := r.client.NotifySubscriptionResolved(ctx, webhook.ValueString(), subscription); err != nil {
+		tflog.Warn(ctx, "Failed to deliver subscription notification webhook", map[string]interface{}{
+			"name":  subscription.FriendlyName,
+			"error": err.Error(),
+		})
+		return
+	}
+
+	tflog.Info(ctx, "Delivered subscription notification webhook", map[string]interface{}{
+		"name": subscription.FriendlyName,
+	})
+}
+
+// findPendingSubscription looks for a pending subscription in Cloud-iQ, retrying up to the
+// provider's sync_read_retries before giving up
+func (r *AzureSubscriptionResource) findPendingSubscription(ctx context.Context, azurePlanID int, name string) (*client.AzureSubscription, error) {
+	attempts := r.settings.SyncReadRetries
+	retry := &client.Backoff{Base: r.settings.SyncReadInterval, Multiplier: 1}
+	for {
+		subscription, err := r.client.FindAzureSubscriptionByName(ctx, azurePlanID, name)
+		if err == nil {
+			return subscription, nil
+		}
+		if retry.Attempt()+1 >= attempts {
+			return nil, err
+		}
+
+		tflog.Debug(ctx, "Pending subscription not found yet, retrying", map[string]interface{}{
+			"name":     name,
+			"attempt":  retry.Attempt() + 1,
+			"attempts": attempts,
+		})
+
+		if err := retry.WaitOrStop(ctx, r.settings.StopContext, 0); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// waitForSync polls Cloud-iQ every sync_read_interval until a subscription created
+// asynchronously appears, ctx is cancelled or the deadline passes
+func (r *AzureSubscriptionResource) waitForSync(ctx context.Context, azurePlanID int, name string, deadline time.Time) (*client.AzureSubscription, error) {
+	started := time.Now()
+	poll := &client.Backoff{Base: r.settings.SyncReadInterval, Multiplier: 1, Deadline: deadline}
+	for {
+		subscription, err := r.client.FindAzureSubscriptionByName(ctx, azurePlanID, name)
+		if err == nil {
+			tflog.Info(ctx, "Subscription synced to Cloud-iQ during create", map[string]interface{}{
+				"name":    name,
+				"id":      subscription.ID,
+				"elapsed": time.Since(started).Round(time.Second).String(),
+			})
+			return subscription, nil
+		}
+
+		tflog.Info(ctx, "Waiting for the subscription to sync to Cloud-iQ", map[string]interface{}{
+			"name":      name,
+			"attempt":   poll.Attempt() + 1,
+			"elapsed":   time.Since(started).Round(time.Second).String(),
+			"remaining": time.Until(deadline).Round(time.Second).String(),
+		})
+
+		if waitErr := poll.WaitOrStop(ctx, r.settings.StopContext, 0); waitErr != nil {
+			if errors.Is(waitErr, client.ErrBackoffExhausted) {
+				return nil, err
+			}
+			return nil, waitErr
+		}
+	}
+}
+
+// readProgress looks up the provisioning progress of a pending subscription from the create
+// operation remembered in private state
+func (r *AzureSubscriptionResource) readProgress(ctx context.Context, private privateState) (int, bool) {
+	raw, diags := getPrivateKey(ctx, private, operationLocationKey)
+	if diags.HasError() || len(raw) == 0 {
+		return 0, false
+	}
+
+	var location string
+	if err := json.Unmarshal(raw, &location); err != nil || location == "" {
+		return 0, false
+	}
+
+	progress, ok := r.client.GetSubscriptionOperationProgress(ctx, location)
+	if ok {
+		tflog.Debug(ctx, "Pending subscription provisioning progress", map[string]interface{}{
+			"percent_complete": progress,
+		})
+	}
+	return progress, ok
+}
+
+// createRequest builds the create request body of a planned subscription
+func createRequest(ctx context.Context, data AzureSubscriptionResourceModel) (client.CreateAzureSubscriptionRequest, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	createReq := client.CreateAzureSubscriptionRequest{
+		Name:     data.Name.ValueString(),
+		Quantity: int(data.Quantity.ValueInt64()),
+	}
+	if !data.SupportPlan.IsUnknown() {
+		createReq.SupportPlan = data.SupportPlan.ValueString()
+	}
+	if !data.ExtraFields.IsNull() {
+		diags.Append(data.ExtraFields.ElementsAs(ctx, &createReq.ExtraFields, false)...)
+	}
+
+	// create_timeout is the deprecated way to set timeouts.create
+	confirmTimeout := client.DefaultConfirmTimeout
+	if !data.CreateTimeout.IsNull() && !data.CreateTimeout.IsUnknown() && data.CreateTimeout.ValueInt64() > 0 {
+		confirmTimeout = time.Duration(data.CreateTimeout.ValueInt64()) * time.Minute
+	}
+	confirmTimeout, timeoutDiags := timeout(data.Timeouts, timeoutCreate, confirmTimeout)
+	diags.Append(timeoutDiags...)
+	createReq.ConfirmTimeout = confirmTimeout
+
+	return createReq, diags
+}
+
+// preflightCreate asks Cloud-iQ at plan time whether the subscription would be accepted, so a bad
+// name, a full Azure Plan or an unavailable offer fails the plan rather than the apply. It is
+// skipped while inputs are unknown and when the API has no preflight endpoint.
+func (r *AzureSubscriptionResource) preflightCreate(ctx context.Context, plan AzureSubscriptionResourceModel, resp *resource.ModifyPlanResponse) {
+	if r.client == nil || plan.AzurePlanID.IsUnknown() || plan.Name.IsUnknown() || plan.Quantity.IsUnknown() ||
+		plan.ExtraFields.IsUnknown() {
+		return
+	}
+
+	createReq, diags := createRequest(ctx, plan)
+	if diags.HasError() {
+		return
+	}
+
+	err := r.client.CreateAzureSubscriptionDryRun(ctx, int(plan.AzurePlanID.ValueInt64()), createReq)
+	switch {
+	case err == nil:
+		tflog.Debug(ctx, "Cloud-iQ would accept the subscription", map[string]interface{}{
+			"name": plan.Name.ValueString(),
+		})
+	case errors.Is(err, client.ErrCreateRejected):
+		resp.Diagnostics.AddError(
+			"Subscription Would Be Rejected",
+			"Cloud-iQ reports that it would not create the subscription '"+plan.Name.ValueString()+"'. "+
+				"Check the name, the remaining capacity of the Azure Plan and the offer.\n\nError: "+err.Error(),
+		)
+	default:
+		// Preflight is best effort; the create itself reports any real problem
+		tflog.Debug(ctx, "Skipping subscription create preflight", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// readApproval follows the approval request of a pending subscription, if its create awaits one.
+// It reports whether Read is done: while the request is pending, and once it is rejected, which
+// removes the resource so the next apply can submit a new request. Once approved, the approval
+// is cleared and Read goes on to look for the subscription like any other pending one.
+func (r *AzureSubscriptionResource) readApproval(ctx context.Context, private privateState, resp *resource.ReadResponse, azurePlanID int, name string, data *AzureSubscriptionResourceModel) bool {
+	raw, diags := getPrivateKey(ctx, private, approvalRequestKey)
+	var requestID int
+	if diags.HasError() || len(raw) == 0 || json.Unmarshal(raw, &requestID) != nil || requestID == 0 {
+		return false
+	}
+
+	approval, err := r.client.GetApprovalRequest(ctx, azurePlanID, requestID)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error Reading Azure Subscription",
+			fmt.Sprintf("Could not read approval request %d of subscription '%s': %v", requestID, name, err),
+		)
+		return true
+	}
+
+	tflog.Debug(ctx, "Read subscription approval request", map[string]interface{}{
+		"name":                name,
+		"approval_request_id": requestID,
+		"status":              approval.Status,
+	})
+
+	switch approval.State() {
+	case client.ApprovalApproved:
+		data.ApprovalStatus = types.StringValue(client.ApprovalApproved)
+		data.Status = types.StringValue("provisioning")
+		resp.Diagnostics.Append(clearPrivateKey(ctx, resp.Private, approvalRequestKey)...)
+		// Provisioning only starts now, so restart the clock for the sync recovery
+		createdAt, _ := json.Marshal(time.Now().UTC().Format(time.RFC3339))
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, createdAtKey, createdAt)...)
+		return false
+	case client.ApprovalRejected:
+		reason := ""
+		if approval.Reason != "" {
+			reason = " Reason: " + approval.Reason
+		}
+		resp.Diagnostics.AddWarning(
+			"Subscription Request Rejected",
+			fmt.Sprintf("Approval request %d for the subscription '%s' was rejected, so the subscription was never "+
+				"created. It has been removed from state; the next apply submits a new request.%s", requestID, name, reason),
+		)
+		resp.State.RemoveResource(ctx)
+		return true
+	}
+
+	// Pending, or a status this provider doesn't know; keep waiting either way
+	data.ApprovalStatus = types.StringValue(client.ApprovalPending)
+	r.pendingWarning(ctx, &resp.Diagnostics,
+		"Subscription Awaiting Approval",
+		fmt.Sprintf("The subscription '%s' is waiting for approval request %d (status '%s'). "+
+			"It is created once approved; run 'terraform refresh' again later.", name, requestID, approval.Status),
+	)
+	resp.Diagnostics.Append(resp.State.Set(ctx, data)...)
+	return true
+}
+
+// triggerSync asks Cloud-iQ to pull in a pending subscription once ARM has confirmed it, so users
+// don't have to click 'Synchronize' in the portal. A successful sync is only triggered once. The
+// returned note describes the outcome for warnings, and is empty if no sync was attempted.
+func (r *AzureSubscriptionResource) triggerSync(ctx context.Context, private privateState, resp *resource.ReadResponse, azurePlanID int, name, guid string) string {
+	if guid == "" || guid == "pending" {
+		// ARM hasn't confirmed the subscription either, so there is nothing to sync yet
+		return ""
+	}
+	if triggered, _ := getPrivateKey(ctx, private, syncTriggeredKey); len(triggered) > 0 {
+		return "A Cloud-iQ sync was triggered on an earlier refresh."
+	}
+
+	err := r.client.TriggerCloudIQSync(ctx, azurePlanID)
+	tflog.Info(ctx, "Triggered Cloud-iQ sync for pending subscription", map[string]interface{}{
+		"name":            name,
+		"subscription_id": guid,
+		"azure_plan_id":   azurePlanID,
+		"error":           fmt.Sprint(err),
+	})
+	switch {
+	case err == nil:
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, syncTriggeredKey, []byte("true"))...)
+		return "A Cloud-iQ sync was triggered; run 'terraform refresh' again in a few minutes."
+	case errors.Is(err, client.ErrSyncNotSupported):
+		return "The Crayon API does not support triggering a sync, so it must be started from the portal."
+	}
+	return "Triggering a Cloud-iQ sync failed and will be retried on the next refresh: " + err.Error() + "."
+}
+
+// recoverSync handles subscriptions that ARM confirmed but Cloud-iQ never picked up. Once
+// sync_recovery_timeout has passed since the create, it reports the known subscription GUID so
+// the user can resolve the state manually. syncNote is the outcome of triggerSync.
+func (r *AzureSubscriptionResource) recoverSync(ctx context.Context, private privateState, resp *resource.ReadResponse, azurePlanID int, name, guid, syncNote string) {
+	timeout := r.settings.SyncRecoveryTimeout
+	if timeout == 0 || guid == "" || guid == "pending" {
+		// Disabled, or ARM hasn't confirmed the subscription either, so there is nothing to sync
+		return
+	}
+
+	raw, diags := getPrivateKey(ctx, private, createdAtKey)
+	var createdAt time.Time
+	var value string
+	if !diags.HasError() && len(raw) > 0 && json.Unmarshal(raw, &value) == nil {
+		createdAt, _ = time.Parse(time.RFC3339, value)
+	}
+	if createdAt.IsZero() {
+		// Created by an older provider version; start the clock now
+		now, _ := json.Marshal(time.Now().UTC().Format(time.RFC3339))
+		resp.Diagnostics.Append(resp.Private.SetKey(ctx, createdAtKey, now)...)
+		return
+	}
+
+	pending := time.Since(createdAt)
+	if pending < timeout {
+		return
+	}
+
+	resp.Diagnostics.AddWarning(
+		"Subscription Not Synced to Cloud-iQ",
+		fmt.Sprintf("Azure confirmed the subscription '%s' (subscription ID %s) %s ago, but it has still not "+
+			"appeared in Azure Plan %d in Cloud-iQ. %s\n\n"+
+			"If it still does not appear, click 'Synchronize' in the Cloud-iQ portal or contact Crayon support "+
+			"with the subscription ID above. If it appears under a different name, remove it from state with "+
+			"'terraform state rm' and import it with 'terraform import' using '%d:<cloud-iq id>'.",
+			name, guid, pending.Round(time.Minute), azurePlanID, syncNote, azurePlanID),
+	)
+}
+
+// protectsImported reports whether destroying the subscription must be refused because it was
+// imported, the provider protects imported subscriptions and the resource doesn't allow it anyway
+func (r *AzureSubscriptionResource) protectsImported(ctx context.Context, private privateState, allowCancelImported types.Bool) bool {
+	if r.client == nil || !r.settings.ProtectImportedSubscriptions || allowCancelImported.ValueBool() {
+		return false
+	}
+
+	raw, diags := private.GetKey(ctx, provenanceKey)
+	var provenance string
+	if diags.HasError() || len(raw) == 0 || json.Unmarshal(raw, &provenance) != nil {
+		// Unknown provenance, e.g. managed since before provenance was recorded
+		return false
+	}
+	return provenance == provenanceImported
+}
+
+// resolveUnknowns replaces the values an update left unknown with their prior state. Computed
+// attributes that are null in state, such as an auto_renew that doesn't apply, are planned as
+// unknown whenever anything else changes, and must be known again after the apply.
+func resolveUnknowns(data *AzureSubscriptionResourceModel, state AzureSubscriptionResourceModel) {
+	planned := reflect.ValueOf(data).Elem()
+	prior := reflect.ValueOf(state)
+	for i := 0; i < planned.NumField(); i++ {
+		if value, ok := planned.Field(i).Interface().(attr.Value); ok && value.IsUnknown() {
+			planned.Field(i).Set(prior.Field(i))
+		}
+	}
+}
+
+// privateState is the read side of the provider's private resource state
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}
+
+// privateStateWriter is the write side of the provider's private resource state
+type privateStateWriter interface {
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// clearedPrivateValue is the value of a cleared private state key. The framework only stores
+// valid JSON, so keys are cleared to null rather than to an empty value.
+var clearedPrivateValue = []byte("null")
+
+// clearPrivateKey clears a private state key
+func clearPrivateKey(ctx context.Context, private privateStateWriter, key string) diag.Diagnostics {
+	return private.SetKey(ctx, key, clearedPrivateValue)
+}
+
+// getPrivateKey returns the value of a private state key, or nil if it is unset or cleared
+func getPrivateKey(ctx context.Context, private privateState, key string) ([]byte, diag.Diagnostics) {
+	raw, diags := private.GetKey(ctx, key)
+	if bytes.Equal(raw, clearedPrivateValue) {
+		return nil, diags
+	}
+	return raw, diags
+}
+
+// deferredName returns a unique placeholder name to create a subscription under with defer_naming
+func deferredName() string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return "tf-deferred-" + hex.EncodeToString(suffix)
+}
+
+// applyDeferredName renames a subscription created under a placeholder name (defer_naming) to
+// its configured name, updating subscription on success. The placeholder is kept in private
+// state until the rename succeeds, so later refreshes retry it. It reports whether the
+// subscription still has the placeholder.
+func (r *AzureSubscriptionResource) applyDeferredName(ctx context.Context, private privateState, setPrivate privateStateWriter, diags *diag.Diagnostics, azurePlanID int, subscription *client.AzureSubscription, name string) bool {
+	raw, getDiags := getPrivateKey(ctx, private, deferredNameKey)
+	if getDiags.HasError() || len(raw) == 0 {
+		return false
+	}
+
+	if subscription.FriendlyName != name {
+		if _, err := r.client.RenameAzureSubscription(ctx, azurePlanID, subscription.ID, name); err != nil {
+			diags.AddWarning(
+				"Could Not Apply Deferred Name",
+				fmt.Sprintf("The subscription %d was created as '%s' but could not be renamed to '%s' yet: %v. "+
+					"The rename is retried on the next refresh.", subscription.ID, subscription.FriendlyName, name, err),
+			)
+			return true
+		}
+		tflog.Info(ctx, "Applied deferred subscription name", map[string]interface{}{
+			"id":          subscription.ID,
+			"placeholder": subscription.FriendlyName,
+			"name":        name,
+		})
+		subscription.FriendlyName = name
+	}
+
+	diags.Append(clearPrivateKey(ctx, setPrivate, deferredNameKey)...)
+	return false
+}
+
+// portalURL returns the Cloud-iQ portal link of a subscription, or null while its ID is unknown
+func (r *AzureSubscriptionResource) portalURL(azurePlanID, subscriptionID int) types.String {
+	if subscriptionID == 0 {
+		return types.StringNull()
+	}
+	return types.StringValue(r.settings.PortalURL(azurePlanID, subscriptionID))
+}
+
+// parseCancellationDate parses a cancellation_date, which must lie in the future.
+// A null date means cancelling immediately and returns the zero time.
+func parseCancellationDate(value types.String) (time.Time, error) {
+	if value.IsNull() || value.IsUnknown() {
+		return time.Time{}, nil
+	}
+
+	date, err := time.Parse("2006-01-02", value.ValueString())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cancellation_date must be in YYYY-MM-DD format, got %q", value.ValueString())
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if !date.After(today) {
+		return time.Time{}, fmt.Errorf("cancellation_date %s is not in the future", value.ValueString())
+	}
+	return date, nil
+}
+
+// cleanupTimeout bounds looking for and cancelling a subscription left by a failed create
+const cleanupTimeout = 2 * time.Minute
+
+// subscriptionIDsNamed returns the IDs of the subscriptions already named name, or nil if they
+// can't be listed
+func (r *AzureSubscriptionResource) subscriptionIDsNamed(ctx context.Context, planID int, name string) map[int]bool {
+	matches, err := r.client.FindAzureSubscriptionsByName(ctx, planID, name)
+	if err != nil {
+		tflog.Debug(ctx, "Could not list subscriptions with the name before creating", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil
+	}
+	ids := make(map[int]bool, len(matches))
+	for _, match := range matches {
+		ids[match.ID] = true
+	}
+	return ids
+}
+
+// cleanupFailedCreate looks for a subscription that a failed create may have left behind, since
+// Terraform won't track it. Subscriptions in existingIDs, those named name before the create, are
+// never considered. With the provider's cleanup_failed_creates set, a single new subscription is
+// cancelled; otherwise, and whenever it can't be told apart, the possible orphan is reported.
+// It gets cleanupTimeout of its own, since the create may have failed by running out of time, but
+// still ends when the provider is stopped.
+func (r *AzureSubscriptionResource) cleanupFailedCreate(ctx context.Context, diags *diag.Diagnostics, planID int, name string, existingIDs map[int]bool) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+	defer cancel()
+	stop := context.AfterFunc(r.settings.StopContext, cancel)
+	defer stop()
+
+	matches, err := r.client.FindAzureSubscriptionsByName(ctx, planID, name)
+	if err != nil {
+		tflog.Debug(ctx, "Could not look for a subscription left by the failed create", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	var orphans []int
+	for _, match := range matches {
+		if !existingIDs[match.ID] {
+			orphans = append(orphans, match.ID)
+		}
+	}
+	slices.Sort(orphans)
+
+	if len(orphans) == 0 {
+		diags.AddWarning(
+			"Subscription May Have Been Created",
+			fmt.Sprintf("The create request for '%s' failed without a definite answer, so Cloud-iQ may still create "+
+				"the subscription, and Terraform won't track it. Check Azure Plan %d in Cloud-iQ before applying "+
+				"again; if the subscription appears, import it with 'terraform import' or cancel it.", name, planID),
+		)
+		return
+	}
+	if len(orphans) > 1 {
+		diags.AddWarning(
+			"Subscription May Have Been Created",
+			fmt.Sprintf("The create request for '%s' failed, and Azure Plan %d has several subscriptions with that "+
+				"name that Terraform doesn't track (IDs %v), so the one it created can't be told apart and none was "+
+				"cancelled. Check them in Cloud-iQ, then import the one to manage with 'terraform import' or cancel it.",
+				name, planID, orphans),
+		)
+		return
+	}
+
+	orphan := orphans[0]
+	if !r.settings.CleanupFailedCreates || existingIDs == nil {
+		detail := fmt.Sprintf("The create request for '%s' failed, but a subscription with that name exists in "+
+			"Cloud-iQ as ID %d and isn't tracked by Terraform. Import it with 'terraform import <address> %d:%d', "+
+			"or cancel it in Cloud-iQ.", name, orphan, planID, orphan)
+		if r.settings.CleanupFailedCreates {
+			detail += " It wasn't cancelled, since the subscriptions with that name couldn't be listed before the " +
+				"create to make sure it is new."
+		} else {
+			detail += " Set the provider's cleanup_failed_creates to cancel such subscriptions automatically."
+		}
+		diags.AddWarning("Subscription Created Despite Error", detail)
+		return
+	}
+
+	tflog.Info(ctx, "Cancelling subscription left by the failed create", map[string]interface{}{
+		"azure_plan_id":   planID,
+		"subscription_id": orphan,
+	})
+	if err := r.client.CancelAzureSubscription(ctx, planID, orphan, time.Time{}); err != nil {
+		diags.AddWarning(
+			"Could Not Clean Up Subscription",
+			fmt.Sprintf("The create request for '%s' failed, but the subscription exists in Cloud-iQ as ID %d and "+
+				"could not be cancelled: %v. Cancel it in Cloud-iQ or import it with 'terraform import <address> %d:%d'.",
+				name, orphan, err, planID, orphan),
+		)
+		return
+	}
+	diags.AddWarning(
+		"Subscription Cleaned Up",
+		fmt.Sprintf("The create request for '%s' failed, but created subscription %d anyway. It was cancelled "+
+			"because cleanup_failed_creates is enabled.", name, orphan),
+	)
+}
+
+// pendingWarning reports that a subscription is still pending sync. With the provider's
+// suppress_pending_warnings set, it is only logged at debug level.
+func (r *AzureSubscriptionResource) pendingWarning(ctx context.Context, diags *diag.Diagnostics, summary, detail string) {
+	if r.settings.SuppressPendingWarnings {
+		tflog.Debug(ctx, summary, map[string]interface{}{
+			"detail": detail,
+		})
+		return
+	}
+	diags.AddWarning(summary, detail)
+}
+
+// stringOrNull returns a null string for empty API values
+func stringOrNull(value string) types.String {
+	if value == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(value)
+}
+
+// setBillingScope maps the billing hierarchy of a subscription, leaving the attributes that
+// don't apply to its billing agreement null
+func setBillingScope(data *AzureSubscriptionResourceModel, scope *client.BillingScope) {
+	data.BillingAgreementType = stringOrNull(scope.Agreement())
+	data.BillingScope = stringOrNull(scope.Scope())
+	data.EnrollmentAccount = stringOrNull(scope.EnrollmentAccount())
+}
+
+// setRefundWindow maps whether cancelling the subscription is refunded, leaving both attributes
+// null for offers without a refund window
+func setRefundWindow(data *AzureSubscriptionResourceModel, subscription *client.AzureSubscription) {
+	window, ok := subscription.RefundWindow(time.Now())
+	if !ok {
+		data.RefundEligible = types.BoolNull()
+		data.CancellationWindowEnds = types.StringNull()
+		return
+	}
+	data.RefundEligible = types.BoolValue(window.Eligible)
+	data.CancellationWindowEnds = stringOrNull(window.Ends)
+}
+
+// refundWindowWarning warns when a subscription is about to be cancelled without a refund. The
+// window end is checked against the current time, since it may have passed since the last refresh.
+func refundWindowWarning(diags *diag.Diagnostics, refundEligible types.Bool, windowEnds types.String) {
+	detail := ""
+	if end, err := time.Parse(time.RFC3339, windowEnds.ValueString()); err == nil && !time.Now().Before(end) {
+		detail = fmt.Sprintf("The refund window of the subscription closed at %s, so cancelling it won't be refunded.", windowEnds.ValueString())
+	} else if !refundEligible.IsNull() && !refundEligible.IsUnknown() && !refundEligible.ValueBool() {
+		detail = "Cloud-iQ reports that cancelling the subscription won't be refunded."
+	}
+	if detail != "" {
+		diags.AddAttributeWarning(path.Root("refund_eligible"), "Cancelling Outside the Refund Window", detail)
+	}
+}
+
+// contactOrNull returns a null string for contacts Cloud-iQ doesn't report
+func contactOrNull(contact *client.Contact) types.String {
+	return stringOrNull(contact.String())
+}
+
+// billingTimestamp converts a billing period date reported by Cloud-iQ to RFC 3339, or null
+// if it is absent or in an unknown format
+func billingTimestamp(value string) types.String {
+	if timestamp, ok := client.BillingTimestamp(value); ok {
+		return types.StringValue(timestamp)
+	}
+	return types.StringNull()
+}
+
+// lastModified returns when a subscription last changed as an RFC 3339 timestamp, or null if
+// Cloud-iQ doesn't report it
+func lastModified(subscription *client.AzureSubscription) types.String {
+	if timestamp, ok := subscription.LastModifiedTimestamp(); ok {
+		return types.StringValue(timestamp)
+	}
+	return types.StringNull()
+}
+
+// desiredStatusFromAPI maps a Cloud-iQ subscription status onto a desired_status value
+func desiredStatusFromAPI(status string) (string, bool) {
+	switch strings.ToLower(status) {
+	case "active":
+		return "active", true
+	case "cancelled", "canceled":
+		return "cancelled", true
+	case "suspended", "disabled":
+		return "suspended", true
+	}
+	return "", false
+}
+
+// statusTransitions lists the valid current -> desired status transitions
+var statusTransitions = map[string][]string{
+	"active":    {"cancelled", "suspended"},
+	"suspended": {"active", "cancelled"},
+	"cancelled": {"active"},
+}
+
+// validateStatusTransition returns an error if the subscription cannot move from current to desired
+func validateStatusTransition(current, desired string) error {
+	if current == "" || current == desired {
+		return nil
+	}
+	for _, allowed := range statusTransitions[current] {
+		if allowed == desired {
+			return nil
+		}
+	}
+	return fmt.Errorf("a %s subscription cannot be changed to %s", current, desired)
+}
+
+// transitionStatus issues the client call that moves a subscription from its current to the desired status
+func (r *AzureSubscriptionResource) transitionStatus(ctx context.Context, azurePlanID, subscriptionID int, current, desired string) error {
+	tflog.Debug(ctx, "Changing Azure subscription status", map[string]interface{}{
+		"id":             subscriptionID,
+		"current_status": current,
+		"desired_status": desired,
+	})
+
+	if err := validateStatusTransition(current, desired); err != nil {
+		return err
+	}
+
+	switch {
+	case current == "cancelled" && desired == "active":
+		err := r.client.ReactivateAzureSubscription(ctx, azurePlanID, subscriptionID)
+		if errors.Is(err, client.ErrReactivationWindowExpired) {
+			return fmt.Errorf("the subscription can no longer be reactivated because its grace period has expired; "+
+				"create a new subscription instead: %w", err)
+		}
+		return err
+	case desired == "active":
+		return r.client.EnableAzureSubscription(ctx, azurePlanID, subscriptionID)
+	case desired == "suspended":
+		return r.client.SuspendAzureSubscription(ctx, azurePlanID, subscriptionID)
+	case desired == "cancelled":
+		return r.client.CancelAzureSubscription(ctx, azurePlanID, subscriptionID, time.Time{})
+	}
+	return fmt.Errorf("unsupported desired status %q", desired)
+}
+
+// tagsSubRead refreshes the environment, the project, the external reference and, if managed, the tags of data. The tags
+// are only fetched if they weren't expanded with the subscription.
+func (r *AzureSubscriptionResource) tagsSubRead(ctx context.Context, azurePlanID int, subscription *client.AzureSubscription, data *AzureSubscriptionResourceModel) subRead {
+	// Decided up front, as copying data while other sub-reads run would race with their writes
+	managesTags := r.managesTags(*data)
+	return subRead{
+		name: "tags",
+		run: func() error {
+			// Only track the environment tag when it is managed by this resource
+			if !data.Environment.IsNull() {
+				environment, err := r.readEnvironment(ctx, azurePlanID, subscription)
+				if err != nil {
+					return err
+				}
+				data.Environment = environment
+			}
+			if !data.Project.IsNull() {
+				project, err := r.readTag(ctx, azurePlanID, subscription, client.ProjectTagKey)
+				if err != nil {
+					return err
+				}
+				data.Project = project
+			}
+			if !data.ExternalReference.IsNull() {
+				reference, err := r.readTag(ctx, azurePlanID, subscription, client.ExternalReferenceTagKey)
+				if err != nil {
+					return err
+				}
+				data.ExternalReference = reference
+			}
+			if managesTags {
+				tags, err := r.subscriptionTags(ctx, azurePlanID, subscription)
+				if err != nil {
+					return err
+				}
+				readTags(data, tags, r.settings.DefaultTags)
+			}
+			return nil
+		},
+	}
+}
+
+// spendingCapSubRead refreshes the spending cap attributes of data from ARM. They are null if
+// the Azure identity can't read the subscription or its offer has no spending limit.
+func (r *AzureSubscriptionResource) spendingCapSubRead(ctx context.Context, subscriptionGUID string, data *AzureSubscriptionResourceModel) subRead {
+	return subRead{
+		name:     "the spending cap",
+		optional: true,
+		run: func() error {
+			armSubscription, err := r.client.GetARMSubscription(ctx, subscriptionGUID)
+			if err != nil && !errors.Is(err, client.ErrARMPermissionDenied) {
+				return err
+			}
+
+			data.SpendingCapEnabled = types.BoolNull()
+			data.SpendingCapReached = types.BoolNull()
+			if err != nil {
+				tflog.Debug(ctx, "Azure identity can't read the subscription, leaving the spending cap unset", map[string]interface{}{
+					"subscription_id": subscriptionGUID,
+				})
+				return nil
+			}
+
+			enabled, reached, applicable := armSubscription.SpendingCap()
+			if !applicable {
+				return nil
+			}
+			data.SpendingCapEnabled = types.BoolValue(enabled)
+			if enabled {
+				data.SpendingCapReached = types.BoolValue(reached)
+			}
+			return nil
+		},
+	}
+}
+
+// readEnvironment returns the environment tag of a subscription, or null if it is not set.
+// Tags already expanded on the subscription are used; otherwise they are fetched.
+func (r *AzureSubscriptionResource) readEnvironment(ctx context.Context, azurePlanID int, subscription *client.AzureSubscription) (types.String, error) {
+	return r.readTag(ctx, azurePlanID, subscription, client.EnvironmentTagKey)
+}
+
+// readTag returns a single tag of a subscription, or null if it is not set
+func (r *AzureSubscriptionResource) readTag(ctx context.Context, azurePlanID int, subscription *client.AzureSubscription, key string) (types.String, error) {
+	tags, err := r.subscriptionTags(ctx, azurePlanID, subscription)
+	if err != nil {
+		return types.StringNull(), err
+	}
+	if value, ok := client.LookupTag(tags, key); ok && value != "" {
+		return types.StringValue(value), nil
+	}
+	return types.StringNull(), nil
+}
+
+// subscriptionTags returns the tags of a subscription, fetching them if they weren't expanded
+func (r *AzureSubscriptionResource) subscriptionTags(ctx context.Context, azurePlanID int, subscription *client.AzureSubscription) (map[string]string, error) {
+	if subscription.Tags == nil {
+		tags, err := r.client.GetAzureSubscriptionTags(ctx, azurePlanID, subscription.ID)
+		if err != nil {
+			return nil, err
+		}
+		subscription.Tags = tags
+	}
+	return subscription.Tags, nil
+}
+
+// tracksTags reports whether Read needs the subscription's tags: for the environment, the
+// project, the external reference or the managed tags
+func (r *AzureSubscriptionResource) tracksTags(data AzureSubscriptionResourceModel) bool {
+	return !data.Environment.IsNull() || !data.Project.IsNull() || !data.ExternalReference.IsNull() || r.managesTags(data)
+}
+
+// managesTags reports whether the resource tracks any tags besides the environment, project and external reference
+func (r *AzureSubscriptionResource) managesTags(data AzureSubscriptionResourceModel) bool {
+	return !data.Tags.IsNull() || !data.TagsAll.IsNull() || len(r.settings.DefaultTags) > 0
+}
+
+// mergedTags returns the provider's default_tags overlaid with the resource's tags
+func (r *AzureSubscriptionResource) mergedTags(ctx context.Context, tags types.Map) (map[string]string, diag.Diagnostics) {
+	var resourceTags map[string]string
+	var diags diag.Diagnostics
+	if !tags.IsNull() && !tags.IsUnknown() {
+		diags = tags.ElementsAs(ctx, &resourceTags, false)
+	}
+	return client.MergeTags(r.settings.DefaultTags, resourceTags), diags
+}
+
+// readTags refreshes tags and tags_all from the subscription's actual tags. Only managed keys
+// are tracked, so default tags echoed back by Cloud-iQ don't show up as a diff on tags. Keys
+// keep the casing they were configured with, even if Cloud-iQ reports them in another.
+func readTags(data *AzureSubscriptionResourceModel, actual, defaults map[string]string) {
+	managed := map[string]bool{}
+	for key := range defaults {
+		managed[key] = true
+	}
+	for key := range data.TagsAll.Elements() {
+		managed[key] = true
+	}
+
+	if !data.Tags.IsNull() {
+		tags := map[string]string{}
+		for key := range data.Tags.Elements() {
+			managed[key] = true
+			if value, ok := client.LookupTag(actual, key); ok {
+				tags[key] = value
+			}
+		}
+		data.Tags = types.MapValueMust(types.StringType, stringMapValues(tags))
+	}
+
+	tagsAll := map[string]string{}
+	for key := range managed {
+		if value, ok := client.LookupTag(actual, key); ok {
+			tagsAll[key] = value
+		}
+	}
+	data.TagsAll = tagsMapValue(tagsAll)
+}
+
+// tagsMapValue converts tags into a map value, null when there are none
+func tagsMapValue(tags map[string]string) types.Map {
+	if len(tags) == 0 {
+		return types.MapNull(types.StringType)
+	}
+	return types.MapValueMust(types.StringType, stringMapValues(tags))
+}
+
+// stringMapValues converts a Go string map into framework values
+func stringMapValues(m map[string]string) map[string]attr.Value {
+	values := make(map[string]attr.Value, len(m))
+	for key, value := range m {
+		values[key] = types.StringValue(value)
+	}
+	return values
 }
 
 func splitImportID(id string) []string {
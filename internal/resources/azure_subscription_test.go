@@ -0,0 +1,2143 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestAzureSubscriptionResource_CreateAndRead(t *testing.T) {
+	fake := newFakeClient()
+	h := newHarness(t, fake)
+
+	planned := h.planned("app-prod")
+	planned.Tags = types.MapValueMust(types.StringType, stringMapValues(map[string]string{"owner": "platform"}))
+	if resp := h.create(planned); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+
+	data := h.model()
+	if data.ID.ValueString() != "1001" {
+		t.Errorf("id = %q, want 1001", data.ID.ValueString())
+	}
+	if data.Status.ValueString() != "active" {
+		t.Errorf("status = %q, want active", data.Status.ValueString())
+	}
+	if want := "https://cloudiq.test/subscriptions/azure/873834/1001"; data.PortalURL.ValueString() != want {
+		t.Errorf("portal_url = %q, want %q", data.PortalURL.ValueString(), want)
+	}
+	if got := fake.tags[1001]["owner"]; got != "platform" {
+		t.Errorf("created with owner tag %q, want platform", got)
+	}
+
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	data = h.model()
+	if data.Name.ValueString() != "app-prod" {
+		t.Errorf("name = %q, want app-prod", data.Name.ValueString())
+	}
+	if tags := data.TagsAll.Elements(); len(tags) != 1 {
+		t.Errorf("tags_all = %v, want the owner tag", tags)
+	}
+}
+
+func TestAzureSubscriptionResource_CreateTagged(t *testing.T) {
+	tests := map[string]struct {
+		untaggedCreates bool
+		wantTagCalls    int
+	}{
+		"inline":   {},
+		"fallback": {untaggedCreates: true, wantTagCalls: 1},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake := newFakeClient()
+			fake.untaggedCreates = test.untaggedCreates
+			h := newHarness(t, fake)
+
+			planned := h.planned("app-prod")
+			planned.Tags = types.MapValueMust(types.StringType, stringMapValues(map[string]string{"owner": "platform"}))
+			planned.Environment = types.StringValue("prod")
+			if resp := h.create(planned); resp.Diagnostics.HasError() {
+				t.Fatalf("create: %s", summaries(resp.Diagnostics))
+			}
+			if got := fake.called("UpdateAzureSubscriptionTags"); got != test.wantTagCalls {
+				t.Errorf("tagged %d times after the create, want %d", got, test.wantTagCalls)
+			}
+			want := map[string]string{"owner": "platform", client.EnvironmentTagKey: "prod"}
+			if got := fake.tags[1001]; !reflect.DeepEqual(got, want) {
+				t.Errorf("tags = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestAzureSubscriptionResource_PortalURLWhilePending(t *testing.T) {
+	fake := newFakeClient()
+	fake.async = true
+	h := newHarness(t, fake)
+	if resp := h.create(h.planned("app-async")); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+	if got := h.model().PortalURL; !got.IsNull() {
+		t.Errorf("portal_url = %v while the Crayon ID is unknown, want null", got)
+	}
+
+	fake.sync("app-async")
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	data := h.model()
+	if want := "https://cloudiq.test/subscriptions/azure/873834/" + data.ID.ValueString(); data.PortalURL.ValueString() != want {
+		t.Errorf("portal_url = %q once synced, want %q", data.PortalURL.ValueString(), want)
+	}
+}
+
+func TestAzureSubscriptionResource_RetryAfterFailedCreate(t *testing.T) {
+	c, err := client.NewClient(client.ClientConfig{MockMode: true, OrganizationID: 4051878})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newHarness(t, nil)
+	var configureResp resource.ConfigureResponse
+	h.resource.Configure(h.ctx, resource.ConfigureRequest{ProviderData: c}, &configureResp)
+	if configureResp.Diagnostics.HasError() {
+		t.Fatalf("configure: %s", summaries(configureResp.Diagnostics))
+	}
+
+	// The mock rejects creates under Azure Plan 0
+	planned := h.planned("app-prod")
+	planned.AzurePlanID = types.Int64Value(0)
+	if resp := h.create(planned); !resp.Diagnostics.HasError() {
+		t.Fatal("create under Azure Plan 0 succeeded, want it to fail")
+	}
+	if resp := h.create(planned); hasSummary(resp.Diagnostics, "Duplicate Subscription Name") {
+		t.Errorf("retrying the failed create: %s, want the name released", summaries(resp.Diagnostics))
+	}
+
+	if resp := h.create(h.planned("App-Prod")); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+	if resp := h.create(h.planned("app-prod")); !hasSummary(resp.Diagnostics, "Duplicate Subscription Name") {
+		t.Errorf("second create of a name differing in case: %s, want Duplicate Subscription Name", summaries(resp.Diagnostics))
+	}
+}
+
+func TestAzureSubscriptionResource_MockModeLifecycle(t *testing.T) {
+	c, err := client.NewClient(client.ClientConfig{MockMode: true, OrganizationID: 4051878})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Configure replaces the harness's client with the mock-mode one, as the provider would
+	h := newHarness(t, nil)
+	var configureResp resource.ConfigureResponse
+	h.resource.Configure(h.ctx, resource.ConfigureRequest{ProviderData: c}, &configureResp)
+	if configureResp.Diagnostics.HasError() {
+		t.Fatalf("configure: %s", summaries(configureResp.Diagnostics))
+	}
+
+	planned := h.planned("app-mock")
+	planned.Tags = types.MapValueMust(types.StringType, stringMapValues(map[string]string{"owner": "platform"}))
+	if resp := h.create(planned); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+	if id := h.model().ID.ValueString(); id != "1001" {
+		t.Errorf("id = %q, want 1001", id)
+	}
+
+	planned = h.model()
+	planned.Name = types.StringValue("app-mock-renamed")
+	planned.Tags = types.MapValueMust(types.StringType, stringMapValues(map[string]string{"owner": "data"}))
+	if resp := h.update(planned); resp.Diagnostics.HasError() {
+		t.Fatalf("update: %s", summaries(resp.Diagnostics))
+	}
+
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	data := h.model()
+	if data.Name.ValueString() != "app-mock-renamed" {
+		t.Errorf("name = %q, want app-mock-renamed", data.Name.ValueString())
+	}
+	if owner := data.TagsAll.Elements()["owner"]; !types.StringValue("data").Equal(owner) {
+		t.Errorf("tags_all owner = %v, want data", owner)
+	}
+
+	if resp := h.delete(); resp.Diagnostics.HasError() {
+		t.Fatalf("delete: %s", summaries(resp.Diagnostics))
+	}
+	sub, err := c.GetAzureSubscription(h.ctx, testPlanID, 1001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.Status != "Cancelled" {
+		t.Errorf("status after delete = %q, want Cancelled", sub.Status)
+	}
+}
+
+func TestAzureSubscriptionResource_UpdateTagsAtOnce(t *testing.T) {
+	fake := newFakeClient()
+	h := newHarness(t, fake)
+
+	planned := h.planned("app-prod")
+	planned.Tags = types.MapValueMust(types.StringType, stringMapValues(map[string]string{"owner": "platform", "team": "core"}))
+	planned.Environment = types.StringValue("dev")
+	planned.Project = types.StringValue("webshop")
+	if resp := h.create(planned); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+
+	planned = h.model()
+	planned.Tags = types.MapValueMust(types.StringType, stringMapValues(map[string]string{"owner": "data"}))
+	planned.Environment = types.StringValue("prod")
+	planned.Project = types.StringNull()
+	planned.ExternalReference = types.StringValue("CHG-1234")
+	if resp := h.update(planned); resp.Diagnostics.HasError() {
+		t.Fatalf("update: %s", summaries(resp.Diagnostics))
+	}
+
+	if got := fake.called("UpdateAzureSubscriptionTags"); got != 1 {
+		t.Errorf("updated tags %d times, want all changes in one update", got)
+	}
+	want := map[string]string{"owner": "data", client.EnvironmentTagKey: "prod", client.ExternalReferenceTagKey: "CHG-1234"}
+	if got := fake.tags[1001]; !reflect.DeepEqual(got, want) {
+		t.Errorf("tags = %v, want %v", got, want)
+	}
+}
+
+// TestAzureSubscriptionResource_DefaultTags plans and applies the provider's default_tags merged
+// under the resource's tags, then detects drift of the managed tags only
+func TestAzureSubscriptionResource_DefaultTags(t *testing.T) {
+	fake := newFakeClient()
+	h := newHarness(t, fake)
+	h.resource.settings.DefaultTags = map[string]string{"cost-center": "1234", "owner": "platform"}
+
+	planned := h.planned("app-prod")
+	planned.Tags = types.MapValueMust(types.StringType, stringMapValues(map[string]string{"owner": "data"}))
+	planned, resp := h.modifyPlan(planned)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("plan: %s", summaries(resp.Diagnostics))
+	}
+	want := types.MapValueMust(types.StringType, stringMapValues(map[string]string{"cost-center": "1234", "owner": "data"}))
+	if !planned.TagsAll.Equal(want) {
+		t.Errorf("planned tags_all = %v, want %v with the resource's owner winning", planned.TagsAll, want)
+	}
+
+	if resp := h.create(planned); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+	if got, want := fake.tags[1001], map[string]string{"cost-center": "1234", "owner": "data"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("created with tags %v, want %v", got, want)
+	}
+
+	// Drift on a managed tag shows up; tags added outside Terraform are ignored
+	fake.tags[1001]["owner"] = "finance"
+	fake.tags[1001]["created-by"] = "portal"
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	data := h.model()
+	if owner := data.Tags.Elements()["owner"]; !types.StringValue("finance").Equal(owner) {
+		t.Errorf("tags owner = %v, want the drifted finance", owner)
+	}
+	want = types.MapValueMust(types.StringType, stringMapValues(map[string]string{"cost-center": "1234", "owner": "finance"}))
+	if !data.TagsAll.Equal(want) {
+		t.Errorf("tags_all = %v, want %v", data.TagsAll, want)
+	}
+
+	// A change to default_tags alone plans an update of tags_all
+	h.resource.settings.DefaultTags = map[string]string{"cost-center": "5678"}
+	planned = h.model()
+	planned.Tags = types.MapValueMust(types.StringType, stringMapValues(map[string]string{"owner": "data"}))
+	planned, resp = h.modifyPlan(planned)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("plan: %s", summaries(resp.Diagnostics))
+	}
+	want = types.MapValueMust(types.StringType, stringMapValues(map[string]string{"cost-center": "5678", "owner": "data"}))
+	if !planned.TagsAll.Equal(want) {
+		t.Errorf("planned tags_all = %v, want %v", planned.TagsAll, want)
+	}
+}
+
+// TestAzureSubscriptionResource_TagKeyCasing reads tags back under the configured casing of their
+// keys, so Cloud-iQ changing the casing isn't reported as drift
+func TestAzureSubscriptionResource_TagKeyCasing(t *testing.T) {
+	fake := newFakeClient()
+	h := newHarness(t, fake)
+
+	planned := h.planned("app-prod")
+	planned.Tags = types.MapValueMust(types.StringType, stringMapValues(map[string]string{"owner": "platform"}))
+	if resp := h.create(planned); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+	fake.tags[1001] = map[string]string{"Owner": "platform"}
+
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	data := h.model()
+	if !planned.Tags.Equal(data.Tags) {
+		t.Errorf("tags = %v, want %v", data.Tags, planned.Tags)
+	}
+	if !planned.Tags.Equal(data.TagsAll) {
+		t.Errorf("tags_all = %v, want %v", data.TagsAll, planned.Tags)
+	}
+}
+
+func TestAzureSubscriptionResource_Environment(t *testing.T) {
+	fake := newFakeClient()
+	h := newHarness(t, fake)
+
+	planned := h.planned("app-prod")
+	planned.Environment = types.StringValue("staging")
+	if resp := h.create(planned); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+	if got := fake.tags[1001][client.EnvironmentTagKey]; got != "staging" {
+		t.Errorf("created with environment tag %q, want staging", got)
+	}
+
+	// A change made in Cloud-iQ shows up as drift
+	fake.tags[1001][client.EnvironmentTagKey] = "prod"
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	if got := h.model().Environment.ValueString(); got != "prod" {
+		t.Errorf("environment = %q, want the changed prod", got)
+	}
+
+	// So does removing the tag
+	delete(fake.tags[1001], client.EnvironmentTagKey)
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	if got := h.model().Environment; !got.IsNull() {
+		t.Errorf("environment = %v, want null once the tag is removed", got)
+	}
+
+	planned = h.model()
+	planned.Environment = types.StringValue("dev")
+	if resp := h.update(planned); resp.Diagnostics.HasError() {
+		t.Fatalf("update: %s", summaries(resp.Diagnostics))
+	}
+	if got := fake.tags[1001][client.EnvironmentTagKey]; got != "dev" {
+		t.Errorf("updated environment tag to %q, want dev", got)
+	}
+}
+
+func TestAzureSubscriptionResource_Reactivate(t *testing.T) {
+	fake := newFakeClient()
+	h := newHarness(t, fake)
+
+	planned := h.planned("app-prod")
+	planned.DesiredStatus = types.StringValue("active")
+	if resp := h.create(planned); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+
+	// Cancelled outside Terraform, which shows up as drift of desired_status
+	fake.subscriptions[1001].Status = "Cancelled"
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	if got := h.model().DesiredStatus.ValueString(); got != "cancelled" {
+		t.Errorf("desired_status = %q, want the actual cancelled", got)
+	}
+
+	planned = h.model()
+	planned.DesiredStatus = types.StringValue("active")
+	if resp := h.update(planned); resp.Diagnostics.HasError() {
+		t.Fatalf("update: %s", summaries(resp.Diagnostics))
+	}
+	if got := fake.called("ReactivateAzureSubscription"); got != 1 {
+		t.Errorf("reactivated %d times, want once", got)
+	}
+	if got := fake.called("EnableAzureSubscription"); got != 0 {
+		t.Errorf("enabled %d times, want the cancelled subscription reactivated instead", got)
+	}
+	if got := fake.subscription(1001).Status; got != "active" {
+		t.Errorf("status = %q, want active", got)
+	}
+}
+
+func TestAzureSubscriptionResource_ReactivationWindowExpired(t *testing.T) {
+	fake := newFakeClient()
+	h := newHarness(t, fake)
+
+	planned := h.planned("app-prod")
+	planned.DesiredStatus = types.StringValue("active")
+	if resp := h.create(planned); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+	fake.subscriptions[1001].Status = "Cancelled"
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+
+	fake.errs["ReactivateAzureSubscription"] = client.ErrReactivationWindowExpired
+	planned = h.model()
+	planned.DesiredStatus = types.StringValue("active")
+	resp := h.update(planned)
+	if !hasSummary(resp.Diagnostics, "Error Updating Azure Subscription") ||
+		!strings.Contains(summaries(resp.Diagnostics), "create a new subscription instead") {
+		t.Errorf("update: %s, want the expired grace period explained", summaries(resp.Diagnostics))
+	}
+}
+
+func TestAzureSubscriptionResource_StatusTransitions(t *testing.T) {
+	tests := map[string]struct {
+		current  string
+		desired  string
+		wantCall string
+	}{
+		"active to suspended":    {current: "Active", desired: "suspended", wantCall: "SuspendAzureSubscription"},
+		"active to cancelled":    {current: "Active", desired: "cancelled", wantCall: "CancelAzureSubscription"},
+		"suspended to active":    {current: "Suspended", desired: "active", wantCall: "EnableAzureSubscription"},
+		"suspended to cancelled": {current: "Suspended", desired: "cancelled", wantCall: "CancelAzureSubscription"},
+		"cancelled to active":    {current: "Cancelled", desired: "active", wantCall: "ReactivateAzureSubscription"},
+		"cancelled to suspended": {current: "Cancelled", desired: "suspended"},
+	}
+	transitions := []string{"SuspendAzureSubscription", "CancelAzureSubscription", "EnableAzureSubscription", "ReactivateAzureSubscription"}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake := newFakeClient()
+			h := newHarness(t, fake)
+			if resp := h.create(h.planned("app-prod")); resp.Diagnostics.HasError() {
+				t.Fatalf("create: %s", summaries(resp.Diagnostics))
+			}
+			fake.subscriptions[1001].Status = test.current
+			if resp := h.read(); resp.Diagnostics.HasError() {
+				t.Fatalf("read: %s", summaries(resp.Diagnostics))
+			}
+
+			planned := h.model()
+			planned.DesiredStatus = types.StringValue(test.desired)
+			_, planResp := h.modifyPlan(planned)
+			updateResp := h.update(planned)
+			if test.wantCall == "" {
+				if !hasSummary(planResp.Diagnostics, "Invalid Status Transition") {
+					t.Errorf("plan = %s, want the transition rejected", summaries(planResp.Diagnostics))
+				}
+				if !updateResp.Diagnostics.HasError() {
+					t.Error("update succeeded, want the transition rejected")
+				}
+			} else {
+				if planResp.Diagnostics.HasError() {
+					t.Errorf("plan: %s", summaries(planResp.Diagnostics))
+				}
+				if updateResp.Diagnostics.HasError() {
+					t.Fatalf("update: %s", summaries(updateResp.Diagnostics))
+				}
+				if got, _ := desiredStatusFromAPI(fake.subscription(1001).Status); got != test.desired {
+					t.Errorf("status = %q, want %s", fake.subscription(1001).Status, test.desired)
+				}
+			}
+			for _, method := range transitions {
+				want := 0
+				if method == test.wantCall {
+					want = 1
+				}
+				if got := fake.called(method); got != want {
+					t.Errorf("called %s %d times, want %d", method, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateStatusTransition(t *testing.T) {
+	for _, current := range []string{"", "active", "suspended", "cancelled"} {
+		for _, desired := range []string{"active", "suspended", "cancelled"} {
+			wantErr := current == "cancelled" && desired == "suspended"
+			if err := validateStatusTransition(current, desired); (err != nil) != wantErr {
+				t.Errorf("validateStatusTransition(%q, %q) = %v, want an error: %v", current, desired, err, wantErr)
+			}
+		}
+	}
+}
+
+func TestDesiredStatusFromAPI(t *testing.T) {
+	tests := map[string]struct {
+		want   string
+		wantOK bool
+	}{
+		"Active":       {want: "active", wantOK: true},
+		"Cancelled":    {want: "cancelled", wantOK: true},
+		"canceled":     {want: "cancelled", wantOK: true},
+		"Suspended":    {want: "suspended", wantOK: true},
+		"Disabled":     {want: "suspended", wantOK: true},
+		"Provisioning": {},
+		"":             {},
+	}
+	for status, test := range tests {
+		got, ok := desiredStatusFromAPI(status)
+		if got != test.want || ok != test.wantOK {
+			t.Errorf("desiredStatusFromAPI(%q) = %q, %v; want %q, %v", status, got, ok, test.want, test.wantOK)
+		}
+	}
+}
+
+func TestAzureSubscriptionResource_ReadExpandsTags(t *testing.T) {
+	t.Run("untagged", func(t *testing.T) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+		if resp := h.create(h.planned("app-prod")); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		if resp := h.read(); resp.Diagnostics.HasError() {
+			t.Fatalf("read: %s", summaries(resp.Diagnostics))
+		}
+		if len(fake.expanded) != 0 || fake.called("GetAzureSubscriptionTags") != 0 {
+			t.Errorf("expanded %v and read tags %d times, want no tags read", fake.expanded, fake.called("GetAzureSubscriptionTags"))
+		}
+	})
+
+	for _, ignoreExpand := range []bool{false, true} {
+		t.Run(fmt.Sprintf("tagged, expand ignored %v", ignoreExpand), func(t *testing.T) {
+			fake := newFakeClient()
+			fake.ignoreExpand = ignoreExpand
+			h := newHarness(t, fake)
+			planned := h.planned("app-prod")
+			planned.Tags = types.MapValueMust(types.StringType, stringMapValues(map[string]string{"owner": "platform"}))
+			if resp := h.create(planned); resp.Diagnostics.HasError() {
+				t.Fatalf("create: %s", summaries(resp.Diagnostics))
+			}
+			tagReads := fake.called("GetAzureSubscriptionTags")
+
+			fake.tags[1001]["owner"] = "data"
+			if resp := h.read(); resp.Diagnostics.HasError() {
+				t.Fatalf("read: %s", summaries(resp.Diagnostics))
+			}
+			if len(fake.expanded) != 1 || fake.expanded[0] != "tags" {
+				t.Errorf("expanded %v, want tags", fake.expanded)
+			}
+			// The tags are only read separately when the expansion didn't include them
+			want := 0
+			if ignoreExpand {
+				want = 1
+			}
+			if got := fake.called("GetAzureSubscriptionTags") - tagReads; got != want {
+				t.Errorf("read tags separately %d times, want %d", got, want)
+			}
+			if owner := h.model().Tags.Elements()["owner"]; !types.StringValue("data").Equal(owner) {
+				t.Errorf("owner = %v, want data", owner)
+			}
+		})
+	}
+}
+
+func TestAzureSubscriptionResource_BillingAccount(t *testing.T) {
+	fake := newFakeClient()
+	h := newHarness(t, fake)
+	if resp := h.create(h.planned("app-prod")); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+	if got := h.model().BillingAccountID; !got.IsNull() {
+		t.Errorf("billing_account_id = %v, want null while not reported", got)
+	}
+
+	fake.subscriptions[1001].BillingAccountID = "payer-7"
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	if got := h.model().BillingAccountID.ValueString(); got != "payer-7" {
+		t.Errorf("billing_account_id = %q, want payer-7", got)
+	}
+}
+
+func TestCreateRequest(t *testing.T) {
+	h := newHarness(t, newFakeClient())
+	planned := h.planned("app-prod")
+	planned.ExtraFields = types.MapValueMust(types.StringType, stringMapValues(map[string]string{"reference": "PO-1234"}))
+
+	req, diags := createRequest(context.Background(), planned)
+	if diags.HasError() {
+		t.Fatalf("createRequest: %s", summaries(diags))
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"app-prod","reference":"PO-1234"}`; string(body) != want {
+		t.Errorf("body = %s, want %s", body, want)
+	}
+
+	req, _ = createRequest(context.Background(), h.planned("app-prod"))
+	if req.ExtraFields != nil {
+		t.Errorf("ExtraFields = %v without extra_create_fields, want none", req.ExtraFields)
+	}
+}
+
+func TestAzureSubscriptionResource_NotificationWebhook(t *testing.T) {
+	t.Run("created synchronously", func(t *testing.T) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+		planned := h.planned("app-prod")
+		planned.Webhook = types.StringValue("https://hooks.example.com/crayon")
+		if resp := h.create(planned); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		if resp := h.read(); resp.Diagnostics.HasError() {
+			t.Fatalf("read: %s", summaries(resp.Diagnostics))
+		}
+		if got := fake.called("NotifySubscriptionResolved"); got != 1 {
+			t.Errorf("notified %d times, want once on create", got)
+		}
+	})
+
+	t.Run("resolved by a read", func(t *testing.T) {
+		fake := newFakeClient()
+		fake.async = true
+		h := newHarness(t, fake)
+		planned := h.planned("app-async")
+		planned.Webhook = types.StringValue("https://hooks.example.com/crayon")
+		if resp := h.create(planned); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		if got := fake.called("NotifySubscriptionResolved"); got != 0 {
+			t.Errorf("notified %d times while pending, want never", got)
+		}
+
+		// A failing webhook doesn't fail the read
+		fake.sync("app-async")
+		fake.errs["NotifySubscriptionResolved"] = errors.New("webhook request failed with status 502")
+		if resp := h.read(); len(resp.Diagnostics) != 0 {
+			t.Fatalf("resolving read: %s", summaries(resp.Diagnostics))
+		}
+		if resp := h.read(); resp.Diagnostics.HasError() {
+			t.Fatalf("read: %s", summaries(resp.Diagnostics))
+		}
+		if got := fake.called("NotifySubscriptionResolved"); got != 1 {
+			t.Errorf("notified %d times, want once when resolved", got)
+		}
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+		if resp := h.create(h.planned("app-prod")); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		if got := fake.called("NotifySubscriptionResolved"); got != 0 {
+			t.Errorf("notified %d times without a webhook, want never", got)
+		}
+	})
+}
+
+func TestAzureSubscriptionResource_PreventCancellationOnUpdate(t *testing.T) {
+	fake := newFakeClient()
+	h := newHarness(t, fake)
+
+	planned := h.planned("app-prod")
+	planned.PreventCancellation = types.BoolValue(true)
+	if resp := h.create(planned); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+
+	// Lifting the protection in the same apply as cancelling isn't enough either
+	for _, prevent := range []bool{true, false} {
+		planned = h.model()
+		planned.PreventCancellation = types.BoolValue(prevent)
+		planned.DesiredStatus = types.StringValue("cancelled")
+		resp := h.update(planned)
+		if !hasSummary(resp.Diagnostics, "Subscription Cancellation Prevented") {
+			t.Errorf("prevent_cancellation = %v: cancelled via desired_status: %s", prevent, summaries(resp.Diagnostics))
+		}
+	}
+	if got := fake.called("CancelAzureSubscription"); got != 0 {
+		t.Errorf("cancelled the subscription %d times, want never", got)
+	}
+	if status := fake.subscription(1001).Status; status != "active" {
+		t.Errorf("status = %q, want active", status)
+	}
+}
+
+func TestAzureSubscriptionResource_PartnerOfRecord(t *testing.T) {
+	t.Run("absent", func(t *testing.T) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+
+		if resp := h.create(h.planned("app-direct")); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		if resp := h.read(); resp.Diagnostics.HasError() {
+			t.Fatalf("read: %s", summaries(resp.Diagnostics))
+		}
+		if por := h.model().PartnerOfRecord; !por.IsNull() {
+			t.Errorf("partner_of_record = %s, want null", por)
+		}
+		if got := fake.called("SetAzureSubscriptionPartnerOfRecord"); got != 0 {
+			t.Errorf("set the partner of record %d times, want never", got)
+		}
+	})
+
+	t.Run("reported by Cloud-iQ", func(t *testing.T) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+
+		if resp := h.create(h.planned("app-partner")); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		fake.subscriptions[1001].PartnerOfRecord = "partner-1"
+		if resp := h.read(); resp.Diagnostics.HasError() {
+			t.Fatalf("read: %s", summaries(resp.Diagnostics))
+		}
+		if por := h.model().PartnerOfRecord.ValueString(); por != "partner-1" {
+			t.Errorf("partner_of_record = %q, want partner-1", por)
+		}
+	})
+
+	t.Run("set", func(t *testing.T) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+
+		planned := h.planned("app-partner")
+		planned.PartnerOfRecord = types.StringValue("partner-1")
+		if resp := h.create(planned); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		if por := fake.subscription(1001).PartnerOfRecord; por != "partner-1" {
+			t.Errorf("created with partner of record %q, want partner-1", por)
+		}
+
+		planned = h.model()
+		planned.PartnerOfRecord = types.StringValue("partner-2")
+		if resp := h.update(planned); resp.Diagnostics.HasError() {
+			t.Fatalf("update: %s", summaries(resp.Diagnostics))
+		}
+		if resp := h.read(); resp.Diagnostics.HasError() {
+			t.Fatalf("read: %s", summaries(resp.Diagnostics))
+		}
+		if por := h.model().PartnerOfRecord.ValueString(); por != "partner-2" {
+			t.Errorf("partner_of_record = %q, want partner-2", por)
+		}
+		if got := fake.called("SetAzureSubscriptionPartnerOfRecord"); got != 2 {
+			t.Errorf("set the partner of record %d times, want 2", got)
+		}
+	})
+}
+
+// TestAzureSubscriptionResource_RenamedOutsideTerraform refreshes a subscription renamed in the
+// portal: the new name is reported and recorded, so the plan renames it back to the config
+func TestAzureSubscriptionResource_RenamedOutsideTerraform(t *testing.T) {
+	fake := newFakeClient()
+	h := newHarness(t, fake)
+	if resp := h.create(h.planned("app-prod")); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+
+	fake.subscriptions[1001].FriendlyName = "app-prod-renamed"
+	resp := h.read()
+	if resp.Diagnostics.HasError() || !hasSummary(resp.Diagnostics, "Subscription Renamed Outside Terraform") {
+		t.Fatalf("read = %s, want a rename warning", summaries(resp.Diagnostics))
+	}
+	refreshed := h.model()
+	if got := refreshed.Name.ValueString(); got != "app-prod-renamed" {
+		t.Fatalf("name = %q after refresh, want the portal name so the plan shows a diff", got)
+	}
+
+	refreshed.Name = types.StringValue("app-prod")
+	if resp := h.update(refreshed); resp.Diagnostics.HasError() {
+		t.Fatalf("update: %s", summaries(resp.Diagnostics))
+	}
+	if got := fake.subscriptions[1001].FriendlyName; got != "app-prod" {
+		t.Errorf("name = %q after apply, want it renamed back to the config", got)
+	}
+	if resp := h.read(); len(resp.Diagnostics) != 0 {
+		t.Errorf("read after apply = %s, want no warnings", summaries(resp.Diagnostics))
+	}
+}
+
+func TestAzureSubscriptionResource_CancellationDate(t *testing.T) {
+	future := time.Now().UTC().AddDate(0, 1, 0).Format("2006-01-02")
+	past := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+
+	t.Run("immediate", func(t *testing.T) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+		if resp := h.create(h.planned("app-prod")); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		if resp := h.delete(); resp.Diagnostics.HasError() {
+			t.Fatalf("delete: %s", summaries(resp.Diagnostics))
+		}
+		if !fake.cancelledOn.IsZero() {
+			t.Errorf("cancelled on %v, want immediately", fake.cancelledOn)
+		}
+	})
+
+	t.Run("scheduled", func(t *testing.T) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+		planned := h.planned("app-prod")
+		planned.CancellationDate = types.StringValue(future)
+		if resp := h.create(planned); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		if resp := h.planDestroy(); resp.Diagnostics.HasError() {
+			t.Fatalf("plan: %s", summaries(resp.Diagnostics))
+		}
+		if resp := h.delete(); resp.Diagnostics.HasError() {
+			t.Fatalf("delete: %s", summaries(resp.Diagnostics))
+		}
+		if got := fake.cancelledOn.Format("2006-01-02"); got != future {
+			t.Errorf("cancelled on %s, want %s", got, future)
+		}
+	})
+
+	t.Run("scheduling rejected", func(t *testing.T) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+		planned := h.planned("app-prod")
+		planned.CancellationDate = types.StringValue(future)
+		if resp := h.create(planned); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		fake.errs["CancelAzureSubscription"] = fmt.Errorf("%w: status 422", client.ErrScheduledCancellationRejected)
+		if resp := h.delete(); !hasSummary(resp.Diagnostics, "Scheduled Cancellation Rejected") {
+			t.Errorf("delete = %s, want the rejection explained", summaries(resp.Diagnostics))
+		}
+	})
+
+	t.Run("date passed", func(t *testing.T) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+		planned := h.planned("app-prod")
+		planned.CancellationDate = types.StringValue(past)
+		if resp := h.create(planned); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		if resp := h.planDestroy(); !hasSummary(resp.Diagnostics, "Invalid Cancellation Date") {
+			t.Errorf("plan = %s, want the passed date rejected", summaries(resp.Diagnostics))
+		}
+		if resp := h.delete(); !resp.Diagnostics.HasError() {
+			t.Error("delete succeeded, want the passed date rejected")
+		}
+		if got := fake.called("CancelAzureSubscription"); got != 0 {
+			t.Errorf("cancelled %d times, want never", got)
+		}
+	})
+}
+
+func TestAzureSubscriptionResource_WaitForCancellation(t *testing.T) {
+	// destroy creates the subscription with wait_for_cancellation and destroys it
+	destroy := func(t *testing.T, fake *fakeClient, edit func(*AzureSubscriptionResourceModel)) *resource.DeleteResponse {
+		t.Helper()
+		h := newHarness(t, fake)
+		planned := h.planned("app-prod")
+		planned.WaitForCancellation = types.BoolValue(true)
+		if edit != nil {
+			edit(&planned)
+		}
+		if resp := h.create(planned); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		return h.delete()
+	}
+
+	t.Run("not waiting by default", func(t *testing.T) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+		if resp := h.create(h.planned("app-prod")); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		fake.cancelStatus = "Cancelling"
+		if resp := h.delete(); resp.Diagnostics.HasError() || resp.Diagnostics.WarningsCount() > 0 {
+			t.Fatalf("delete: %s", summaries(resp.Diagnostics))
+		}
+		if got := fake.called("WaitForSubscriptionStatus"); got != 0 {
+			t.Errorf("waited %d times, want fire-and-forget", got)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		fake := newFakeClient()
+		resp := destroy(t, fake, nil)
+		if resp.Diagnostics.HasError() || resp.Diagnostics.WarningsCount() > 0 {
+			t.Fatalf("delete: %s", summaries(resp.Diagnostics))
+		}
+		if got := fake.called("WaitForSubscriptionStatus"); got != 1 {
+			t.Errorf("waited %d times, want 1", got)
+		}
+		if fake.waitTimeout != cancellationWaitTimeout {
+			t.Errorf("waited up to %v, want %v", fake.waitTimeout, cancellationWaitTimeout)
+		}
+	})
+
+	t.Run("delete timeout bounds the wait", func(t *testing.T) {
+		fake := newFakeClient()
+		resp := destroy(t, fake, func(planned *AzureSubscriptionResourceModel) {
+			planned.Timeouts = types.ObjectValueMust(timeoutsAttrTypes, map[string]attr.Value{
+				timeoutCreate: types.StringNull(),
+				timeoutRead:   types.StringNull(),
+				timeoutUpdate: types.StringNull(),
+				timeoutDelete: types.StringValue("5m"),
+			})
+		})
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("delete: %s", summaries(resp.Diagnostics))
+		}
+		if fake.waitTimeout != 5*time.Minute {
+			t.Errorf("waited up to %v, want the delete timeout", fake.waitTimeout)
+		}
+	})
+
+	t.Run("cancellation not confirmed", func(t *testing.T) {
+		fake := newFakeClient()
+		fake.cancelStatus = "Cancelling"
+		resp := destroy(t, fake, nil)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("delete = %s, want the subscription removed from state", summaries(resp.Diagnostics))
+		}
+		if !hasSummary(resp.Diagnostics, "Cancellation Not Confirmed") {
+			t.Errorf("delete = %s, want the unconfirmed cancellation warned about", summaries(resp.Diagnostics))
+		}
+	})
+
+	t.Run("wait fails", func(t *testing.T) {
+		fake := newFakeClient()
+		fake.errs["WaitForSubscriptionStatus"] = context.Canceled
+		resp := destroy(t, fake, nil)
+		if resp.Diagnostics.HasError() || !hasSummary(resp.Diagnostics, "Cancellation Not Confirmed") {
+			t.Errorf("delete = %s, want only a warning", summaries(resp.Diagnostics))
+		}
+	})
+
+	t.Run("scheduled cancellation", func(t *testing.T) {
+		fake := newFakeClient()
+		resp := destroy(t, fake, func(planned *AzureSubscriptionResourceModel) {
+			planned.CancellationDate = types.StringValue(time.Now().UTC().AddDate(0, 1, 0).Format("2006-01-02"))
+		})
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("delete: %s", summaries(resp.Diagnostics))
+		}
+		if fake.cancelledOn.IsZero() {
+			t.Fatal("cancelled immediately, want scheduled")
+		}
+		if got := fake.called("WaitForSubscriptionStatus"); got != 0 {
+			t.Errorf("waited %d times for a scheduled cancellation, want never", got)
+		}
+	})
+}
+
+func TestParseCancellationDate(t *testing.T) {
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	tests := map[string]struct {
+		value    types.String
+		wantZero bool
+		wantErr  bool
+	}{
+		"null":      {value: types.StringNull(), wantZero: true},
+		"unknown":   {value: types.StringUnknown(), wantZero: true},
+		"tomorrow":  {value: types.StringValue(tomorrow)},
+		"today":     {value: types.StringValue(time.Now().UTC().Format("2006-01-02")), wantErr: true},
+		"past":      {value: types.StringValue("2020-01-31"), wantErr: true},
+		"timestamp": {value: types.StringValue(tomorrow + "T00:00:00Z"), wantErr: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			date, err := parseCancellationDate(test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("err = %v, want an error: %v", err, test.wantErr)
+			}
+			if err == nil && date.IsZero() != test.wantZero {
+				t.Errorf("date = %v, want zero: %v", date, test.wantZero)
+			}
+		})
+	}
+}
+
+func TestAzureSubscriptionResource_SupportPlan(t *testing.T) {
+	t.Run("not reported", func(t *testing.T) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+		if resp := h.create(h.planned("app-prod")); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		if resp := h.read(); resp.Diagnostics.HasError() {
+			t.Fatalf("read: %s", summaries(resp.Diagnostics))
+		}
+		if got := h.model().SupportPlan; !got.IsNull() {
+			t.Errorf("support_plan = %v, want null", got)
+		}
+
+		fake.subscriptions[1001].SupportPlan = "Standard"
+		if resp := h.read(); resp.Diagnostics.HasError() {
+			t.Fatalf("read: %s", summaries(resp.Diagnostics))
+		}
+		if got := h.model().SupportPlan.ValueString(); got != "Standard" {
+			t.Errorf("support_plan = %q once reported, want Standard", got)
+		}
+	})
+
+	t.Run("chosen at create", func(t *testing.T) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+		planned := h.planned("app-prod")
+		planned.SupportPlan = types.StringValue("ProfessionalDirect")
+		if resp := h.create(planned); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		if got := fake.subscription(1001).SupportPlan; got != "ProfessionalDirect" {
+			t.Errorf("created with support plan %q, want ProfessionalDirect", got)
+		}
+
+		// Cloud-iQ not reporting it keeps the configured one
+		fake.subscriptions[1001].SupportPlan = ""
+		if resp := h.read(); resp.Diagnostics.HasError() {
+			t.Fatalf("read: %s", summaries(resp.Diagnostics))
+		}
+		if got := h.model().SupportPlan.ValueString(); got != "ProfessionalDirect" {
+			t.Errorf("support_plan = %q, want the configured one kept", got)
+		}
+
+		planned = h.model()
+		planned.SupportPlan = types.StringValue("Standard")
+		if _, resp := h.modifyPlan(planned); !hasSummary(resp.Diagnostics, "Support Plan Cannot Be Changed") {
+			t.Errorf("plan = %s, want changing the support plan rejected", summaries(resp.Diagnostics))
+		}
+	})
+}
+
+func TestAzureSubscriptionResource_Import(t *testing.T) {
+	tests := map[string]struct {
+		id          string
+		wantSummary string
+	}{
+		"IDs":                             {id: "873834:1001"},
+		"portal URL":                      {id: "https://cloudiq.crayon.com/subscriptions/azure/873834/1001?organizationId=4051878"},
+		"portal URL without organization": {id: "https://cloudiq.crayon.com/subscriptions/azure/873834/1001"},
+		"portal URL of another organization": {
+			id:          "https://cloudiq.crayon.com/subscriptions/azure/873834/1001?organizationId=1234",
+			wantSummary: "Organization Mismatch",
+		},
+		"portal URL of another page": {
+			id:          "https://cloudiq.crayon.com/subscriptions/azure/873834",
+			wantSummary: "Invalid Import ID",
+		},
+		"subscription ID only": {id: "1001", wantSummary: "Invalid Import ID"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake := newFakeClient()
+			fake.add(testPlanID, "app-prod", "active")
+			h := newHarness(t, fake)
+
+			resp := h.importState(test.id)
+			if test.wantSummary != "" {
+				if !hasSummary(resp.Diagnostics, test.wantSummary) {
+					t.Errorf("import = %s, want %q", summaries(resp.Diagnostics), test.wantSummary)
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("import: %s", summaries(resp.Diagnostics))
+			}
+			if resp := h.read(); resp.Diagnostics.HasError() {
+				t.Fatalf("read: %s", summaries(resp.Diagnostics))
+			}
+			data := h.model()
+			if data.AzurePlanID.ValueInt64() != testPlanID || data.ID.ValueString() != "1001" || data.Name.ValueString() != "app-prod" {
+				t.Errorf("imported azure_plan_id %v, id %v, name %v, want subscription 1001", data.AzurePlanID, data.ID, data.Name)
+			}
+		})
+	}
+}
+
+func TestAzureSubscriptionResource_DeletedOutsideTerraform(t *testing.T) {
+	t.Run("read", func(t *testing.T) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+		if resp := h.create(h.planned("app-gone")); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		delete(fake.subscriptions, 1001)
+
+		resp := h.read()
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("read: %s", summaries(resp.Diagnostics))
+		}
+		if !resp.State.Raw.IsNull() {
+			t.Error("a subscription that no longer exists was kept in state")
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+		if resp := h.create(h.planned("app-gone")); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		delete(fake.subscriptions, 1001)
+
+		if resp := h.delete(); resp.Diagnostics.HasError() {
+			t.Fatalf("delete: %s", summaries(resp.Diagnostics))
+		}
+	})
+}
+
+func TestAzureSubscriptionResource_ConfigureRejectsOtherProviderData(t *testing.T) {
+	r := &AzureSubscriptionResource{}
+	var resp resource.ConfigureResponse
+	r.Configure(context.Background(), resource.ConfigureRequest{ProviderData: "not a client"}, &resp)
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("Configure accepted provider data that isn't a client.SubscriptionAPI")
+	}
+}
+
+func TestAzureSubscriptionResource_ConfigureAcceptsOtherBackends(t *testing.T) {
+	fake := newFakeClient()
+	r := &AzureSubscriptionResource{}
+	var resp resource.ConfigureResponse
+	r.Configure(context.Background(), resource.ConfigureRequest{ProviderData: fake}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("configure: %s", summaries(resp.Diagnostics))
+	}
+	if r.client != fake {
+		t.Errorf("client = %T, want the fake backend", r.client)
+	}
+	if r.settings.StopContext == nil || r.settings.PortalURL == nil || r.settings.SyncReadRetries != 1 {
+		t.Errorf("settings = %+v, want the defaults for a backend without provider configuration", r.settings)
+	}
+}
+
+func TestAzureSubscriptionResource_PendingSyncRecovery(t *testing.T) {
+	fake := newFakeClient()
+	fake.async = true
+	fake.confirmedGUID = "11111111-2222-3333-4444-555555555555"
+	h := newHarness(t, fake)
+	h.resource.settings.SyncRecoveryTimeout = time.Hour
+
+	if resp := h.create(h.planned("app-pending")); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+	if id := h.model().ID.ValueString(); id != "pending-app-pending" {
+		t.Fatalf("id = %q, want pending-app-pending", id)
+	}
+
+	// The first refresh of an ARM-confirmed subscription triggers a Cloud-iQ sync, later ones don't
+	for i := 0; i < 2; i++ {
+		resp := h.read()
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("read %d: %s", i, summaries(resp.Diagnostics))
+		}
+		if hasSummary(resp.Diagnostics, "Subscription Not Synced to Cloud-iQ") {
+			t.Errorf("read %d reported the subscription as not synced before sync_recovery_timeout", i)
+		}
+	}
+	if got := fake.called("TriggerCloudIQSync"); got != 1 {
+		t.Errorf("triggered %d syncs, want 1", got)
+	}
+	if got := h.privateKey(syncTriggeredKey); got != "true" {
+		t.Errorf("private %s = %q, want true", syncTriggeredKey, got)
+	}
+
+	// Once sync_recovery_timeout has passed, the known GUID is reported
+	createdAt, _ := json.Marshal(time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339))
+	h.private.Private.SetKey(h.ctx, createdAtKey, createdAt)
+	resp := h.read()
+	if !hasSummary(resp.Diagnostics, "Subscription Not Synced to Cloud-iQ") {
+		t.Errorf("read after sync_recovery_timeout didn't report the unsynced subscription:\n%s", summaries(resp.Diagnostics))
+	}
+
+	// A cleared key reads as never triggered
+	if diags := clearPrivateKey(h.ctx, h.private.Private, syncTriggeredKey); diags.HasError() {
+		t.Fatalf("clear: %s", summaries(diags))
+	}
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	if got := fake.called("TriggerCloudIQSync"); got != 2 {
+		t.Errorf("triggered %d syncs after clearing %s, want 2", got, syncTriggeredKey)
+	}
+}
+
+// TestAzureSubscriptionResource_PendingSyncTrigger explains in the pending warning how the sync
+// trigger went, and retries it on later refreshes until it succeeds (the client itself remembers
+// an API without a sync endpoint)
+func TestAzureSubscriptionResource_PendingSyncTrigger(t *testing.T) {
+	tests := map[string]struct {
+		err         error
+		confirmed   bool
+		wantDetail  string
+		wantTrigger int
+	}{
+		"triggered": {
+			confirmed:   true,
+			wantDetail:  "A Cloud-iQ sync was triggered",
+			wantTrigger: 1,
+		},
+		"not supported": {
+			err:         fmt.Errorf("%w (status 404)", client.ErrSyncNotSupported),
+			confirmed:   true,
+			wantDetail:  "does not support triggering a sync",
+			wantTrigger: 2,
+		},
+		"failing": {
+			err:         errors.New("connection reset"),
+			confirmed:   true,
+			wantDetail:  "will be retried on the next refresh: connection reset",
+			wantTrigger: 2,
+		},
+		"not confirmed by ARM": {
+			wantDetail: "click 'Synchronize' in the Cloud-iQ portal",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake := newFakeClient()
+			fake.async = true
+			if test.confirmed {
+				fake.confirmedGUID = "11111111-2222-3333-4444-555555555555"
+			}
+			fake.errs["TriggerCloudIQSync"] = test.err
+			h := newHarness(t, fake)
+			if resp := h.create(h.planned("app-pending")); resp.Diagnostics.HasError() {
+				t.Fatalf("create: %s", summaries(resp.Diagnostics))
+			}
+
+			for i := 0; i < 2; i++ {
+				resp := h.read()
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("read %d: %s", i, summaries(resp.Diagnostics))
+				}
+				if i == 0 && !strings.Contains(summaries(resp.Diagnostics), test.wantDetail) {
+					t.Errorf("read diagnostics don't mention %q:\n%s", test.wantDetail, summaries(resp.Diagnostics))
+				}
+			}
+			if got := fake.called("TriggerCloudIQSync"); got != test.wantTrigger {
+				t.Errorf("triggered %d syncs over 2 refreshes, want %d", got, test.wantTrigger)
+			}
+		})
+	}
+}
+
+func TestAzureSubscriptionResource_PendingResolved(t *testing.T) {
+	fake := newFakeClient()
+	fake.async = true
+	fake.progress = 40
+	h := newHarness(t, fake)
+
+	if resp := h.create(h.planned("app-async")); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+	if got := h.model().Progress.ValueInt64(); got != 40 {
+		t.Errorf("provisioning_progress = %d, want 40", got)
+	}
+	if h.privateKey(operationLocationKey) == "" || h.privateKey(createdAtKey) == "" {
+		t.Fatalf("create didn't record the pending operation: %q, %q", h.privateKey(operationLocationKey), h.privateKey(createdAtKey))
+	}
+
+	// Still pending: the progress is refreshed from the recorded operation
+	fake.progress = 80
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("pending read: %s", summaries(resp.Diagnostics))
+	}
+	if got := h.model().Progress.ValueInt64(); got != 80 {
+		t.Errorf("provisioning_progress = %d, want 80", got)
+	}
+
+	id := fake.sync("app-async")
+	resp := h.read()
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("resolving read: %s", summaries(resp.Diagnostics))
+	}
+	data := h.model()
+	if want := strconv.Itoa(id); data.ID.ValueString() != want {
+		t.Errorf("id = %q, want %q", data.ID.ValueString(), want)
+	}
+	if !data.Progress.IsNull() {
+		t.Errorf("provisioning_progress = %v, want null once resolved", data.Progress)
+	}
+	for _, key := range []string{operationLocationKey, createdAtKey, syncTriggeredKey} {
+		if got := h.privateKey(key); got != "null" {
+			t.Errorf("private %s = %q, want it cleared", key, got)
+		}
+	}
+
+	// Cleared keys read as absent
+	progressCalls := fake.called("GetSubscriptionOperationProgress")
+	if _, ok := h.resource.readProgress(h.ctx, h.private.Private); ok {
+		t.Error("readProgress reported progress from a cleared operation")
+	}
+	if got := fake.called("GetSubscriptionOperationProgress"); got != progressCalls {
+		t.Error("readProgress polled a cleared operation")
+	}
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read after resolving: %s", summaries(resp.Diagnostics))
+	}
+}
+
+func TestAzureSubscriptionResource_DeferNaming(t *testing.T) {
+	fake := newFakeClient()
+	h := newHarness(t, fake)
+
+	planned := h.planned("app-named-later")
+	planned.DeferNaming = types.BoolValue(true)
+	resp := h.create(planned)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+
+	data := h.model()
+	if data.Name.ValueString() != "app-named-later" {
+		t.Errorf("name = %q, want app-named-later", data.Name.ValueString())
+	}
+	id, _ := strconv.Atoi(data.ID.ValueString())
+	if got := fake.subscription(id).FriendlyName; got != "app-named-later" {
+		t.Errorf("subscription is named %q, want it renamed to app-named-later", got)
+	}
+	if got := h.privateKey(deferredNameKey); got != "null" {
+		t.Errorf("private %s = %q, want it cleared after the rename", deferredNameKey, got)
+	}
+
+	// Once renamed, refreshes leave the name alone
+	if resp := h.read(); resp.Diagnostics.HasError() || len(resp.Diagnostics) > 0 {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	if got := fake.called("RenameAzureSubscription"); got != 1 {
+		t.Errorf("renamed %d times, want 1", got)
+	}
+}
+
+func TestAzureSubscriptionResource_DeferNamingRetriesFailedRename(t *testing.T) {
+	fake := newFakeClient()
+	fake.errs["RenameAzureSubscription"] = errors.New("rename not allowed yet")
+	h := newHarness(t, fake)
+
+	planned := h.planned("app-named-later")
+	planned.DeferNaming = types.BoolValue(true)
+	resp := h.create(planned)
+	if resp.Diagnostics.HasError() || !hasSummary(resp.Diagnostics, "Could Not Apply Deferred Name") {
+		t.Fatalf("create: want a deferred name warning, got:\n%s", summaries(resp.Diagnostics))
+	}
+	placeholder := h.privateKey(deferredNameKey)
+	if placeholder == "" || placeholder == "null" {
+		t.Fatalf("private %s = %q, want the placeholder kept for a retry", deferredNameKey, placeholder)
+	}
+
+	// The next refresh retries the rename and clears the placeholder
+	delete(fake.errs, "RenameAzureSubscription")
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	if got := h.model().Name.ValueString(); got != "app-named-later" {
+		t.Errorf("name = %q, want app-named-later", got)
+	}
+	if got := h.privateKey(deferredNameKey); got != "null" {
+		t.Errorf("private %s = %q, want it cleared after the rename", deferredNameKey, got)
+	}
+}
+
+func TestAzureSubscriptionResource_SuppressPendingWarnings(t *testing.T) {
+	for _, suppress := range []bool{false, true} {
+		t.Run(fmt.Sprintf("suppress %v", suppress), func(t *testing.T) {
+			fake := newFakeClient()
+			fake.async = true
+			h := newHarness(t, fake)
+			h.resource.settings.SuppressPendingWarnings = suppress
+
+			var diags diag.Diagnostics
+			create := h.create(h.planned("app-async"))
+			diags.Append(create.Diagnostics...)
+			read := h.read()
+			diags.Append(read.Diagnostics...)
+
+			approvals := newFakeClient()
+			approvals.requireApproval = true
+			approval := newHarness(t, approvals)
+			approval.resource.settings.SuppressPendingWarnings = suppress
+			diags.Append(approval.create(approval.planned("app-approval")).Diagnostics...)
+
+			if diags.HasError() {
+				t.Fatalf("diagnostics: %s", summaries(diags))
+			}
+			for _, summary := range []string{"Subscription Creation In Progress", "Subscription Still Pending", "Subscription Awaiting Approval"} {
+				if got := hasSummary(diags, summary); got == suppress {
+					t.Errorf("warned %q: %v, want %v", summary, got, !suppress)
+				}
+			}
+
+			// Only the diagnostics change, never the state
+			if got := h.model().SubscriptionID.ValueString(); got != "pending" {
+				t.Errorf("subscription_id = %q, want pending", got)
+			}
+		})
+	}
+}
+
+func TestAzureSubscriptionResource_ApprovalApproved(t *testing.T) {
+	fake := newFakeClient()
+	fake.requireApproval = true
+	h := newHarness(t, fake)
+
+	if resp := h.create(h.planned("app-approved")); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+	if got := h.model().ApprovalStatus.ValueString(); got != client.ApprovalPending {
+		t.Errorf("approval_status = %q, want pending", got)
+	}
+
+	resp := h.read()
+	if resp.Diagnostics.HasError() || !hasSummary(resp.Diagnostics, "Subscription Awaiting Approval") {
+		t.Fatalf("read while pending: want an approval warning, got:\n%s", summaries(resp.Diagnostics))
+	}
+
+	// Once approved, the request is cleared and Read follows the subscription's provisioning
+	fake.decide(1, "Approved")
+	resp = h.read()
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("read after approval: %s", summaries(resp.Diagnostics))
+	}
+	data := h.model()
+	if data.ApprovalStatus.ValueString() != client.ApprovalApproved || data.Status.ValueString() != "provisioning" {
+		t.Errorf("approval_status = %q, status = %q, want approved and provisioning", data.ApprovalStatus.ValueString(), data.Status.ValueString())
+	}
+	if got := h.privateKey(approvalRequestKey); got != "null" {
+		t.Errorf("private %s = %q, want it cleared", approvalRequestKey, got)
+	}
+	if h.privateKey(createdAtKey) == "" {
+		t.Errorf("private %s is unset, want the sync recovery clock restarted", createdAtKey)
+	}
+
+	approvalReads := fake.called("GetApprovalRequest")
+	fake.add(testPlanID, "app-approved", "active")
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("resolving read: %s", summaries(resp.Diagnostics))
+	}
+	if got := fake.called("GetApprovalRequest"); got != approvalReads {
+		t.Error("Read followed a cleared approval request")
+	}
+	if id := h.model().ID.ValueString(); strings.HasPrefix(id, "pending-") {
+		t.Errorf("id = %q, want the Crayon ID once synced", id)
+	}
+}
+
+func TestAzureSubscriptionResource_ApprovalRejected(t *testing.T) {
+	fake := newFakeClient()
+	fake.requireApproval = true
+	h := newHarness(t, fake)
+
+	if resp := h.create(h.planned("app-rejected")); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+
+	fake.decide(1, "Rejected")
+	resp := h.read()
+	if resp.Diagnostics.HasError() || !hasSummary(resp.Diagnostics, "Subscription Request Rejected") {
+		t.Fatalf("read: want a rejection warning, got:\n%s", summaries(resp.Diagnostics))
+	}
+	if !resp.State.Raw.IsNull() {
+		t.Error("a rejected request was kept in state")
+	}
+}
+
+func TestAzureSubscriptionResource_DeleteWithdrawsPendingApproval(t *testing.T) {
+	fake := newFakeClient()
+	fake.requireApproval = true
+	h := newHarness(t, fake)
+
+	if resp := h.create(h.planned("app-withdrawn")); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+	if resp := h.delete(); resp.Diagnostics.HasError() {
+		t.Fatalf("delete: %s", summaries(resp.Diagnostics))
+	}
+	if got := fake.called("WithdrawApprovalRequest"); got != 1 {
+		t.Errorf("withdrew %d approval requests, want 1", got)
+	}
+}
+
+func TestAzureSubscriptionResource_CleanupFailedCreate(t *testing.T) {
+	tests := map[string]struct {
+		cleanup  bool
+		existing int // subscriptions with the name before the create
+		orphans  int // subscriptions with the name the failed create leaves behind
+		want     string
+		canceled bool
+	}{
+		"cancels the new subscription": {
+			cleanup: true, existing: 1, orphans: 1,
+			want: "Subscription Cleaned Up", canceled: true,
+		},
+		"only warns without cleanup_failed_creates": {
+			orphans: 1,
+			want:    "Subscription Created Despite Error",
+		},
+		"leaves existing subscriptions alone": {
+			cleanup: true, existing: 1,
+			want: "Subscription May Have Been Created",
+		},
+		"only warns if new subscriptions are ambiguous": {
+			cleanup: true, orphans: 2,
+			want: "Subscription May Have Been Created",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake := newFakeClient()
+			fake.errs["CreateAzureSubscriptionWithRequest"] = fmt.Errorf("%w: request timed out", client.ErrCreateOutcomeUnknown)
+			fake.orphans = test.orphans
+			h := newHarness(t, fake)
+			h.resource.settings.CleanupFailedCreates = test.cleanup
+
+			var existing []int
+			for i := 0; i < test.existing; i++ {
+				existing = append(existing, fake.add(testPlanID, "app-orphan", "active"))
+			}
+
+			resp := h.create(h.planned("app-orphan"))
+			if !hasSummary(resp.Diagnostics, "Error Creating Azure Subscription") {
+				t.Fatalf("create: want an error, got:\n%s", summaries(resp.Diagnostics))
+			}
+			if !hasSummary(resp.Diagnostics, test.want) {
+				t.Errorf("want a %q warning, got:\n%s", test.want, summaries(resp.Diagnostics))
+			}
+			want := 0
+			if test.canceled {
+				want = 1
+			}
+			if got := fake.called("CancelAzureSubscription"); got != want {
+				t.Errorf("cancelled %d subscriptions, want %d", got, want)
+			}
+			for _, id := range existing {
+				if status := fake.subscription(id).Status; status != "active" {
+					t.Errorf("existing subscription %d is %s, want it left active", id, status)
+				}
+			}
+		})
+	}
+}
+
+// createTimeouts returns a timeouts block setting only the create timeout
+func createTimeouts(create string) types.Object {
+	return types.ObjectValueMust(timeoutsAttrTypes, map[string]attr.Value{
+		timeoutCreate: types.StringValue(create),
+		timeoutRead:   types.StringNull(),
+		timeoutUpdate: types.StringNull(),
+		timeoutDelete: types.StringNull(),
+	})
+}
+
+func TestAzureSubscriptionResource_CreateTimeout(t *testing.T) {
+	fake := newFakeClient()
+	h := newHarness(t, fake)
+
+	planned := h.planned("app-timeout")
+	planned.Timeouts = createTimeouts("30m")
+	start := time.Now()
+	if resp := h.create(planned); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+
+	deadline := fake.contexts["CreateAzureSubscriptionWithRequest"].deadline
+	if deadline.IsZero() || deadline.Before(start.Add(30*time.Minute)) || deadline.After(time.Now().Add(30*time.Minute)) {
+		t.Errorf("created with deadline %v, want 30 minutes after the create started at %v", deadline, start)
+	}
+}
+
+func TestAzureSubscriptionResource_CleanupAfterCreateTimeout(t *testing.T) {
+	fake := newFakeClient()
+	fake.errs["CreateAzureSubscriptionWithRequest"] = fmt.Errorf("%w: %w", client.ErrCreateOutcomeUnknown, context.DeadlineExceeded)
+	fake.orphans = 1
+	h := newHarness(t, fake)
+	h.resource.settings.CleanupFailedCreates = true
+
+	planned := h.planned("app-timeout")
+	planned.Timeouts = createTimeouts("1ns")
+	resp := h.create(planned)
+	if !hasSummary(resp.Diagnostics, "Subscription Cleaned Up") {
+		t.Fatalf("want the orphan cleaned up, got:\n%s", summaries(resp.Diagnostics))
+	}
+	if fake.contexts["CreateAzureSubscriptionWithRequest"].err == nil {
+		t.Fatal("the create timeout didn't bound the create request")
+	}
+	for _, method := range []string{"FindAzureSubscriptionsByName", "CancelAzureSubscription"} {
+		if err := fake.contexts[method].err; err != nil {
+			t.Errorf("%s was called with an ended context: %v", method, err)
+		}
+	}
+}
+
+func TestFindPendingSubscriptionRetries(t *testing.T) {
+	for _, retries := range []int{1, 3} {
+		fake := newFakeClient()
+		r := &AzureSubscriptionResource{client: fake, settings: testSettings()}
+		r.settings.SyncReadRetries = retries
+
+		if _, err := r.findPendingSubscription(context.Background(), testPlanID, "missing"); err == nil {
+			t.Fatalf("sync_read_retries = %d: found a missing subscription", retries)
+		}
+		if got := fake.called("FindAzureSubscriptionByName"); got != retries {
+			t.Errorf("sync_read_retries = %d: looked %d times", retries, got)
+		}
+	}
+}
+
+func TestWaitForSync(t *testing.T) {
+	t.Run("deadline", func(t *testing.T) {
+		fake := newFakeClient()
+		r := &AzureSubscriptionResource{client: fake, settings: testSettings()}
+		_, err := r.waitForSync(context.Background(), testPlanID, "missing", time.Now().Add(20*time.Millisecond))
+		if err == nil || errors.Is(err, client.ErrBackoffExhausted) {
+			t.Errorf("err = %v, want the last lookup's error", err)
+		}
+		if fake.called("FindAzureSubscriptionByName") < 2 {
+			t.Error("waitForSync didn't poll until the deadline")
+		}
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		fake := newFakeClient()
+		r := &AzureSubscriptionResource{client: fake, settings: testSettings()}
+		r.settings.SyncReadInterval = time.Hour
+		stop, cancel := context.WithCancel(context.Background())
+		cancel()
+		r.settings.StopContext = stop
+		_, err := r.waitForSync(context.Background(), testPlanID, "missing", time.Now().Add(time.Hour))
+		if !errors.Is(err, client.ErrStopped) {
+			t.Errorf("err = %v, want ErrStopped", err)
+		}
+	})
+
+	t.Run("synced", func(t *testing.T) {
+		fake := newFakeClient()
+		id := fake.add(testPlanID, "app-synced", "active")
+		r := &AzureSubscriptionResource{client: fake, settings: testSettings()}
+		subscription, err := r.waitForSync(context.Background(), testPlanID, "app-synced", time.Now().Add(time.Hour))
+		if err != nil || subscription.ID != id {
+			t.Errorf("waitForSync = %v, %v, want subscription %d", subscription, err, id)
+		}
+	})
+}
+
+// TestAzureSubscriptionResource_ReadSubReads runs the tags and spending cap sub-reads of Read
+// together; run it with -race to check they only touch their own attributes.
+func TestAzureSubscriptionResource_ReadSubReads(t *testing.T) {
+	fake := newFakeClient()
+	h := newHarness(t, fake)
+	h.resource.settings.HasAzureCredentials = true
+	h.resource.settings.DefaultTags = map[string]string{"cost-center": "1234"}
+
+	planned := h.planned("app-tagged")
+	planned.Environment = types.StringValue("prod")
+	planned.Tags = types.MapValueMust(types.StringType, stringMapValues(map[string]string{"owner": "platform"}))
+	if resp := h.create(planned); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+
+	for i := 0; i < 10; i++ {
+		if resp := h.read(); resp.Diagnostics.HasError() {
+			t.Fatalf("read: %s", summaries(resp.Diagnostics))
+		}
+	}
+	data := h.model()
+	if data.Environment.ValueString() != "prod" || len(data.TagsAll.Elements()) != 2 {
+		t.Errorf("environment = %v, tags_all = %v, want prod and both tags", data.Environment, data.TagsAll)
+	}
+	if fake.called("GetARMSubscription") == 0 {
+		t.Error("the spending cap wasn't read")
+	}
+}
+
+// TestAzureSubscriptionResource_CreatePreflight plans new subscriptions against the create dry-run:
+// a rejection fails the plan, while a missing or failing preflight doesn't
+func TestAzureSubscriptionResource_ARMConfirmationUnavailable(t *testing.T) {
+	tests := map[string]struct {
+		unavailable bool
+		policy      string
+		wantCreated bool
+		wantError   bool
+	}{
+		"available":         {policy: client.ARMUnavailableError, wantCreated: true},
+		"warn":              {unavailable: true, policy: client.ARMUnavailableWarn, wantCreated: true},
+		"error":             {unavailable: true, policy: client.ARMUnavailableError, wantError: true},
+		"warn when not set": {unavailable: true, wantCreated: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake := newFakeClient()
+			if test.unavailable {
+				fake.errs["CheckARMConfirmation"] = fmt.Errorf("%w: no Azure CLI session", client.ErrARMUnavailable)
+			}
+			h := newHarness(t, fake)
+			h.resource.settings.ARMUnavailablePolicy = test.policy
+
+			resp := h.create(h.planned("app-prod"))
+			if got := resp.Diagnostics.HasError(); got != test.wantError {
+				t.Fatalf("create = %s, want error: %v", summaries(resp.Diagnostics), test.wantError)
+			}
+			if got := hasSummary(resp.Diagnostics, "ARM Confirmation Unavailable"); got != test.unavailable {
+				t.Errorf("create = %s, want the unavailable ARM confirmation reported: %v", summaries(resp.Diagnostics), test.unavailable)
+			}
+			if got := fake.called("CreateAzureSubscriptionWithRequest"); (got == 1) != test.wantCreated {
+				t.Errorf("sent %d create requests, want created: %v", got, test.wantCreated)
+			}
+		})
+	}
+}
+
+func TestAzureSubscriptionResource_CreatePreflight(t *testing.T) {
+	tests := map[string]struct {
+		err          error
+		wantRejected bool
+	}{
+		"accepted":        {},
+		"rejected":        {err: fmt.Errorf("%w: name taken", client.ErrCreateRejected), wantRejected: true},
+		"no preflight":    {err: client.ErrDryRunNotSupported},
+		"preflight fails": {err: errors.New("connection reset")},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake := newFakeClient()
+			fake.errs["CreateAzureSubscriptionDryRun"] = test.err
+			h := newHarness(t, fake)
+
+			_, resp := h.modifyPlan(h.planned("app-prod"))
+			if got := hasSummary(resp.Diagnostics, "Subscription Would Be Rejected"); got != test.wantRejected {
+				t.Errorf("plan diagnostics %s, want rejected: %v", summaries(resp.Diagnostics), test.wantRejected)
+			}
+			if !test.wantRejected && resp.Diagnostics.HasError() {
+				t.Errorf("plan: %s", summaries(resp.Diagnostics))
+			}
+			if fake.called("CreateAzureSubscriptionDryRun") != 1 {
+				t.Errorf("dry-run called %d times, want once", fake.called("CreateAzureSubscriptionDryRun"))
+			}
+		})
+	}
+
+	// Unknown inputs and existing subscriptions aren't preflighted
+	fake := newFakeClient()
+	h := newHarness(t, fake)
+	planned := h.planned("app-prod")
+	planned.Name = types.StringUnknown()
+	if _, resp := h.modifyPlan(planned); resp.Diagnostics.HasError() {
+		t.Fatalf("plan: %s", summaries(resp.Diagnostics))
+	}
+	if resp := h.create(h.planned("app-prod")); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+	if _, resp := h.modifyPlan(h.model()); resp.Diagnostics.HasError() {
+		t.Fatalf("plan: %s", summaries(resp.Diagnostics))
+	}
+	if n := fake.called("CreateAzureSubscriptionDryRun"); n != 0 {
+		t.Errorf("dry-run called %d times, want none for an unknown name or an existing subscription", n)
+	}
+}
+
+// TestAzureSubscriptionResource_BillingPeriod maps the billing period and renewal date to RFC 3339
+// once Cloud-iQ reports them, and keeps them null while it doesn't
+func TestAzureSubscriptionResource_BillingPeriod(t *testing.T) {
+	fake := newFakeClient()
+	h := newHarness(t, fake)
+	if resp := h.create(h.planned("app-prod")); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	data := h.model()
+	for name, value := range map[string]types.String{
+		"current_billing_period_start": data.CurrentBillingPeriodStart,
+		"current_billing_period_end":   data.CurrentBillingPeriodEnd,
+		"next_renewal_date":            data.NextRenewalDate,
+	} {
+		if !value.IsNull() {
+			t.Errorf("%s = %v while not reported, want null", name, value)
+		}
+	}
+
+	fake.subscriptions[1001].CurrentBillingPeriodStart = "2024-05-01"
+	fake.subscriptions[1001].CurrentBillingPeriodEnd = "2024-05-31T23:59:59"
+	fake.subscriptions[1001].NextRenewalDate = "2025-05-01T00:00:00Z"
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	data = h.model()
+	for name, test := range map[string]struct {
+		got  types.String
+		want string
+	}{
+		"current_billing_period_start": {data.CurrentBillingPeriodStart, "2024-05-01T00:00:00Z"},
+		"current_billing_period_end":   {data.CurrentBillingPeriodEnd, "2024-05-31T23:59:59Z"},
+		"next_renewal_date":            {data.NextRenewalDate, "2025-05-01T00:00:00Z"},
+	} {
+		if test.got.ValueString() != test.want {
+			t.Errorf("%s = %v, want %s", name, test.got, test.want)
+		}
+	}
+
+	// A date in an unknown format is dropped rather than failing the read
+	fake.subscriptions[1001].NextRenewalDate = "01.05.2025"
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	if got := h.model().NextRenewalDate; !got.IsNull() {
+		t.Errorf("next_renewal_date = %v for an unknown format, want null", got)
+	}
+}
+
+// TestAzureSubscriptionResource_ProtectImported records whether a subscription was created or
+// imported, and refuses to destroy imported ones while protect_imported_subscriptions is set
+func TestAzureSubscriptionResource_LastModified(t *testing.T) {
+	fake := newFakeClient()
+	h := newHarness(t, fake)
+	if resp := h.create(h.planned("app-prod")); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+	if got := h.model().LastModified; !got.IsNull() {
+		t.Errorf("last_modified = %v while not reported, want null", got)
+	}
+
+	fake.subscriptions[1001].LastModified = "2024-05-31T23:59:59.123"
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	if got := h.model().LastModified.ValueString(); got != "2024-05-31T23:59:59Z" {
+		t.Errorf("last_modified = %q, want 2024-05-31T23:59:59Z", got)
+	}
+
+	// Timestamps in an unknown format aren't passed on verbatim
+	fake.subscriptions[1001].LastModified = "31/05/2024"
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	if got := h.model().LastModified; !got.IsNull() {
+		t.Errorf("last_modified = %v for an unknown format, want null", got)
+	}
+}
+
+func TestAzureSubscriptionResource_ProtectImported(t *testing.T) {
+	// setup returns a harness managing subscription 1001, created or imported as provenance says
+	setup := func(t *testing.T, provenance string, protect bool) (*fakeClient, *harness) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+		h.resource.settings.ProtectImportedSubscriptions = protect
+		switch provenance {
+		case provenanceCreated:
+			if resp := h.create(h.planned("app-prod")); resp.Diagnostics.HasError() {
+				t.Fatalf("create: %s", summaries(resp.Diagnostics))
+			}
+		case provenanceImported:
+			fake.add(testPlanID, "app-prod", "active")
+			if resp := h.importState("873834:1001"); resp.Diagnostics.HasError() {
+				t.Fatalf("import: %s", summaries(resp.Diagnostics))
+			}
+		default:
+			// Managed since before provenance was recorded
+			if resp := h.create(h.planned("app-prod")); resp.Diagnostics.HasError() {
+				t.Fatalf("create: %s", summaries(resp.Diagnostics))
+			}
+			h.private.Private = newOf(h.private.Private)
+		}
+		if resp := h.read(); resp.Diagnostics.HasError() {
+			t.Fatalf("read: %s", summaries(resp.Diagnostics))
+		}
+		return fake, h
+	}
+
+	tests := map[string]struct {
+		provenance    string
+		protect       bool
+		wantProtected bool
+	}{
+		"created":                  {provenance: provenanceCreated, protect: true},
+		"imported":                 {provenance: provenanceImported, protect: true, wantProtected: true},
+		"imported, not protecting": {provenance: provenanceImported},
+		"unknown provenance":       {protect: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake, h := setup(t, test.provenance, test.protect)
+
+			planResp := h.planDestroy()
+			if got := hasSummary(planResp.Diagnostics, "Imported Subscription Protected"); got != test.wantProtected {
+				t.Errorf("plan destroy = %s, want protected: %v", summaries(planResp.Diagnostics), test.wantProtected)
+			}
+			resp := h.delete()
+			if got := hasSummary(resp.Diagnostics, "Imported Subscription Protected"); got != test.wantProtected {
+				t.Errorf("delete = %s, want protected: %v", summaries(resp.Diagnostics), test.wantProtected)
+			}
+			if cancelled := fake.called("CancelAzureSubscription") > 0; cancelled == test.wantProtected {
+				t.Errorf("cancelled: %v, want %v", cancelled, !test.wantProtected)
+			}
+		})
+	}
+
+	t.Run("allow_cancel_imported", func(t *testing.T) {
+		fake, h := setup(t, provenanceImported, true)
+		planned := h.model()
+		planned.AllowCancelImported = types.BoolValue(true)
+		if resp := h.update(planned); resp.Diagnostics.HasError() {
+			t.Fatalf("update: %s", summaries(resp.Diagnostics))
+		}
+
+		if resp := h.planDestroy(); resp.Diagnostics.HasError() {
+			t.Fatalf("plan destroy: %s", summaries(resp.Diagnostics))
+		}
+		if resp := h.delete(); resp.Diagnostics.HasError() {
+			t.Fatalf("delete: %s", summaries(resp.Diagnostics))
+		}
+		if fake.called("CancelAzureSubscription") != 1 {
+			t.Error("the imported subscription wasn't cancelled although allow_cancel_imported is set")
+		}
+	})
+}
+
+// TestAzureSubscriptionResource_Contacts maps the account manager and technical contact once
+// Cloud-iQ reports them, and keeps them null while it doesn't
+func TestAzureSubscriptionResource_Contacts(t *testing.T) {
+	fake := newFakeClient()
+	h := newHarness(t, fake)
+	if resp := h.create(h.planned("app-prod")); resp.Diagnostics.HasError() {
+		t.Fatalf("create: %s", summaries(resp.Diagnostics))
+	}
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	if data := h.model(); !data.AccountManager.IsNull() || !data.TechnicalContact.IsNull() {
+		t.Errorf("account_manager = %v, technical_contact = %v while not reported, want null", data.AccountManager, data.TechnicalContact)
+	}
+
+	fake.subscriptions[1001].AccountManager = &client.Contact{Name: "Jane Doe", Email: "jane@contoso.com"}
+	fake.subscriptions[1001].TechnicalContact = &client.Contact{Email: "ops@contoso.com"}
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	data := h.model()
+	if got := data.AccountManager.ValueString(); got != "Jane Doe <jane@contoso.com>" {
+		t.Errorf("account_manager = %q, want Jane Doe <jane@contoso.com>", got)
+	}
+	if got := data.TechnicalContact.ValueString(); got != "ops@contoso.com" {
+		t.Errorf("technical_contact = %q, want ops@contoso.com", got)
+	}
+
+	// A contact Cloud-iQ stops reporting goes back to null
+	fake.subscriptions[1001].TechnicalContact = nil
+	if resp := h.read(); resp.Diagnostics.HasError() {
+		t.Fatalf("read: %s", summaries(resp.Diagnostics))
+	}
+	if got := h.model().TechnicalContact; !got.IsNull() {
+		t.Errorf("technical_contact = %v once no longer reported, want null", got)
+	}
+}
+
+func TestAzureSubscriptionResource_BillingScope(t *testing.T) {
+	tests := map[string]struct {
+		scope                                *client.BillingScope
+		wantAgreement, wantScope, wantEnroll string
+	}{
+		"MCA": {
+			scope:         &client.BillingScope{AgreementType: "MCA", BillingAccountID: "5e98e158", BillingProfileID: "AW4F", InvoiceSectionID: "SH3V"},
+			wantAgreement: "mca", wantScope: "/providers/Microsoft.Billing/billingAccounts/5e98e158/billingProfiles/AW4F/invoiceSections/SH3V",
+		},
+		"EA": {
+			scope:         &client.BillingScope{ScopeID: "/providers/Microsoft.Billing/billingAccounts/1234567/enrollmentAccounts/7654321"},
+			wantAgreement: "ea", wantScope: "/providers/Microsoft.Billing/billingAccounts/1234567/enrollmentAccounts/7654321", wantEnroll: "7654321",
+		},
+		"not reported": {},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake := newFakeClient()
+			h := newHarness(t, fake)
+			if resp := h.create(h.planned("app-prod")); resp.Diagnostics.HasError() {
+				t.Fatalf("create: %s", summaries(resp.Diagnostics))
+			}
+			fake.subscriptions[1001].BillingScope = test.scope
+			if resp := h.read(); resp.Diagnostics.HasError() {
+				t.Fatalf("read: %s", summaries(resp.Diagnostics))
+			}
+
+			data := h.model()
+			for attribute, got := range map[string]struct {
+				value types.String
+				want  string
+			}{
+				"billing_agreement_type": {data.BillingAgreementType, test.wantAgreement},
+				"billing_scope":          {data.BillingScope, test.wantScope},
+				"enrollment_account":     {data.EnrollmentAccount, test.wantEnroll},
+			} {
+				if got.want == "" && !got.value.IsNull() {
+					t.Errorf("%s = %v, want null", attribute, got.value)
+				}
+				if got.want != "" && got.value.ValueString() != got.want {
+					t.Errorf("%s = %v, want %q", attribute, got.value, got.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAzureSubscriptionResource_RefundWindow(t *testing.T) {
+	future := time.Now().UTC().AddDate(0, 0, 3).Format(time.RFC3339)
+	past := time.Now().UTC().AddDate(0, 0, -3).Format(time.RFC3339)
+
+	tests := map[string]struct {
+		windowEnds   string
+		eligible     *bool
+		wantEligible types.Bool
+		wantEnds     types.String
+		wantWarning  bool
+	}{
+		"eligible": {
+			windowEnds:   future,
+			wantEligible: types.BoolValue(true), wantEnds: types.StringValue(future),
+		},
+		"expired": {
+			windowEnds:   past,
+			wantEligible: types.BoolValue(false), wantEnds: types.StringValue(past), wantWarning: true,
+		},
+		"not refundable": {
+			eligible:     new(bool),
+			wantEligible: types.BoolValue(false), wantEnds: types.StringNull(), wantWarning: true,
+		},
+		"not applicable": {
+			wantEligible: types.BoolNull(), wantEnds: types.StringNull(),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fake := newFakeClient()
+			h := newHarness(t, fake)
+			if resp := h.create(h.planned("app-prod")); resp.Diagnostics.HasError() {
+				t.Fatalf("create: %s", summaries(resp.Diagnostics))
+			}
+			fake.subscriptions[1001].CancellationWindowEnds = test.windowEnds
+			fake.subscriptions[1001].RefundEligible = test.eligible
+			if resp := h.read(); resp.Diagnostics.HasError() {
+				t.Fatalf("read: %s", summaries(resp.Diagnostics))
+			}
+
+			data := h.model()
+			if !data.RefundEligible.Equal(test.wantEligible) {
+				t.Errorf("refund_eligible = %v, want %v", data.RefundEligible, test.wantEligible)
+			}
+			if !data.CancellationWindowEnds.Equal(test.wantEnds) {
+				t.Errorf("cancellation_window_ends = %v, want %v", data.CancellationWindowEnds, test.wantEnds)
+			}
+
+			resp := h.planDestroy()
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("plan destroy: %s", summaries(resp.Diagnostics))
+			}
+			if got := hasSummary(resp.Diagnostics, "Cancelling Outside the Refund Window"); got != test.wantWarning {
+				t.Errorf("plan destroy warned about the refund window: %v, want %v", got, test.wantWarning)
+			}
+
+			planned := h.model()
+			planned.DesiredStatus = types.StringValue("cancelled")
+			_, resp = h.modifyPlan(planned)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("plan: %s", summaries(resp.Diagnostics))
+			}
+			if got := hasSummary(resp.Diagnostics, "Cancelling Outside the Refund Window"); got != test.wantWarning {
+				t.Errorf("plan to cancel warned about the refund window: %v, want %v", got, test.wantWarning)
+			}
+		})
+	}
+}
+
+func TestAzureSubscriptionResource_AutoRenew(t *testing.T) {
+	t.Run("set and changed", func(t *testing.T) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+		planned := h.planned("app-prod")
+		planned.AutoRenew = types.BoolValue(true)
+		if resp := h.create(planned); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		if got := fake.subscription(1001).AutoRenew; got == nil || !*got {
+			t.Errorf("created with auto-renewal %v, want it turned on", got)
+		}
+		if got := h.model().AutoRenew; !got.Equal(types.BoolValue(true)) {
+			t.Errorf("auto_renew = %v after create, want true", got)
+		}
+
+		planned = h.model()
+		planned.AutoRenew = types.BoolValue(false)
+		if resp := h.update(planned); resp.Diagnostics.HasError() {
+			t.Fatalf("update: %s", summaries(resp.Diagnostics))
+		}
+		if got := fake.subscription(1001).AutoRenew; got == nil || *got {
+			t.Errorf("auto-renewal %v after update, want it turned off", got)
+		}
+		if n := fake.called("SetAzureSubscriptionAutoRenew"); n != 2 {
+			t.Errorf("set auto-renewal %d times, want once at create and once at update", n)
+		}
+
+		// A change made outside Terraform shows up on refresh
+		enabled := true
+		fake.subscriptions[1001].AutoRenew = &enabled
+		if resp := h.read(); resp.Diagnostics.HasError() {
+			t.Fatalf("read: %s", summaries(resp.Diagnostics))
+		}
+		if got := h.model().AutoRenew; !got.Equal(types.BoolValue(true)) {
+			t.Errorf("auto_renew = %v after the change outside Terraform, want true", got)
+		}
+	})
+
+	t.Run("reported when not set", func(t *testing.T) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+		if resp := h.create(h.planned("app-prod")); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		enabled := true
+		fake.subscriptions[1001].AutoRenew = &enabled
+		if resp := h.read(); resp.Diagnostics.HasError() {
+			t.Fatalf("read: %s", summaries(resp.Diagnostics))
+		}
+		if got := h.model().AutoRenew; !got.Equal(types.BoolValue(true)) {
+			t.Errorf("auto_renew = %v, want the reported true", got)
+		}
+		if n := fake.called("SetAzureSubscriptionAutoRenew"); n != 0 {
+			t.Errorf("set auto-renewal %d times without it being configured", n)
+		}
+	})
+
+	t.Run("not applicable", func(t *testing.T) {
+		fake := newFakeClient()
+		h := newHarness(t, fake)
+		if resp := h.create(h.planned("app-prod")); resp.Diagnostics.HasError() {
+			t.Fatalf("create: %s", summaries(resp.Diagnostics))
+		}
+		if got := h.model().AutoRenew; !got.IsNull() {
+			t.Errorf("auto_renew = %v for an offer without a toggle, want null", got)
+		}
+
+		// Other changes leave it null rather than unknown
+		planned := h.model()
+		planned.Name = types.StringValue("app-renamed")
+		planned.AutoRenew = types.BoolUnknown()
+		if resp := h.update(planned); resp.Diagnostics.HasError() {
+			t.Fatalf("update: %s", summaries(resp.Diagnostics))
+		}
+		if got := h.model().AutoRenew; !got.IsNull() {
+			t.Errorf("auto_renew = %v after an unrelated update, want null", got)
+		}
+
+		fake.errs["SetAzureSubscriptionAutoRenew"] = fmt.Errorf("%w: status 405", client.ErrAutoRenewNotApplicable)
+		planned = h.model()
+		planned.AutoRenew = types.BoolValue(true)
+		if resp := h.update(planned); !hasSummary(resp.Diagnostics, "Auto-Renewal Not Applicable") {
+			t.Errorf("update = %s, want Auto-Renewal Not Applicable", summaries(resp.Diagnostics))
+		}
+	})
+}
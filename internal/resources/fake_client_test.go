@@ -0,0 +1,696 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// Ensure fakeClient satisfies client.SubscriptionAPI.
+var _ client.SubscriptionAPI = &fakeClient{}
+
+// fakeClient is an in-memory client.SubscriptionAPI. Subscriptions are created active and
+// synchronously, unless async or requireApproval is set.
+type fakeClient struct {
+	mu sync.Mutex
+
+	subscriptions map[int]*client.AzureSubscription
+	tags          map[int]map[string]string
+	nextID        int
+
+	// async makes creates return a pending subscription that only shows up once sync is called
+	async    bool
+	unsynced map[string]*client.AzureSubscription
+
+	// confirmedGUID is the subscription GUID of pending creates, as if ARM had confirmed them
+	confirmedGUID string
+
+	// requireApproval makes creates return an approval request instead of a subscription
+	requireApproval bool
+	approvals       map[int]*client.ApprovalRequest
+
+	// progress is the provisioning progress reported for pending creates, if not 0
+	progress int
+
+	// untaggedCreates makes creates ignore the request's tags, like create endpoints without tag support
+	untaggedCreates bool
+
+	// ignoreExpand makes GetAzureSubscription ignore $expand, like API versions without it
+	ignoreExpand bool
+	// expanded records the $expand fields of the last GetAzureSubscription call
+	expanded []string
+
+	// cancelledOn records the effective date of the last cancellation, zero if immediate
+	cancelledOn time.Time
+	// cancelStatus is the status a cancellation leaves, "cancelled" if empty; set it to one that
+	// WaitForSubscriptionStatus isn't waiting for to make the cancellation never complete
+	cancelStatus string
+	// waitTimeout records the timeout of the last WaitForSubscriptionStatus call
+	waitTimeout time.Duration
+
+	// orphans is how many subscriptions with the name a failing create still stores, as if the
+	// request timed out after Cloud-iQ accepted it, and others with the name landed meanwhile
+	orphans int
+
+	// errs makes the named methods fail with the given error
+	errs map[string]error
+
+	// calls records the methods called, in order
+	calls []string
+
+	// contexts records the context of each method's last call
+	contexts map[string]callContext
+}
+
+// callContext is the state of a call's context when the call was made
+type callContext struct {
+	deadline time.Time // Zero without a deadline
+	err      error
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		subscriptions: map[int]*client.AzureSubscription{},
+		tags:          map[int]map[string]string{},
+		nextID:        1001,
+		unsynced:      map[string]*client.AzureSubscription{},
+		approvals:     map[int]*client.ApprovalRequest{},
+		errs:          map[string]error{},
+		contexts:      map[string]callContext{},
+	}
+}
+
+// call records a call to method and returns the error it should fail with, if any
+func (f *fakeClient) call(ctx context.Context, method string) error {
+	f.calls = append(f.calls, method)
+	deadline, _ := ctx.Deadline()
+	f.contexts[method] = callContext{deadline: deadline, err: ctx.Err()}
+	return f.errs[method]
+}
+
+// called returns how often method was called
+func (f *fakeClient) called(method string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	count := 0
+	for _, call := range f.calls {
+		if call == method {
+			count++
+		}
+	}
+	return count
+}
+
+// add stores a subscription as if it existed in Cloud-iQ and returns its ID
+func (f *fakeClient) add(azurePlanID int, name, status string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := f.nextID
+	f.nextID++
+	f.subscriptions[id] = &client.AzureSubscription{
+		ID:             id,
+		FriendlyName:   name,
+		SubscriptionID: fmt.Sprintf("00000000-0000-0000-0000-%012d", id),
+		Status:         status,
+		AzurePlanID:    azurePlanID,
+	}
+	return id
+}
+
+// sync makes a pending subscription appear in Cloud-iQ and returns its ID
+func (f *fakeClient) sync(name string) int {
+	f.mu.Lock()
+	pending := f.unsynced[name]
+	delete(f.unsynced, name)
+	f.mu.Unlock()
+	return f.add(pending.AzurePlanID, name, "active")
+}
+
+// subscription returns a copy of a stored subscription, or nil
+func (f *fakeClient) subscription(id int) *client.AzureSubscription {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if sub, ok := f.subscriptions[id]; ok {
+		copied := *sub
+		return &copied
+	}
+	return nil
+}
+
+// notFound returns the error of the Crayon API for a missing subscription
+func notFound(method, path string) error {
+	return &client.APIError{StatusCode: http.StatusNotFound, Method: method, Path: path, Body: "not found"}
+}
+
+func (f *fakeClient) CreateAzureSubscriptionWithRequest(ctx context.Context, azurePlanID int, reqBody client.CreateAzureSubscriptionRequest) (*client.AzureSubscription, error) {
+	f.mu.Lock()
+	if err := f.call(ctx, "CreateAzureSubscriptionWithRequest"); err != nil {
+		f.mu.Unlock()
+		for i := 0; i < f.orphans; i++ {
+			f.add(azurePlanID, reqBody.Name, "active")
+		}
+		return nil, err
+	}
+	switch {
+	case f.requireApproval:
+		id := len(f.approvals) + 1
+		f.approvals[id] = &client.ApprovalRequest{ID: id, Status: "Pending"}
+		f.mu.Unlock()
+		return &client.AzureSubscription{FriendlyName: reqBody.Name, Status: client.StatusPendingApproval, ApprovalRequestID: id}, nil
+	case f.async:
+		pending := &client.AzureSubscription{
+			FriendlyName:      reqBody.Name,
+			SubscriptionID:    "pending",
+			Status:            "provisioning",
+			AzurePlanID:       azurePlanID,
+			OperationLocation: "https://management.azure.com/operations/" + reqBody.Name,
+		}
+		if f.confirmedGUID != "" {
+			pending.SubscriptionID = f.confirmedGUID
+		}
+		f.unsynced[reqBody.Name] = pending
+		f.mu.Unlock()
+		copied := *pending
+		return &copied, nil
+	}
+	f.mu.Unlock()
+
+	id := f.add(azurePlanID, reqBody.Name, "active")
+	f.mu.Lock()
+	if !f.untaggedCreates {
+		f.tags[id] = client.MergeTags(reqBody.Tags, nil)
+	}
+	f.subscriptions[id].SupportPlan = reqBody.SupportPlan
+	f.mu.Unlock()
+	return f.GetAzureSubscription(ctx, azurePlanID, id, "tags")
+}
+
+func (f *fakeClient) CreateAzureSubscriptionDryRun(ctx context.Context, azurePlanID int, reqBody client.CreateAzureSubscriptionRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.call(ctx, "CreateAzureSubscriptionDryRun")
+}
+
+func (f *fakeClient) GetAzureSubscription(ctx context.Context, azurePlanID, subscriptionID int, expand ...string) (*client.AzureSubscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "GetAzureSubscription"); err != nil {
+		return nil, err
+	}
+	sub, ok := f.subscriptions[subscriptionID]
+	if !ok {
+		return nil, notFound(http.MethodGet, fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID))
+	}
+	copied := *sub
+	f.expanded = expand
+	for _, field := range expand {
+		if field == "tags" && !f.ignoreExpand {
+			copied.Tags = client.MergeTags(f.tags[subscriptionID], nil)
+		}
+	}
+	return &copied, nil
+}
+
+func (f *fakeClient) FindAzureSubscriptionByName(ctx context.Context, azurePlanID int, name string) (*client.AzureSubscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "FindAzureSubscriptionByName"); err != nil {
+		return nil, err
+	}
+	for _, sub := range f.subscriptions {
+		if sub.AzurePlanID == azurePlanID && sub.FriendlyName == name {
+			copied := *sub
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("subscription '%s' not found in Azure Plan %d", name, azurePlanID)
+}
+
+func (f *fakeClient) FindAzureSubscriptionsByName(ctx context.Context, azurePlanID int, name string) ([]client.AzureSubscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "FindAzureSubscriptionsByName"); err != nil {
+		return nil, err
+	}
+	var found []client.AzureSubscription
+	for _, sub := range f.subscriptions {
+		if sub.AzurePlanID == azurePlanID && sub.FriendlyName == name {
+			found = append(found, *sub)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeClient) RenameAzureSubscription(ctx context.Context, azurePlanID, subscriptionID int, newName string) (*client.AzureSubscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "RenameAzureSubscription"); err != nil {
+		return nil, err
+	}
+	sub, ok := f.subscriptions[subscriptionID]
+	if !ok {
+		return nil, notFound(http.MethodPatch, fmt.Sprintf("/api/v1/subscriptions/%d/rename", subscriptionID))
+	}
+	sub.FriendlyName = newName
+	copied := *sub
+	return &copied, nil
+}
+
+// setStatus changes the status of a stored subscription on behalf of method
+func (f *fakeClient) setStatus(ctx context.Context, method string, subscriptionID int, status string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, method); err != nil {
+		return err
+	}
+	sub, ok := f.subscriptions[subscriptionID]
+	if !ok {
+		return notFound(http.MethodPost, fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID))
+	}
+	sub.Status = status
+	return nil
+}
+
+func (f *fakeClient) CancelAzureSubscription(ctx context.Context, azurePlanID, subscriptionID int, effectiveDate time.Time) error {
+	f.mu.Lock()
+	f.cancelledOn = effectiveDate
+	status := f.cancelStatus
+	f.mu.Unlock()
+	if status == "" {
+		status = "cancelled"
+	}
+	return f.setStatus(ctx, "CancelAzureSubscription", subscriptionID, status)
+}
+
+func (f *fakeClient) EnableAzureSubscription(ctx context.Context, azurePlanID, subscriptionID int) error {
+	return f.setStatus(ctx, "EnableAzureSubscription", subscriptionID, "active")
+}
+
+func (f *fakeClient) SuspendAzureSubscription(ctx context.Context, azurePlanID, subscriptionID int) error {
+	return f.setStatus(ctx, "SuspendAzureSubscription", subscriptionID, "suspended")
+}
+
+func (f *fakeClient) ReactivateAzureSubscription(ctx context.Context, azurePlanID, subscriptionID int) error {
+	return f.setStatus(ctx, "ReactivateAzureSubscription", subscriptionID, "active")
+}
+
+// WaitForSubscriptionStatus returns the subscription if its status is one of statuses, and
+// times out immediately otherwise, as nothing changes the status while waiting
+func (f *fakeClient) WaitForSubscriptionStatus(ctx context.Context, azurePlanID, subscriptionID int, statuses []string, timeout time.Duration) (*client.AzureSubscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.waitTimeout = timeout
+	if err := f.call(ctx, "WaitForSubscriptionStatus"); err != nil {
+		return nil, err
+	}
+	sub, ok := f.subscriptions[subscriptionID]
+	if !ok {
+		return nil, notFound(http.MethodGet, fmt.Sprintf("/api/v1/subscriptions/%d", subscriptionID))
+	}
+	copied := *sub
+	for _, status := range statuses {
+		if strings.EqualFold(sub.Status, status) {
+			return &copied, nil
+		}
+	}
+	return &copied, fmt.Errorf("%w: subscription %d is still %s after %v", client.ErrStatusWaitTimeout, subscriptionID, sub.Status, timeout)
+}
+
+func (f *fakeClient) ReserveSubscriptionName(ctx context.Context, azurePlanID int, name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.call(ctx, "ReserveSubscriptionName")
+}
+
+func (f *fakeClient) ReleaseSubscriptionName(azurePlanID int, name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, "ReleaseSubscriptionName")
+}
+
+func (f *fakeClient) GetAzureSubscriptionTags(ctx context.Context, azurePlanID, subscriptionID int) (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "GetAzureSubscriptionTags"); err != nil {
+		return nil, err
+	}
+	return client.MergeTags(f.tags[subscriptionID], nil), nil
+}
+
+func (f *fakeClient) SetAzureSubscriptionTag(ctx context.Context, azurePlanID, subscriptionID int, key, value string) error {
+	return f.UpdateAzureSubscriptionTags(ctx, azurePlanID, subscriptionID, map[string]string{key: value}, nil)
+}
+
+func (f *fakeClient) UpdateAzureSubscriptionTags(ctx context.Context, azurePlanID, subscriptionID int, set map[string]string, remove []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "UpdateAzureSubscriptionTags"); err != nil {
+		return err
+	}
+	tags := client.MergeTags(f.tags[subscriptionID], set)
+	for _, key := range remove {
+		delete(tags, key)
+	}
+	f.tags[subscriptionID] = tags
+	return nil
+}
+
+func (f *fakeClient) SetAzureSubscriptionQuantity(ctx context.Context, azurePlanID, subscriptionID, quantity int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "SetAzureSubscriptionQuantity"); err != nil {
+		return err
+	}
+	f.subscriptions[subscriptionID].Quantity = quantity
+	return nil
+}
+
+func (f *fakeClient) SetAzureSubscriptionAutoRenew(ctx context.Context, azurePlanID, subscriptionID int, enabled bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "SetAzureSubscriptionAutoRenew"); err != nil {
+		return err
+	}
+	f.subscriptions[subscriptionID].AutoRenew = &enabled
+	return nil
+}
+
+func (f *fakeClient) SetAzureSubscriptionPartnerOfRecord(ctx context.Context, azurePlanID, subscriptionID int, partnerID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "SetAzureSubscriptionPartnerOfRecord"); err != nil {
+		return err
+	}
+	f.subscriptions[subscriptionID].PartnerOfRecord = partnerID
+	return nil
+}
+
+func (f *fakeClient) GetSubscriptionOperationProgress(ctx context.Context, operationLocation string) (int, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, "GetSubscriptionOperationProgress")
+	if operationLocation == "" {
+		panic("GetSubscriptionOperationProgress called without an operation")
+	}
+	return f.progress, f.progress > 0
+}
+
+func (f *fakeClient) GetApprovalRequest(ctx context.Context, azurePlanID, requestID int) (*client.ApprovalRequest, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "GetApprovalRequest"); err != nil {
+		return nil, err
+	}
+	approval, ok := f.approvals[requestID]
+	if !ok {
+		return nil, notFound(http.MethodGet, fmt.Sprintf("/api/v1/approvals/%d", requestID))
+	}
+	copied := *approval
+	return &copied, nil
+}
+
+// decide approves or rejects an approval request
+func (f *fakeClient) decide(requestID int, status string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.approvals[requestID].Status = status
+}
+
+func (f *fakeClient) WithdrawApprovalRequest(ctx context.Context, azurePlanID, requestID int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.call(ctx, "WithdrawApprovalRequest")
+}
+
+func (f *fakeClient) TriggerCloudIQSync(ctx context.Context, azurePlanID int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.call(ctx, "TriggerCloudIQSync")
+}
+
+func (f *fakeClient) GetARMSubscription(ctx context.Context, subscriptionID string) (*client.ARMSubscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.call(ctx, "GetARMSubscription"); err != nil {
+		return nil, err
+	}
+	return &client.ARMSubscription{SubscriptionID: subscriptionID, State: "Enabled"}, nil
+}
+
+func (f *fakeClient) NotifySubscriptionResolved(ctx context.Context, webhookURL string, sub *client.AzureSubscription) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.call(ctx, "NotifySubscriptionResolved")
+}
+
+func (f *fakeClient) VerifyAzurePlanOrganization(ctx context.Context, azurePlanID int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.call(ctx, "VerifyAzurePlanOrganization")
+}
+
+func (f *fakeClient) CheckARMConfirmation(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.call(ctx, "CheckARMConfirmation")
+}
+
+// testSettings returns the provider configuration of test resources
+func testSettings() client.SubscriptionSettings {
+	return client.SubscriptionSettings{
+		OrganizationID:       4051878,
+		SyncReadRetries:      1,
+		SyncReadInterval:     time.Millisecond,
+		ARMUnavailablePolicy: client.ARMUnavailableWarn,
+		PortalURL: func(azurePlanID, subscriptionID int) string {
+			return fmt.Sprintf("https://cloudiq.test/subscriptions/azure/%d/%d", azurePlanID, subscriptionID)
+		},
+		StopContext: context.Background(),
+	}
+}
+
+// testPlanID is the Azure Plan test subscriptions are created under
+const testPlanID = 873834
+
+// harness drives an AzureSubscriptionResource the way the framework does, keeping the state and
+// private state between operations
+type harness struct {
+	t        *testing.T
+	ctx      context.Context
+	resource *AzureSubscriptionResource
+	schema   schema.Schema
+	state    tfsdk.State
+	private  *resource.ReadResponse // only Private is used; it has the framework's private state type
+}
+
+func newHarness(t *testing.T, fake *fakeClient) *harness {
+	t.Helper()
+	ctx := context.Background()
+	r := &AzureSubscriptionResource{client: fake, settings: testSettings()}
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	if schemaResp.Diagnostics.HasError() {
+		t.Fatalf("schema: %v", schemaResp.Diagnostics)
+	}
+
+	h := &harness{t: t, ctx: ctx, resource: r, schema: schemaResp.Schema}
+	h.state = h.nullState()
+	h.private = &resource.ReadResponse{}
+	h.private.Private = newOf(h.private.Private)
+	return h
+}
+
+// newOf returns a new zero value of what p points to. It creates the framework's private state,
+// whose type is internal to the framework and can't be named.
+func newOf[T any](_ *T) *T {
+	return new(T)
+}
+
+func (h *harness) nullState() tfsdk.State {
+	return tfsdk.State{Schema: h.schema, Raw: tftypes.NewValue(h.schema.Type().TerraformType(h.ctx), nil)}
+}
+
+// planned returns the planned model of a new subscription named name: computed attributes are
+// unknown and everything else is null
+func (h *harness) planned(name string) AzureSubscriptionResourceModel {
+	var data AzureSubscriptionResourceModel
+	value := reflect.ValueOf(&data).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		attribute, computed := h.schema.Attributes[field.Tag.Get("tfsdk")], false
+		if attribute != nil {
+			computed = attribute.IsComputed()
+		}
+		var null, unknown interface{}
+		switch field.Type {
+		case reflect.TypeOf(types.String{}):
+			null, unknown = types.StringNull(), types.StringUnknown()
+		case reflect.TypeOf(types.Int64{}):
+			null, unknown = types.Int64Null(), types.Int64Unknown()
+		case reflect.TypeOf(types.Bool{}):
+			null, unknown = types.BoolNull(), types.BoolUnknown()
+		case reflect.TypeOf(types.Map{}):
+			null, unknown = types.MapNull(types.StringType), types.MapUnknown(types.StringType)
+		case reflect.TypeOf(types.Object{}):
+			null, unknown = types.ObjectNull(timeoutsAttrTypes), types.ObjectUnknown(timeoutsAttrTypes)
+		default:
+			h.t.Fatalf("unsupported model field type %v", field.Type)
+		}
+		if computed {
+			value.Field(i).Set(reflect.ValueOf(unknown))
+		} else {
+			value.Field(i).Set(reflect.ValueOf(null))
+		}
+	}
+	data.AzurePlanID = types.Int64Value(testPlanID)
+	data.Name = types.StringValue(name)
+	return data
+}
+
+// model returns the current state
+func (h *harness) model() AzureSubscriptionResourceModel {
+	h.t.Helper()
+	var data AzureSubscriptionResourceModel
+	if diags := h.state.Get(h.ctx, &data); diags.HasError() {
+		h.t.Fatalf("state: %v", diags)
+	}
+	return data
+}
+
+// privateKey returns a key of the private state
+func (h *harness) privateKey(key string) string {
+	value, _ := h.private.Private.GetKey(h.ctx, key)
+	return string(value)
+}
+
+func (h *harness) plan(data AzureSubscriptionResourceModel) tfsdk.Plan {
+	h.t.Helper()
+	plan := tfsdk.Plan{Schema: h.schema}
+	if diags := plan.Set(h.ctx, &data); diags.HasError() {
+		h.t.Fatalf("plan: %v", diags)
+	}
+	return plan
+}
+
+// modifyPlan plans the data against the current state, returning the modified plan
+func (h *harness) modifyPlan(data AzureSubscriptionResourceModel) (AzureSubscriptionResourceModel, *resource.ModifyPlanResponse) {
+	h.t.Helper()
+	plan := h.plan(data)
+	resp := &resource.ModifyPlanResponse{Plan: plan, Private: h.private.Private}
+	h.resource.ModifyPlan(h.ctx, resource.ModifyPlanRequest{
+		Config:  tfsdk.Config{Schema: h.schema, Raw: plan.Raw},
+		Plan:    plan,
+		State:   h.state,
+		Private: h.private.Private,
+	}, resp)
+	var planned AzureSubscriptionResourceModel
+	if diags := resp.Plan.Get(h.ctx, &planned); diags.HasError() {
+		h.t.Fatalf("modified plan: %v", diags)
+	}
+	return planned, resp
+}
+
+// planDestroy plans the destruction of the subscription in the current state
+func (h *harness) planDestroy() *resource.ModifyPlanResponse {
+	h.t.Helper()
+	plan := tfsdk.Plan{Schema: h.schema, Raw: h.nullState().Raw}
+	resp := &resource.ModifyPlanResponse{Plan: plan, Private: h.private.Private}
+	h.resource.ModifyPlan(h.ctx, resource.ModifyPlanRequest{
+		Config:  tfsdk.Config{Schema: h.schema, Raw: plan.Raw},
+		Plan:    plan,
+		State:   h.state,
+		Private: h.private.Private,
+	}, resp)
+	return resp
+}
+
+// importState imports the subscription with the given import ID
+func (h *harness) importState(id string) *resource.ImportStateResponse {
+	h.t.Helper()
+	resp := &resource.ImportStateResponse{State: h.nullState(), Private: h.private.Private}
+	h.resource.ImportState(h.ctx, resource.ImportStateRequest{ID: id}, resp)
+	if !resp.Diagnostics.HasError() {
+		h.state = resp.State
+	}
+	return resp
+}
+
+// create applies the creation of a subscription with the planned data
+func (h *harness) create(data AzureSubscriptionResourceModel) *resource.CreateResponse {
+	h.t.Helper()
+	resp := &resource.CreateResponse{State: h.nullState(), Private: h.private.Private}
+	h.resource.Create(h.ctx, resource.CreateRequest{Plan: h.plan(data)}, resp)
+	if !resp.Diagnostics.HasError() {
+		h.state = resp.State
+	}
+	return resp
+}
+
+// read refreshes the state
+func (h *harness) read() *resource.ReadResponse {
+	h.t.Helper()
+	resp := &resource.ReadResponse{State: h.state, Private: h.private.Private}
+	h.resource.Read(h.ctx, resource.ReadRequest{State: h.state, Private: h.private.Private}, resp)
+	if !resp.Diagnostics.HasError() {
+		h.state = resp.State
+	}
+	return resp
+}
+
+// update applies the planned data to the current state
+func (h *harness) update(data AzureSubscriptionResourceModel) *resource.UpdateResponse {
+	h.t.Helper()
+	resp := &resource.UpdateResponse{State: h.state, Private: h.private.Private}
+	h.resource.Update(h.ctx, resource.UpdateRequest{Plan: h.plan(data), State: h.state, Private: h.private.Private}, resp)
+	if !resp.Diagnostics.HasError() {
+		h.state = resp.State
+	}
+	return resp
+}
+
+// delete destroys the subscription in the current state
+func (h *harness) delete() *resource.DeleteResponse {
+	h.t.Helper()
+	resp := &resource.DeleteResponse{State: h.state, Private: h.private.Private}
+	h.resource.Delete(h.ctx, resource.DeleteRequest{State: h.state, Private: h.private.Private}, resp)
+	if !resp.Diagnostics.HasError() {
+		h.state = h.nullState()
+	}
+	return resp
+}
+
+// summaries lists the diagnostics, for assertions and failure messages
+func summaries(diags diag.Diagnostics) string {
+	var lines []string
+	for _, d := range diags {
+		lines = append(lines, fmt.Sprintf("%s: %s: %s", d.Severity(), d.Summary(), d.Detail()))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// hasSummary reports whether diags has a diagnostic with the given summary
+func hasSummary(diags diag.Diagnostics, summary string) bool {
+	for _, d := range diags {
+		if d.Summary() == summary {
+			return true
+		}
+	}
+	return false
+}
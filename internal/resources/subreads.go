@@ -0,0 +1,63 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crayon-cloud/terraform-provider-crayon/internal/client"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// maxConcurrentSubReads bounds the API calls a single Read makes at once
+const maxConcurrentSubReads = 4
+
+// subRead is a part of Read that needs its own API calls and is independent of the other
+// parts. Each sub-read must only write the attributes it owns.
+type subRead struct {
+	// name describes what is read, e.g. "tags"
+	name string
+
+	// optional sub-reads leave their attributes unchanged with a warning when they fail,
+	// rather than failing the whole read
+	optional bool
+
+	run func() error
+}
+
+// runSubReads runs reads concurrently and waits for all of them, reporting every failure:
+// optional ones as warnings and the others as errors. Reads not started before ctx is done
+// fail with its error.
+func runSubReads(ctx context.Context, reads []subRead, subject string, diags *diag.Diagnostics) {
+	errs := make([]error, len(reads))
+	started := make([]bool, len(reads))
+	if err := client.FanOut(ctx, len(reads), maxConcurrentSubReads, func(i int) {
+		started[i] = true
+		errs[i] = reads[i].run()
+	}); err != nil {
+		for i := range errs {
+			if !started[i] {
+				errs[i] = err
+			}
+		}
+	}
+
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if reads[i].optional {
+			diags.AddWarning(
+				"Incomplete Read of Azure Subscription",
+				fmt.Sprintf("Could not read %s of %s; they are left unchanged until the next refresh: %v", reads[i].name, subject, err),
+			)
+			continue
+		}
+		diags.AddError(
+			"Error Reading Azure Subscription",
+			fmt.Sprintf("Could not read %s of %s: %v", reads[i].name, subject, err),
+		)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package resources
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+func TestRunSubReadsConcurrently(t *testing.T) {
+	// Each read waits for the other to start, so they only finish if run at the same time
+	started := [2]chan struct{}{make(chan struct{}), make(chan struct{})}
+	read := func(self, other int) func() error {
+		return func() error {
+			close(started[self])
+			select {
+			case <-started[other]:
+				return nil
+			case <-time.After(5 * time.Second):
+				return errors.New("the other read didn't run concurrently")
+			}
+		}
+	}
+
+	var diags diag.Diagnostics
+	runSubReads(context.Background(), []subRead{
+		{name: "first", run: read(0, 1)},
+		{name: "second", run: read(1, 0)},
+	}, "subscription ID 1001", &diags)
+	if diags.HasError() {
+		t.Fatal(summaries(diags))
+	}
+}
+
+func TestRunSubReadsPartialFailure(t *testing.T) {
+	var succeeded bool
+	var diags diag.Diagnostics
+	runSubReads(context.Background(), []subRead{
+		{name: "tags", run: func() error { return errors.New("tags unavailable") }},
+		{name: "the spending cap", optional: true, run: func() error { return errors.New("ARM unavailable") }},
+		{name: "contacts", run: func() error { succeeded = true; return nil }},
+	}, "subscription ID 1001", &diags)
+
+	if !succeeded {
+		t.Error("a failing read kept the others from running")
+	}
+	if got := diags.ErrorsCount(); got != 1 {
+		t.Errorf("%d errors, want 1 for the required read:\n%s", got, summaries(diags))
+	}
+	if got := diags.WarningsCount(); got != 1 {
+		t.Errorf("%d warnings, want 1 for the optional read:\n%s", got, summaries(diags))
+	}
+	for _, d := range diags {
+		if !strings.Contains(d.Detail(), "subscription ID 1001") {
+			t.Errorf("diagnostic %q doesn't name the subscription", d.Detail())
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Operations of the timeouts block
+const (
+	timeoutCreate = "create"
+	timeoutRead   = "read"
+	timeoutUpdate = "update"
+	timeoutDelete = "delete"
+)
+
+// timeoutsAttrTypes are the attribute types of the timeouts block
+var timeoutsAttrTypes = map[string]attr.Type{
+	timeoutCreate: types.StringType,
+	timeoutRead:   types.StringType,
+	timeoutUpdate: types.StringType,
+	timeoutDelete: types.StringType,
+}
+
+// timeoutsBlock returns the standard Terraform timeouts block, e.g. timeouts { create = "30m" }.
+// descriptions explains what each operation's timeout bounds.
+func timeoutsBlock(descriptions map[string]string) schema.Block {
+	attributes := make(map[string]schema.Attribute, len(timeoutsAttrTypes))
+	for operation := range timeoutsAttrTypes {
+		attributes[operation] = schema.StringAttribute{
+			Description: descriptions[operation] + ` A duration such as "30s", "10m" or "2h45m".`,
+			Optional:    true,
+			Validators: []validator.String{
+				durationString(),
+			},
+		}
+	}
+	return schema.SingleNestedBlock{
+		Description: "Timeouts of the resource's operations.",
+		Attributes:  attributes,
+	}
+}
+
+// timeout returns the duration the timeouts block configures for operation, or fallback if unset
+func timeout(timeouts types.Object, operation string, fallback time.Duration) (time.Duration, diag.Diagnostics) {
+	if timeouts.IsNull() || timeouts.IsUnknown() {
+		return fallback, nil
+	}
+
+	value, ok := timeouts.Attributes()[operation].(types.String)
+	if !ok || value.IsNull() || value.IsUnknown() {
+		return fallback, nil
+	}
+
+	duration, err := time.ParseDuration(value.ValueString())
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddAttributeError(
+			path.Root("timeouts").AtName(operation),
+			"Invalid Timeout",
+			fmt.Sprintf("The %s timeout must be a duration such as \"30m\". Got: %s", operation, value.ValueString()),
+		)
+		return fallback, diags
+	}
+	return duration, nil
+}
+
+// withTimeout bounds ctx by the duration the timeouts block configures for operation, if any
+func withTimeout(ctx context.Context, timeouts types.Object, operation string) (context.Context, context.CancelFunc, diag.Diagnostics) {
+	duration, diags := timeout(timeouts, operation, 0)
+	if duration <= 0 {
+		return ctx, func() {}, diags
+	}
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	return ctx, cancel, diags
+}
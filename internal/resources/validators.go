@@ -0,0 +1,251 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package resources
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// stringOneOfValidator validates that a string attribute is one of a fixed set of values.
+type stringOneOfValidator struct {
+	values []string
+}
+
+// stringOneOf returns a validator which ensures the configured value is one of values.
+func stringOneOf(values ...string) validator.String {
+	return stringOneOfValidator{values: values}
+}
+
+func (v stringOneOfValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be one of: %s", strings.Join(v.values, ", "))
+}
+
+func (v stringOneOfValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v stringOneOfValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	for _, allowed := range v.values {
+		if value == allowed {
+			return
+		}
+	}
+
+	resp.Diagnostics.AddAttributeError(
+		req.Path,
+		"Invalid Attribute Value",
+		fmt.Sprintf("Attribute %s %s, got: %q", req.Path, v.Description(ctx), value),
+	)
+}
+
+// httpURLValidator validates that a string attribute is an absolute http(s) URL.
+type httpURLValidator struct{}
+
+// httpURL returns a validator which ensures the configured value is an absolute http(s) URL.
+func httpURL() validator.String {
+	return httpURLValidator{}
+}
+
+func (v httpURLValidator) Description(ctx context.Context) string {
+	return "value must be an absolute http or https URL"
+}
+
+func (v httpURLValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v httpURLValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Attribute Value",
+			fmt.Sprintf("Attribute %s %s, got: %q", req.Path, v.Description(ctx), value),
+		)
+	}
+}
+
+// mapKeysOneOfValidator validates that every key of a map attribute is one of a fixed set of values.
+type mapKeysOneOfValidator struct {
+	keys []string
+}
+
+// mapKeysOneOf returns a validator which ensures every configured map key is one of keys.
+func mapKeysOneOf(keys ...string) validator.Map {
+	return mapKeysOneOfValidator{keys: keys}
+}
+
+func (v mapKeysOneOfValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("map keys must be one of: %s", strings.Join(v.keys, ", "))
+}
+
+func (v mapKeysOneOfValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v mapKeysOneOfValidator) ValidateMap(ctx context.Context, req validator.MapRequest, resp *validator.MapResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	for key := range req.ConfigValue.Elements() {
+		allowed := false
+		for _, k := range v.keys {
+			if key == k {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			resp.Diagnostics.AddAttributeError(
+				req.Path,
+				"Invalid Attribute Value",
+				fmt.Sprintf("Attribute %s %s, got: %q", req.Path, v.Description(ctx), key),
+			)
+		}
+	}
+}
+
+// stringLengthBetweenValidator validates that a string attribute's length is within bounds.
+type stringLengthBetweenValidator struct {
+	min, max int
+}
+
+// stringLengthBetween returns a validator which ensures the configured value is between min and max characters long.
+func stringLengthBetween(min, max int) validator.String {
+	return stringLengthBetweenValidator{min: min, max: max}
+}
+
+func (v stringLengthBetweenValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be between %d and %d characters long", v.min, v.max)
+}
+
+func (v stringLengthBetweenValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v stringLengthBetweenValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if length := utf8.RuneCountInString(req.ConfigValue.ValueString()); length < v.min || length > v.max {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Attribute Value",
+			fmt.Sprintf("Attribute %s %s, got %d characters", req.Path, v.Description(ctx), length),
+		)
+	}
+}
+
+// durationStringValidator validates that a string attribute is a positive Go duration such as "30m".
+type durationStringValidator struct{}
+
+// durationString returns a validator which ensures the configured value is a positive duration.
+func durationString() validator.String {
+	return durationStringValidator{}
+}
+
+func (v durationStringValidator) Description(ctx context.Context) string {
+	return `value must be a positive duration such as "30s", "10m" or "2h45m"`
+}
+
+func (v durationStringValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v durationStringValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	if duration, err := time.ParseDuration(value); err != nil || duration <= 0 {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Attribute Value",
+			fmt.Sprintf("Attribute %s %s, got: %q", req.Path, v.Description(ctx), value),
+		)
+	}
+}
+
+// int64AtLeastValidator validates that an integer attribute is at least a minimum value.
+type int64AtLeastValidator struct {
+	min int64
+}
+
+// int64AtLeast returns a validator which ensures the configured value is at least min.
+func int64AtLeast(min int64) validator.Int64 {
+	return int64AtLeastValidator{min: min}
+}
+
+func (v int64AtLeastValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be at least %d", v.min)
+}
+
+func (v int64AtLeastValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v int64AtLeastValidator) ValidateInt64(ctx context.Context, req validator.Int64Request, resp *validator.Int64Response) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if value := req.ConfigValue.ValueInt64(); value < v.min {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Attribute Value",
+			fmt.Sprintf("Attribute %s %s, got: %d", req.Path, v.Description(ctx), value),
+		)
+	}
+}
+
+// dateStringValidator validates that a string attribute is a date in YYYY-MM-DD format.
+type dateStringValidator struct{}
+
+// dateString returns a validator which ensures the configured value is a YYYY-MM-DD date.
+func dateString() validator.String {
+	return dateStringValidator{}
+}
+
+func (v dateStringValidator) Description(ctx context.Context) string {
+	return "value must be a date in YYYY-MM-DD format"
+}
+
+func (v dateStringValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v dateStringValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Attribute Value",
+			fmt.Sprintf("Attribute %s %s, got: %q", req.Path, v.Description(ctx), value),
+		)
+	}
+}
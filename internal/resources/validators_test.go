@@ -0,0 +1,112 @@
+// Copyright (c) 2024
+// SPDX-License-Identifier: MPL-2.0
+
+package resources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// validateString runs v against value, returning whether it was accepted
+func validateString(v validator.String, value types.String) bool {
+	var resp validator.StringResponse
+	v.ValidateString(context.Background(), validator.StringRequest{Path: path.Root("attr"), ConfigValue: value}, &resp)
+	return !resp.Diagnostics.HasError()
+}
+
+func TestStringOneOf(t *testing.T) {
+	v := stringOneOf("dev", "test", "staging", "prod")
+
+	tests := map[string]struct {
+		value types.String
+		want  bool
+	}{
+		"allowed":      {value: types.StringValue("prod"), want: true},
+		"not allowed":  {value: types.StringValue("production")},
+		"other casing": {value: types.StringValue("Prod")},
+		"empty":        {value: types.StringValue("")},
+		"null":         {value: types.StringNull(), want: true},
+		"unknown":      {value: types.StringUnknown(), want: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := validateString(v, test.value); got != test.want {
+				t.Errorf("accepted %v = %v, want %v", test.value, got, test.want)
+			}
+		})
+	}
+}
+
+func TestHTTPURL(t *testing.T) {
+	tests := map[string]struct {
+		value types.String
+		want  bool
+	}{
+		"https":        {value: types.StringValue("https://hooks.slack.com/services/T0/B0/x"), want: true},
+		"http":         {value: types.StringValue("http://localhost:8080/hook"), want: true},
+		"other scheme": {value: types.StringValue("ftp://example.com/hook")},
+		"relative":     {value: types.StringValue("/hook")},
+		"no host":      {value: types.StringValue("https:///hook")},
+		"not a URL":    {value: types.StringValue("://")},
+		"null":         {value: types.StringNull(), want: true},
+		"unknown":      {value: types.StringUnknown(), want: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := validateString(httpURL(), test.value); got != test.want {
+				t.Errorf("accepted %v = %v, want %v", test.value, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMapKeysOneOf(t *testing.T) {
+	v := mapKeysOneOf("offerId", "quantity", "reference", "description")
+
+	tests := map[string]struct {
+		value types.Map
+		want  bool
+	}{
+		"known keys":  {value: types.MapValueMust(types.StringType, stringMapValues(map[string]string{"reference": "PO-1234", "quantity": "2"})), want: true},
+		"empty":       {value: types.MapValueMust(types.StringType, stringMapValues(map[string]string{})), want: true},
+		"unknown key": {value: types.MapValueMust(types.StringType, stringMapValues(map[string]string{"reference": "PO-1234", "costCenter": "42"}))},
+		"key casing":  {value: types.MapValueMust(types.StringType, stringMapValues(map[string]string{"OfferId": "MS-AZR-0017G"}))},
+		"null":        {value: types.MapNull(types.StringType), want: true},
+		"unknown":     {value: types.MapUnknown(types.StringType), want: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var resp validator.MapResponse
+			v.ValidateMap(context.Background(), validator.MapRequest{Path: path.Root("attr"), ConfigValue: test.value}, &resp)
+			if got := !resp.Diagnostics.HasError(); got != test.want {
+				t.Errorf("accepted %v = %v, want %v", test.value, got, test.want)
+			}
+		})
+	}
+}
+
+func TestDateString(t *testing.T) {
+	tests := map[string]struct {
+		value types.String
+		want  bool
+	}{
+		"date":         {value: types.StringValue("2030-01-31"), want: true},
+		"invalid day":  {value: types.StringValue("2030-02-30")},
+		"other format": {value: types.StringValue("31/01/2030")},
+		"timestamp":    {value: types.StringValue("2030-01-31T00:00:00Z")},
+		"null":         {value: types.StringNull(), want: true},
+		"unknown":      {value: types.StringUnknown(), want: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := validateString(dateString(), test.value); got != test.want {
+				t.Errorf("accepted %v = %v, want %v", test.value, got, test.want)
+			}
+		})
+	}
+}
@@ -7,6 +7,9 @@ import (
 	"context"
 	"flag"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/crayon-cloud/terraform-provider-crayon/internal/provider"
@@ -39,7 +42,11 @@ func main() {
 		Debug:   debug,
 	}
 
-	err := providerserver.Serve(context.Background(), provider.New(version), opts)
+	// Terraform interrupts the provider on shutdown (e.g. Ctrl-C); stop polling promptly when it does
+	stopCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := providerserver.Serve(context.Background(), provider.NewWithStopContext(stopCtx, version), opts)
 
 	if err != nil {
 		log.Fatal(err.Error())